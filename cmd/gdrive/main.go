@@ -5,6 +5,8 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -41,12 +43,25 @@ func run() int {
 
 	rootCmd.AddCommand(cli.FileCmd())
 	rootCmd.AddCommand(cli.FolderCmd())
+	rootCmd.AddCommand(cli.TrashCmd())
 	rootCmd.AddCommand(cli.SearchCmd())
+	rootCmd.AddCommand(cli.DrivesCmd())
+	rootCmd.AddCommand(cli.FormatsCmd())
+	rootCmd.AddCommand(cli.CacheCmd())
+	rootCmd.AddCommand(cli.VerifyCmd())
 	rootCmd.AddCommand(cli.ActivityCmd())
+	rootCmd.AddCommand(cli.CommentsCmd())
+	rootCmd.AddCommand(cli.AuditCmd())
+	rootCmd.AddCommand(cli.ShareCmd())
 	rootCmd.AddCommand(cli.MCPCmd())
 	rootCmd.AddCommand(cli.SkillCmd())
 
-	if err := rootCmd.Execute(); err != nil {
+	// Ctrl-C / SIGTERM cancel the command context, which aborts in-flight
+	// Drive API calls instead of leaving them running.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		return 1
 	}
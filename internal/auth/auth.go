@@ -15,6 +15,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
@@ -24,6 +25,7 @@ import (
 	"google.golang.org/api/driveactivity/v2"
 	"google.golang.org/api/option"
 
+	"gdrive/internal/retry"
 	"gdrive/internal/telemetry"
 )
 
@@ -49,19 +51,28 @@ const (
 	tokenFilePerm = 0600
 
 	// Default config paths
-	DefaultConfigDirName       = ".credentials"
-	DefaultTokenFileName       = "token_gdrive.json"
-	DefaultCredentialsFileName = "google_credentials.json"
+	DefaultConfigDirName         = ".credentials"
+	DefaultTokenFileName         = "token_gdrive.json"
+	DefaultPathCacheFileName     = "path_cache_gdrive.json"
+	DefaultUploadStateFileName   = "uploads_gdrive.json"
+	DefaultFormatsFileName       = "formats_gdrive.json"
+	DefaultSharingPolicyFileName = "sharing_policy_gdrive.yaml"
+	DefaultCredentialsFileName   = "google_credentials.json"
 
 	// Environment variable names
 	EnvConfigDir       = "GDRIVE_CONFIG_DIR"
 	EnvCredentialsPath = "GDRIVE_CREDENTIALS_PATH"
+	EnvMaxRetries      = "GDRIVE_MAX_RETRIES"
+	EnvRetryBudget     = "GDRIVE_RETRY_BUDGET"
+	EnvSharingPolicy   = "GDRIVE_SHARING_POLICY"
 )
 
-// Config holds the configuration paths for authentication.
+// Config holds the configuration paths for authentication and the retry
+// budget applied to every Google API client built from it.
 type Config struct {
 	ConfigDir       string
 	CredentialsPath string
+	Retry           retry.Policy
 }
 
 // NewConfig creates a new Config with priority: CLI args > env vars > defaults.
@@ -90,9 +101,31 @@ func NewConfig(cliConfigDir, cliCredentialsPath string) *Config {
 	}
 	// If still empty, will be resolved by GetCredentialsPath
 
+	// Retry budget: Env > Default (CLI flags are applied by the caller)
+	cfg.Retry = retry.DefaultPolicy()
+	if v := os.Getenv(EnvMaxRetries); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			cfg.Retry.MaxRetries = n
+		}
+	}
+	if v := os.Getenv(EnvRetryBudget); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			cfg.Retry.MaxElapsed = d
+		}
+	}
+
 	return cfg
 }
 
+// httpClient wraps client with the retrying transport configured by cfg.
+func (c *Config) httpClient(client *http.Client) *http.Client {
+	policy := retry.DefaultPolicy()
+	if c != nil {
+		policy = c.Retry
+	}
+	return retry.WrapClient(client, policy)
+}
+
 // GetConfigDir returns the config directory path.
 func (c *Config) GetConfigDir() string {
 	return c.ConfigDir
@@ -103,6 +136,30 @@ func (c *Config) GetTokenPath() string {
 	return filepath.Join(c.ConfigDir, DefaultTokenFileName)
 }
 
+// GetPathCachePath returns the on-disk path resolution cache file path.
+func (c *Config) GetPathCachePath() string {
+	return filepath.Join(c.ConfigDir, DefaultPathCacheFileName)
+}
+
+// GetUploadStatePath returns the file recording interrupted resumable uploads.
+func (c *Config) GetUploadStatePath() string {
+	return filepath.Join(c.ConfigDir, DefaultUploadStateFileName)
+}
+
+// GetFormatsPath returns the file caching the export formats Drive supports.
+func (c *Config) GetFormatsPath() string {
+	return filepath.Join(c.ConfigDir, DefaultFormatsFileName)
+}
+
+// GetSharingPolicyPath returns the sharing policy file path: the
+// GDRIVE_SHARING_POLICY environment variable, or a file in the config dir.
+func (c *Config) GetSharingPolicyPath() string {
+	if path := os.Getenv(EnvSharingPolicy); path != "" {
+		return path
+	}
+	return filepath.Join(c.ConfigDir, DefaultSharingPolicyFileName)
+}
+
 // GetCredentialsPath returns the credentials file path.
 func (c *Config) GetCredentialsPath() (string, error) {
 	// If explicitly set via CLI or env, use it
@@ -349,34 +406,39 @@ func getValidatedToken(ctx context.Context, cfg *Config, config *oauth2.Config)
 // In MCP mode (context has OAuth config + token), uses context credentials.
 // In CLI mode, uses file-based credentials.
 func GetAuthenticatedService(ctx context.Context, cfg *Config) (srv *drive.Service, err error) {
+	srv, _, err = GetAuthenticatedServiceAndClient(ctx, cfg)
+	return srv, err
+}
+
+// GetAuthenticatedServiceAndClient is GetAuthenticatedService that also
+// returns the authenticated HTTP client, for requests the generated Drive
+// client cannot make (such as resumable upload sessions).
+func GetAuthenticatedServiceAndClient(ctx context.Context, cfg *Config) (srv *drive.Service, client *http.Client, err error) {
 	ctx, span := telemetry.StartSpan(ctx, "auth.drive_service",
 		attribute.String("auth.mode", authMode(ctx)),
 	)
 	defer func() { telemetry.EndSpan(span, err) }()
 
-	if client := GetClientFromContext(ctx); client != nil {
-		srv, err = drive.NewService(ctx, option.WithHTTPClient(client))
+	if c := GetClientFromContext(ctx); c != nil {
+		client = cfg.httpClient(c)
+	} else {
+		config, err := loadOAuthConfig(cfg)
 		if err != nil {
-			return nil, fmt.Errorf("unable to create Drive client: %w", err)
+			return nil, nil, err
 		}
-		return srv, nil
-	}
 
-	config, err := loadOAuthConfig(cfg)
-	if err != nil {
-		return nil, err
-	}
-
-	tok, err := getValidatedToken(ctx, cfg, config)
-	if err != nil {
-		return nil, err
+		tok, err := getValidatedToken(ctx, cfg, config)
+		if err != nil {
+			return nil, nil, err
+		}
+		client = cfg.httpClient(config.Client(ctx, tok))
 	}
 
-	srv, err = drive.NewService(ctx, option.WithHTTPClient(config.Client(ctx, tok)))
+	srv, err = drive.NewService(ctx, option.WithHTTPClient(client))
 	if err != nil {
-		return nil, fmt.Errorf("unable to create Drive client: %w", err)
+		return nil, nil, fmt.Errorf("unable to create Drive client: %w", err)
 	}
-	return srv, nil
+	return srv, client, nil
 }
 
 // GetAuthenticatedActivityService returns an authenticated Drive Activity service.
@@ -387,7 +449,7 @@ func GetAuthenticatedActivityService(ctx context.Context, cfg *Config) (srv *dri
 	defer func() { telemetry.EndSpan(span, err) }()
 
 	if client := GetClientFromContext(ctx); client != nil {
-		srv, err = driveactivity.NewService(ctx, option.WithHTTPClient(client))
+		srv, err = driveactivity.NewService(ctx, option.WithHTTPClient(cfg.httpClient(client)))
 		if err != nil {
 			return nil, fmt.Errorf("unable to create Drive Activity client: %w", err)
 		}
@@ -404,7 +466,7 @@ func GetAuthenticatedActivityService(ctx context.Context, cfg *Config) (srv *dri
 		return nil, err
 	}
 
-	srv, err = driveactivity.NewService(ctx, option.WithHTTPClient(config.Client(ctx, tok)))
+	srv, err = driveactivity.NewService(ctx, option.WithHTTPClient(cfg.httpClient(config.Client(ctx, tok))))
 	if err != nil {
 		return nil, fmt.Errorf("unable to create Drive Activity client: %w", err)
 	}
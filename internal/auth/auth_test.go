@@ -5,8 +5,11 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"golang.org/x/oauth2"
+
+	"gdrive/internal/retry"
 )
 
 func TestConfigPriority(t *testing.T) {
@@ -100,6 +103,41 @@ func TestConfigPriority(t *testing.T) {
 	}
 }
 
+func TestConfigRetryPolicy(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		t.Setenv(EnvMaxRetries, "")
+		t.Setenv(EnvRetryBudget, "")
+
+		cfg := NewConfig("", "")
+		if cfg.Retry != retry.DefaultPolicy() {
+			t.Errorf("Retry = %+v, want %+v", cfg.Retry, retry.DefaultPolicy())
+		}
+	})
+
+	t.Run("env overrides", func(t *testing.T) {
+		t.Setenv(EnvMaxRetries, "0")
+		t.Setenv(EnvRetryBudget, "30s")
+
+		cfg := NewConfig("", "")
+		if cfg.Retry.MaxRetries != 0 {
+			t.Errorf("MaxRetries = %d, want 0", cfg.Retry.MaxRetries)
+		}
+		if cfg.Retry.MaxElapsed != 30*time.Second {
+			t.Errorf("MaxElapsed = %s, want 30s", cfg.Retry.MaxElapsed)
+		}
+	})
+
+	t.Run("invalid env ignored", func(t *testing.T) {
+		t.Setenv(EnvMaxRetries, "many")
+		t.Setenv(EnvRetryBudget, "-1s")
+
+		cfg := NewConfig("", "")
+		if cfg.Retry != retry.DefaultPolicy() {
+			t.Errorf("Retry = %+v, want %+v", cfg.Retry, retry.DefaultPolicy())
+		}
+	})
+}
+
 func TestConfigIntegration(t *testing.T) {
 	// Save original env vars
 	origConfigDir := os.Getenv(EnvConfigDir)
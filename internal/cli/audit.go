@@ -0,0 +1,318 @@
+package cli
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"gdrive/internal/drive"
+)
+
+var (
+	domainFlag    string
+	principalFlag string
+	removeFlag    string
+)
+
+// AuditCmd returns the audit command.
+func AuditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Report on who can access Drive content",
+		Long:  `Commands that report on the access to a whole folder tree.`,
+	}
+
+	cmd.AddCommand(auditSharingCmd())
+
+	return cmd
+}
+
+func auditSharingCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sharing REMOTE_FOLDER",
+		Short: "List every permission under a folder",
+		Long: `Walk a folder tree and list the permissions of every item, the folder
+included, to know who can reach anything under it. Each permission is
+flagged as:
+  public      anyone with the link can open the item
+  external    the user, group or domain is outside your domain
+  user        granted to an individual user rather than a group or domain
+  inherited   granted on a folder above rather than on the item itself
+
+Your domain is the one of your email address, or --domain. Drive only marks
+inherited grants in Shared Drives; elsewhere a grant counts as inherited
+when the folder holding the item grants the same role to the same
+principal.
+
+--remove strips the access of a user, group, domain or 'anyone' from the
+folder and everything under it, after confirmation. Owners cannot be
+removed, and access a Shared Drive item gets from outside the folder stays.
+
+Examples:
+  gdrive audit sharing Projects/ACME
+  gdrive audit sharing Projects/ACME --principal alice@example.com
+  gdrive audit sharing Projects/ACME -o csv > acme-sharing.csv
+  gdrive audit sharing Projects/ACME --remove contractor@partner.com
+  gdrive audit sharing 1a2b3c4d5e --id --domain example.com --json`,
+		Args: cobra.ExactArgs(1),
+		RunE: runAuditSharing,
+	}
+
+	cmd.Flags().BoolVar(&useIDFlag, "id", false, "Treat REMOTE_FOLDER as a Drive folder ID")
+	cmd.Flags().StringVar(&domainFlag, "domain", "", "Your domain, for external principals (default: the domain of your email)")
+	cmd.Flags().StringVar(&principalFlag, "principal", "", "Only list the permissions of this email address, domain or 'anyone'")
+	cmd.Flags().StringVar(&removeFlag, "remove", "", "Remove the access of this email address, domain or 'anyone' from the whole tree")
+	cmd.Flags().BoolVar(&jsonFlag, "json", false, "Output the report as JSON, same as --output json")
+
+	return cmd
+}
+
+// sharingRecord is the record of a permission found by audit sharing.
+// Principal is an email address, a domain or "anyone".
+type sharingRecord struct {
+	Path           string `json:"path"`
+	FileID         string `json:"fileId"`
+	MimeType       string `json:"mimeType"`
+	PermissionID   string `json:"permissionId"`
+	Principal      string `json:"principal"`
+	Type           string `json:"type"`
+	Role           string `json:"role"`
+	DisplayName    string `json:"displayName"`
+	ExpirationTime string `json:"expirationTime"`
+	Inherited      bool   `json:"inherited"`
+	InheritedFrom  string `json:"inheritedFrom"`
+	Public         bool   `json:"public"`
+	External       bool   `json:"external"`
+	Individual     bool   `json:"individual"`
+}
+
+func newSharingRecord(e *drive.SharingEntry) sharingRecord {
+	p := e.Permission
+	return sharingRecord{
+		Path:           e.Path,
+		FileID:         e.FileID,
+		MimeType:       e.MimeType,
+		PermissionID:   p.Id,
+		Principal:      e.Principal,
+		Type:           p.Type,
+		Role:           p.Role,
+		DisplayName:    p.DisplayName,
+		ExpirationTime: driveTime(p.ExpirationTime),
+		Inherited:      e.Inherited,
+		InheritedFrom:  e.InheritedFrom,
+		Public:         e.Public,
+		External:       e.External,
+		Individual:     p.Type == "user",
+	}
+}
+
+// flags returns the audit flags of r, as shown in the table.
+func (r sharingRecord) flags() []string {
+	var flags []string
+	if r.Public {
+		flags = append(flags, "public")
+	}
+	if r.External {
+		flags = append(flags, "external")
+	}
+	if r.Individual {
+		flags = append(flags, "user")
+	}
+	if r.Inherited {
+		flags = append(flags, "inherited")
+	}
+	return flags
+}
+
+// accessRemovalRecord is the record of a permission audit sharing --remove
+// found. Status is removed, skipped or error.
+type accessRemovalRecord struct {
+	Path         string `json:"path"`
+	FileID       string `json:"fileId"`
+	PermissionID string `json:"permissionId"`
+	Principal    string `json:"principal"`
+	Role         string `json:"role"`
+	Status       string `json:"status"`
+	Detail       string `json:"detail"`
+}
+
+func runAuditSharing(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
+	if err != nil {
+		return err
+	}
+
+	folderID := args[0]
+	if !useIDFlag {
+		folderID, err = ds.ResolvePath(ctx, args[0], true)
+		if err != nil {
+			return fmt.Errorf("remote folder not found: %v", err)
+		}
+	}
+	if removeFlag != "" {
+		return stripAccess(cmd, ds, folderID, args[0])
+	}
+
+	var records []sharingRecord
+	err = ds.AuditSharing(ctx, folderID, drive.SharingAuditOptions{Domain: domainFlag, Principal: principalFlag},
+		func(e *drive.SharingEntry) { records = append(records, newSharingRecord(e)) })
+	if err != nil {
+		return err
+	}
+	if structuredOutput() {
+		return printRecords(records)
+	}
+
+	printSharingReport(records)
+	return nil
+}
+
+func printSharingReport(records []sharingRecord) {
+	if len(records) == 0 {
+		fmt.Println("No permissions found")
+		return
+	}
+
+	color.Cyan("\n🔐 Sharing:")
+	fmt.Printf("%-50s %-35s %-7s %-10s %s\n", "Path", "Principal", "Type", "Role", "Flags")
+	fmt.Println(strings.Repeat("-", 130))
+	items := map[string]bool{}
+	for _, r := range records {
+		items[r.FileID] = true
+		p := r.Path
+		if len(p) > 50 {
+			p = "..." + p[len(p)-47:]
+		}
+		flags := strings.Join(r.flags(), ", ")
+		if r.Public || r.External {
+			flags = color.YellowString("%s", flags)
+		}
+		fmt.Printf("%-50s %-35s %-7s %-10s %s\n", p, r.Principal, r.Type, r.Role, flags)
+	}
+
+	// Summary by principal, the most widely shared first
+	type reach struct {
+		principal, typ string
+		items          int
+		roles          []string
+		flags          []string
+	}
+	byPrincipal := map[string]*reach{}
+	for _, r := range records {
+		key := r.Type + " " + strings.ToLower(r.Principal)
+		s := byPrincipal[key]
+		if s == nil {
+			s = &reach{principal: r.Principal, typ: r.Type}
+			byPrincipal[key] = s
+		}
+		s.items++
+		if !slices.Contains(s.roles, r.Role) {
+			s.roles = append(s.roles, r.Role)
+		}
+		for _, f := range r.flags() {
+			if f != "inherited" && !slices.Contains(s.flags, f) {
+				s.flags = append(s.flags, f)
+			}
+		}
+	}
+	summary := slices.Collect(maps.Values(byPrincipal))
+	slices.SortFunc(summary, func(a, b *reach) int {
+		if a.items != b.items {
+			return b.items - a.items
+		}
+		return strings.Compare(a.principal, b.principal)
+	})
+
+	color.Cyan("\n👥 Principals:")
+	for _, s := range summary {
+		line := fmt.Sprintf("  %-35s %-7s %-25s %d item(s)", s.principal, s.typ, strings.Join(s.roles, ", "), s.items)
+		if len(s.flags) > 0 {
+			line += "  " + color.YellowString("%s", strings.Join(s.flags, ", "))
+		}
+		fmt.Println(line)
+	}
+
+	var public, external, individual int
+	for _, r := range records {
+		if r.Public {
+			public++
+		}
+		if r.External {
+			external++
+		}
+		if r.Individual {
+			individual++
+		}
+	}
+	fmt.Printf("\n%d item(s), %d permission(s): %d public link(s), %d external, %d individual user grant(s)\n",
+		len(items), len(records), public, external, individual)
+}
+
+// stripAccess removes the access of --remove from the tree under folderID,
+// named name, after confirmation.
+func stripAccess(cmd *cobra.Command, ds *drive.Service, folderID, name string) error {
+	ctx := cmd.Context()
+	var found int
+	items := map[string]bool{}
+	err := ds.AuditSharing(ctx, folderID, drive.SharingAuditOptions{Domain: domainFlag, Principal: removeFlag},
+		func(e *drive.SharingEntry) {
+			found++
+			items[e.FileID] = true
+		})
+	if err != nil {
+		return err
+	}
+	if found == 0 {
+		fmt.Printf("%s has no access to anything under %s\n", removeFlag, name)
+		return printRecords([]accessRemovalRecord(nil))
+	}
+	if !confirm(fmt.Sprintf("Remove the access of %s to %d item(s) under %s?", removeFlag, len(items), name)) {
+		color.Yellow("Cancelled")
+		return nil
+	}
+
+	var records []accessRemovalRecord
+	var removed, skipped, failed int
+	err = ds.StripAccess(ctx, folderID, removeFlag, func(r drive.AccessRemoval) {
+		record := accessRemovalRecord{
+			Path:         r.Path,
+			FileID:       r.FileID,
+			PermissionID: r.Permission.Id,
+			Principal:    drive.Principal(r.Permission),
+			Role:         r.Permission.Role,
+			Status:       "removed",
+		}
+		switch {
+		case r.Err != nil:
+			failed++
+			record.Status, record.Detail = "error", r.Err.Error()
+			color.Red("✗ %s: %v", r.Path, r.Err)
+		case r.Skipped != "":
+			skipped++
+			record.Status, record.Detail = "skipped", r.Skipped
+			color.Yellow("- %s: kept %s (%s)", r.Path, r.Permission.Role, r.Skipped)
+		default:
+			removed++
+			fmt.Printf("  removed %s from %s\n", r.Permission.Role, r.Path)
+		}
+		records = append(records, record)
+	})
+	if err != nil {
+		return err
+	}
+	if err := printRecords(records); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%d removed, %d kept, %d failed\n", removed, skipped, failed)
+	if failed > 0 {
+		return fmt.Errorf("could not remove %d permission(s) of %s", failed, removeFlag)
+	}
+	color.Green("✓ Access of %s removed under %s", removeFlag, name)
+	return nil
+}
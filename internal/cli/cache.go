@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"gdrive/internal/drive"
+)
+
+// CacheCmd returns the cache command.
+func CacheCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the path resolution cache",
+		Long: `Commands for the on-disk path-to-ID cache.
+
+With --path-cache disk, folder lookups made while resolving paths such as
+"Documents/Projects/2024" are stored in the config directory and reused by
+later invocations until they expire (--cache-ttl). Entries are dropped
+automatically when gdrive renames, moves or deletes the item; use
+"cache clear" after changing folders outside gdrive.`,
+	}
+
+	cmd.AddCommand(cacheClearCmd())
+	cmd.AddCommand(cacheStatsCmd())
+
+	return cmd
+}
+
+func cacheClearCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "clear",
+		Short: "Remove all cached path lookups",
+		Args:  cobra.NoArgs,
+		RunE:  runCacheClear,
+	}
+}
+
+func cacheStatsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stats",
+		Short: "Show path cache statistics",
+		Args:  cobra.NoArgs,
+		RunE:  runCacheStats,
+	}
+}
+
+func openDiskPathCache() (*drive.PathCache, error) {
+	return drive.OpenPathCache(globalConfig.GetPathCachePath(), cacheTTLFlag)
+}
+
+// cacheRecord is the record of the cache commands. Cleared is the number of
+// paths cache clear removed.
+type cacheRecord struct {
+	File         string `json:"file"`
+	Size         int64  `json:"size"`
+	Mode         string `json:"mode"`
+	TTL          string `json:"ttl"`
+	Entries      int    `json:"entries"`
+	OldestExpiry string `json:"oldestExpiry"`
+	NewestExpiry string `json:"newestExpiry"`
+	Cleared      int    `json:"cleared"`
+}
+
+func runCacheClear(cmd *cobra.Command, args []string) error {
+	cache, err := openDiskPathCache()
+	if err != nil {
+		return err
+	}
+
+	n, err := cache.Clear()
+	if err != nil {
+		return err
+	}
+
+	color.Green("✓ Cleared %d cached path(s)", n)
+	return printRecord(cacheRecord{File: globalConfig.GetPathCachePath(), Mode: pathCacheFlag, Cleared: n})
+}
+
+func runCacheStats(cmd *cobra.Command, args []string) error {
+	cache, err := openDiskPathCache()
+	if err != nil {
+		return err
+	}
+
+	stats := cache.Stats()
+
+	if structuredOutput() {
+		return printRecord(cacheRecord{
+			File:         stats.File,
+			Size:         stats.FileSize,
+			Mode:         pathCacheFlag,
+			TTL:          stats.TTL.String(),
+			Entries:      stats.Entries,
+			OldestExpiry: recordTime(stats.OldestExp),
+			NewestExpiry: recordTime(stats.NewestExp),
+		})
+	}
+
+	color.Cyan("\n🗂  Path Cache:")
+	fmt.Printf("  File:     %s\n", stats.File)
+	if stats.FileSize > 0 {
+		fmt.Printf("  Size:     %s\n", formatSize(stats.FileSize))
+	} else {
+		fmt.Printf("  Size:     (not created)\n")
+	}
+	fmt.Printf("  Mode:     %s\n", pathCacheFlag)
+	fmt.Printf("  TTL:      %s\n", stats.TTL)
+	fmt.Printf("  Entries:  %d\n", stats.Entries)
+	if stats.Entries > 0 {
+		fmt.Printf("  Expires:  %s – %s\n",
+			stats.OldestExp.Local().Format(time.DateTime),
+			stats.NewestExp.Local().Format(time.DateTime))
+	}
+
+	return nil
+}
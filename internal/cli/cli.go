@@ -3,12 +3,14 @@ package cli
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -19,6 +21,7 @@ import (
 
 	"gdrive/internal/auth"
 	"gdrive/internal/drive"
+	"gdrive/internal/retry"
 )
 
 // Command flags
@@ -34,17 +37,68 @@ var (
 	roleFlag      string
 	notifyFlag    bool
 	messageFlag   string
+	shareTypeFlag string
+	expiresFlag   string
+	discoverFlag  bool
+	overrideFlag  bool
 	daysBackFlag  int
 	mimeTypeFlag  string
 	formatFlag    string
 	convertFlag   bool
+	limitFlag     int64
+	pageTokenFlag string
+	chunkSizeFlag string
+	permanentFlag bool
+	exportFlag    string
+	nameFlag      string
 )
 
 // Global config and flags
 var (
 	configDirFlag       string
 	credentialsPathFlag string
+	timeoutFlag         time.Duration
+	maxRetriesFlag      int
+	retryBudgetFlag     time.Duration
+	pathCacheFlag       string
+	cacheTTLFlag        time.Duration
+	pickFlag            string
+	noFollowFlag        bool
 	globalConfig        *auth.Config
+	cancelTimeout       context.CancelFunc
+	pathCache           *drive.PathCache
+)
+
+// noTimeout is the annotation of commands that run a server, which --timeout
+// does not bound: they limit each call instead.
+const noTimeout = "no-timeout"
+
+func init() {
+	// Finalizers run even when the command fails, unlike post-run hooks
+	cobra.OnFinalize(stopTimeout)
+}
+
+// stopTimeout releases the deadline set by --timeout.
+func stopTimeout() {
+	if cancelTimeout != nil {
+		cancelTimeout()
+		cancelTimeout = nil
+	}
+}
+
+// Path cache modes for --path-cache.
+const (
+	pathCacheMemory = "memory"
+	pathCacheDisk   = "disk"
+	pathCacheOff    = "off"
+)
+
+// Strategies for --pick.
+const (
+	pickError       = "error"
+	pickNewest      = "newest"
+	pickOldest      = "oldest"
+	pickInteractive = "interactive"
 )
 
 // SetupRootCommand configures the root command with global flags.
@@ -53,10 +107,64 @@ func SetupRootCommand(rootCmd *cobra.Command) {
 		"Config directory (default: $HOME/.gdrive, env: GDRIVE_CONFIG_DIR)")
 	rootCmd.PersistentFlags().StringVar(&credentialsPathFlag, "credentials", "",
 		"Path to credentials.json file (env: GDRIVE_CREDENTIALS_PATH)")
-
-	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+	rootCmd.PersistentFlags().DurationVar(&timeoutFlag, "timeout", 0,
+		"Abort the command if it runs longer than this (e.g. 30s, 5m; 0 = no limit; mcp uses --tool-timeout instead)")
+	rootCmd.PersistentFlags().IntVar(&maxRetriesFlag, "max-retries", retry.DefaultMaxRetries,
+		"Retries for rate-limited or failed Google API calls, 0 to disable (env: GDRIVE_MAX_RETRIES)")
+	rootCmd.PersistentFlags().DurationVar(&retryBudgetFlag, "retry-budget", retry.DefaultMaxElapsed,
+		"Maximum total time spent waiting between retries of one call (env: GDRIVE_RETRY_BUDGET)")
+	rootCmd.PersistentFlags().StringVar(&pathCacheFlag, "path-cache", pathCacheMemory,
+		"Path-to-ID cache: memory, disk (shared across runs, stored in the config dir) or off (env: GDRIVE_PATH_CACHE)")
+	rootCmd.PersistentFlags().DurationVar(&cacheTTLFlag, "cache-ttl", drive.DefaultPathCacheTTL,
+		"How long cached path lookups stay valid (env: GDRIVE_CACHE_TTL)")
+	rootCmd.PersistentFlags().StringVar(&pickFlag, "pick", pickError,
+		"When several items share a name in a path: error, newest, oldest or interactive (env: GDRIVE_PICK)")
+	rootCmd.PersistentFlags().BoolVar(&noFollowFlag, "no-follow-shortcuts", false,
+		"Do not resolve shortcuts in paths and downloads; folder download skips them")
+	rootCmd.PersistentFlags().StringVarP(&outputFlag, "output", "o", outputTable,
+		"Output format: table, json, ndjson, csv, yaml or template=TEXT, a Go template run on each record (env: GDRIVE_OUTPUT)")
+
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
 		// Initialize global config with priority: CLI flags > env vars > defaults
 		globalConfig = auth.NewConfig(configDirFlag, credentialsPathFlag)
+		if cmd.Flags().Changed("max-retries") {
+			globalConfig.Retry.MaxRetries = maxRetriesFlag
+		}
+		if cmd.Flags().Changed("retry-budget") {
+			globalConfig.Retry.MaxElapsed = retryBudgetFlag
+		}
+		if !cmd.Flags().Changed("path-cache") {
+			if v := os.Getenv("GDRIVE_PATH_CACHE"); v != "" {
+				pathCacheFlag = v
+			}
+		}
+		if !cmd.Flags().Changed("pick") {
+			if v := os.Getenv("GDRIVE_PICK"); v != "" {
+				pickFlag = v
+			}
+		}
+		if !cmd.Flags().Changed("cache-ttl") {
+			if v := os.Getenv("GDRIVE_CACHE_TTL"); v != "" {
+				if d, err := time.ParseDuration(v); err == nil {
+					cacheTTLFlag = d
+				}
+			}
+		}
+
+		if err := setupOutput(cmd); err != nil {
+			return err
+		}
+
+		// Bound the whole command; in-flight Drive calls are cancelled with it
+		if timeoutFlag > 0 && cmd.Annotations[noTimeout] == "" {
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeoutFlag)
+			cmd.SetContext(ctx)
+			cancelTimeout = cancel
+		}
+		return nil
+	}
+	rootCmd.PersistentPostRun = func(cmd *cobra.Command, args []string) {
+		resetOutput()
 	}
 }
 
@@ -74,10 +182,13 @@ func FileCmd() *cobra.Command {
 	cmd.AddCommand(fileRenameCmd())
 	cmd.AddCommand(fileMoveCmd())
 	cmd.AddCommand(fileCopyCmd())
+	cmd.AddCommand(fileShortcutCmd())
 	cmd.AddCommand(fileInfoCmd())
+	cmd.AddCommand(fileMetaCmd())
 	cmd.AddCommand(fileShareCmd())
 	cmd.AddCommand(fileSharePublicCmd())
 	cmd.AddCommand(filePermissionsCmd())
+	cmd.AddCommand(fileUpdatePermissionCmd())
 	cmd.AddCommand(fileRemovePermissionCmd())
 	cmd.AddCommand(fileRemovePublicCmd())
 
@@ -103,13 +214,22 @@ func FolderCmd() *cobra.Command {
 // SearchCmd returns the search command.
 func SearchCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "search QUERY",
+		Use:   "search [QUERY]",
 		Short: "Search for files and folders on Google Drive",
 		Long: `Search for files and folders on Google Drive. Displays file name, ID, and last modification date.
 
+QUERY matches names; leave it out (or pass "") to rely on the filters alone.
+--fulltext also looks inside content and descriptions.
+
 File types can be shortcuts (image, audio, video, prez, doc, spreadsheet, txt, pdf, folder)
 or explicit MIME types (e.g., image/jpeg, application/pdf).
 
+Dates are written 2026-01-31 (midnight local time) or in RFC 3339; --modified-within
+and --created-within take an age such as 36h, 7d or 2w. Sizes take K, M and G
+suffixes; size bounds leave out folders and Google Workspace files, which have no size.
+--sort orders by modified or created (newest first), name (A to Z) or size (largest
+first); --reverse flips it. Full-text results are ordered by relevance and cannot be sorted.
+
 Examples:
   gdrive search report
   gdrive search "budget 2024" --max 20
@@ -118,8 +238,18 @@ Examples:
   gdrive search "My Project" --type folder
   gdrive search contract --type doc -m 10
   gdrive search report --parent Documents/Projects
-  gdrive search report --parent 1a2b3c4d5e --id`,
-		Args: cobra.ExactArgs(1),
+  gdrive search report --parent 1a2b3c4d5e --id
+  gdrive search report --drive Engineering
+  gdrive search report --parent @Engineering/Specs
+  gdrive search "" --property project=ACME-42 --property state=reviewed
+  gdrive search report --app-property pipeline=ingested
+  gdrive search --fulltext "purchase order" --modified-within 7d
+  gdrive search invoice --modified-after 2026-01-01 --modified-before 2026-04-01
+  gdrive search --owner me --visibility anyoneWithLink
+  gdrive search --shared-with-me --shared-by alice@example.com --sort modified
+  gdrive search --type video --min-size 1G --sort size
+  gdrive search --starred --sort name`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: runSearch,
 	}
 
@@ -127,7 +257,26 @@ Examples:
 	cmd.Flags().StringVarP(&fileTypeFlag, "type", "t", "", "Filter by file types (comma-separated)")
 	cmd.Flags().String("parent", "", "Restrict search to direct children of this folder (path or ID with --id)")
 	cmd.Flags().BoolVar(&useIDFlag, "id", false, "Treat --parent value as a Drive folder ID")
-	cmd.Flags().BoolVar(&jsonFlag, "json", false, "Output results as JSON array (untruncated names)")
+	cmd.Flags().String("drive", "", "Restrict search to this Shared Drive (name, or ID with --id)")
+	cmd.Flags().StringArray("property", nil, "Only files with this property, as key=value (repeatable)")
+	cmd.Flags().StringArray("app-property", nil, "Only files with this appProperty, as key=value (repeatable)")
+	cmd.Flags().String("fulltext", "", "Only files whose content, name or description contains this text")
+	cmd.Flags().String("modified-after", "", "Only files modified on or after this date")
+	cmd.Flags().String("modified-before", "", "Only files modified before this date")
+	cmd.Flags().String("modified-within", "", "Only files modified in this past period, e.g. 7d")
+	cmd.Flags().String("created-after", "", "Only files created on or after this date")
+	cmd.Flags().String("created-before", "", "Only files created before this date")
+	cmd.Flags().String("created-within", "", "Only files created in this past period, e.g. 2w")
+	cmd.Flags().String("owner", "", "Only files owned by this email address ('me' for yours)")
+	cmd.Flags().String("shared-by", "", "Only files shared with you by this email address")
+	cmd.Flags().Bool("starred", false, "Only starred files")
+	cmd.Flags().Bool("shared-with-me", false, "Only files in 'Shared with me'")
+	cmd.Flags().String("visibility", "", "Only files with this visibility: "+strings.Join(drive.Visibilities, ", "))
+	cmd.Flags().String("min-size", "", "Only files of at least this size, e.g. 10M")
+	cmd.Flags().String("max-size", "", "Only files of at most this size, e.g. 1G")
+	cmd.Flags().String("sort", "", "Order results by modified, created, name or size")
+	cmd.Flags().Bool("reverse", false, "Reverse the order given by --sort")
+	cmd.Flags().BoolVar(&jsonFlag, "json", false, "Output results as JSON array, same as --output json")
 
 	return cmd
 }
@@ -148,13 +297,206 @@ func ActivityCmd() *cobra.Command {
 	return cmd
 }
 
+// driveServiceOverride allows tests to run commands against a fake Drive
+// service instead of the authenticated one.
+var driveServiceOverride func(ctx context.Context) (*drive.Service, error)
+
 // getDriveService returns an authenticated drive service bound to ctx.
 func getDriveService(ctx context.Context) (*drive.Service, error) {
-	srv, err := auth.GetAuthenticatedService(ctx, globalConfig)
+	var ds *drive.Service
+	if driveServiceOverride != nil {
+		var err error
+		if ds, err = driveServiceOverride(ctx); err != nil {
+			return nil, err
+		}
+	} else {
+		srv, client, err := auth.GetAuthenticatedServiceAndClient(ctx, globalConfig)
+		if err != nil {
+			return nil, fmt.Errorf("authentication error: %w", err)
+		}
+		ds = drive.NewService(srv)
+		ds.HTTP = client
+	}
+	ds.FormatsFile = globalConfig.GetFormatsPath()
+	cache, err := getPathCache()
+	if err != nil {
+		return nil, err
+	}
+	picker, err := getPicker()
 	if err != nil {
-		return nil, fmt.Errorf("authentication error: %w", err)
+		return nil, err
 	}
-	return drive.NewService(srv), nil
+	ds.Cache = cache
+	ds.Pick = picker
+	ds.NoFollowShortcuts = noFollowFlag
+	return ds, nil
+}
+
+// getSharingService returns the drive service of the commands that share,
+// enforcing the sharing policy unless --override-policy is given. Only these
+// commands read the policy, so a broken one does not stop the others.
+func getSharingService(ctx context.Context) (*drive.Service, error) {
+	ds, err := getDriveService(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if overrideFlag {
+		color.Yellow("⚠ Ignoring the sharing policy")
+		return ds, nil
+	}
+	if ds.Policy, err = loadSharingPolicy(); err != nil {
+		return nil, fmt.Errorf("%w\nAdministrators can share anyway with --override-policy", err)
+	}
+	return ds, nil
+}
+
+// loadSharingPolicy reads the sharing policy, which is optional unless
+// GDRIVE_SHARING_POLICY names it.
+func loadSharingPolicy() (*drive.SharingPolicy, error) {
+	path := globalConfig.GetSharingPolicyPath()
+	policy, err := drive.LoadSharingPolicy(path)
+	if err == nil && policy == nil && os.Getenv(auth.EnvSharingPolicy) != "" {
+		return nil, fmt.Errorf("sharing policy %s not found", path)
+	}
+	return policy, err
+}
+
+// explainPolicy adds to errors of sharing the policy denied how admins can
+// go past it.
+func explainPolicy(err error) error {
+	if errors.Is(err, drive.ErrPolicyDenied) {
+		return fmt.Errorf("%w\nAdministrators can share anyway with --override-policy", err)
+	}
+	return err
+}
+
+// enableResumableUploads records upload sessions in the config dir, so an
+// interrupted upload resumes on the next run, and applies --chunk-size.
+func enableResumableUploads(ds *drive.Service) error {
+	chunkSize, err := parseSize(chunkSizeFlag)
+	if err != nil {
+		return fmt.Errorf("invalid --chunk-size: %w", err)
+	}
+	if chunkSize < drive.UploadChunkAlign {
+		return fmt.Errorf("invalid --chunk-size: must be at least 256K")
+	}
+	uploads, err := drive.OpenUploadStateStore(globalConfig.GetUploadStatePath())
+	if err != nil {
+		return err
+	}
+	ds.ChunkSize = chunkSize
+	ds.Uploads = uploads
+	return nil
+}
+
+// parseSize parses a byte size such as "512K", "8M", "1.5G" or "1048576".
+// Units are binary (K = 1024).
+func parseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "B"), "I")
+	multiplier := 1.0
+	if s != "" {
+		switch s[len(s)-1] {
+		case 'K':
+			multiplier = 1 << 10
+		case 'M':
+			multiplier = 1 << 20
+		case 'G':
+			multiplier = 1 << 30
+		case 'T':
+			multiplier = 1 << 40
+		}
+		if multiplier > 1 {
+			s = s[:len(s)-1]
+		}
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q (e.g. 512K, 8M, 1G)", s)
+	}
+	return int64(n * multiplier), nil
+}
+
+// getPicker returns the duplicate-name strategy selected by --pick.
+func getPicker() (drive.Picker, error) {
+	switch pickFlag {
+	case pickError, "":
+		return nil, nil
+	case pickNewest:
+		return drive.PickNewest, nil
+	case pickOldest:
+		return drive.PickOldest, nil
+	case pickInteractive:
+		return promptPick, nil
+	default:
+		return nil, fmt.Errorf("invalid --pick %q (use %s, %s, %s or %s)",
+			pickFlag, pickError, pickNewest, pickOldest, pickInteractive)
+	}
+}
+
+// pickMu serializes prompts from parallel operations.
+var pickMu sync.Mutex
+
+// promptPick asks the user which of several same-named items to use.
+func promptPick(_ context.Context, amb *drive.AmbiguousPathError) (*driveapi.File, error) {
+	pickMu.Lock()
+	defer pickMu.Unlock()
+
+	color.Yellow("\n%d items are named %q in the same folder:", len(amb.Candidates), amb.Name)
+	fmt.Printf("%-4s %-44s %-20s %12s  %s\n", "#", "ID", "Modified", "Size", "Owner")
+	for i, c := range amb.Candidates {
+		modified := "N/A"
+		if t, err := time.Parse(time.RFC3339, c.ModifiedTime); err == nil {
+			modified = t.Local().Format("2006-01-02 15:04")
+		}
+		size := "-"
+		if c.MimeType != drive.DriveFolderMimeType {
+			size = formatSize(c.Size)
+		}
+		fmt.Printf("%-4d %-44s %-20s %12s  %s\n", i+1, c.Id, modified, size, drive.CandidateOwner(c))
+	}
+
+	fmt.Printf("\nPick one [1-%d]: ", len(amb.Candidates))
+	var response string
+	fmt.Scanln(&response)
+	var n int
+	if _, err := fmt.Sscan(response, &n); err != nil || n < 1 || n > len(amb.Candidates) {
+		return nil, amb
+	}
+	return amb.Candidates[n-1], nil
+}
+
+// getPathCache returns the process-wide path cache selected by --path-cache,
+// or nil when caching is off.
+func getPathCache() (*drive.PathCache, error) {
+	if pathCache != nil {
+		return pathCache, nil
+	}
+	switch pathCacheFlag {
+	case pathCacheOff:
+		return nil, nil
+	case pathCacheMemory, "":
+		pathCache = drive.NewPathCache(cacheTTLFlag)
+	case pathCacheDisk:
+		c, err := drive.OpenPathCache(globalConfig.GetPathCachePath(), cacheTTLFlag)
+		if err != nil {
+			return nil, err
+		}
+		pathCache = c
+	default:
+		return nil, fmt.Errorf("invalid --path-cache %q (use %s, %s or %s)",
+			pathCacheFlag, pathCacheMemory, pathCacheDisk, pathCacheOff)
+	}
+	return pathCache, nil
+}
+
+// confirm asks a yes/no question and reports whether the answer was yes.
+func confirm(question string) bool {
+	fmt.Printf("%s (y/N): ", question)
+	var response string
+	fmt.Scanln(&response)
+	response = strings.ToLower(response)
+	return response == "y" || response == "yes"
 }
 
 func confirmOverwrite(localPath string, remoteSize int64) bool {
@@ -184,6 +526,8 @@ func printItemRow(ds *drive.Service, item *driveapi.File) {
 	itemType := "📄 File"
 	if ds.IsFolder(item) {
 		itemType = "📁 Folder"
+	} else if ds.IsShortcut(item) {
+		itemType = "🔗 Link"
 	}
 
 	modifiedStr := "N/A"
@@ -241,16 +585,18 @@ Examples:
   gdrive file download MySheet --format csv        # Google Sheet as CSV
 
 Workspace export formats (used only when the source is a Google Workspace file):
-  Docs:   md (Markdown), pdf (default), docx, txt, html
-  Sheets: xlsx (default), csv, pdf
-  Slides: pptx (default), pdf`,
+  Docs:   pdf (default), md, docx, odt, rtf, txt, html, epub, zip
+  Sheets: xlsx (default), csv, tsv, ods, pdf, zip
+  Slides: pptx (default), pdf, odp, txt
+Drive decides which formats each type supports; 'gdrive formats' lists them
+all, including Drawings and Apps Script projects.`,
 		Args: cobra.RangeArgs(1, 2),
 		RunE: runFileDownload,
 	}
 
 	cmd.Flags().BoolVar(&overwriteFlag, "overwrite", false, "Overwrite without asking")
 	cmd.Flags().BoolVar(&useIDFlag, "id", false, "Treat remote_file as a Drive file ID")
-	cmd.Flags().StringVar(&formatFlag, "format", "", "Export format for Google Workspace files (md, pdf, docx, txt, html, xlsx, csv, pptx). Ignored for binary files.")
+	cmd.Flags().StringVar(&formatFlag, "format", "", "Export format for Google Workspace files, see 'gdrive formats'. Ignored for binary files.")
 
 	return cmd
 }
@@ -261,13 +607,18 @@ func fileUploadCmd() *cobra.Command {
 		Short: "Upload a file to Google Drive",
 		Long: `Upload a file to Google Drive. If file exists, creates a new version.
 
+Uploads are resumable: if one is interrupted (network loss, Ctrl-C, crash),
+running the same command again continues from the last uploaded chunk, as
+long as the local file has not changed.
+
 Examples:
   gdrive file upload ./myfile.txt Parameters/bin
   gdrive file upload /path/to/file.pdf Documents
   gdrive file upload ./myfile.txt 1a2b3c4d5e --id
   gdrive file upload ./toto.ogg Documents --run-after 'trash "{}"'
   gdrive file upload ./spec.md Documents --convert        # → Google Doc
-  gdrive file upload ./data.csv Reports --convert         # → Google Sheet`,
+  gdrive file upload ./data.csv Reports --convert         # → Google Sheet
+  gdrive file upload ./video.mp4 Videos --chunk-size 64M`,
 		Args: cobra.ExactArgs(2),
 		RunE: runFileUpload,
 	}
@@ -275,6 +626,7 @@ Examples:
 	cmd.Flags().BoolVar(&useIDFlag, "id", false, "Treat remote_folder as a Drive folder ID")
 	cmd.Flags().StringVar(&mimeTypeFlag, "mime", "", "Force MIME type (default: auto-detect from extension)")
 	cmd.Flags().BoolVar(&convertFlag, "convert", false, "Convert source file to a Google Workspace type (Docs/Sheets/Slides) based on extension")
+	cmd.Flags().StringVar(&chunkSizeFlag, "chunk-size", "8M", "Resumable upload chunk size, a multiple of 256K (e.g. 256K, 8M, 64M)")
 	cmd.Flags().String("run-after", "", "Shell command to run after a successful upload ({} is replaced by LOCAL_FILE)")
 
 	return cmd
@@ -283,17 +635,24 @@ Examples:
 func fileDeleteCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "delete FILE",
-		Short: "Delete a file from Google Drive",
-		Long: `Delete a file from Google Drive.
+		Short: "Move a file to the trash",
+		Long: `Move a file or folder to the Google Drive trash. Trashed items can be
+brought back with 'gdrive trash restore' until the trash is emptied
+(Drive empties it automatically after 30 days).
+
+With --permanent the file is deleted immediately, bypassing the trash.
+This cannot be undone and asks for confirmation.
 
 Examples:
   gdrive file delete Parameters/file.txt
-  gdrive file delete 1a2b3c4d5e --id`,
+  gdrive file delete 1a2b3c4d5e --id
+  gdrive file delete Parameters/old.txt --permanent`,
 		Args: cobra.ExactArgs(1),
 		RunE: runFileDelete,
 	}
 
 	cmd.Flags().BoolVar(&useIDFlag, "id", false, "Treat FILE as a Drive file ID")
+	cmd.Flags().BoolVar(&permanentFlag, "permanent", false, "Delete permanently instead of moving to the trash")
 
 	return cmd
 }
@@ -355,6 +714,31 @@ Examples:
 	return cmd
 }
 
+func fileShortcutCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "shortcut TARGET FOLDER",
+		Short: "Create a shortcut to a file or folder",
+		Long: `Create a shortcut in FOLDER pointing to TARGET, a file or folder. The
+shortcut is named after the target unless --name is given.
+
+Shortcuts are followed like the items they point to: paths can go through
+shortcuts to folders, and downloading a shortcut downloads its target. Use
+the global --no-follow-shortcuts flag to treat them as plain items.
+
+Examples:
+  gdrive file shortcut Reports/q3.pdf Team/Current
+  gdrive file shortcut "@Engineering/Specs" Projects --name Specs
+  gdrive file shortcut 1a2b3c4d5e 1xyz789 --id`,
+		Args: cobra.ExactArgs(2),
+		RunE: runFileShortcut,
+	}
+
+	cmd.Flags().BoolVar(&useIDFlag, "id", false, "Treat TARGET and FOLDER as Drive IDs")
+	cmd.Flags().StringVar(&nameFlag, "name", "", "Name of the shortcut (default: the target's name)")
+
+	return cmd
+}
+
 func fileInfoCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "info FILE",
@@ -375,22 +759,41 @@ Examples:
 
 func fileShareCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "share FILE EMAIL",
-		Short: "Share a file with a user",
-		Long: `Share a file with a user via email.
+		Use:   "share FILE EMAIL|DOMAIN",
+		Short: "Share a file with a user, group or domain",
+		Long: `Share a file with a user or a Google group via email, or with everyone in a
+domain. Sharing again with the same user, group or domain changes their role.
+
+--expires ends the access of a user or group at a date (2026-12-31) or
+after a period (7d, 2w, 36h).
+
+--role owner transfers the ownership of the file to a user. Drive makes them
+a pending owner, sharing the file with them as writer first if needed, and
+emails them; they become owner once they accept, and you keep writer access.
+Items in Shared Drives belong to the drive and cannot change owner.
+
+Sharing that the sharing policy in the config dir forbids is refused;
+administrators can share anyway with --override-policy.
 
 Examples:
   gdrive file share Parameters/file.txt user@example.com
-  gdrive file share Parameters/file.txt user@example.com --role writer
+  gdrive file share Parameters/file.txt user@example.com --role writer --expires 30d
+  gdrive file share Parameters/file.txt team@example.com --type group --role commenter
+  gdrive file share Parameters/file.txt example.com --type domain --discoverable
+  gdrive file share Parameters/file.txt new-owner@example.com --role owner
   gdrive file share 1a2b3c4d5e user@example.com --id --no-notify`,
 		Args: cobra.ExactArgs(2),
 		RunE: runFileShare,
 	}
 
 	cmd.Flags().BoolVar(&useIDFlag, "id", false, "Treat FILE as a Drive file ID")
-	cmd.Flags().StringVar(&roleFlag, "role", "reader", "Permission role (reader, writer, commenter)")
+	cmd.Flags().StringVar(&shareTypeFlag, "type", "user", "Share with a user, group or domain")
+	cmd.Flags().StringVar(&roleFlag, "role", "reader", "Permission role (reader, commenter, writer, or owner to transfer ownership)")
+	cmd.Flags().StringVar(&expiresFlag, "expires", "", "End the access at a date or after a period, e.g. 2026-12-31 or 7d (users and groups)")
+	cmd.Flags().BoolVar(&discoverFlag, "discoverable", false, "Let people in the domain find the file by search (domains)")
 	cmd.Flags().BoolVar(&notifyFlag, "no-notify", false, "Do not send notification email")
 	cmd.Flags().StringVar(&messageFlag, "message", "", "Custom message for the notification email")
+	cmd.Flags().BoolVar(&overrideFlag, "override-policy", false, "Share even if the sharing policy forbids it (administrators)")
 
 	return cmd
 }
@@ -401,6 +804,9 @@ func fileSharePublicCmd() *cobra.Command {
 		Short: "Share a file with anyone who has the link",
 		Long: `Share a file with anyone who has the link.
 
+Sharing that the sharing policy in the config dir forbids is refused;
+administrators can share anyway with --override-policy.
+
 Examples:
   gdrive file share-public Parameters/file.txt
   gdrive file share-public Parameters/file.txt --role writer
@@ -411,6 +817,7 @@ Examples:
 
 	cmd.Flags().BoolVar(&useIDFlag, "id", false, "Treat FILE as a Drive file ID")
 	cmd.Flags().StringVar(&roleFlag, "role", "reader", "Permission role (reader, writer, commenter)")
+	cmd.Flags().BoolVar(&overrideFlag, "override-policy", false, "Share even if the sharing policy forbids it (administrators)")
 
 	return cmd
 }
@@ -433,6 +840,31 @@ Examples:
 	return cmd
 }
 
+func fileUpdatePermissionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "update-permission FILE PERMISSION_ID",
+		Short: "Change the role or expiration of a permission",
+		Long: `Change the role or the expiration of an existing permission. Use the
+'permissions' command to get permission IDs. Use 'share --role owner' to
+transfer ownership.
+
+Examples:
+  gdrive file update-permission Parameters/file.txt 12345678 --role writer
+  gdrive file update-permission Parameters/file.txt 12345678 --expires 2026-12-31
+  gdrive file update-permission 1a2b3c4d5e 12345678 --id --no-expiration`,
+		Args: cobra.ExactArgs(2),
+		RunE: runFileUpdatePermission,
+	}
+
+	cmd.Flags().BoolVar(&useIDFlag, "id", false, "Treat FILE as a Drive file ID")
+	cmd.Flags().String("role", "", "New role (reader, commenter, writer)")
+	cmd.Flags().StringVar(&expiresFlag, "expires", "", "End the access at a date or after a period, e.g. 2026-12-31 or 7d")
+	cmd.Flags().Bool("no-expiration", false, "Make the permission permanent")
+	cmd.Flags().BoolVar(&overrideFlag, "override-policy", false, "Update even if the sharing policy forbids it (administrators)")
+
+	return cmd
+}
+
 func fileRemovePermissionCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "remove-permission FILE PERMISSION_ID",
@@ -507,6 +939,7 @@ Examples:
 
 	cmd.Flags().BoolVar(&useIDFlag, "id", false, "Treat remote_folder as a Drive folder ID")
 	cmd.Flags().Bool("create", false, "Create a subfolder named after LOCAL_SRC inside REMOTE_FOLDER and upload into it")
+	cmd.Flags().StringVar(&chunkSizeFlag, "chunk-size", "8M", "Resumable upload chunk size, a multiple of 256K (e.g. 256K, 8M, 64M)")
 	cmd.Flags().String("run-after", "", "Shell command to run after a successful upload ({} is replaced by LOCAL_SRC)")
 
 	return cmd
@@ -518,13 +951,21 @@ func folderDownloadCmd() *cobra.Command {
 		Short: "Download a folder recursively from Google Drive",
 		Long: `Download a folder recursively from Google Drive.
 
+Google Workspace files are exported: Docs to PDF, Sheets to XLSX, Slides to
+PPTX and Drawings to PDF, unless --export picks another format for a type.
+The extension of the format is appended to the name, e.g. "Plan" becomes
+"Plan.pdf". Forms, Sites and other types that cannot be exported are
+skipped. With --new-only, an export is compared with the modification time
+of the Workspace file, like any other file.
+
 Examples:
   gdrive folder download Parameters/bin ./downloads
   gdrive folder download Documents/Projects ./backup --overwrite
   gdrive folder download 1a2b3c4d5e ./downloads --id
   gdrive folder download Documents ./backup --parallel 10
   gdrive folder download Documents ./backup --new-only
-  gdrive folder download Documents ./backup --new-only --overwrite`,
+  gdrive folder download Documents ./backup --new-only --overwrite
+  gdrive folder download Documents ./backup --export doc=md,sheet=xlsx,slide=pdf`,
 		Args: cobra.ExactArgs(2),
 		RunE: runFolderDownload,
 	}
@@ -533,6 +974,7 @@ Examples:
 	cmd.Flags().BoolVar(&useIDFlag, "id", false, "Treat remote_folder as a Drive folder ID")
 	cmd.Flags().IntVarP(&parallelFlag, "parallel", "p", 5, "Number of parallel downloads (1-20)")
 	cmd.Flags().BoolVar(&newOnlyFlag, "new-only", false, "Only download new or newer files from Drive")
+	cmd.Flags().StringVar(&exportFlag, "export", "", "Export formats for Google Workspace files, e.g. doc=md,sheet=xlsx,slide=pdf (types: doc, sheet, slide, drawing)")
 
 	return cmd
 }
@@ -546,12 +988,16 @@ func folderListCmd() *cobra.Command {
 Examples:
   gdrive folder list Parameters/bin
   gdrive folder list Documents
-  gdrive folder list 1a2b3c4d5e --id`,
+  gdrive folder list 1a2b3c4d5e --id
+  gdrive folder list Photos/Camera --limit 100
+  gdrive folder list Photos/Camera --limit 100 --page-token TOKEN`,
 		Args: cobra.ExactArgs(1),
 		RunE: runFolderList,
 	}
 
 	cmd.Flags().BoolVar(&useIDFlag, "id", false, "Treat remote_folder as a Drive folder ID")
+	cmd.Flags().Int64Var(&limitFlag, "limit", 0, "Maximum number of items to list (0 = all)")
+	cmd.Flags().StringVar(&pageTokenFlag, "page-token", "", "Continue a listing from the token printed by a previous --limit run")
 
 	return cmd
 }
@@ -580,18 +1026,24 @@ Examples:
 func activityRevisionsCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "revisions FILE",
-		Short: "List revision history for a file",
+		Short: "List, download and restore file revisions",
 		Long: `List all revisions for a specific file.
 Shows modification time, size, and who made the change.
 
+The subcommands act on one revision, identified by the ID in the list:
+download it, restore it as the current content, pin it so Drive keeps it
+forever, or delete it.
+
 Examples:
   gdrive activity revisions Parameters/file.txt
-  gdrive activity revisions 1a2b3c4d5e --id`,
+  gdrive activity revisions 1a2b3c4d5e --id
+  gdrive activity revisions download Parameters/file.txt 42`,
 		Args: cobra.ExactArgs(1),
 		RunE: runActivityRevisions,
 	}
 
 	cmd.Flags().BoolVar(&useIDFlag, "id", false, "Treat FILE as a Drive file ID")
+	addRevisionCommands(cmd)
 
 	return cmd
 }
@@ -645,7 +1097,8 @@ Examples:
 // Run functions for file commands
 
 func runFileDownload(cmd *cobra.Command, args []string) error {
-	ds, err := getDriveService(cmd.Context())
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
 	if err != nil {
 		return err
 	}
@@ -658,11 +1111,12 @@ func runFileDownload(cmd *cobra.Command, args []string) error {
 
 	var fileID string
 	var filename string
+	var fileItem *driveapi.File
 
 	if useIDFlag {
 		// Use remote_file as file ID directly
 		fileID = remoteFile
-		fileItem, err := ds.API.Files.Get(fileID).Fields("id, name, size, modifiedTime").Do()
+		fileItem, err = ds.Backend.GetFile(ctx, fileID, "id, name, mimeType, size, modifiedTime")
 		if err != nil {
 			return fmt.Errorf("file not found: %v", err)
 		}
@@ -683,14 +1137,14 @@ func runFileDownload(cmd *cobra.Command, args []string) error {
 		// Resolve parent folder
 		parentID := "root"
 		if folderPath != "" {
-			parentID, err = ds.ResolvePath(folderPath, true)
+			parentID, err = ds.ResolvePath(ctx, folderPath, true)
 			if err != nil {
 				return err
 			}
 		}
 
 		// Find file
-		fileItem, err := ds.FindFile(filename, parentID)
+		fileItem, err = ds.FindFile(ctx, filename, parentID)
 		if err != nil {
 			return err
 		}
@@ -714,16 +1168,24 @@ func runFileDownload(cmd *cobra.Command, args []string) error {
 	}
 
 	// Download file
-	if err := ds.DownloadFile(fileID, localPath, formatFlag, true, true); err != nil {
+	if err := ds.DownloadFile(ctx, fileID, localPath, formatFlag, true, true); err != nil {
 		return err
 	}
 
+	// Workspace files land on disk under the extension of their export
+	if ds.IsGoogleWorkspaceFile(fileItem) {
+		if format, _, err := ds.ResolveExportFormat(ctx, fileItem.MimeType, formatFlag); err == nil {
+			localPath = ds.AdjustFilename(localPath, format)
+		}
+	}
+
 	color.Green("Downloaded: %s", localPath)
-	return nil
+	return printRecord(downloadRecord(fileItem, remoteFile, localPath))
 }
 
 func runFileUpload(cmd *cobra.Command, args []string) error {
-	ds, err := getDriveService(cmd.Context())
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
 	if err != nil {
 		return err
 	}
@@ -731,6 +1193,10 @@ func runFileUpload(cmd *cobra.Command, args []string) error {
 	localFile := args[0]
 	remoteFolder := args[1]
 
+	if err := enableResumableUploads(ds); err != nil {
+		return err
+	}
+
 	// Check local file exists
 	if _, err := os.Stat(localFile); os.IsNotExist(err) {
 		return fmt.Errorf("local file not found: %s", localFile)
@@ -749,7 +1215,7 @@ func runFileUpload(cmd *cobra.Command, args []string) error {
 	if useIDFlag {
 		folderID = remoteFolder
 	} else {
-		folderID, err = ds.ResolvePath(remoteFolder, true)
+		folderID, err = ds.ResolvePath(ctx, remoteFolder, true)
 		if err != nil {
 			color.Red("Remote folder does not exist: %s", remoteFolder)
 			fmt.Println("Use 'gdrive folder create' to create it first")
@@ -758,7 +1224,8 @@ func runFileUpload(cmd *cobra.Command, args []string) error {
 	}
 
 	// Upload file
-	if _, err := ds.UploadFile(localFile, folderID, mimeTypeFlag, convertFlag, true); err != nil {
+	fileID, err := ds.UploadFile(ctx, localFile, folderID, mimeTypeFlag, convertFlag, true)
+	if err != nil {
 		return err
 	}
 
@@ -774,11 +1241,18 @@ func runFileUpload(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	return nil
+	return printRecord(transferRecord{
+		ID:         fileID,
+		Name:       filepath.Base(localFile),
+		RemotePath: remoteFolder + "/" + filepath.Base(localFile),
+		LocalPath:  localFile,
+		Size:       stat.Size(),
+	})
 }
 
 func runFileDelete(cmd *cobra.Command, args []string) error {
-	ds, err := getDriveService(cmd.Context())
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
 	if err != nil {
 		return err
 	}
@@ -794,12 +1268,12 @@ func runFileDelete(cmd *cobra.Command, args []string) error {
 		dir := filepath.Dir(filePath)
 		filename := filepath.Base(filePath)
 
-		parentID, err := ds.ResolvePath(dir, true)
+		parentID, err := ds.ResolvePath(ctx, dir, true)
 		if err != nil {
 			return fmt.Errorf("parent folder not found: %v", err)
 		}
 
-		file, err := ds.FindFile(filename, parentID)
+		file, err := ds.FindFile(ctx, filename, parentID)
 		if err != nil {
 			return err
 		}
@@ -809,26 +1283,33 @@ func runFileDelete(cmd *cobra.Command, args []string) error {
 		fileID = file.Id
 	}
 
-	// Confirm deletion
-	fmt.Printf("Are you sure you want to delete this file? (y/N): ")
-	var response string
-	fmt.Scanln(&response)
-	if strings.ToLower(response) != "y" && strings.ToLower(response) != "yes" {
+	if !permanentFlag {
+		file, err := ds.TrashFile(ctx, fileID)
+		if err != nil {
+			return err
+		}
+		color.Green("✓ Moved to trash: %s", file.Name)
+		fmt.Println("  Restore it with: gdrive trash restore " + fileID + " --id")
+		return printRecord(newTrashedRecord(file))
+	}
+
+	if !confirm("Permanently delete this file? This cannot be undone.") {
 		color.Yellow("Deletion cancelled")
 		return nil
 	}
 
 	// Delete file
-	if err := ds.DeleteFile(fileID); err != nil {
+	if err := ds.DeleteFile(ctx, fileID); err != nil {
 		return err
 	}
 
-	color.Green("✓ File deleted successfully")
-	return nil
+	color.Green("✓ File deleted permanently")
+	return printRecord(fileRecord{ID: fileID})
 }
 
 func runFileRename(cmd *cobra.Command, args []string) error {
-	ds, err := getDriveService(cmd.Context())
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
 	if err != nil {
 		return err
 	}
@@ -845,12 +1326,12 @@ func runFileRename(cmd *cobra.Command, args []string) error {
 		dir := filepath.Dir(filePath)
 		filename := filepath.Base(filePath)
 
-		parentID, err := ds.ResolvePath(dir, true)
+		parentID, err := ds.ResolvePath(ctx, dir, true)
 		if err != nil {
 			return fmt.Errorf("parent folder not found: %v", err)
 		}
 
-		file, err := ds.FindFile(filename, parentID)
+		file, err := ds.FindFile(ctx, filename, parentID)
 		if err != nil {
 			return err
 		}
@@ -861,7 +1342,7 @@ func runFileRename(cmd *cobra.Command, args []string) error {
 	}
 
 	// Rename file
-	renamedFile, err := ds.RenameFile(fileID, newName)
+	renamedFile, err := ds.RenameFile(ctx, fileID, newName)
 	if err != nil {
 		return err
 	}
@@ -872,11 +1353,12 @@ func runFileRename(cmd *cobra.Command, args []string) error {
 	if renamedFile.WebViewLink != "" {
 		fmt.Printf("  Link: %s\n", renamedFile.WebViewLink)
 	}
-	return nil
+	return printRecord(newFileRecord(renamedFile, ""))
 }
 
 func runFileMove(cmd *cobra.Command, args []string) error {
-	ds, err := getDriveService(cmd.Context())
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
 	if err != nil {
 		return err
 	}
@@ -893,12 +1375,12 @@ func runFileMove(cmd *cobra.Command, args []string) error {
 		dir := filepath.Dir(filePath)
 		filename := filepath.Base(filePath)
 
-		parentID, err := ds.ResolvePath(dir, true)
+		parentID, err := ds.ResolvePath(ctx, dir, true)
 		if err != nil {
 			return fmt.Errorf("parent folder not found: %v", err)
 		}
 
-		file, err := ds.FindFile(filename, parentID)
+		file, err := ds.FindFile(ctx, filename, parentID)
 		if err != nil {
 			return err
 		}
@@ -913,14 +1395,14 @@ func runFileMove(cmd *cobra.Command, args []string) error {
 	if useIDFlag {
 		targetFolderID = targetFolder
 	} else {
-		targetFolderID, err = ds.ResolvePath(targetFolder, true)
+		targetFolderID, err = ds.ResolvePath(ctx, targetFolder, true)
 		if err != nil {
 			return fmt.Errorf("target folder not found: %v", err)
 		}
 	}
 
 	// Move file
-	movedFile, err := ds.MoveFile(fileID, targetFolderID)
+	movedFile, err := ds.MoveFile(ctx, fileID, targetFolderID)
 	if err != nil {
 		return err
 	}
@@ -928,11 +1410,49 @@ func runFileMove(cmd *cobra.Command, args []string) error {
 	color.Green("✓ File moved successfully")
 	fmt.Printf("  Name: %s\n", movedFile.Name)
 	fmt.Printf("  ID:   %s\n", movedFile.Id)
-	return nil
+	var movedPath string
+	if !useIDFlag {
+		movedPath = strings.Trim(targetFolder, "/") + "/" + movedFile.Name
+	}
+	return printRecord(newFileRecord(movedFile, movedPath))
+}
+
+func runFileShortcut(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
+	if err != nil {
+		return err
+	}
+
+	targetID, folderID := args[0], args[1]
+	if !useIDFlag {
+		if targetID, err = resolveRemoteFile(ctx, ds, args[0]); err != nil {
+			return err
+		}
+		if folderID, err = ds.ResolvePath(ctx, args[1], true); err != nil {
+			return fmt.Errorf("folder not found: %v", err)
+		}
+	}
+
+	shortcut, err := ds.CreateShortcut(ctx, targetID, folderID, nameFlag)
+	if err != nil {
+		return err
+	}
+
+	color.Green("✓ Shortcut created successfully")
+	fmt.Printf("  Name:   %s\n", shortcut.Name)
+	fmt.Printf("  ID:     %s\n", shortcut.Id)
+	fmt.Printf("  Target: %s\n", targetID)
+	var shortcutPath string
+	if !useIDFlag {
+		shortcutPath = strings.Trim(args[1], "/") + "/" + shortcut.Name
+	}
+	return printRecord(newFileRecord(shortcut, shortcutPath))
 }
 
 func runFileCopy(cmd *cobra.Command, args []string) error {
-	ds, err := getDriveService(cmd.Context())
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
 	if err != nil {
 		return err
 	}
@@ -952,12 +1472,12 @@ func runFileCopy(cmd *cobra.Command, args []string) error {
 		dir := filepath.Dir(filePath)
 		filename := filepath.Base(filePath)
 
-		parentID, err := ds.ResolvePath(dir, true)
+		parentID, err := ds.ResolvePath(ctx, dir, true)
 		if err != nil {
 			return fmt.Errorf("parent folder not found: %v", err)
 		}
 
-		file, err := ds.FindFile(filename, parentID)
+		file, err := ds.FindFile(ctx, filename, parentID)
 		if err != nil {
 			return err
 		}
@@ -973,7 +1493,7 @@ func runFileCopy(cmd *cobra.Command, args []string) error {
 		if useIDFlag {
 			parentFolderID = parentFlag
 		} else {
-			parentFolderID, err = ds.ResolvePath(parentFlag, true)
+			parentFolderID, err = ds.ResolvePath(ctx, parentFlag, true)
 			if err != nil {
 				return fmt.Errorf("parent folder not found: %v", err)
 			}
@@ -981,7 +1501,7 @@ func runFileCopy(cmd *cobra.Command, args []string) error {
 	}
 
 	// Copy file
-	copiedFile, err := ds.CopyFile(fileID, drive.CopyOptions{
+	copiedFile, err := ds.CopyFile(ctx, fileID, drive.CopyOptions{
 		NewName:        newName,
 		ParentFolderID: parentFolderID,
 	})
@@ -995,11 +1515,12 @@ func runFileCopy(cmd *cobra.Command, args []string) error {
 	if copiedFile.WebViewLink != "" {
 		fmt.Printf("  Link: %s\n", copiedFile.WebViewLink)
 	}
-	return nil
+	return printRecord(newFileRecord(copiedFile, ""))
 }
 
 func runFileInfo(cmd *cobra.Command, args []string) error {
-	ds, err := getDriveService(cmd.Context())
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
 	if err != nil {
 		return err
 	}
@@ -1015,12 +1536,12 @@ func runFileInfo(cmd *cobra.Command, args []string) error {
 		dir := filepath.Dir(filePath)
 		filename := filepath.Base(filePath)
 
-		parentID, err := ds.ResolvePath(dir, true)
+		parentID, err := ds.ResolvePath(ctx, dir, true)
 		if err != nil {
 			return fmt.Errorf("parent folder not found: %v", err)
 		}
 
-		file, err := ds.FindFile(filename, parentID)
+		file, err := ds.FindFile(ctx, filename, parentID)
 		if err != nil {
 			return err
 		}
@@ -1031,11 +1552,16 @@ func runFileInfo(cmd *cobra.Command, args []string) error {
 	}
 
 	// Get file info
-	fileInfo, err := ds.GetFileInfo(fileID)
+	fileInfo, err := ds.GetFileInfo(ctx, fileID)
 	if err != nil {
 		return err
 	}
 
+	catalog := ds.ExportCatalog(ctx)
+	if structuredOutput() {
+		return printRecord(newFileInfoRecord(fileInfo, catalog.FormatNames(fileInfo.MimeType)))
+	}
+
 	// Display file information
 	color.Cyan("\n📄 File Information:")
 	fmt.Printf("  Name:     %s\n", fileInfo.Name)
@@ -1072,11 +1598,41 @@ func runFileInfo(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  Path:     %s\n", strings.Join(pathNames, " / "))
 	}
 
+	if fileInfo.Starred {
+		fmt.Printf("  Starred:  yes\n")
+	}
+	if fileInfo.Description != "" {
+		fmt.Printf("  Desc:     %s\n", fileInfo.Description)
+	}
+
+	// Display shortcut target
+	if len(fileInfo.Target) > 0 {
+		target := fileInfo.Target[len(fileInfo.Target)-1]
+		if target.Name == "" {
+			fmt.Printf("  Target:   %s (not accessible)\n", target.ID)
+		} else {
+			targetNames := make([]string, len(fileInfo.Target))
+			for i, component := range fileInfo.Target {
+				targetNames[i] = component.Name
+			}
+			fmt.Printf("  Target:   %s (%s)\n", strings.Join(targetNames, " / "), target.ID)
+		}
+	}
+
+	// Display export formats of Workspace files
+	if len(catalog.Exports[fileInfo.MimeType]) > 0 {
+		fmt.Printf("  Export:   %s\n", formatList(catalog, fileInfo.MimeType))
+	}
+
+	printProperties("Properties", fileInfo.Properties)
+	printProperties("App properties", fileInfo.AppProperties)
+
 	return nil
 }
 
 func runFileShare(cmd *cobra.Command, args []string) error {
-	ds, err := getDriveService(cmd.Context())
+	ctx := cmd.Context()
+	ds, err := getSharingService(ctx)
 	if err != nil {
 		return err
 	}
@@ -1093,12 +1649,12 @@ func runFileShare(cmd *cobra.Command, args []string) error {
 		dir := filepath.Dir(filePath)
 		filename := filepath.Base(filePath)
 
-		parentID, err := ds.ResolvePath(dir, true)
+		parentID, err := ds.ResolvePath(ctx, dir, true)
 		if err != nil {
 			return fmt.Errorf("parent folder not found: %v", err)
 		}
 
-		file, err := ds.FindFile(filename, parentID)
+		file, err := ds.FindFile(ctx, filename, parentID)
 		if err != nil {
 			return err
 		}
@@ -1108,22 +1664,67 @@ func runFileShare(cmd *cobra.Command, args []string) error {
 		fileID = file.Id
 	}
 
-	// Share file
-	if err := ds.ShareFile(fileID, drive.ShareOptions{
-		Email:   email,
-		Role:    roleFlag,
-		Notify:  !notifyFlag,
-		Message: messageFlag,
-	}); err != nil {
-		return err
+	if roleFlag == "owner" {
+		if shareTypeFlag != "user" || expiresFlag != "" {
+			return fmt.Errorf("ownership can only go to a user, without expiration")
+		}
+		perm, err := ds.TransferOwnership(ctx, fileID, email)
+		if err != nil {
+			return explainPolicy(err)
+		}
+		color.Green("✓ Ownership offered to %s", email)
+		fmt.Println("  They become owner once they accept; you keep writer access.")
+		return printRecord(newPermissionRecord(fileID, perm))
+	}
+
+	opts := drive.ShareOptions{
+		Type:         shareTypeFlag,
+		Email:        email,
+		Role:         roleFlag,
+		Notify:       !notifyFlag,
+		Message:      messageFlag,
+		Discoverable: discoverFlag,
+	}
+	if shareTypeFlag == "domain" {
+		opts.Email, opts.Domain = "", email
+	}
+	if expiresFlag != "" {
+		if opts.Expires, err = parseExpiration(expiresFlag); err != nil {
+			return err
+		}
+	}
+
+	perm, err := ds.ShareFile(ctx, fileID, opts)
+	if err != nil {
+		return explainPolicy(err)
 	}
 
 	color.Green("✓ File shared successfully with %s as %s", email, roleFlag)
-	return nil
+	if perm.ExpirationTime != "" {
+		fmt.Printf("  Access expires: %s\n", perm.ExpirationTime)
+	}
+	return printRecord(newPermissionRecord(fileID, perm))
+}
+
+// parseExpiration parses when a permission ends: a date as accepted by
+// drive.ParseDate, or a period from now such as 7d.
+func parseExpiration(s string) (time.Time, error) {
+	if d, err := parseAge(s); err == nil {
+		if d == 0 {
+			return time.Time{}, fmt.Errorf("invalid expiration %q: the period must not be empty", s)
+		}
+		return time.Now().Add(d), nil
+	}
+	t, err := drive.ParseDate(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid expiration %q (use e.g. 2026-12-31 or 7d)", s)
+	}
+	return t, nil
 }
 
 func runFileSharePublic(cmd *cobra.Command, args []string) error {
-	ds, err := getDriveService(cmd.Context())
+	ctx := cmd.Context()
+	ds, err := getSharingService(ctx)
 	if err != nil {
 		return err
 	}
@@ -1139,12 +1740,12 @@ func runFileSharePublic(cmd *cobra.Command, args []string) error {
 		dir := filepath.Dir(filePath)
 		filename := filepath.Base(filePath)
 
-		parentID, err := ds.ResolvePath(dir, true)
+		parentID, err := ds.ResolvePath(ctx, dir, true)
 		if err != nil {
 			return fmt.Errorf("parent folder not found: %v", err)
 		}
 
-		file, err := ds.FindFile(filename, parentID)
+		file, err := ds.FindFile(ctx, filename, parentID)
 		if err != nil {
 			return err
 		}
@@ -1155,16 +1756,18 @@ func runFileSharePublic(cmd *cobra.Command, args []string) error {
 	}
 
 	// Share with anyone
-	if err := ds.ShareWithAnyone(fileID, roleFlag); err != nil {
-		return err
+	perm, err := ds.ShareWithAnyone(ctx, fileID, roleFlag)
+	if err != nil {
+		return explainPolicy(err)
 	}
 
 	color.Green("✓ File is now shared with anyone who has the link as %s", roleFlag)
-	return nil
+	return printRecord(newPermissionRecord(fileID, perm))
 }
 
 func runFilePermissions(cmd *cobra.Command, args []string) error {
-	ds, err := getDriveService(cmd.Context())
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
 	if err != nil {
 		return err
 	}
@@ -1180,12 +1783,12 @@ func runFilePermissions(cmd *cobra.Command, args []string) error {
 		dir := filepath.Dir(filePath)
 		filename := filepath.Base(filePath)
 
-		parentID, err := ds.ResolvePath(dir, true)
+		parentID, err := ds.ResolvePath(ctx, dir, true)
 		if err != nil {
 			return fmt.Errorf("parent folder not found: %v", err)
 		}
 
-		file, err := ds.FindFile(filename, parentID)
+		file, err := ds.FindFile(ctx, filename, parentID)
 		if err != nil {
 			return err
 		}
@@ -1196,11 +1799,19 @@ func runFilePermissions(cmd *cobra.Command, args []string) error {
 	}
 
 	// List permissions
-	permissions, err := ds.ListPermissions(fileID)
+	permissions, err := ds.ListPermissions(ctx, fileID)
 	if err != nil {
 		return err
 	}
 
+	if structuredOutput() {
+		records := make([]permissionRecord, 0, len(permissions))
+		for _, perm := range permissions {
+			records = append(records, newPermissionRecord(fileID, perm))
+		}
+		return printRecords(records)
+	}
+
 	if len(permissions) == 0 {
 		color.Yellow("No permissions found")
 		return nil
@@ -1254,6 +1865,12 @@ func runFilePermissions(cmd *cobra.Command, args []string) error {
 
 		fmt.Printf("\n%s\n", displayInfo)
 		fmt.Printf("   Role: %s\n", role)
+		if perm.PendingOwner {
+			fmt.Printf("   Pending owner: yes\n")
+		}
+		if perm.ExpirationTime != "" {
+			fmt.Printf("   Expires: %s\n", perm.ExpirationTime)
+		}
 		fmt.Printf("   Permission ID: %s\n", permID)
 	}
 
@@ -1261,7 +1878,8 @@ func runFilePermissions(cmd *cobra.Command, args []string) error {
 }
 
 func runFileRemovePermission(cmd *cobra.Command, args []string) error {
-	ds, err := getDriveService(cmd.Context())
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
 	if err != nil {
 		return err
 	}
@@ -1278,12 +1896,12 @@ func runFileRemovePermission(cmd *cobra.Command, args []string) error {
 		dir := filepath.Dir(filePath)
 		filename := filepath.Base(filePath)
 
-		parentID, err := ds.ResolvePath(dir, true)
+		parentID, err := ds.ResolvePath(ctx, dir, true)
 		if err != nil {
 			return fmt.Errorf("parent folder not found: %v", err)
 		}
 
-		file, err := ds.FindFile(filename, parentID)
+		file, err := ds.FindFile(ctx, filename, parentID)
 		if err != nil {
 			return err
 		}
@@ -1294,16 +1912,52 @@ func runFileRemovePermission(cmd *cobra.Command, args []string) error {
 	}
 
 	// Remove permission
-	if err := ds.RemovePermission(fileID, permissionID); err != nil {
+	if err := ds.RemovePermission(ctx, fileID, permissionID); err != nil {
 		return err
 	}
 
 	color.Green("✓ Permission removed successfully")
-	return nil
+	return printRecord(permissionRecord{FileID: fileID, ID: permissionID})
+}
+
+func runFileUpdatePermission(cmd *cobra.Command, args []string) error {
+	var update drive.PermissionUpdate
+	update.Role, _ = cmd.Flags().GetString("role")
+	update.RemoveExpiration, _ = cmd.Flags().GetBool("no-expiration")
+	if expiresFlag != "" {
+		var err error
+		if update.Expires, err = parseExpiration(expiresFlag); err != nil {
+			return err
+		}
+	}
+
+	ctx := cmd.Context()
+	ds, err := getSharingService(ctx)
+	if err != nil {
+		return err
+	}
+
+	fileID := args[0]
+	if !useIDFlag {
+		if fileID, err = resolveRemoteFile(ctx, ds, args[0]); err != nil {
+			return err
+		}
+	}
+	perm, err := ds.UpdatePermission(ctx, fileID, args[1], update)
+	if err != nil {
+		return explainPolicy(err)
+	}
+
+	color.Green("✓ Permission updated: %s", perm.Role)
+	if perm.ExpirationTime != "" {
+		fmt.Printf("  Access expires: %s\n", perm.ExpirationTime)
+	}
+	return printRecord(newPermissionRecord(fileID, perm))
 }
 
 func runFileRemovePublic(cmd *cobra.Command, args []string) error {
-	ds, err := getDriveService(cmd.Context())
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
 	if err != nil {
 		return err
 	}
@@ -1319,12 +1973,12 @@ func runFileRemovePublic(cmd *cobra.Command, args []string) error {
 		dir := filepath.Dir(filePath)
 		filename := filepath.Base(filePath)
 
-		parentID, err := ds.ResolvePath(dir, true)
+		parentID, err := ds.ResolvePath(ctx, dir, true)
 		if err != nil {
 			return fmt.Errorf("parent folder not found: %v", err)
 		}
 
-		file, err := ds.FindFile(filename, parentID)
+		file, err := ds.FindFile(ctx, filename, parentID)
 		if err != nil {
 			return err
 		}
@@ -1335,33 +1989,41 @@ func runFileRemovePublic(cmd *cobra.Command, args []string) error {
 	}
 
 	// Remove public access
-	if err := ds.RemovePublicAccess(fileID); err != nil {
+	if err := ds.RemovePublicAccess(ctx, fileID); err != nil {
 		return err
 	}
 
 	color.Green("✓ Public access removed successfully")
-	return nil
+	return printRecord(permissionRecord{FileID: fileID, Type: "anyone"})
 }
 
 // Run functions for folder commands
 
 func runFolderCreate(cmd *cobra.Command, args []string) error {
-	ds, err := getDriveService(cmd.Context())
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
 	if err != nil {
 		return err
 	}
 
 	remoteFolder := args[0]
-	if _, err := ds.CreateFolderPath(remoteFolder); err != nil {
+	folderID, err := ds.CreateFolderPath(ctx, remoteFolder)
+	if err != nil {
 		return err
 	}
 
 	color.Green("Folder path created: %s", remoteFolder)
-	return nil
+	return printRecord(fileRecord{
+		ID:       folderID,
+		Name:     path.Base(strings.Trim(remoteFolder, "/")),
+		Path:     strings.Trim(remoteFolder, "/"),
+		MimeType: drive.DriveFolderMimeType,
+	})
 }
 
 func runFolderUpload(cmd *cobra.Command, args []string) error {
-	ds, err := getDriveService(cmd.Context())
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
 	if err != nil {
 		return err
 	}
@@ -1369,6 +2031,10 @@ func runFolderUpload(cmd *cobra.Command, args []string) error {
 	localSrc := args[0]
 	remoteFolder := args[1]
 
+	if err := enableResumableUploads(ds); err != nil {
+		return err
+	}
+
 	// Check local folder exists
 	stat, err := os.Stat(localSrc)
 	if os.IsNotExist(err) {
@@ -1383,7 +2049,7 @@ func runFolderUpload(cmd *cobra.Command, args []string) error {
 	if useIDFlag {
 		folderID = remoteFolder
 	} else {
-		folderID, err = ds.ResolvePath(remoteFolder, true)
+		folderID, err = ds.ResolvePath(ctx, remoteFolder, true)
 		if err != nil {
 			color.Red("Remote folder does not exist: %s", remoteFolder)
 			fmt.Println("Use 'gdrive folder create' to create it first")
@@ -1396,7 +2062,7 @@ func runFolderUpload(cmd *cobra.Command, args []string) error {
 	uploadRemotePath := remoteFolder
 	if createFlag, _ := cmd.Flags().GetBool("create"); createFlag {
 		baseName := filepath.Base(strings.TrimRight(localSrc, "/"))
-		existing, err := ds.FindFile(baseName, folderID)
+		existing, err := ds.FindFile(ctx, baseName, folderID)
 		if err != nil {
 			return err
 		}
@@ -1408,7 +2074,7 @@ func runFolderUpload(cmd *cobra.Command, args []string) error {
 				MimeType: "application/vnd.google-apps.folder",
 				Parents:  []string{folderID},
 			}
-			folder, err := ds.API.Files.Create(fileMetadata).Fields("id").Do()
+			folder, err := ds.Backend.CreateFile(ctx, fileMetadata, nil, "id")
 			if err != nil {
 				return fmt.Errorf("failed to create subfolder %q: %w", baseName, err)
 			}
@@ -1419,7 +2085,8 @@ func runFolderUpload(cmd *cobra.Command, args []string) error {
 	}
 
 	// Upload recursively
-	if err := uploadFolderRecursive(ds, localSrc, uploadParentID, uploadRemotePath); err != nil {
+	var uploaded transferLog
+	if err := uploadFolderRecursive(ctx, ds, localSrc, uploadParentID, uploadRemotePath, &uploaded); err != nil {
 		return err
 	}
 
@@ -1435,10 +2102,10 @@ func runFolderUpload(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	return nil
+	return printRecords(uploaded.records)
 }
 
-func uploadFolderRecursive(ds *drive.Service, localPath, parentID, remotePath string) error {
+func uploadFolderRecursive(ctx context.Context, ds *drive.Service, localPath, parentID, remotePath string, uploaded *transferLog) error {
 	entries, err := os.ReadDir(localPath)
 	if err != nil {
 		return err
@@ -1449,7 +2116,7 @@ func uploadFolderRecursive(ds *drive.Service, localPath, parentID, remotePath st
 
 		if entry.IsDir() {
 			// Create subfolder if doesn't exist
-			subfolderItem, err := ds.FindFile(entry.Name(), parentID)
+			subfolderItem, err := ds.FindFile(ctx, entry.Name(), parentID)
 			if err != nil {
 				return err
 			}
@@ -1464,7 +2131,7 @@ func uploadFolderRecursive(ds *drive.Service, localPath, parentID, remotePath st
 					MimeType: "application/vnd.google-apps.folder",
 					Parents:  []string{parentID},
 				}
-				folder, err := ds.API.Files.Create(fileMetadata).Fields("id").Do()
+				folder, err := ds.Backend.CreateFile(ctx, fileMetadata, nil, "id")
 				if err != nil {
 					return err
 				}
@@ -1473,14 +2140,20 @@ func uploadFolderRecursive(ds *drive.Service, localPath, parentID, remotePath st
 			}
 
 			// Recurse into subfolder
-			if err := uploadFolderRecursive(ds, itemPath, subfolderID, remotePath+"/"+entry.Name()); err != nil {
+			if err := uploadFolderRecursive(ctx, ds, itemPath, subfolderID, remotePath+"/"+entry.Name(), uploaded); err != nil {
 				return err
 			}
 		} else {
 			// Upload file (auto-detect MIME from extension)
-			if _, err := ds.UploadFile(itemPath, parentID, "", false, true); err != nil {
+			fileID, err := ds.UploadFile(ctx, itemPath, parentID, "", false, true)
+			if err != nil {
 				return err
 			}
+			record := transferRecord{ID: fileID, Name: entry.Name(), RemotePath: remotePath + "/" + entry.Name(), LocalPath: itemPath}
+			if info, err := entry.Info(); err == nil {
+				record.Size = info.Size()
+			}
+			uploaded.add(record)
 		}
 	}
 
@@ -1488,7 +2161,8 @@ func uploadFolderRecursive(ds *drive.Service, localPath, parentID, remotePath st
 }
 
 func runFolderDownload(cmd *cobra.Command, args []string) error {
-	ds, err := getDriveService(cmd.Context())
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
 	if err != nil {
 		return err
 	}
@@ -1500,13 +2174,17 @@ func runFolderDownload(cmd *cobra.Command, args []string) error {
 	if parallelFlag < 1 || parallelFlag > 20 {
 		return fmt.Errorf("parallel downloads must be between 1 and 20")
 	}
+	exportFormats, err := ds.ParseExportSpec(ctx, exportFlag)
+	if err != nil {
+		return fmt.Errorf("invalid --export: %w", err)
+	}
 
 	// Get folder ID
 	var folderID string
 	if useIDFlag {
 		folderID = remoteFolder
 	} else {
-		folderID, err = ds.ResolvePath(remoteFolder, true)
+		folderID, err = ds.ResolvePath(ctx, remoteFolder, true)
 		if err != nil {
 			return fmt.Errorf("remote folder not found: %v", err)
 		}
@@ -1518,53 +2196,83 @@ func runFolderDownload(cmd *cobra.Command, args []string) error {
 	}
 
 	// Download recursively
-	if err := downloadFolderRecursive(ds, folderID, localFolder, overwriteFlag, parallelFlag, newOnlyFlag); err != nil {
+	var downloaded transferLog
+	if err := downloadFolderRecursive(ctx, ds, folderID, localFolder, remoteFolder, overwriteFlag, parallelFlag, newOnlyFlag, exportFormats, map[string]bool{}, &downloaded); err != nil {
 		return err
 	}
 
 	color.Green("Downloaded folder: %s -> %s", remoteFolder, localFolder)
-	return nil
+	return printRecords(downloaded.records)
 }
 
-func downloadFolderRecursive(ds *drive.Service, folderID, localPath string, overwrite bool, parallel int, newOnly bool) error {
-	items, err := ds.ListFolder(folderID)
-	if err != nil {
-		return err
-	}
+// downloadFolderRecursive downloads the content of a folder to localPath,
+// adding the files it downloads to downloaded. Google Workspace files are
+// exported to the format exportFormats gives for their MIME type, or to the
+// default format of the type. Shortcuts are downloaded as their target
+// under their own name; ancestors holds the folders being downloaded, so
+// shortcuts back to one of them are skipped.
+func downloadFolderRecursive(ctx context.Context, ds *drive.Service, folderID, localPath, remotePath string, overwrite bool, parallel int, newOnly bool, exportFormats map[string]string, ancestors map[string]bool, downloaded *transferLog) error {
+	ancestors[folderID] = true
+	defer delete(ancestors, folderID)
+
+	// Download files in parallel with limited concurrency
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	var errMu sync.Mutex
+	var errors []error
+
+	// Items are streamed page by page, folders first: subfolders are
+	// processed recursively (sequential), files are downloaded in parallel.
+	for item, err := range ds.FolderItems(ctx, folderID) {
+		if err != nil {
+			wg.Wait()
+			return err
+		}
+
+		if ds.IsShortcut(item) {
+			if ds.NoFollowShortcuts {
+				color.Cyan("Skipped shortcut: %s", item.Name)
+				continue
+			}
+			target, err := ds.ResolveShortcut(ctx, item)
+			if err != nil {
+				color.Yellow("Skipped shortcut: %s (%v)", item.Name, err)
+				continue
+			}
+			if ancestors[target.Id] {
+				color.Yellow("Skipped shortcut: %s (points to a folder being downloaded)", item.Name)
+				continue
+			}
+			item = target
+		}
 
-	// First, process all folders recursively (sequential)
-	for _, item := range items {
 		if ds.IsFolder(item) {
 			// Create local subfolder and recurse
 			subfolderPath := filepath.Join(localPath, item.Name)
 			if err := os.MkdirAll(subfolderPath, 0755); err != nil {
+				wg.Wait()
 				return err
 			}
-			if err := downloadFolderRecursive(ds, item.Id, subfolderPath, overwrite, parallel, newOnly); err != nil {
+			if err := downloadFolderRecursive(ctx, ds, item.Id, subfolderPath, remotePath+"/"+item.Name, overwrite, parallel, newOnly, exportFormats, ancestors, downloaded); err != nil {
+				wg.Wait()
 				return err
 			}
+			continue
 		}
-	}
-
-	// Then, download all files in parallel
-	var filesToDownload []*driveapi.File
-	for _, item := range items {
-		if !ds.IsFolder(item) && !ds.IsGoogleWorkspaceFile(item) {
-			filesToDownload = append(filesToDownload, item)
-		} else if ds.IsGoogleWorkspaceFile(item) {
-			// Skip Google Workspace files
-			color.Yellow("Skipped Google Workspace file: %s (use export instead)", item.Name)
-		}
-	}
 
-	// Download files in parallel with limited concurrency
-	sem := make(chan struct{}, parallel)
-	var wg sync.WaitGroup
-	var errMu sync.Mutex
-	var errors []error
-
-	for _, item := range filesToDownload {
 		filePath := filepath.Join(localPath, item.Name)
+		format := ""
+		if ds.IsGoogleWorkspaceFile(item) {
+			var err error
+			format, _, err = ds.ResolveExportFormat(ctx, item.MimeType, exportFormats[item.MimeType])
+			if err != nil {
+				color.Yellow("Skipped Google Workspace file: %s (%v)", item.Name, err)
+				continue
+			}
+			// The export is what lands on disk, so --new-only and the
+			// overwrite checks look at it
+			filePath = filepath.Join(localPath, drive.ExportFilename(item.Name, format))
+		}
 
 		// Check if file exists locally
 		localStat, localExists := os.Stat(filePath)
@@ -1605,20 +2313,29 @@ func downloadFolderRecursive(ds *drive.Service, folderID, localPath string, over
 		}
 
 		wg.Add(1)
-		go func(fileItem *driveapi.File, path string) {
+		go func(fileItem *driveapi.File, path, format string) {
 			defer wg.Done()
 
-			// Acquire semaphore
-			sem <- struct{}{}
+			// Acquire semaphore, unless the command was cancelled while waiting
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				errMu.Lock()
+				errors = append(errors, ctx.Err())
+				errMu.Unlock()
+				return
+			}
 			defer func() { <-sem }()
 
 			// Download file
-			if err := ds.DownloadFile(fileItem.Id, path, "", true, true); err != nil {
+			if err := ds.DownloadFile(ctx, fileItem.Id, path, format, true, true); err != nil {
 				errMu.Lock()
 				errors = append(errors, fmt.Errorf("failed to download %s: %v", fileItem.Name, err))
 				errMu.Unlock()
+				return
 			}
-		}(item, filePath)
+			downloaded.add(downloadRecord(fileItem, remotePath+"/"+fileItem.Name, path))
+		}(item, filePath, format)
 	}
 
 	// Wait for all downloads to complete
@@ -1633,7 +2350,8 @@ func downloadFolderRecursive(ds *drive.Service, folderID, localPath string, over
 }
 
 func runFolderList(cmd *cobra.Command, args []string) error {
-	ds, err := getDriveService(cmd.Context())
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
 	if err != nil {
 		return err
 	}
@@ -1645,23 +2363,18 @@ func runFolderList(cmd *cobra.Command, args []string) error {
 	if useIDFlag {
 		folderID = remoteFolder
 	} else {
-		folderID, err = ds.ResolvePath(remoteFolder, true)
+		folderID, err = ds.ResolvePath(ctx, remoteFolder, true)
 		if err != nil {
 			return fmt.Errorf("folder not found: %v", err)
 		}
 	}
 
 	// Get folder contents
-	items, err := ds.ListFolder(folderID)
+	items, nextPageToken, err := ds.ListFolderPage(ctx, folderID, limitFlag, pageTokenFlag)
 	if err != nil {
 		return err
 	}
 
-	if len(items) == 0 {
-		color.Yellow("Folder '%s' is empty", remoteFolder)
-		return nil
-	}
-
 	// Sort: folders first, then files
 	sort.Slice(items, func(i, j int) bool {
 		if ds.IsFolder(items[i]) != ds.IsFolder(items[j]) {
@@ -1670,32 +2383,141 @@ func runFolderList(cmd *cobra.Command, args []string) error {
 		return items[i].Name < items[j].Name
 	})
 
+	if structuredOutput() {
+		if nextPageToken != "" {
+			color.Yellow("More items available, continue with: --page-token %s", nextPageToken)
+		}
+		folderPath := strings.Trim(remoteFolder, "/")
+		if useIDFlag {
+			if components, err := ds.GetFilePath(ctx, folderID); err == nil {
+				folderPath = joinPath(components)
+			}
+		}
+		records := make([]fileRecord, 0, len(items))
+		for _, item := range items {
+			records = append(records, newFileRecord(item, folderPath+"/"+item.Name))
+		}
+		return printRecords(records)
+	}
+
+	if len(items) == 0 {
+		color.Yellow("Folder '%s' is empty", remoteFolder)
+		return nil
+	}
+
 	// Print header
 	fmt.Printf("\nContents of %s\n", remoteFolder)
 	fmt.Println(strings.Repeat("─", 120))
 	fmt.Printf("%-10s %-40s %-44s %-20s %12s\n", "Type", "Name", "ID", "Modified", "Size")
 	fmt.Println(strings.Repeat("─", 120))
 
-	// Print rows
+	// Print rows, with the target of shortcuts below them
 	for _, item := range items {
 		printItemRow(ds, item)
+		if ds.IsShortcut(item) {
+			if target, err := ds.ShortcutTarget(ctx, item, "id, name"); err == nil {
+				fmt.Printf("%-10s ↳ %s (%s)\n", "", target.Name, target.Id)
+			} else {
+				fmt.Printf("%-10s ↳ %s (not accessible)\n", "", item.ShortcutDetails.TargetId)
+			}
+		}
 	}
 
 	fmt.Println(strings.Repeat("─", 120))
 	fmt.Printf("\nTotal items: %d\n", len(items))
+	if nextPageToken != "" {
+		color.Yellow("More items available, continue with: --page-token %s", nextPageToken)
+	}
 
 	return nil
 }
 
 // Run functions for search and activity commands
 
+// searchFilters reads the filters of the search command that map directly
+// to drive.SearchOptions.
+func searchFilters(cmd *cobra.Command) (drive.SearchOptions, error) {
+	flags := cmd.Flags()
+	var opts drive.SearchOptions
+	opts.FullText, _ = flags.GetString("fulltext")
+	opts.Owner, _ = flags.GetString("owner")
+	opts.SharedBy, _ = flags.GetString("shared-by")
+	opts.Starred, _ = flags.GetBool("starred")
+	opts.SharedWithMe, _ = flags.GetBool("shared-with-me")
+	opts.Visibility, _ = flags.GetString("visibility")
+	opts.Sort, _ = flags.GetString("sort")
+	opts.Reverse, _ = flags.GetBool("reverse")
+
+	now := time.Now()
+	for _, bound := range []struct {
+		flag   string
+		dst    *time.Time
+		within bool
+	}{
+		{"modified-after", &opts.ModifiedAfter, false},
+		{"modified-before", &opts.ModifiedBefore, false},
+		{"modified-within", &opts.ModifiedAfter, true},
+		{"created-after", &opts.CreatedAfter, false},
+		{"created-before", &opts.CreatedBefore, false},
+		{"created-within", &opts.CreatedAfter, true},
+	} {
+		value, _ := flags.GetString(bound.flag)
+		if value == "" {
+			continue
+		}
+		var (
+			t   time.Time
+			err error
+		)
+		if bound.within {
+			var age time.Duration
+			age, err = parseAge(value)
+			t = now.Add(-age)
+		} else {
+			t, err = drive.ParseDate(value)
+		}
+		if err != nil {
+			return opts, fmt.Errorf("invalid --%s: %w", bound.flag, err)
+		}
+		if !bound.dst.IsZero() {
+			return opts, fmt.Errorf("--%s conflicts with another bound on the same time", bound.flag)
+		}
+		*bound.dst = t
+	}
+
+	for _, bound := range []struct {
+		flag string
+		dst  *int64
+	}{{"min-size", &opts.MinSize}, {"max-size", &opts.MaxSize}} {
+		value, _ := flags.GetString(bound.flag)
+		if value == "" {
+			continue
+		}
+		n, err := parseSize(value)
+		if err != nil {
+			return opts, fmt.Errorf("invalid --%s: %w", bound.flag, err)
+		}
+		*bound.dst = n
+	}
+
+	return opts, nil
+}
+
 func runSearch(cmd *cobra.Command, args []string) error {
-	ds, err := getDriveService(cmd.Context())
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
 	if err != nil {
 		return err
 	}
 
-	query := args[0]
+	var query string
+	if len(args) > 0 {
+		query = args[0]
+	}
+	opts, err := searchFilters(cmd)
+	if err != nil {
+		return err
+	}
 
 	// Parse file types if provided
 	var fileTypes []string
@@ -1713,7 +2535,7 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		if useIDFlag {
 			parentID = parentFlag
 		} else {
-			id, err := ds.ResolvePath(parentFlag, true)
+			id, err := ds.ResolvePath(ctx, parentFlag, true)
 			if err != nil {
 				return fmt.Errorf("resolving --parent %q: %w", parentFlag, err)
 			}
@@ -1721,47 +2543,69 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	if !jsonFlag {
+	// Resolve Shared Drive if provided
+	driveFlag, _ := cmd.Flags().GetString("drive")
+	driveFlag = strings.TrimPrefix(driveFlag, drive.SharedDrivePrefix)
+	var driveID string
+	if driveFlag != "" {
+		if useIDFlag {
+			driveID = driveFlag
+		} else {
+			sd, err := ds.FindSharedDrive(ctx, driveFlag)
+			if err != nil {
+				return err
+			}
+			driveID = sd.Id
+		}
+	}
+
+	// Parse property filters if provided
+	propertyFlags, _ := cmd.Flags().GetStringArray("property")
+	properties, err := drive.ParseProperties(propertyFlags)
+	if err != nil {
+		return err
+	}
+	appPropertyFlags, _ := cmd.Flags().GetStringArray("app-property")
+	appProperties, err := drive.ParseProperties(appPropertyFlags)
+	if err != nil {
+		return err
+	}
+
+	label := query
+	if label == "" {
+		label = "any name"
+	}
+	if !structuredOutput() {
 		switch {
 		case len(fileTypes) > 0 && parentFlag != "":
-			color.Cyan("Searching for: %s (types: %s, parent: %s)", query, strings.Join(fileTypes, ", "), parentFlag)
+			color.Cyan("Searching for: %s (types: %s, parent: %s)", label, strings.Join(fileTypes, ", "), parentFlag)
 		case len(fileTypes) > 0:
-			color.Cyan("Searching for: %s (types: %s)", query, strings.Join(fileTypes, ", "))
+			color.Cyan("Searching for: %s (types: %s)", label, strings.Join(fileTypes, ", "))
 		case parentFlag != "":
-			color.Cyan("Searching for: %s (parent: %s)", query, parentFlag)
+			color.Cyan("Searching for: %s (parent: %s)", label, parentFlag)
 		default:
-			color.Cyan("Searching for: %s", query)
+			color.Cyan("Searching for: %s", label)
 		}
 	}
 
 	// Search for files
-	items, err := ds.SearchFiles(query, fileTypes, parentID, maxResults)
+	opts.FileTypes = fileTypes
+	opts.ParentID = parentID
+	opts.DriveID = driveID
+	opts.MaxResults = maxResults
+	opts.Properties = properties
+	opts.AppProperties = appProperties
+	items, err := ds.SearchFiles(ctx, query, opts)
 	if err != nil {
 		return err
 	}
 
-	if jsonFlag {
-		// Emit a stable, machine-readable JSON array (untruncated names).
-		type jsonItem struct {
-			ID           string `json:"id"`
-			Name         string `json:"name"`
-			MimeType     string `json:"mimeType"`
-			ModifiedTime string `json:"modifiedTime,omitempty"`
-			Size         int64  `json:"size,omitempty"`
+	if structuredOutput() {
+		records := make([]fileRecord, 0, len(items))
+		for _, item := range items {
+			records = append(records, newFileRecord(item, ""))
 		}
-		out := make([]jsonItem, 0, len(items))
-		for _, it := range items {
-			out = append(out, jsonItem{
-				ID:           it.Id,
-				Name:         it.Name,
-				MimeType:     it.MimeType,
-				ModifiedTime: it.ModifiedTime,
-				Size:         it.Size,
-			})
-		}
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(out)
+		return printRecords(records)
 	}
 
 	if len(items) == 0 {
@@ -1770,7 +2614,7 @@ func runSearch(cmd *cobra.Command, args []string) error {
 	}
 
 	// Print header
-	fmt.Printf("\nSearch Results for '%s'\n", query)
+	fmt.Printf("\nSearch Results for '%s'\n", label)
 	fmt.Println(strings.Repeat("─", 120))
 	fmt.Printf("%-10s %-40s %-44s %-20s %12s\n", "Type", "Name", "ID", "Modified", "Size")
 	fmt.Println(strings.Repeat("─", 120))
@@ -1787,17 +2631,26 @@ func runSearch(cmd *cobra.Command, args []string) error {
 }
 
 func runActivityChanges(cmd *cobra.Command, args []string) error {
-	ds, err := getDriveService(cmd.Context())
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
 	if err != nil {
 		return err
 	}
 
 	// Get changes
-	changes, err := ds.ListChanges(maxResults)
+	changes, err := ds.ListChanges(ctx, maxResults)
 	if err != nil {
 		return err
 	}
 
+	if structuredOutput() {
+		records := make([]changeRecord, 0, len(changes))
+		for _, change := range changes {
+			records = append(records, newChangeRecord(change))
+		}
+		return printRecords(records)
+	}
+
 	if len(changes) == 0 {
 		fmt.Println("No recent changes found")
 		return nil
@@ -1845,7 +2698,8 @@ func runActivityChanges(cmd *cobra.Command, args []string) error {
 }
 
 func runActivityRevisions(cmd *cobra.Command, args []string) error {
-	ds, err := getDriveService(cmd.Context())
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
 	if err != nil {
 		return err
 	}
@@ -1860,12 +2714,12 @@ func runActivityRevisions(cmd *cobra.Command, args []string) error {
 		dir := filepath.Dir(filePath)
 		filename := filepath.Base(filePath)
 
-		parentID, err := ds.ResolvePath(dir, true)
+		parentID, err := ds.ResolvePath(ctx, dir, true)
 		if err != nil {
 			return fmt.Errorf("parent folder not found: %v", err)
 		}
 
-		file, err := ds.FindFile(filename, parentID)
+		file, err := ds.FindFile(ctx, filename, parentID)
 		if err != nil {
 			return err
 		}
@@ -1876,17 +2730,25 @@ func runActivityRevisions(cmd *cobra.Command, args []string) error {
 	}
 
 	// Get file info
-	fileInfo, err := ds.GetFileInfo(fileID)
+	fileInfo, err := ds.GetFileInfo(ctx, fileID)
 	if err != nil {
 		return fmt.Errorf("unable to get file info: %v", err)
 	}
 
 	// Get revisions
-	revisions, err := ds.ListRevisions(fileID)
+	revisions, err := ds.ListRevisions(ctx, fileID)
 	if err != nil {
 		return err
 	}
 
+	if structuredOutput() {
+		records := make([]revisionRecord, 0, len(revisions))
+		for i := len(revisions) - 1; i >= 0; i-- {
+			records = append(records, newRevisionRecord(fileID, revisions[i]))
+		}
+		return printRecords(records)
+	}
+
 	if len(revisions) == 0 {
 		fmt.Println("No revisions found for this file")
 		return nil
@@ -1897,19 +2759,21 @@ func runActivityRevisions(cmd *cobra.Command, args []string) error {
 	fmt.Printf("File ID: %s\n", fileID)
 	fmt.Printf("Path: %v\n\n", fileInfo.Path)
 
+	// Revision IDs are shown in full since the revision commands take them.
+	// Those of binary files are much longer than those of Workspace files.
+	idWidth := len("Revision ID")
+	for _, rev := range revisions {
+		idWidth = max(idWidth, len(rev.ID))
+	}
+
 	// Display header
-	fmt.Printf("%-15s %-25s %-15s %-30s %-10s\n", "Revision ID", "Modified Time", "Size", "Modified By", "Keep")
-	fmt.Println(strings.Repeat("-", 100))
+	fmt.Printf("%-*s %-25s %-15s %-30s %-10s\n", idWidth, "Revision ID", "Modified Time", "Size", "Modified By", "Keep")
+	fmt.Println(strings.Repeat("-", idWidth+85))
 
 	// Display revisions (reverse order - newest first)
 	for i := len(revisions) - 1; i >= 0; i-- {
 		rev := revisions[i]
 
-		revID := rev.ID
-		if len(revID) > 15 {
-			revID = revID[:12] + "..."
-		}
-
 		modifiedTime := rev.ModifiedTime.Format("2006-01-02 15:04:05")
 
 		sizeStr := formatSize(rev.Size)
@@ -1927,7 +2791,7 @@ func runActivityRevisions(cmd *cobra.Command, args []string) error {
 			keepStr = color.GreenString("Yes")
 		}
 
-		fmt.Printf("%-15s %-25s %-15s %-30s %-10s\n", revID, modifiedTime, sizeStr, modifiedBy, keepStr)
+		fmt.Printf("%-*s %-25s %-15s %-30s %-10s\n", idWidth, rev.ID, modifiedTime, sizeStr, modifiedBy, keepStr)
 	}
 
 	fmt.Printf("\nTotal: %d revisions\n", len(revisions))
@@ -1938,17 +2802,22 @@ func runActivityRevisions(cmd *cobra.Command, args []string) error {
 }
 
 func runActivityDeleted(cmd *cobra.Command, args []string) error {
-	ds, err := getDriveService(cmd.Context())
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
 	if err != nil {
 		return err
 	}
 
 	// Get deleted files
-	files, err := ds.ListTrashedFiles(daysBackFlag, maxResults)
+	files, err := ds.ListTrashedFiles(ctx, daysBackFlag, maxResults)
 	if err != nil {
 		return err
 	}
 
+	if structuredOutput() {
+		return printTrashedRecords(files)
+	}
+
 	if len(files) == 0 {
 		fmt.Printf("No deleted files found in the last %d days\n", daysBackFlag)
 		return nil
@@ -2000,18 +2869,28 @@ func runActivityDeleted(cmd *cobra.Command, args []string) error {
 }
 
 func runActivityHistory(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
 	// Get Activity service
-	activityService, err := auth.GetAuthenticatedActivityService(cmd.Context(), globalConfig)
+	activityService, err := auth.GetAuthenticatedActivityService(ctx, globalConfig)
 	if err != nil {
 		return err
 	}
 
 	// Query activities
-	activities, err := drive.QueryDriveActivity(activityService, daysBackFlag, maxResults)
+	activities, err := drive.QueryDriveActivity(ctx, activityService, daysBackFlag, maxResults)
 	if err != nil {
 		return err
 	}
 
+	if structuredOutput() {
+		records := make([]activityRecord, 0, len(activities))
+		for _, activity := range activities {
+			records = append(records, newActivityRecord(activity))
+		}
+		return printRecords(records)
+	}
+
 	if len(activities) == 0 {
 		fmt.Printf("No activities found in the last %d days\n", daysBackFlag)
 		return nil
@@ -0,0 +1,805 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	driveapi "google.golang.org/api/drive/v3"
+	"gopkg.in/yaml.v3"
+
+	"gdrive/internal/auth"
+	"gdrive/internal/drive"
+	"gdrive/internal/drivetest"
+)
+
+// runCLI runs gdrive with args against srv. Every run starts from a fresh
+// command tree so flag variables are reset to their defaults.
+func runCLI(t *testing.T, srv *drivetest.Server, args ...string) error {
+	t.Helper()
+
+	origDrive := driveServiceOverride
+	driveServiceOverride = func(ctx context.Context) (*drive.Service, error) {
+		ds := drive.NewService(srv.API(t))
+		ds.HTTP = srv.Client()
+		return ds, nil
+	}
+	t.Cleanup(func() { driveServiceOverride = origDrive })
+
+	root := &cobra.Command{Use: "gdrive", SilenceUsage: true, SilenceErrors: true}
+	SetupRootCommand(root)
+	root.AddCommand(FileCmd(), FolderCmd(), TrashCmd(), SearchCmd(), FormatsCmd(), VerifyCmd(), ActivityCmd(), CommentsCmd(), AuditCmd(), ShareCmd())
+	root.SetArgs(append([]string{"--config-dir", t.TempDir(), "--path-cache", "off"}, args...))
+	// The post-run hook that restores stdout does not run on errors
+	defer resetOutput()
+	return root.ExecuteContext(t.Context())
+}
+
+// runCLIOutput runs gdrive like runCLI and returns the records it printed.
+func runCLIOutput(t *testing.T, srv *drivetest.Server, args ...string) (string, error) {
+	t.Helper()
+	var buf bytes.Buffer
+	orig := recordOutput
+	recordOutput = &buf
+	defer func() { recordOutput = orig }()
+	err := runCLI(t, srv, args...)
+	return buf.String(), err
+}
+
+// answerPrompts makes confirmation prompts read answers until the test
+// ends.
+func answerPrompts(t *testing.T, answers string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "answers")
+	if err := os.WriteFile(path, []byte(answers), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	in, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdin := os.Stdin
+	os.Stdin = in
+	t.Cleanup(func() {
+		os.Stdin = stdin
+		in.Close()
+	})
+}
+
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestFolderUploadDownloadVerify(t *testing.T) {
+	srv := drivetest.NewServer(t)
+	src := writeTree(t, map[string]string{
+		"a.txt":       "alpha",
+		"sub/b.txt":   "bravo",
+		"sub/c/d.bin": "delta",
+	})
+
+	if err := runCLI(t, srv, "folder", "create", "Backup"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runCLI(t, srv, "folder", "upload", src, "Backup"); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := t.TempDir()
+	if err := runCLI(t, srv, "folder", "download", "Backup", dst); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{"a.txt": "alpha", "sub/b.txt": "bravo", "sub/c/d.bin": "delta"} {
+		got, err := os.ReadFile(filepath.Join(dst, name))
+		if err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v; want %q", name, got, err, want)
+		}
+	}
+
+	if err := runCLI(t, srv, "verify", dst, "Backup"); err != nil {
+		t.Errorf("verify after download: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dst, "sub", "b.txt"), []byte("changed"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runCLI(t, srv, "verify", dst, "Backup"); err == nil {
+		t.Error("verify did not report the modified file")
+	}
+}
+
+func TestFolderDownloadExportsWorkspaceFiles(t *testing.T) {
+	srv := drivetest.NewServer(t)
+	team := srv.AddFolder(drivetest.RootID, "Team")
+	doc := srv.AddDoc(team, "Plan v1.2", "application/vnd.google-apps.document", []byte("# Plan"))
+	srv.AddDoc(team, "Budget", "application/vnd.google-apps.spreadsheet", []byte("a,b"))
+
+	dst := t.TempDir()
+	if err := runCLI(t, srv, "folder", "download", "Team", dst, "--export", "doc=md"); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{"Plan v1.2.md": "# Plan", "Budget.xlsx": "a,b"} {
+		got, err := os.ReadFile(filepath.Join(dst, name))
+		if err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v; want %q", name, got, err, want)
+		}
+	}
+
+	// Unchanged on Drive: --new-only leaves the export alone
+	if err := os.WriteFile(filepath.Join(dst, "Plan v1.2.md"), []byte("local"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	modified, _ := time.Parse(time.RFC3339, srv.File(doc).ModifiedTime)
+	if err := os.Chtimes(filepath.Join(dst, "Plan v1.2.md"), modified, modified); err != nil {
+		t.Fatal(err)
+	}
+	if err := runCLI(t, srv, "folder", "download", "Team", dst, "--export", "doc=md", "--new-only"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dst, "Plan v1.2.md")); string(got) != "local" {
+		t.Errorf("unchanged doc exported again: %q", got)
+	}
+
+	// Edited on Drive: --new-only exports it again
+	srv.Update(doc, []byte("# Plan v2"))
+	if err := runCLI(t, srv, "folder", "download", "Team", dst, "--export", "doc=md", "--new-only", "--overwrite"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dst, "Plan v1.2.md")); string(got) != "# Plan v2" {
+		t.Errorf("edited doc not exported again: %q", got)
+	}
+
+	if err := runCLI(t, srv, "folder", "download", "Team", dst, "--export", "doc=xlsx"); err == nil {
+		t.Error("an invalid --export mapping should fail")
+	}
+}
+
+func TestFormatsAndExportValidation(t *testing.T) {
+	srv := drivetest.NewServer(t)
+	docs := srv.AddFolder(drivetest.RootID, "Docs")
+	srv.AddDoc(docs, "Plan", "application/vnd.google-apps.document", []byte("plan"))
+	dst := t.TempDir()
+
+	for _, args := range [][]string{{"formats"}, {"formats", "--refresh"}, {"formats", "--json"}, {"file", "info", "Docs/Plan"}} {
+		if err := runCLI(t, srv, args...); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+	}
+
+	if err := runCLI(t, srv, "file", "download", "Docs/Plan", dst, "--format", "epub"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dst, "Plan.epub")); string(got) != "plan" {
+		t.Errorf("epub export = %q", got)
+	}
+	if err := runCLI(t, srv, "file", "download", "Docs/Plan", dst, "--format", "bogus"); err == nil {
+		t.Error("an unsupported --format should fail")
+	}
+}
+
+func TestShortcuts(t *testing.T) {
+	srv := drivetest.NewServer(t)
+	team := srv.AddFolder(drivetest.RootID, "Team")
+	shared := srv.AddFolder(drivetest.RootID, "Shared")
+	srv.AddFile(shared, "budget.csv", []byte("1,2"))
+	srv.AddShortcut(team, "Budget", shared)
+	srv.AddShortcut(shared, "Back to Team", team)
+
+	if err := runCLI(t, srv, "file", "shortcut", "Shared/budget.csv", "Team", "--name", "budget link"); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"folder", "list", "Team"}, {"file", "info", "Team/budget link"}} {
+		if err := runCLI(t, srv, args...); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+	}
+
+	// Shortcuts are downloaded as their target; the one leading back to
+	// Team is skipped instead of recursing forever
+	dst := t.TempDir()
+	if err := runCLI(t, srv, "folder", "download", "Team", dst); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"budget link", "Budget/budget.csv"} {
+		if got, _ := os.ReadFile(filepath.Join(dst, name)); string(got) != "1,2" {
+			t.Errorf("%s = %q", name, got)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dst, "Budget", "Back to Team")); err == nil {
+		t.Error("shortcut to an ancestor was downloaded")
+	}
+
+	dst = t.TempDir()
+	if err := runCLI(t, srv, "--no-follow-shortcuts", "folder", "download", "Team", dst); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(dst); len(entries) != 0 {
+		t.Errorf("shortcuts downloaded with --no-follow-shortcuts: %v", entries)
+	}
+	if err := runCLI(t, srv, "--no-follow-shortcuts", "folder", "list", "Team/Budget"); err == nil {
+		t.Error("path through a shortcut resolved with --no-follow-shortcuts")
+	}
+}
+
+func TestFileMeta(t *testing.T) {
+	srv := drivetest.NewServer(t)
+	reports := srv.AddFolder(drivetest.RootID, "Reports")
+	id := srv.AddFile(reports, "q3.pdf", []byte("%PDF"))
+
+	for _, args := range [][]string{
+		{"file", "meta", "set", "Reports/q3.pdf", "project=ACME-42", "state=draft", "--description", "Q3 figures", "--starred"},
+		{"file", "meta", "set", "Reports/q3.pdf", "pipeline=ingested", "--app"},
+		{"file", "meta", "unset", "Reports/q3.pdf", "state", "--starred"},
+		{"file", "meta", "get", id, "--id", "--json"},
+		{"file", "info", "Reports/q3.pdf"},
+		{"search", "q3", "--property", "project=ACME-42", "--app-property", "pipeline=ingested"},
+	} {
+		if err := runCLI(t, srv, args...); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+	}
+	f := srv.File(id)
+	if f.Description != "Q3 figures" || f.Starred || len(f.Properties) != 1 || f.Properties["project"] != "ACME-42" || f.AppProperties["pipeline"] != "ingested" {
+		t.Errorf("metadata = %q %v %v %v", f.Description, f.Starred, f.Properties, f.AppProperties)
+	}
+
+	for _, args := range [][]string{
+		{"file", "meta", "set", "Reports/q3.pdf"},
+		{"file", "meta", "set", "Reports/q3.pdf", "project"},
+		{"search", "q3", "--property", "project"},
+	} {
+		if err := runCLI(t, srv, args...); err == nil {
+			t.Errorf("%v should fail", args)
+		}
+	}
+}
+
+func TestSearchFilters(t *testing.T) {
+	srv := drivetest.NewServer(t)
+	srv.AddFile(drivetest.RootID, "invoice.pdf", []byte("purchase order"))
+	srv.AddFile(drivetest.RootID, "clip.mp4", make([]byte, 2048))
+
+	for _, args := range [][]string{
+		{"search", "--fulltext", "purchase", "--modified-within", "7d", "--type", "pdf"},
+		{"search", "invoice", "--modified-after", "2020-01-01", "--created-before", "2100-01-01T00:00:00Z"},
+		{"search", "--owner", "me", "--visibility", "limited", "--sort", "name", "--reverse"},
+		{"search", "--starred", "--shared-with-me", "--shared-by", "alice@example.com"},
+		{"search", "--min-size", "1K", "--max-size", "1G", "--sort", "size", "--json"},
+	} {
+		if err := runCLI(t, srv, args...); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"search", "--modified-after", "last week"},
+		{"search", "--modified-after", "2026-01-01", "--modified-within", "7d"},
+		{"search", "--created-within", "soon"},
+		{"search", "--min-size", "big"},
+		{"search", "--sort", "owner"},
+		{"search", "--fulltext", "order", "--sort", "name"},
+		{"search", "--visibility", "public"},
+	} {
+		if err := runCLI(t, srv, args...); err == nil {
+			t.Errorf("%v should fail", args)
+		}
+	}
+}
+
+func TestOutputFormats(t *testing.T) {
+	srv := drivetest.NewServer(t)
+	reports := srv.AddFolder(drivetest.RootID, "Reports")
+	long := "Quarterly report for the board, with a name tables truncate.pdf"
+	longID := srv.AddFile(reports, long, []byte("%PDF-1.7"))
+	srv.AddFile(reports, "q3.pdf", []byte("%PDF"))
+
+	out, err := runCLIOutput(t, srv, "folder", "list", "Reports", "--output", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var files []fileRecord
+	if err := json.Unmarshal([]byte(out), &files); err != nil {
+		t.Fatalf("json output %q: %v", out, err)
+	}
+	if len(files) != 2 || files[0].ID != longID || files[0].Name != long || files[0].Path != "Reports/"+long || files[0].Size != 8 {
+		t.Errorf("json records = %+v", files)
+	}
+	if _, err := time.Parse(time.RFC3339, files[0].ModifiedTime); err != nil {
+		t.Errorf("modifiedTime: %v", err)
+	}
+
+	out, err = runCLIOutput(t, srv, "folder", "list", "Reports", "-o", "ndjson")
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); err != nil || len(lines) != 2 || !json.Valid([]byte(lines[1])) {
+		t.Errorf("ndjson output %q, %v", out, err)
+	}
+
+	out, err = runCLIOutput(t, srv, "folder", "list", "Reports", "-o", "csv")
+	rows, csvErr := csv.NewReader(strings.NewReader(out)).ReadAll()
+	if err != nil || csvErr != nil || len(rows) != 3 || rows[0][0] != "id" || rows[1][1] != long {
+		t.Errorf("csv output %q, %v, %v", out, err, csvErr)
+	}
+
+	out, err = runCLIOutput(t, srv, "folder", "list", "Reports", "-o", "yaml")
+	var items []map[string]any
+	if yamlErr := yaml.Unmarshal([]byte(out), &items); err != nil || yamlErr != nil || len(items) != 2 || items[1]["name"] != "q3.pdf" {
+		t.Errorf("yaml output %q, %v, %v", out, err, yamlErr)
+	}
+
+	out, err = runCLIOutput(t, srv, "folder", "list", "Reports", "-o", "template={{.Name}}:{{.Size}}")
+	if want := long + ":8\nq3.pdf:4\n"; err != nil || out != want {
+		t.Errorf("template output = %q, %v; want %q", out, err, want)
+	}
+
+	// Single items print an object; --json and GDRIVE_OUTPUT pick a format too
+	out, err = runCLIOutput(t, srv, "file", "info", "Reports/q3.pdf", "-o", "json")
+	var info fileInfoRecord
+	if jsonErr := json.Unmarshal([]byte(out), &info); err != nil || jsonErr != nil || !strings.HasSuffix(info.Path, "Reports/q3.pdf") || info.Owners == nil {
+		t.Errorf("file info output %q, %v, %v", out, err, jsonErr)
+	}
+	out, err = runCLIOutput(t, srv, "search", "q3", "--json")
+	if err != nil || !strings.HasPrefix(out, "[") || !strings.Contains(out, `"name": "q3.pdf"`) {
+		t.Errorf("search --json output %q, %v", out, err)
+	}
+	for _, args := range [][]string{
+		{"file", "permissions", "Reports/q3.pdf"},
+		{"file", "meta", "get", "Reports/q3.pdf"},
+		{"activity", "revisions", "Reports/q3.pdf"},
+		{"activity", "deleted"},
+		{"trash", "list"},
+		{"formats"},
+	} {
+		out, err := runCLIOutput(t, srv, append(args, "-o", "json")...)
+		if err != nil || !json.Valid([]byte(out)) {
+			t.Errorf("%v: output %q, %v", args, out, err)
+		}
+	}
+	t.Setenv("GDRIVE_OUTPUT", "template={{.ID}}")
+	local := writeTree(t, map[string]string{"notes.txt": "notes"})
+	out, err = runCLIOutput(t, srv, "file", "upload", filepath.Join(local, "notes.txt"), "Reports")
+	if f := srv.File(strings.TrimSpace(out)); err != nil || f == nil || f.Name != "notes.txt" {
+		t.Errorf("upload output %q, %v", out, err)
+	}
+
+	for _, spec := range []string{"xml", "json=pretty", "template=", "template={{.Name", "template={{.Nope}}"} {
+		if err := runCLI(t, srv, "folder", "list", "Reports", "-o", spec); err == nil {
+			t.Errorf("--output %q should fail", spec)
+		}
+	}
+}
+
+func TestFileRenameMoveCopy(t *testing.T) {
+	srv := drivetest.NewServer(t)
+	docs := srv.AddFolder(drivetest.RootID, "Docs")
+	archive := srv.AddFolder(drivetest.RootID, "Archive")
+	id := srv.AddFile(docs, "draft.txt", []byte("text"))
+
+	if err := runCLI(t, srv, "file", "rename", "Docs/draft.txt", "final.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runCLI(t, srv, "file", "move", "Docs/final.txt", "Archive"); err != nil {
+		t.Fatal(err)
+	}
+	if f := srv.File(id); f.Name != "final.txt" || len(f.Parents) != 1 || f.Parents[0] != archive {
+		t.Fatalf("after rename and move: name %q parents %v", f.Name, f.Parents)
+	}
+
+	if err := runCLI(t, srv, "file", "copy", "Archive/final.txt", "copy.txt", "--parent", "Docs"); err != nil {
+		t.Fatal(err)
+	}
+	children := srv.Children(docs)
+	if len(children) != 1 || srv.File(children[0]).Name != "copy.txt" {
+		t.Errorf("Docs children = %v", children)
+	}
+}
+
+func TestFileDeleteTrashesAndRestores(t *testing.T) {
+	srv := drivetest.NewServer(t)
+	docs := srv.AddFolder(drivetest.RootID, "Docs")
+	id := srv.AddFile(docs, "old.txt", []byte("text"))
+
+	if err := runCLI(t, srv, "file", "delete", "Docs/old.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if f := srv.File(id); f == nil || !f.Trashed {
+		t.Fatalf("file not in the trash: %+v", f)
+	}
+
+	if err := runCLI(t, srv, "trash", "restore", "Docs/old.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if f := srv.File(id); f.Trashed || f.Parents[0] != docs {
+		t.Errorf("after restore: trashed=%v parents=%v", f.Trashed, f.Parents)
+	}
+	if err := runCLI(t, srv, "trash", "restore", "Docs/missing.txt"); err == nil {
+		t.Error("restoring an item that is not in the trash should fail")
+	}
+}
+
+func TestRevisionsDownloadRestorePin(t *testing.T) {
+	srv := drivetest.NewServer(t)
+	docs := srv.AddFolder(drivetest.RootID, "Docs")
+	id := srv.AddFile(docs, "notes.txt", []byte("v1"))
+	srv.Update(id, []byte("v2"))
+	first := srv.Revisions(id)[0].Id
+
+	dst := t.TempDir()
+	if err := runCLI(t, srv, "activity", "revisions", "download", "Docs/notes.txt", first, dst); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := os.ReadFile(filepath.Join(dst, "notes-rev"+first+".txt")); err != nil || string(got) != "v1" {
+		t.Errorf("downloaded revision = %q, %v", got, err)
+	}
+
+	if err := runCLI(t, srv, "activity", "revisions", "restore", "Docs/notes.txt", first); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(srv.Content(id)); got != "v1" {
+		t.Errorf("content after restore = %q", got)
+	}
+
+	if err := runCLI(t, srv, "activity", "revisions", "pin", id, first, "--id"); err != nil {
+		t.Fatal(err)
+	}
+	if !srv.Revisions(id)[0].KeepForever {
+		t.Error("revision not pinned")
+	}
+	if err := runCLI(t, srv, "activity", "revisions", "unpin", id, first, "--id"); err != nil {
+		t.Fatal(err)
+	}
+	if srv.Revisions(id)[0].KeepForever {
+		t.Error("revision still pinned")
+	}
+}
+
+func TestComments(t *testing.T) {
+	srv := drivetest.NewServer(t)
+	notes := srv.AddFolder(drivetest.RootID, "Notes")
+	id := srv.AddDoc(notes, "plan", "application/vnd.google-apps.document", []byte("The launch is in May."))
+	review := srv.AddComment(id, driveapi.User{DisplayName: "Alice"}, "Is May still realistic?", "launch is in May")
+
+	for _, args := range [][]string{
+		{"comments", "add", "Notes/plan", "Add the budget", "--quote", "The launch"},
+		{"comments", "reply", "Notes/plan", review, "Yes, QA signed off"},
+		{"comments", "resolve", id, review, "--id"},
+		{"comments", "list", "Notes/plan"},
+	} {
+		if err := runCLI(t, srv, args...); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+	}
+
+	list := func(args ...string) []commentRecord {
+		t.Helper()
+		out, err := runCLIOutput(t, srv, append([]string{"comments", "list", "Notes/plan", "--json"}, args...)...)
+		var records []commentRecord
+		if err != nil || json.Unmarshal([]byte(out), &records) != nil {
+			t.Fatalf("comments list %v = %q, %v", args, out, err)
+		}
+		return records
+	}
+	all := list()
+	if len(all) != 2 || !all[0].Resolved || all[0].QuotedText != "launch is in May" || len(all[0].Replies) != 2 || all[1].QuotedText != "The launch" {
+		t.Fatalf("comments = %+v", all)
+	}
+	if open := list("--unresolved"); len(open) != 1 || open[0].ID != all[1].ID {
+		t.Errorf("unresolved = %+v", open)
+	}
+	if mine := list("--mine"); len(mine) != 1 || !mine[0].Mine {
+		t.Errorf("mine = %+v", mine)
+	}
+
+	if err := runCLI(t, srv, "comments", "resolve", "Notes/plan", review, "--reopen"); err != nil {
+		t.Fatal(err)
+	}
+	if open := list("--unresolved"); len(open) != 2 {
+		t.Errorf("unresolved after reopen = %+v", open)
+	}
+
+	answerPrompts(t, "y\n")
+	if err := runCLI(t, srv, "comments", "delete", "Notes/plan", all[1].ID); err != nil {
+		t.Fatal(err)
+	}
+	if left := list(); len(left) != 1 || left[0].ID != review {
+		t.Errorf("comments after delete = %+v", left)
+	}
+}
+
+func TestFileShareTypes(t *testing.T) {
+	srv := drivetest.NewServer(t)
+	reports := srv.AddFolder(drivetest.RootID, "Reports")
+	id := srv.AddFile(reports, "q3.pdf", []byte("%PDF"))
+
+	for _, args := range [][]string{
+		{"file", "share", "Reports/q3.pdf", "team@example.com", "--type", "group", "--role", "commenter", "--expires", "2w"},
+		{"file", "share", "Reports/q3.pdf", "example.com", "--type", "domain", "--discoverable"},
+		{"file", "share", "Reports/q3.pdf", "bob@example.com", "--expires", "2099-12-31", "--no-notify"},
+		{"file", "share", "Reports/q3.pdf", "carol@example.com", "--role", "owner"},
+	} {
+		if err := runCLI(t, srv, args...); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+	}
+
+	perms := map[string]*driveapi.Permission{}
+	for _, p := range srv.Permissions(id) {
+		perms[p.EmailAddress+p.Domain] = p
+	}
+	if g := perms["team@example.com"]; g == nil || g.Type != "group" || g.Role != "commenter" || g.ExpirationTime == "" {
+		t.Errorf("group permission = %+v", g)
+	}
+	if d := perms["example.com"]; d == nil || d.Type != "domain" || !d.AllowFileDiscovery {
+		t.Errorf("domain permission = %+v", d)
+	}
+	if c := perms["carol@example.com"]; c == nil || c.Role != "writer" || !c.PendingOwner {
+		t.Errorf("new owner permission = %+v", c)
+	}
+
+	bob := perms["bob@example.com"]
+	out, err := runCLIOutput(t, srv, "file", "update-permission", "Reports/q3.pdf", bob.Id, "--role", "writer", "--no-expiration", "--output", "json")
+	var record permissionRecord
+	if err != nil || json.Unmarshal([]byte(out), &record) != nil {
+		t.Fatalf("update-permission = %q, %v", out, err)
+	}
+	if record.Role != "writer" || record.ExpirationTime != "" || record.EmailAddress != "bob@example.com" {
+		t.Errorf("updated permission = %+v", record)
+	}
+
+	for _, args := range [][]string{
+		{"file", "share", "Reports/q3.pdf", "example.com", "--type", "domain", "--expires", "7d"},
+		{"file", "share", "Reports/q3.pdf", "team@example.com", "--type", "group", "--role", "owner"},
+		{"file", "share", "Reports/q3.pdf", "dan@example.com", "--expires", "someday"},
+		{"file", "update-permission", "Reports/q3.pdf", bob.Id},
+	} {
+		if err := runCLI(t, srv, args...); err == nil {
+			t.Errorf("%v should fail", args)
+		}
+	}
+}
+
+func TestSharingPolicy(t *testing.T) {
+	srv := drivetest.NewServer(t)
+	confidential := srv.AddFolder(drivetest.RootID, "Confidential")
+	secret := srv.AddFile(confidential, "salaries.xlsx", []byte("1,2"))
+	srv.AddFile(srv.AddFolder(drivetest.RootID, "Marketing"), "flyer.pdf", []byte("%PDF"))
+
+	policy := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(policy, []byte("allowedDomains: [example.com]\nforbiddenAnyoneRoles: [writer]\nprotectedPaths: [Confidential]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(auth.EnvSharingPolicy, policy)
+
+	for _, args := range [][]string{
+		{"file", "share-public", "Marketing/flyer.pdf", "--role", "writer"},
+		{"file", "share", "Marketing/flyer.pdf", "someone@gmail.com", "--role", "writer"},
+		{"file", "share-public", "Confidential/salaries.xlsx"},
+	} {
+		err := runCLI(t, srv, args...)
+		if !errors.Is(err, drive.ErrPolicyDenied) || !strings.Contains(err.Error(), "--override-policy") {
+			t.Errorf("%v: err = %v, want a policy denial", args, err)
+		}
+	}
+
+	manifest := filepath.Join(t.TempDir(), "acme.yaml")
+	if err := os.WriteFile(manifest, []byte("- {path: Marketing/flyer.pdf, grants: [{principal: alice@example.com, role: reader}, {principal: anyone, role: writer}]}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runCLI(t, srv, "share", "apply", manifest); !errors.Is(err, drive.ErrPolicyDenied) {
+		t.Errorf("share apply: err = %v, want a policy denial", err)
+	}
+
+	if err := runCLI(t, srv, "file", "share-public", "Confidential/salaries.xlsx", "--override-policy"); err != nil {
+		t.Fatalf("share-public --override-policy: %v", err)
+	}
+	if perms := srv.Permissions(secret); len(perms) != 2 {
+		t.Errorf("permissions after override = %+v", perms)
+	}
+
+	if err := os.WriteFile(policy, []byte("allowedDomain: [example.com]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for name, path := range map[string]string{"broken": policy, "missing": filepath.Join(t.TempDir(), "missing.yaml")} {
+		t.Setenv(auth.EnvSharingPolicy, path)
+		if err := runCLI(t, srv, "file", "share-public", "Marketing/flyer.pdf"); err == nil {
+			t.Errorf("sharing with a %s policy should fail", name)
+		}
+		if err := runCLI(t, srv, "folder", "list", "Marketing"); err != nil {
+			t.Errorf("folder list with a %s policy: %v", name, err)
+		}
+		if err := runCLI(t, srv, "file", "share-public", "Marketing/flyer.pdf", "--override-policy"); err != nil {
+			t.Errorf("share-public --override-policy with a %s policy: %v", name, err)
+		}
+	}
+}
+
+func TestAuditSharing(t *testing.T) {
+	srv := drivetest.NewServer(t)
+	projects := srv.AddFolder(drivetest.RootID, "Projects")
+	acme := srv.AddFolder(projects, "ACME")
+	spec := srv.AddFile(acme, "spec.txt", []byte("spec"))
+	contractor := driveapi.Permission{Type: "user", Role: "writer", EmailAddress: "contractor@partner.com"}
+	srv.Share(acme, contractor)
+	srv.Share(spec, contractor)
+	srv.Share(spec, driveapi.Permission{Type: "anyone", Role: "reader"})
+
+	if err := runCLI(t, srv, "audit", "sharing", "Projects/ACME"); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := runCLIOutput(t, srv, "audit", "sharing", "Projects/ACME", "-o", "csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	if err != nil || len(rows) != 6 || rows[0][0] != "path" {
+		t.Fatalf("csv report = %q, %v", out, err)
+	}
+
+	out, err = runCLIOutput(t, srv, "audit", "sharing", acme, "--id", "--principal", "contractor@partner.com", "--json")
+	var records []sharingRecord
+	if err != nil || json.Unmarshal([]byte(out), &records) != nil || len(records) != 2 {
+		t.Fatalf("json report = %q, %v", out, err)
+	}
+	if direct := records[0]; direct.Path != "ACME" || direct.Inherited || !direct.External || !direct.Individual {
+		t.Errorf("direct grant = %+v", direct)
+	}
+	if inherited := records[1]; inherited.Path != "ACME/spec.txt" || !inherited.Inherited || inherited.InheritedFrom != acme {
+		t.Errorf("inherited grant = %+v", inherited)
+	}
+
+	answerPrompts(t, "y\n")
+	if err := runCLI(t, srv, "audit", "sharing", "Projects/ACME", "--remove", "contractor@partner.com"); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{acme, spec} {
+		for _, p := range srv.Permissions(id) {
+			if p.EmailAddress == "contractor@partner.com" {
+				t.Errorf("%s still shared with the contractor", id)
+			}
+		}
+	}
+	if len(srv.Permissions(spec)) != 2 {
+		t.Errorf("permissions of spec = %+v", srv.Permissions(spec))
+	}
+}
+
+func TestShareApply(t *testing.T) {
+	srv := drivetest.NewServer(t)
+	projects := srv.AddFolder(drivetest.RootID, "Projects")
+	acme := srv.AddFolder(projects, "ACME")
+	spec := srv.AddFile(acme, "spec.txt", []byte("spec"))
+	srv.Share(acme, driveapi.Permission{Type: "user", Role: "reader", EmailAddress: "alice@example.com"})
+	srv.Share(acme, driveapi.Permission{Type: "user", Role: "writer", EmailAddress: "old@example.com"})
+
+	dir := writeTree(t, map[string]string{
+		"acme.yaml": `
+- path: Projects/ACME
+  grants:
+    - {principal: alice@example.com, role: writer}
+    - {principal: team@example.com, type: group, role: commenter}
+`,
+		"spec.csv": "path,principal,role\nProjects/ACME/spec.txt,example.com,reader\n",
+		"acme.txt": "",
+	})
+	manifest := filepath.Join(dir, "acme.yaml")
+
+	apply := func(args ...string) []sharingChangeRecord {
+		t.Helper()
+		out, err := runCLIOutput(t, srv, append([]string{"share", "apply", "--output", "json"}, args...)...)
+		var records []sharingChangeRecord
+		if err != nil || json.Unmarshal([]byte(out), &records) != nil {
+			t.Fatalf("share apply %v = %q, %v", args, out, err)
+		}
+		return records
+	}
+
+	plan := apply(manifest, "--prune", "--dry-run")
+	if len(plan) != 3 || plan[0].Action != "update" || plan[0].PreviousRole != "reader" || plan[1].Action != "add" ||
+		plan[2].Action != "remove" || plan[2].Principal != "old@example.com" || plan[2].Status != "planned" {
+		t.Fatalf("plan = %+v", plan)
+	}
+	if len(srv.Permissions(acme)) != 3 {
+		t.Fatal("--dry-run changed permissions")
+	}
+
+	if applied := apply(manifest, "--no-notify"); len(applied) != 2 || applied[0].Status != "applied" || applied[1].Status != "applied" {
+		t.Errorf("applied = %+v", applied)
+	}
+	answerPrompts(t, "n\n")
+	if err := runCLI(t, srv, "share", "apply", manifest, "--prune"); err != nil {
+		t.Fatal(err)
+	}
+	if len(srv.Permissions(acme)) != 4 {
+		t.Fatal("declining the confirmation still removed permissions")
+	}
+	if applied := apply(manifest, "--prune", "--yes"); len(applied) != 1 || applied[0].Action != "remove" {
+		t.Errorf("applied with prune = %+v", applied)
+	}
+	if left := apply(manifest, "--prune"); len(left) != 0 {
+		t.Errorf("plan after apply = %+v", left)
+	}
+	if perms := srv.Permissions(acme); len(perms) != 3 {
+		t.Errorf("permissions = %+v", perms)
+	}
+
+	if err := runCLI(t, srv, "share", "apply", filepath.Join(dir, "spec.csv")); err != nil {
+		t.Fatal(err)
+	}
+	if perms := srv.Permissions(spec); len(perms) != 2 || perms[1].Type != "domain" {
+		t.Errorf("permissions of spec = %+v", perms)
+	}
+
+	if err := runCLI(t, srv, "share", "apply", filepath.Join(dir, "acme.txt")); err == nil {
+		t.Error("manifest of unknown type should be refused")
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{"30d", 30 * 24 * time.Hour, true},
+		{"2w", 14 * 24 * time.Hour, true},
+		{"36h", 36 * time.Hour, true},
+		{"0d", 0, true},
+		{"-1d", 0, false},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := parseAge(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("parseAge(%q) = %v, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	var ctxs []context.Context
+	record := func(cmd *cobra.Command, args []string) error {
+		ctxs = append(ctxs, cmd.Context())
+		return errors.New("failed")
+	}
+	root := &cobra.Command{Use: "gdrive", SilenceUsage: true, SilenceErrors: true}
+	SetupRootCommand(root)
+	root.AddCommand(
+		&cobra.Command{Use: "list", RunE: record},
+		&cobra.Command{Use: "serve", RunE: record, Annotations: map[string]string{noTimeout: "true"}},
+	)
+	// The post-run hook that restores stdout does not run on errors
+	defer resetOutput()
+
+	for _, name := range []string{"list", "serve"} {
+		root.SetArgs([]string{"--config-dir", t.TempDir(), "--timeout", "1h", name})
+		if err := root.ExecuteContext(t.Context()); err == nil {
+			t.Fatalf("%s: expected the error of the command", name)
+		}
+	}
+	if _, ok := ctxs[0].Deadline(); !ok {
+		t.Error("--timeout should bound the command")
+	}
+	if ctxs[0].Err() == nil {
+		t.Error("the deadline should be released when the command fails")
+	}
+	if _, ok := ctxs[1].Deadline(); ok {
+		t.Error("--timeout should not bound a server")
+	}
+}
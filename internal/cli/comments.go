@@ -0,0 +1,384 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	driveapi "google.golang.org/api/drive/v3"
+
+	"gdrive/internal/drive"
+)
+
+var (
+	unresolvedFlag bool
+	mineFlag       bool
+	quoteFlag      string
+	reopenFlag     bool
+)
+
+// CommentsCmd returns the comments command.
+func CommentsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "comments",
+		Short: "Read, add, answer and resolve comments on a file",
+		Long: `Commands for the comments of a Drive file, the ones reviewers leave in
+Google Docs, Sheets and Slides or on any file in the Drive viewer.
+
+Comments are identified by the IDs 'gdrive comments list' shows. A comment
+may quote the text it is about; resolving it closes the discussion, which
+stays readable and can be reopened.`,
+	}
+
+	cmd.AddCommand(commentsListCmd())
+	cmd.AddCommand(commentsAddCmd())
+	cmd.AddCommand(commentsReplyCmd())
+	cmd.AddCommand(commentsResolveCmd())
+	cmd.AddCommand(commentsDeleteCmd())
+
+	return cmd
+}
+
+func commentsListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list FILE",
+		Short: "List the comments of a file with their replies",
+		Long: `List the comments of a file, oldest first, with the text they quote, their
+author, whether they are resolved, and their replies.
+
+Examples:
+  gdrive comments list Notes/plan
+  gdrive comments list Notes/plan --unresolved
+  gdrive comments list 1a2b3c4d5e --id --mine --json`,
+		Args: cobra.ExactArgs(1),
+		RunE: runCommentsList,
+	}
+
+	cmd.Flags().BoolVar(&useIDFlag, "id", false, "Treat FILE as a Drive file ID")
+	cmd.Flags().BoolVar(&unresolvedFlag, "unresolved", false, "Only show comments that are not resolved")
+	cmd.Flags().BoolVar(&mineFlag, "mine", false, "Only show comments you wrote")
+	cmd.Flags().BoolVar(&jsonFlag, "json", false, "Output the comments as JSON, same as --output json")
+
+	return cmd
+}
+
+func commentsAddCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add FILE TEXT",
+		Short: "Comment on a file",
+		Long: `Add a comment to a file. --quote sets the text of the file the comment is
+about, which Drive shows along with it. The Docs editors only highlight
+the quoted text of comments made in the editor itself.
+
+Examples:
+  gdrive comments add Notes/plan "Please add the budget"
+  gdrive comments add Notes/plan "Is May still realistic?" --quote "launch is in May"`,
+		Args: cobra.ExactArgs(2),
+		RunE: runCommentsAdd,
+	}
+
+	cmd.Flags().BoolVar(&useIDFlag, "id", false, "Treat FILE as a Drive file ID")
+	cmd.Flags().StringVar(&quoteFlag, "quote", "", "Text of the file the comment is about")
+
+	return cmd
+}
+
+func commentsReplyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reply FILE COMMENT_ID TEXT",
+		Short: "Reply to a comment",
+		Long: `Reply to a comment listed by 'gdrive comments list'.
+
+Examples:
+  gdrive comments reply Notes/plan AAAAxyz "Done, see section 3"`,
+		Args: cobra.ExactArgs(3),
+		RunE: runCommentsReply,
+	}
+
+	cmd.Flags().BoolVar(&useIDFlag, "id", false, "Treat FILE as a Drive file ID")
+
+	return cmd
+}
+
+func commentsResolveCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resolve FILE COMMENT_ID [TEXT]",
+		Short: "Resolve a comment, or reopen it",
+		Long: `Mark a comment as resolved, optionally with a closing reply. With --reopen,
+open a resolved comment again.
+
+Examples:
+  gdrive comments resolve Notes/plan AAAAxyz
+  gdrive comments resolve Notes/plan AAAAxyz "Fixed in the new draft"
+  gdrive comments resolve Notes/plan AAAAxyz --reopen`,
+		Args: cobra.RangeArgs(2, 3),
+		RunE: runCommentsResolve,
+	}
+
+	cmd.Flags().BoolVar(&useIDFlag, "id", false, "Treat FILE as a Drive file ID")
+	cmd.Flags().BoolVar(&reopenFlag, "reopen", false, "Reopen the comment instead of resolving it")
+
+	return cmd
+}
+
+func commentsDeleteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete FILE COMMENT_ID",
+		Short: "Delete a comment",
+		Long: `Delete a comment and its replies. Only the author of a comment can delete
+it. This cannot be undone and asks for confirmation; resolving keeps the
+discussion instead.
+
+Examples:
+  gdrive comments delete Notes/plan AAAAxyz`,
+		Args: cobra.ExactArgs(2),
+		RunE: runCommentsDelete,
+	}
+
+	cmd.Flags().BoolVar(&useIDFlag, "id", false, "Treat FILE as a Drive file ID")
+
+	return cmd
+}
+
+// resolveCommentFile returns the file whose comments a command acts on.
+func resolveCommentFile(ctx context.Context, ds *drive.Service, arg string) (*driveapi.File, error) {
+	fileID := arg
+	if !useIDFlag {
+		var err error
+		if fileID, err = resolveRemoteFile(ctx, ds, arg); err != nil {
+			return nil, err
+		}
+	}
+	file, err := ds.Backend.GetFile(ctx, fileID, "id, name")
+	if err != nil {
+		return nil, fmt.Errorf("file not found: %v", err)
+	}
+	return file, nil
+}
+
+// commentRecord describes a comment and its replies.
+type commentRecord struct {
+	FileID       string        `json:"fileId"`
+	ID           string        `json:"id"`
+	Author       string        `json:"author"`
+	AuthorEmail  string        `json:"authorEmail"`
+	Mine         bool          `json:"mine"`
+	Content      string        `json:"content"`
+	QuotedText   string        `json:"quotedText"`
+	Anchor       string        `json:"anchor"`
+	Resolved     bool          `json:"resolved"`
+	CreatedTime  string        `json:"createdTime"`
+	ModifiedTime string        `json:"modifiedTime"`
+	Replies      []replyRecord `json:"replies"`
+}
+
+// replyRecord describes a reply to a comment. Action is "resolve" or
+// "reopen" for the replies that changed the state of the comment.
+type replyRecord struct {
+	ID          string `json:"id"`
+	Author      string `json:"author"`
+	AuthorEmail string `json:"authorEmail"`
+	Mine        bool   `json:"mine"`
+	Content     string `json:"content"`
+	Action      string `json:"action"`
+	CreatedTime string `json:"createdTime"`
+}
+
+func newCommentRecord(fileID string, c *drive.Comment) commentRecord {
+	r := commentRecord{
+		FileID:       fileID,
+		ID:           c.ID,
+		Author:       c.Author,
+		AuthorEmail:  c.AuthorEmail,
+		Mine:         c.Mine,
+		Content:      c.Content,
+		QuotedText:   c.QuotedText,
+		Anchor:       c.Anchor,
+		Resolved:     c.Resolved,
+		CreatedTime:  recordTime(c.CreatedTime),
+		ModifiedTime: recordTime(c.ModifiedTime),
+		Replies:      []replyRecord{},
+	}
+	for _, reply := range c.Replies {
+		r.Replies = append(r.Replies, replyRecord{
+			ID:          reply.ID,
+			Author:      reply.Author,
+			AuthorEmail: reply.AuthorEmail,
+			Mine:        reply.Mine,
+			Content:     reply.Content,
+			Action:      reply.Action,
+			CreatedTime: recordTime(reply.CreatedTime),
+		})
+	}
+	return r
+}
+
+// printComment prints a comment with its replies below it.
+func printComment(c *drive.Comment) {
+	state := ""
+	if c.Resolved {
+		state = color.GreenString(" [resolved]")
+	}
+	color.New(color.Bold).Printf("\n%s", c.ID)
+	fmt.Printf("  %s, %s%s\n", c.Author, c.CreatedTime.Format("2006-01-02 15:04:05"), state)
+	if c.QuotedText != "" {
+		fmt.Printf("  %s\n", color.New(color.Faint).Sprintf("> %s", c.QuotedText))
+	}
+	fmt.Printf("  %s\n", indentLines(c.Content, "  "))
+	for _, reply := range c.Replies {
+		text := reply.Content
+		switch {
+		case reply.Action == "resolve" && text == "":
+			text = color.New(color.Faint).Sprint("marked as resolved")
+		case reply.Action == "reopen" && text == "":
+			text = color.New(color.Faint).Sprint("reopened")
+		}
+		fmt.Printf("    ↳ %s: %s\n", reply.Author, indentLines(text, "      "))
+	}
+}
+
+// indentLines indents every line of s but the first.
+func indentLines(s, indent string) string {
+	return strings.ReplaceAll(s, "\n", "\n"+indent)
+}
+
+func runCommentsList(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
+	if err != nil {
+		return err
+	}
+
+	file, err := resolveCommentFile(ctx, ds, args[0])
+	if err != nil {
+		return err
+	}
+	comments, err := ds.ListComments(ctx, file.Id, drive.CommentFilter{Unresolved: unresolvedFlag, Mine: mineFlag})
+	if err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		records := make([]commentRecord, 0, len(comments))
+		for _, c := range comments {
+			records = append(records, newCommentRecord(file.Id, c))
+		}
+		return printRecords(records)
+	}
+
+	if len(comments) == 0 {
+		fmt.Println("No comments found")
+		return nil
+	}
+	color.Cyan("\n💬 Comments on %s", file.Name)
+	open := 0
+	for _, c := range comments {
+		printComment(c)
+		if !c.Resolved {
+			open++
+		}
+	}
+	fmt.Printf("\nTotal: %d comments, %d unresolved\n", len(comments), open)
+	return nil
+}
+
+func runCommentsAdd(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
+	if err != nil {
+		return err
+	}
+
+	file, err := resolveCommentFile(ctx, ds, args[0])
+	if err != nil {
+		return err
+	}
+	comment, err := ds.AddComment(ctx, file.Id, args[1], quoteFlag)
+	if err != nil {
+		return err
+	}
+
+	color.Green("✓ Commented on %s", file.Name)
+	fmt.Printf("  Comment ID: %s\n", comment.ID)
+	return printRecord(newCommentRecord(file.Id, comment))
+}
+
+func runCommentsReply(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
+	if err != nil {
+		return err
+	}
+
+	file, err := resolveCommentFile(ctx, ds, args[0])
+	if err != nil {
+		return err
+	}
+	if _, err := ds.ReplyToComment(ctx, file.Id, args[1], args[2]); err != nil {
+		return err
+	}
+
+	color.Green("✓ Replied to comment %s on %s", args[1], file.Name)
+	if !structuredOutput() {
+		return nil
+	}
+	comment, err := ds.GetComment(ctx, file.Id, args[1])
+	if err != nil {
+		return err
+	}
+	return printRecord(newCommentRecord(file.Id, comment))
+}
+
+func runCommentsResolve(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
+	if err != nil {
+		return err
+	}
+
+	file, err := resolveCommentFile(ctx, ds, args[0])
+	if err != nil {
+		return err
+	}
+	text := ""
+	if len(args) > 2 {
+		text = args[2]
+	}
+	comment, err := ds.ResolveComment(ctx, file.Id, args[1], text, reopenFlag)
+	if err != nil {
+		return err
+	}
+
+	if reopenFlag {
+		color.Green("✓ Reopened comment %s on %s", args[1], file.Name)
+	} else {
+		color.Green("✓ Resolved comment %s on %s", args[1], file.Name)
+	}
+	return printRecord(newCommentRecord(file.Id, comment))
+}
+
+func runCommentsDelete(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
+	if err != nil {
+		return err
+	}
+
+	file, err := resolveCommentFile(ctx, ds, args[0])
+	if err != nil {
+		return err
+	}
+
+	if !confirm(fmt.Sprintf("Delete comment %s on %s and its replies? This cannot be undone.", args[1], file.Name)) {
+		color.Yellow("Cancelled")
+		return nil
+	}
+	if err := ds.DeleteComment(ctx, file.Id, args[1]); err != nil {
+		return err
+	}
+
+	color.Green("✓ Deleted comment %s on %s", args[1], file.Name)
+	return printRecord(commentRecord{FileID: file.Id, ID: args[1], Replies: []replyRecord{}})
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// DrivesCmd returns the drives command.
+func DrivesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "drives",
+		Short: "Shared Drive operations",
+		Long: `Commands for working with Shared Drives.
+
+Any remote path can start with @DriveName to address a Shared Drive
+instead of My Drive, e.g. "@Engineering/Specs/design.pdf".`,
+	}
+
+	cmd.AddCommand(drivesListCmd())
+
+	return cmd
+}
+
+func drivesListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List Shared Drives you are a member of",
+		Long: `List all Shared Drives you are a member of, with their IDs.
+
+Examples:
+  gdrive drives list
+  gdrive drives list --json`,
+		Args: cobra.NoArgs,
+		RunE: runDrivesList,
+	}
+
+	cmd.Flags().BoolVar(&jsonFlag, "json", false, "Output results as JSON array, same as --output json")
+
+	return cmd
+}
+
+// driveRecord is the record of a Shared Drive.
+type driveRecord struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	CreatedTime string `json:"createdTime"`
+	Hidden      bool   `json:"hidden"`
+}
+
+func runDrivesList(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
+	if err != nil {
+		return err
+	}
+
+	drives, err := ds.ListSharedDrives(ctx)
+	if err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		records := make([]driveRecord, 0, len(drives))
+		for _, d := range drives {
+			records = append(records, driveRecord{
+				ID:          d.Id,
+				Name:        d.Name,
+				CreatedTime: driveTime(d.CreatedTime),
+				Hidden:      d.Hidden,
+			})
+		}
+		return printRecords(records)
+	}
+
+	if len(drives) == 0 {
+		color.Yellow("No Shared Drives found")
+		return nil
+	}
+
+	fmt.Println("\nShared Drives")
+	fmt.Println(strings.Repeat("─", 100))
+	fmt.Printf("%-40s %-36s %-20s\n", "Name", "ID", "Created")
+	fmt.Println(strings.Repeat("─", 100))
+
+	for _, d := range drives {
+		name := d.Name
+		if len(name) > 38 {
+			name = name[:35] + "..."
+		}
+		created := "N/A"
+		if t, err := time.Parse(time.RFC3339, d.CreatedTime); err == nil {
+			created = t.Format("2006-01-02 15:04")
+		}
+		fmt.Printf("%-40s %-36s %-20s\n", name, d.Id, created)
+	}
+
+	fmt.Println(strings.Repeat("─", 100))
+	fmt.Printf("\nTotal drives: %d\n", len(drives))
+
+	return nil
+}
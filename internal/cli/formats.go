@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"gdrive/internal/drive"
+)
+
+var refreshFlag bool
+
+// FormatsCmd returns the formats command.
+func FormatsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "formats",
+		Short: "List the formats Google Workspace files export to",
+		Long: `List the formats each Google Workspace type can be exported to, as
+reported by Drive, and the file types Drive converts to Workspace files on
+upload.
+
+The list is read from the Drive About API and cached in the config directory
+for a day; --refresh asks Drive again. The names in the Formats column are the
+values accepted by --format and --export, and the extensions given to
+exported files.
+
+Examples:
+  gdrive formats
+  gdrive formats --refresh
+  gdrive formats --json`,
+		Args: cobra.NoArgs,
+		RunE: runFormats,
+	}
+
+	cmd.Flags().BoolVar(&refreshFlag, "refresh", false, "Ask Drive again instead of using the cached list")
+	cmd.Flags().BoolVar(&jsonFlag, "json", false, "Output the formats as JSON, same as --output json")
+
+	return cmd
+}
+
+// formatList returns the export formats of mimeType, with the default one
+// marked.
+func formatList(catalog *drive.ExportCatalog, mimeType string) string {
+	def := catalog.DefaultFormat(mimeType)
+	names := catalog.FormatNames(mimeType)
+	for i, name := range names {
+		if name == def {
+			names[i] = name + " (default)"
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// formatRecord is the record of a Workspace type and its formats.
+type formatRecord struct {
+	Type     string   `json:"type"`
+	MimeType string   `json:"mimeType"`
+	Default  string   `json:"default"`
+	Formats  []string `json:"formats"`
+	Imports  []string `json:"importsFrom"`
+}
+
+func runFormats(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
+	if err != nil {
+		return err
+	}
+
+	catalog := ds.ExportCatalog(ctx)
+	if refreshFlag {
+		if catalog, err = ds.RefreshExportCatalog(ctx); err != nil {
+			return err
+		}
+	}
+
+	if structuredOutput() {
+		imports := catalog.ImportSources()
+		records := make([]formatRecord, 0, len(catalog.Exports))
+		for _, mimeType := range catalog.Types() {
+			records = append(records, formatRecord{
+				Type:     drive.WorkspaceTypeName(mimeType),
+				MimeType: mimeType,
+				Default:  catalog.DefaultFormat(mimeType),
+				Formats:  nonNilSlice(catalog.FormatNames(mimeType)),
+				Imports:  nonNilSlice(imports[mimeType]),
+			})
+		}
+		return printRecords(records)
+	}
+
+	source := "built-in list, Drive could not be reached"
+	if !catalog.Fetched.IsZero() {
+		source = "from Drive, " + catalog.Fetched.Local().Format("2006-01-02 15:04")
+	}
+	color.Cyan("\nExport formats (%s)", source)
+	fmt.Println(strings.Repeat("─", 100))
+	fmt.Printf("%-14s %-8s %s\n", "Type", "Default", "Formats")
+	fmt.Println(strings.Repeat("─", 100))
+	for _, mimeType := range catalog.Types() {
+		fmt.Printf("%-14s %-8s %s\n", drive.WorkspaceTypeName(mimeType), catalog.DefaultFormat(mimeType),
+			strings.Join(catalog.FormatNames(mimeType), ", "))
+	}
+	fmt.Println(strings.Repeat("─", 100))
+
+	imports := catalog.ImportSources()
+	if len(imports) == 0 {
+		return nil
+	}
+	color.Cyan("\nConverted on upload")
+	fmt.Println(strings.Repeat("─", 100))
+	for _, mimeType := range catalog.Types() {
+		if exts := imports[mimeType]; len(exts) > 0 {
+			fmt.Printf("%-14s %s\n", drive.WorkspaceTypeName(mimeType), strings.Join(exts, ", "))
+		}
+	}
+	fmt.Println(strings.Repeat("─", 100))
+
+	return nil
+}
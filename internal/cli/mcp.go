@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"gdrive/internal/auth"
 	"gdrive/internal/mcp"
 )
 
@@ -22,12 +24,14 @@ func MCPCmd() *cobra.Command {
 		vaultToken      string
 		vaultSecretPath string
 		credentialFile  string
+		toolTimeout     time.Duration
 	)
 
 	cmd := &cobra.Command{
-		Use:   "mcp",
-		Short: "Start MCP HTTP Streamable server",
-		Long:  "Start an MCP (Model Context Protocol) HTTP Streamable server that exposes Google Drive operations as MCP tools for AI agents",
+		Use:         "mcp",
+		Short:       "Start MCP HTTP Streamable server",
+		Long:        "Start an MCP (Model Context Protocol) HTTP Streamable server that exposes Google Drive operations as MCP tools for AI agents",
+		Annotations: map[string]string{outputNone: "true", noTimeout: "true"},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Environment variable fallbacks (for Cloud Run deployment)
 			if !cmd.Flags().Changed("port") {
@@ -62,6 +66,13 @@ func MCPCmd() *cobra.Command {
 					credentialFile = v
 				}
 			}
+			if !cmd.Flags().Changed("tool-timeout") {
+				if v := os.Getenv("TOOL_TIMEOUT"); v != "" {
+					if d, err := time.ParseDuration(v); err == nil {
+						toolTimeout = d
+					}
+				}
+			}
 
 			// Vault config from env vars (VPS deployment)
 			if vaultAddr == "" {
@@ -88,6 +99,10 @@ func MCPCmd() *cobra.Command {
 				VaultToken:      vaultToken,
 				VaultSecretPath: vaultSecretPath,
 				CredentialFile:  credentialFile,
+				ToolTimeout:     toolTimeout,
+				// The policy of the CLI, from --config-dir or GDRIVE_SHARING_POLICY
+				SharingPolicyFile:     globalConfig.GetSharingPolicyPath(),
+				SharingPolicyRequired: os.Getenv(auth.EnvSharingPolicy) != "",
 			}
 
 			srv, err := mcp.NewServer(cmd.Context(), cfg)
@@ -95,7 +110,7 @@ func MCPCmd() *cobra.Command {
 				return fmt.Errorf("create MCP server: %w", err)
 			}
 
-			return srv.Start()
+			return srv.Start(cmd.Context())
 		},
 	}
 
@@ -108,6 +123,7 @@ func MCPCmd() *cobra.Command {
 	cmd.Flags().StringVar(&vaultAddr, "vault-addr", "", "HashiCorp Vault address (env: VAULT_ADDR)")
 	cmd.Flags().StringVar(&vaultToken, "vault-token", "", "HashiCorp Vault token (env: VAULT_TOKEN)")
 	cmd.Flags().StringVar(&vaultSecretPath, "vault-secret-path", "", "Vault secret path (env: VAULT_SECRET_PATH)")
+	cmd.Flags().DurationVar(&toolTimeout, "tool-timeout", mcp.DefaultToolTimeout, "Deadline for a single tool call; slow tools get a longer allowance (env: TOOL_TIMEOUT)")
 
 	return cmd
 }
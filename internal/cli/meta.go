@@ -0,0 +1,250 @@
+package cli
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"gdrive/internal/drive"
+)
+
+var (
+	appFlag         bool
+	descriptionFlag string
+	starredFlag     bool
+)
+
+func fileMetaCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "meta",
+		Short: "Read and edit file properties, description and star",
+		Long: `Read and edit the metadata of a file besides its name: its description,
+whether it is starred, and its custom properties.
+
+Properties are key=value pairs that every app with access to the file can
+see; they can be searched with 'gdrive search --property'. With --app, the
+commands act on appProperties instead, which only gdrive's OAuth client can
+read. Drive allows 124 bytes per property, key and value together.`,
+	}
+
+	cmd.AddCommand(fileMetaGetCmd())
+	cmd.AddCommand(fileMetaSetCmd())
+	cmd.AddCommand(fileMetaUnsetCmd())
+
+	return cmd
+}
+
+func fileMetaGetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get FILE",
+		Short: "Show the description, star and properties of a file",
+		Long: `Show the description, starred flag, properties and appProperties of a file.
+
+Examples:
+  gdrive file meta get Reports/q3.pdf
+  gdrive file meta get 1a2b3c4d5e --id --json`,
+		Args: cobra.ExactArgs(1),
+		RunE: runFileMetaGet,
+	}
+
+	cmd.Flags().BoolVar(&useIDFlag, "id", false, "Treat FILE as a Drive file ID")
+	cmd.Flags().BoolVar(&jsonFlag, "json", false, "Output the metadata as JSON, same as --output json")
+
+	return cmd
+}
+
+func fileMetaSetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set FILE [KEY=VALUE...]",
+		Short: "Set properties, the description or the star of a file",
+		Long: `Set properties of a file, replacing the value of existing keys. Other
+properties are kept. --description and --starred change the description and
+the starred flag in the same call.
+
+Examples:
+  gdrive file meta set Reports/q3.pdf project=ACME-42 state=reviewed
+  gdrive file meta set Reports/q3.pdf pipeline=ingested --app
+  gdrive file meta set Reports/q3.pdf --description "Q3 figures, final" --starred
+  gdrive file meta set 1a2b3c4d5e --id --starred=false`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: runFileMetaSet,
+	}
+
+	cmd.Flags().BoolVar(&useIDFlag, "id", false, "Treat FILE as a Drive file ID")
+	cmd.Flags().BoolVar(&appFlag, "app", false, "Set appProperties instead of properties")
+	cmd.Flags().StringVar(&descriptionFlag, "description", "", "Set the description of the file")
+	cmd.Flags().BoolVar(&starredFlag, "starred", false, "Star the file, or unstar it with --starred=false")
+
+	return cmd
+}
+
+func fileMetaUnsetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unset FILE [KEY...]",
+		Short: "Remove properties, the description or the star of a file",
+		Long: `Remove properties of a file. Removing a key the file does not have is not
+an error. --description clears the description and --starred unstars the
+file.
+
+Examples:
+  gdrive file meta unset Reports/q3.pdf state
+  gdrive file meta unset Reports/q3.pdf pipeline --app
+  gdrive file meta unset Reports/q3.pdf --description --starred`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: runFileMetaUnset,
+	}
+
+	cmd.Flags().BoolVar(&useIDFlag, "id", false, "Treat FILE as a Drive file ID")
+	cmd.Flags().BoolVar(&appFlag, "app", false, "Remove appProperties instead of properties")
+	cmd.Flags().Bool("description", false, "Clear the description of the file")
+	cmd.Flags().Bool("starred", false, "Unstar the file")
+
+	return cmd
+}
+
+// resolveMetaFile returns the ID of the file a meta command acts on.
+func resolveMetaFile(cmd *cobra.Command, ds *drive.Service, arg string) (string, error) {
+	if useIDFlag {
+		return arg, nil
+	}
+	return resolveRemoteFile(cmd.Context(), ds, arg)
+}
+
+// printProperties prints a property map sorted by key, under title.
+func printProperties(title string, props map[string]string) {
+	if len(props) == 0 {
+		return
+	}
+	fmt.Printf("  %s:\n", title)
+	for _, key := range slices.Sorted(maps.Keys(props)) {
+		fmt.Printf("    %s = %s\n", key, props[key])
+	}
+}
+
+// metadataRecord is the record of the meta commands.
+type metadataRecord struct {
+	ID            string            `json:"id"`
+	Name          string            `json:"name"`
+	Description   string            `json:"description"`
+	Starred       bool              `json:"starred"`
+	Properties    map[string]string `json:"properties"`
+	AppProperties map[string]string `json:"appProperties"`
+}
+
+func newMetadataRecord(meta *drive.Metadata) metadataRecord {
+	return metadataRecord{
+		ID:            meta.ID,
+		Name:          meta.Name,
+		Description:   meta.Description,
+		Starred:       meta.Starred,
+		Properties:    nonNil(meta.Properties),
+		AppProperties: nonNil(meta.AppProperties),
+	}
+}
+
+func printMetadata(meta *drive.Metadata) {
+	color.Cyan("\n🏷  %s (%s)", meta.Name, meta.ID)
+	starred := "no"
+	if meta.Starred {
+		starred = "yes"
+	}
+	fmt.Printf("  Starred:     %s\n", starred)
+	if meta.Description != "" {
+		fmt.Printf("  Description: %s\n", meta.Description)
+	}
+	printProperties("Properties", meta.Properties)
+	printProperties("App properties", meta.AppProperties)
+}
+
+func runFileMetaGet(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
+	if err != nil {
+		return err
+	}
+
+	fileID, err := resolveMetaFile(cmd, ds, args[0])
+	if err != nil {
+		return err
+	}
+	meta, err := ds.GetMetadata(ctx, fileID)
+	if err != nil {
+		return err
+	}
+
+	if structuredOutput() {
+		return printRecord(newMetadataRecord(meta))
+	}
+
+	printMetadata(meta)
+	return nil
+}
+
+func runFileMetaSet(cmd *cobra.Command, args []string) error {
+	props, err := drive.ParseProperties(args[1:])
+	if err != nil {
+		return err
+	}
+	var update drive.MetadataUpdate
+	if appFlag {
+		update.AppProperties = props
+	} else {
+		update.Properties = props
+	}
+	if cmd.Flags().Changed("description") {
+		update.Description = &descriptionFlag
+	}
+	if cmd.Flags().Changed("starred") {
+		update.Starred = &starredFlag
+	}
+	return updateMetadata(cmd, args[0], update)
+}
+
+func runFileMetaUnset(cmd *cobra.Command, args []string) error {
+	var update drive.MetadataUpdate
+	if appFlag {
+		update.UnsetApp = args[1:]
+	} else {
+		update.Unset = args[1:]
+	}
+	if ok, _ := cmd.Flags().GetBool("description"); ok {
+		update.Description = new(string)
+	}
+	if ok, _ := cmd.Flags().GetBool("starred"); ok {
+		update.Starred = new(bool)
+	}
+	return updateMetadata(cmd, args[0], update)
+}
+
+// updateMetadata applies update to the file named by arg and prints the
+// result.
+func updateMetadata(cmd *cobra.Command, arg string, update drive.MetadataUpdate) error {
+	if update.IsEmpty() {
+		return fmt.Errorf("nothing to change: give properties, --description or --starred")
+	}
+
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
+	if err != nil {
+		return err
+	}
+
+	fileID, err := resolveMetaFile(cmd, ds, arg)
+	if err != nil {
+		return err
+	}
+	meta, err := ds.UpdateMetadata(ctx, fileID, update)
+	if err != nil {
+		return err
+	}
+
+	color.Green("✓ Metadata of %s updated", meta.Name)
+	if structuredOutput() {
+		return printRecord(newMetadataRecord(meta))
+	}
+	printMetadata(meta)
+	return nil
+}
@@ -0,0 +1,271 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Formats for --output. Every format but table prints the records of a
+// command instead of its table, with the same field names in all of them.
+const (
+	outputTable    = "table"
+	outputJSON     = "json"
+	outputNDJSON   = "ndjson"
+	outputCSV      = "csv"
+	outputYAML     = "yaml"
+	outputTemplate = "template"
+)
+
+// outputNone is the annotation of commands that print a document rather
+// than records, and so only support the table output. GDRIVE_OUTPUT is
+// ignored for them.
+const outputNone = "output-none"
+
+var (
+	outputFlag    string
+	outputFormat  = outputTable
+	outputTmpl    *template.Template
+	recordOutput  io.Writer = os.Stdout
+	restoreStdout func()
+)
+
+// setupOutput parses --output and, for structured formats, sends what
+// commands print for people to stderr so stdout only carries records.
+func setupOutput(cmd *cobra.Command) error {
+	spec := outputFlag
+	if !cmd.Flags().Changed("output") {
+		if jsonFlag {
+			spec = outputJSON
+		} else if v := os.Getenv("GDRIVE_OUTPUT"); v != "" {
+			spec = v
+		}
+	}
+
+	format, tmpl, err := parseOutput(spec)
+	if err != nil {
+		return err
+	}
+	if format != outputTable && cmd.Annotations[outputNone] != "" {
+		if cmd.Flags().Changed("output") {
+			return fmt.Errorf("%s has no structured output, --output must be table", cmd.CommandPath())
+		}
+		format, tmpl = outputTable, nil
+	}
+	outputFormat, outputTmpl = format, tmpl
+
+	if format != outputTable && restoreStdout == nil {
+		stdout, colorOutput := os.Stdout, color.Output
+		os.Stdout, color.Output = os.Stderr, color.Error
+		restoreStdout = func() {
+			os.Stdout, color.Output = stdout, colorOutput
+			restoreStdout = nil
+		}
+	}
+	return nil
+}
+
+// resetOutput undoes setupOutput.
+func resetOutput() {
+	if restoreStdout != nil {
+		restoreStdout()
+	}
+	outputFormat, outputTmpl = outputTable, nil
+}
+
+// parseOutput parses an --output value: table, json, ndjson, csv, yaml or
+// template=TEXT, where TEXT is a Go template run on each record.
+func parseOutput(spec string) (string, *template.Template, error) {
+	name, text, hasText := strings.Cut(spec, "=")
+	switch name {
+	case outputTable, outputJSON, outputNDJSON, outputCSV, outputYAML:
+		if hasText {
+			return "", nil, fmt.Errorf("invalid --output %q: only template takes a value", spec)
+		}
+		return name, nil, nil
+	case outputTemplate:
+		if text == "" {
+			return "", nil, fmt.Errorf("invalid --output %q: give the template, e.g. template='{{.ID}}'", spec)
+		}
+		tmpl, err := template.New("output").Funcs(template.FuncMap{
+			"json": func(v any) (string, error) {
+				data, err := json.Marshal(v)
+				return string(data), err
+			},
+			"join": strings.Join,
+		}).Parse(text)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid --output template: %w", err)
+		}
+		return name, tmpl, nil
+	default:
+		return "", nil, fmt.Errorf("invalid --output %q (use table, json, ndjson, csv, yaml or template=TEXT)", spec)
+	}
+}
+
+// structuredOutput reports whether commands print records instead of
+// tables.
+func structuredOutput() bool {
+	return outputFormat != outputTable
+}
+
+// printRecords prints the records of a command listing items. json and
+// yaml print them as a list, the other formats one per line. It prints
+// nothing with the table output.
+func printRecords[T any](records []T) error {
+	if records == nil {
+		records = []T{}
+	}
+	switch outputFormat {
+	case outputTable:
+		return nil
+	case outputJSON:
+		return writeJSON(records)
+	case outputYAML:
+		return writeYAML(records)
+	case outputCSV:
+		return writeCSV(records)
+	}
+	for _, r := range records {
+		if err := writeLine(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printRecord prints the record of a command acting on a single item. It
+// prints nothing with the table output.
+func printRecord[T any](record T) error {
+	switch outputFormat {
+	case outputTable:
+		return nil
+	case outputJSON:
+		return writeJSON(record)
+	case outputYAML:
+		return writeYAML(record)
+	case outputCSV:
+		return writeCSV([]T{record})
+	}
+	return writeLine(record)
+}
+
+func writeJSON(v any) error {
+	enc := json.NewEncoder(recordOutput)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// writeLine prints one record as compact JSON or through the template.
+func writeLine(record any) error {
+	if outputFormat == outputNDJSON {
+		return json.NewEncoder(recordOutput).Encode(record)
+	}
+	var buf bytes.Buffer
+	if err := outputTmpl.Execute(&buf, record); err != nil {
+		return fmt.Errorf("--output template: %w", err)
+	}
+	buf.WriteByte('\n')
+	_, err := recordOutput.Write(buf.Bytes())
+	return err
+}
+
+// writeYAML prints v with the field names and order of its JSON encoding.
+func writeYAML(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	blockStyle(&doc)
+	enc := yaml.NewEncoder(recordOutput)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// blockStyle drops the flow style and quotes JSON decodes to, leaving the
+// encoder to quote only the strings that need it.
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
+
+// writeCSV prints records under a header of their JSON field names. Lists
+// are joined with ';', maps written as key=value pairs sorted by key.
+func writeCSV[T any](records []T) error {
+	typ := reflect.TypeFor[T]()
+	var fields []int
+	var header []string
+	for i := range typ.NumField() {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		fields = append(fields, i)
+		header = append(header, name)
+	}
+
+	w := csv.NewWriter(recordOutput)
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for _, r := range records {
+		v := reflect.ValueOf(r)
+		row := make([]string, len(fields))
+		for j, i := range fields {
+			row[j] = csvValue(v.Field(i))
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func csvValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = csvValue(v.Index(i))
+		}
+		return strings.Join(items, ";")
+	case reflect.Map:
+		m, _ := v.Interface().(map[string]string)
+		pairs := make([]string, 0, len(m))
+		for _, k := range slices.Sorted(maps.Keys(m)) {
+			pairs = append(pairs, k+"="+m[k])
+		}
+		return strings.Join(pairs, ";")
+	}
+	data, _ := json.Marshal(v.Interface())
+	return string(data)
+}
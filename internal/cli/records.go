@@ -0,0 +1,311 @@
+package cli
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	driveapi "google.golang.org/api/drive/v3"
+
+	"gdrive/internal/drive"
+)
+
+// Records are what commands print with --output. Their fields keep the same
+// names whatever the command, are always present, and hold full values:
+// untruncated names, sizes in bytes and times in RFC 3339. The Go field
+// names are those --output template uses.
+
+// fileRecord describes a file or folder. Path is empty when the command
+// does not know where the item is, as for search results.
+type fileRecord struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Path         string `json:"path"`
+	MimeType     string `json:"mimeType"`
+	Size         int64  `json:"size"`
+	ModifiedTime string `json:"modifiedTime"`
+	WebViewLink  string `json:"webViewLink"`
+	TargetID     string `json:"targetId"`
+}
+
+func newFileRecord(f *driveapi.File, path string) fileRecord {
+	r := fileRecord{
+		ID:           f.Id,
+		Name:         f.Name,
+		Path:         path,
+		MimeType:     f.MimeType,
+		Size:         f.Size,
+		ModifiedTime: driveTime(f.ModifiedTime),
+		WebViewLink:  f.WebViewLink,
+	}
+	if f.ShortcutDetails != nil {
+		r.TargetID = f.ShortcutDetails.TargetId
+	}
+	return r
+}
+
+// fileInfoRecord is the record of file info.
+type fileInfoRecord struct {
+	ID            string            `json:"id"`
+	Name          string            `json:"name"`
+	Path          string            `json:"path"`
+	MimeType      string            `json:"mimeType"`
+	Size          int64             `json:"size"`
+	CreatedTime   string            `json:"createdTime"`
+	ModifiedTime  string            `json:"modifiedTime"`
+	WebViewLink   string            `json:"webViewLink"`
+	DriveID       string            `json:"driveId"`
+	Owners        []string          `json:"owners"`
+	Description   string            `json:"description"`
+	Starred       bool              `json:"starred"`
+	Properties    map[string]string `json:"properties"`
+	AppProperties map[string]string `json:"appProperties"`
+	TargetID      string            `json:"targetId"`
+	TargetPath    string            `json:"targetPath"`
+	ExportFormats []string          `json:"exportFormats"`
+}
+
+func newFileInfoRecord(info *drive.FileInfo, exportFormats []string) fileInfoRecord {
+	r := fileInfoRecord{
+		ID:            info.ID,
+		Name:          info.Name,
+		Path:          joinPath(info.Path),
+		MimeType:      info.MimeType,
+		Size:          info.Size,
+		CreatedTime:   driveTime(info.CreatedTime),
+		ModifiedTime:  driveTime(info.ModifiedTime),
+		WebViewLink:   info.WebViewLink,
+		DriveID:       info.DriveID,
+		Owners:        []string{},
+		Description:   info.Description,
+		Starred:       info.Starred,
+		Properties:    nonNil(info.Properties),
+		AppProperties: nonNil(info.AppProperties),
+		ExportFormats: exportFormats,
+	}
+	for _, owner := range info.Owners {
+		r.Owners = append(r.Owners, owner.EmailAddress)
+	}
+	if n := len(info.Target); n > 0 {
+		r.TargetID = info.Target[n-1].ID
+		if info.Target[n-1].Name != "" {
+			r.TargetPath = joinPath(info.Target)
+		}
+	}
+	if r.ExportFormats == nil {
+		r.ExportFormats = []string{}
+	}
+	return r
+}
+
+// permissionRecord describes a permission on a file. EmailAddress is set
+// for users and groups, Domain for domains. ExpirationTime is empty for
+// permanent permissions.
+type permissionRecord struct {
+	FileID         string `json:"fileId"`
+	ID             string `json:"id"`
+	Type           string `json:"type"`
+	Role           string `json:"role"`
+	EmailAddress   string `json:"emailAddress"`
+	Domain         string `json:"domain"`
+	DisplayName    string `json:"displayName"`
+	ExpirationTime string `json:"expirationTime"`
+	PendingOwner   bool   `json:"pendingOwner"`
+	Discoverable   bool   `json:"discoverable"`
+}
+
+func newPermissionRecord(fileID string, p *driveapi.Permission) permissionRecord {
+	return permissionRecord{
+		FileID:         fileID,
+		ID:             p.Id,
+		Type:           p.Type,
+		Role:           p.Role,
+		EmailAddress:   p.EmailAddress,
+		Domain:         p.Domain,
+		DisplayName:    p.DisplayName,
+		ExpirationTime: driveTime(p.ExpirationTime),
+		PendingOwner:   p.PendingOwner,
+		Discoverable:   p.AllowFileDiscovery,
+	}
+}
+
+// transferRecord describes a file copied between Drive and the local disk.
+// RemotePath starts with the remote argument of the command, which is an ID
+// with --id.
+type transferRecord struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	RemotePath string `json:"remotePath"`
+	LocalPath  string `json:"localPath"`
+	Size       int64  `json:"size"`
+}
+
+// downloadRecord is the record of f downloaded to localPath.
+func downloadRecord(f *driveapi.File, remotePath, localPath string) transferRecord {
+	r := transferRecord{ID: f.Id, Name: f.Name, RemotePath: remotePath, LocalPath: localPath}
+	if stat, err := os.Stat(localPath); err == nil {
+		r.Size = stat.Size()
+	}
+	return r
+}
+
+// transferLog collects the records of the files a folder transfer copied.
+// It is safe for concurrent use.
+type transferLog struct {
+	mu      sync.Mutex
+	records []transferRecord
+}
+
+func (l *transferLog) add(r transferRecord) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.records = append(l.records, r)
+}
+
+// trashedRecord describes an item in the trash.
+type trashedRecord struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	MimeType    string `json:"mimeType"`
+	Size        int64  `json:"size"`
+	TrashedTime string `json:"trashedTime"`
+	TrashedBy   string `json:"trashedBy"`
+}
+
+func newTrashedRecord(f *driveapi.File) trashedRecord {
+	r := trashedRecord{
+		ID:          f.Id,
+		Name:        f.Name,
+		MimeType:    f.MimeType,
+		Size:        f.Size,
+		TrashedTime: driveTime(f.TrashedTime),
+	}
+	if f.TrashingUser != nil {
+		r.TrashedBy = f.TrashingUser.EmailAddress
+	}
+	return r
+}
+
+func printTrashedRecords(files []*driveapi.File) error {
+	records := make([]trashedRecord, 0, len(files))
+	for _, f := range files {
+		records = append(records, newTrashedRecord(f))
+	}
+	return printRecords(records)
+}
+
+// changeRecord describes a change listed by activity changes. ChangeType
+// is added, modified or removed.
+type changeRecord struct {
+	FileID     string `json:"fileId"`
+	FileName   string `json:"fileName"`
+	MimeType   string `json:"mimeType"`
+	ChangeType string `json:"changeType"`
+	ModifiedBy string `json:"modifiedBy"`
+	Time       string `json:"time"`
+}
+
+func newChangeRecord(c *drive.ChangeInfo) changeRecord {
+	changeType := "added"
+	switch {
+	case c.Removed:
+		changeType = "removed"
+	case c.ChangeType == "Modified":
+		changeType = "modified"
+	}
+	return changeRecord{
+		FileID:     c.FileID,
+		FileName:   c.FileName,
+		MimeType:   c.MimeType,
+		ChangeType: changeType,
+		ModifiedBy: c.ModifiedBy,
+		Time:       recordTime(c.ChangeTime),
+	}
+}
+
+// revisionRecord describes a revision of a file.
+type revisionRecord struct {
+	FileID       string `json:"fileId"`
+	ID           string `json:"id"`
+	ModifiedTime string `json:"modifiedTime"`
+	Size         int64  `json:"size"`
+	MimeType     string `json:"mimeType"`
+	ModifiedBy   string `json:"modifiedBy"`
+	KeepForever  bool   `json:"keepForever"`
+	Published    bool   `json:"published"`
+}
+
+func newRevisionRecord(fileID string, rev *drive.RevisionInfo) revisionRecord {
+	return revisionRecord{
+		FileID:       fileID,
+		ID:           rev.ID,
+		ModifiedTime: recordTime(rev.ModifiedTime),
+		Size:         rev.Size,
+		MimeType:     rev.MimeType,
+		ModifiedBy:   rev.ModifiedBy,
+		KeepForever:  rev.KeepForever,
+		Published:    rev.Published,
+	}
+}
+
+// activityRecord describes an event of activity history.
+type activityRecord struct {
+	Time         string   `json:"time"`
+	Action       string   `json:"action"`
+	Detail       string   `json:"detail"`
+	Actors       []string `json:"actors"`
+	Targets      []string `json:"targets"`
+	TargetTitles []string `json:"targetTitles"`
+}
+
+func newActivityRecord(a *drive.DriveActivityInfo) activityRecord {
+	return activityRecord{
+		Time:         recordTime(a.Timestamp),
+		Action:       a.ActionType,
+		Detail:       a.ActionDetail,
+		Actors:       nonNilSlice(a.Actors),
+		Targets:      nonNilSlice(a.Targets),
+		TargetTitles: nonNilSlice(a.TargetTitles),
+	}
+}
+
+// recordTime formats t in RFC 3339, or returns "" if t is zero.
+func recordTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// driveTime formats a time returned by Drive like recordTime.
+func driveTime(s string) string {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return s
+	}
+	return recordTime(t)
+}
+
+// joinPath joins path components with '/'.
+func joinPath(components []drive.PathComponent) string {
+	names := make([]string, len(components))
+	for i, c := range components {
+		names[i] = c.Name
+	}
+	return strings.Join(names, "/")
+}
+
+func nonNil(m map[string]string) map[string]string {
+	if m == nil {
+		return map[string]string{}
+	}
+	return m
+}
+
+func nonNilSlice(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
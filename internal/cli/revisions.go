@@ -0,0 +1,257 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	driveapi "google.golang.org/api/drive/v3"
+
+	"gdrive/internal/drive"
+)
+
+// addRevisionCommands adds the commands acting on a single revision to the
+// 'activity revisions' command, which lists them.
+func addRevisionCommands(cmd *cobra.Command) {
+	cmd.AddCommand(revisionsDownloadCmd())
+	cmd.AddCommand(revisionsRestoreCmd())
+	cmd.AddCommand(revisionsPinCmd(true))
+	cmd.AddCommand(revisionsPinCmd(false))
+	cmd.AddCommand(revisionsDeleteCmd())
+}
+
+func revisionsDownloadCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "download FILE REV_ID [LOCAL_FOLDER]",
+		Short: "Download one revision of a file",
+		Long: `Download a revision listed by 'gdrive activity revisions FILE'. The copy
+is named after the file with the revision ID appended, e.g. report-rev3.pdf,
+and gets the modification time of the revision.
+
+Revisions of Google Workspace files are exported like 'gdrive file download'
+does: to --format, or to PDF for Docs, XLSX for Sheets and PPTX for Slides.
+
+Examples:
+  gdrive activity revisions download Reports/q3.pdf 0B7x2k9
+  gdrive activity revisions download Notes/plan 42 ./old --format md
+  gdrive activity revisions download 1a2b3c4d5e 42 --id`,
+		Args: cobra.RangeArgs(2, 3),
+		RunE: runRevisionsDownload,
+	}
+
+	cmd.Flags().BoolVar(&useIDFlag, "id", false, "Treat FILE as a Drive file ID")
+	cmd.Flags().StringVar(&formatFlag, "format", "", "Export format for Google Workspace revisions, see 'gdrive formats'. Ignored for binary files.")
+	cmd.Flags().BoolVar(&overwriteFlag, "overwrite", false, "Overwrite without asking")
+
+	return cmd
+}
+
+func revisionsRestoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore FILE REV_ID",
+		Short: "Make an old revision the current content",
+		Long: `Make an old revision the current content of a file by uploading it again.
+The restored content becomes a new revision; the revisions in between are
+kept.
+
+Revisions of Google Docs, Sheets and Slides go through DOCX, XLSX and PPTX,
+which Drive converts back, so formatting those formats cannot hold is lost.
+
+Examples:
+  gdrive activity revisions restore Reports/q3.pdf 0B7x2k9
+  gdrive activity revisions restore 1a2b3c4d5e 42 --id`,
+		Args: cobra.ExactArgs(2),
+		RunE: runRevisionsRestore,
+	}
+
+	cmd.Flags().BoolVar(&useIDFlag, "id", false, "Treat FILE as a Drive file ID")
+
+	return cmd
+}
+
+func revisionsPinCmd(keep bool) *cobra.Command {
+	use, short, long := "pin", "Keep a revision forever",
+		`Keep a revision forever. Drive otherwise deletes old revisions of binary
+files after 30 days, or once a file has more than 100 of them. Revisions of
+Google Workspace files cannot be pinned.
+
+Examples:
+  gdrive activity revisions pin Reports/q3.pdf 0B7x2k9
+  gdrive activity revisions pin 1a2b3c4d5e 0B7x2k9 --id`
+	if !keep {
+		use, short, long = "unpin", "Let Drive purge a revision again",
+			`Remove the keep-forever flag from a revision, so Drive deletes it in due
+course like any other old revision.
+
+Examples:
+  gdrive activity revisions unpin Reports/q3.pdf 0B7x2k9`
+	}
+
+	cmd := &cobra.Command{
+		Use:   use + " FILE REV_ID",
+		Short: short,
+		Long:  long,
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRevisionsPin(cmd, args, keep)
+		},
+	}
+
+	cmd.Flags().BoolVar(&useIDFlag, "id", false, "Treat FILE as a Drive file ID")
+
+	return cmd
+}
+
+func revisionsDeleteCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete FILE REV_ID",
+		Short: "Permanently delete a revision",
+		Long: `Permanently delete a revision of a binary file. Deleting the current
+revision reverts the file to the previous one. Drive does not allow deleting
+the only revision of a file, nor revisions of Google Workspace files. This
+cannot be undone and asks for confirmation.
+
+Examples:
+  gdrive activity revisions delete Reports/q3.pdf 0B7x2k9`,
+		Args: cobra.ExactArgs(2),
+		RunE: runRevisionsDelete,
+	}
+
+	cmd.Flags().BoolVar(&useIDFlag, "id", false, "Treat FILE as a Drive file ID")
+
+	return cmd
+}
+
+// resolveRevisionFile returns the file whose revisions a command acts on.
+func resolveRevisionFile(ctx context.Context, ds *drive.Service, arg string) (*driveapi.File, error) {
+	fileID := arg
+	if !useIDFlag {
+		var err error
+		if fileID, err = resolveRemoteFile(ctx, ds, arg); err != nil {
+			return nil, err
+		}
+	}
+	file, err := ds.Backend.GetFile(ctx, fileID, "id, name, mimeType")
+	if err != nil {
+		return nil, fmt.Errorf("file not found: %v", err)
+	}
+	return file, nil
+}
+
+// revisionFilename names the local copy of a revision of name.
+func revisionFilename(name, revisionID string) string {
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + "-rev" + revisionID + ext
+}
+
+func runRevisionsDownload(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
+	if err != nil {
+		return err
+	}
+
+	file, err := resolveRevisionFile(ctx, ds, args[0])
+	if err != nil {
+		return err
+	}
+	localFolder := "."
+	if len(args) > 2 {
+		localFolder = args[2]
+	}
+	localPath := filepath.Join(localFolder, revisionFilename(file.Name, args[1]))
+
+	if !overwriteFlag {
+		if _, err := os.Stat(localPath); err == nil {
+			if !confirmOverwrite(localPath, 0) {
+				color.Yellow("Download cancelled")
+				return nil
+			}
+		}
+	}
+
+	localPath, err = ds.DownloadRevision(ctx, file.Id, args[1], localPath, formatFlag, true)
+	if err != nil {
+		return err
+	}
+
+	color.Green("Downloaded revision %s of %s: %s", args[1], file.Name, localPath)
+	return printRecord(downloadRecord(file, args[0], localPath))
+}
+
+func runRevisionsRestore(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
+	if err != nil {
+		return err
+	}
+
+	file, err := resolveRevisionFile(ctx, ds, args[0])
+	if err != nil {
+		return err
+	}
+
+	restored, err := ds.RestoreRevision(ctx, file.Id, args[1])
+	if err != nil {
+		return err
+	}
+
+	color.Green("✓ Restored revision %s of %s", args[1], file.Name)
+	if restored.HeadRevisionId != "" {
+		fmt.Printf("  New revision: %s\n", restored.HeadRevisionId)
+	}
+	return printRecord(revisionRecord{FileID: file.Id, ID: restored.HeadRevisionId, MimeType: restored.MimeType})
+}
+
+func runRevisionsPin(cmd *cobra.Command, args []string, keep bool) error {
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
+	if err != nil {
+		return err
+	}
+
+	file, err := resolveRevisionFile(ctx, ds, args[0])
+	if err != nil {
+		return err
+	}
+
+	rev, err := ds.SetRevisionKeepForever(ctx, file.Id, args[1], keep)
+	if err != nil {
+		return err
+	}
+
+	if keep {
+		color.Green("✓ Revision %s of %s will be kept forever", args[1], file.Name)
+	} else {
+		color.Green("✓ Revision %s of %s is no longer pinned", args[1], file.Name)
+	}
+	return printRecord(newRevisionRecord(file.Id, rev))
+}
+
+func runRevisionsDelete(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
+	if err != nil {
+		return err
+	}
+
+	file, err := resolveRevisionFile(ctx, ds, args[0])
+	if err != nil {
+		return err
+	}
+
+	if !confirm(fmt.Sprintf("Permanently delete revision %s of %s? This cannot be undone.", args[1], file.Name)) {
+		color.Yellow("Cancelled")
+		return nil
+	}
+	if err := ds.DeleteRevision(ctx, file.Id, args[1]); err != nil {
+		return err
+	}
+
+	color.Green("✓ Deleted revision %s of %s", args[1], file.Name)
+	return printRecord(revisionRecord{FileID: file.Id, ID: args[1]})
+}
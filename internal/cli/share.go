@@ -0,0 +1,241 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"gdrive/internal/drive"
+)
+
+var (
+	dryRunFlag bool
+	pruneFlag  bool
+	yesFlag    bool
+)
+
+// ShareCmd returns the share command.
+func ShareCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "share",
+		Short: "Manage sharing of many files at once",
+		Long: `Commands that share several files and folders at once. To share a single
+file, use 'gdrive file share'.`,
+	}
+
+	cmd.AddCommand(shareApplyCmd())
+
+	return cmd
+}
+
+func shareApplyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "apply MANIFEST",
+		Short: "Make sharing match a YAML or CSV manifest",
+		Long: `Read a manifest declaring who should have which role on Drive paths,
+compare it with their current permissions, print the plan, and apply it.
+
+A YAML manifest (.yaml, .yml) lists paths with their grants:
+
+  - path: Projects/ACME
+    grants:
+      - {principal: alice@example.com, role: writer}
+      - {principal: team@example.com, type: group, role: commenter}
+      - {principal: contractor@partner.com, role: reader, expires: 2026-12-31}
+      - {principal: example.com, role: reader}
+      - {principal: anyone, role: reader}
+
+A CSV manifest (.csv) has one grant per row, under a header with path,
+principal and role columns and optionally type and expires:
+
+  path,principal,type,role,expires
+  Projects/ACME,alice@example.com,,writer,
+  Projects/ACME,team@example.com,group,commenter,
+
+Principals are email addresses, domains or 'anyone'. Email addresses are
+users unless their type is group. Roles are reader, commenter or writer,
+and fileOrganizer or organizer in Shared Drives. Only users and groups can
+expire, at a date; leaving expires out makes the access permanent.
+
+The plan adds missing grants and changes roles and expirations. With
+--prune it also removes the permissions of the listed paths the manifest
+does not declare, except owners and the access Shared Drive items get from
+their drive, after asking for confirmation unless --yes is given. Paths not
+in the manifest are left alone.
+
+Changes the sharing policy forbids fail, and the others are still applied;
+administrators can apply them all with --override-policy.
+
+Examples:
+  gdrive share apply acme.yaml --dry-run
+  gdrive share apply acme.yaml
+  gdrive share apply team.csv --prune --yes --no-notify`,
+		Args: cobra.ExactArgs(1),
+		RunE: runShareApply,
+	}
+
+	cmd.Flags().BoolVar(&dryRunFlag, "dry-run", false, "Print the plan without changing anything")
+	cmd.Flags().BoolVar(&pruneFlag, "prune", false, "Remove permissions the manifest does not declare on its paths")
+	cmd.Flags().BoolVarP(&yesFlag, "yes", "y", false, "Remove permissions with --prune without asking for confirmation")
+	cmd.Flags().BoolVar(&notifyFlag, "no-notify", false, "Do not send notification emails to new users and groups")
+	cmd.Flags().BoolVar(&overrideFlag, "override-policy", false, "Apply changes the sharing policy forbids (administrators)")
+
+	return cmd
+}
+
+// sharingChangeRecord is the record of a change share apply planned.
+// Action is add, update or remove; Status is planned with --dry-run, else
+// applied or error. Role and ExpirationTime are the declared access, or the
+// removed one.
+type sharingChangeRecord struct {
+	Path           string `json:"path"`
+	FileID         string `json:"fileId"`
+	Action         string `json:"action"`
+	Principal      string `json:"principal"`
+	Type           string `json:"type"`
+	Role           string `json:"role"`
+	PreviousRole   string `json:"previousRole"`
+	ExpirationTime string `json:"expirationTime"`
+	Status         string `json:"status"`
+	Detail         string `json:"detail"`
+}
+
+func newSharingChangeRecord(c *drive.SharingChange) sharingChangeRecord {
+	record := sharingChangeRecord{
+		Path:           c.Path,
+		FileID:         c.FileID,
+		Action:         string(c.Action),
+		Principal:      c.Grant.Principal,
+		Type:           c.Grant.Type,
+		Role:           c.Grant.Role,
+		ExpirationTime: recordTime(c.Grant.Expires),
+		Status:         "planned",
+	}
+	if c.Permission != nil {
+		record.PreviousRole = c.Permission.Role
+	}
+	return record
+}
+
+func runShareApply(cmd *cobra.Command, args []string) error {
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(args[0])), ".")
+	if format == "yml" {
+		format = "yaml"
+	}
+	if format != "yaml" && format != "csv" {
+		return fmt.Errorf("unknown manifest type %s: use a .yaml, .yml or .csv file", args[0])
+	}
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	targets, err := drive.ParseSharingManifest(f, format)
+	if err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+	ds, err := getSharingService(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Plan everything before changing anything
+	var changes []*drive.SharingChange
+	for _, target := range targets {
+		fileID, err := resolveRemoteFile(ctx, ds, target.Path)
+		if err != nil {
+			return err
+		}
+		planned, err := ds.PlanSharing(ctx, fileID, target, pruneFlag)
+		if err != nil {
+			return err
+		}
+		changes = append(changes, planned...)
+	}
+
+	counts := map[drive.SharingAction]int{}
+	color.Cyan("\n📋 Plan:")
+	for _, c := range changes {
+		counts[c.Action]++
+		printSharingChange(c)
+	}
+	if len(changes) == 0 {
+		fmt.Println("  Sharing already matches the manifest")
+	}
+	fmt.Printf("\n%d to add, %d to change, %d to remove\n",
+		counts[drive.SharingAdd], counts[drive.SharingUpdate], counts[drive.SharingRemove])
+
+	records := make([]sharingChangeRecord, 0, len(changes))
+	for _, c := range changes {
+		records = append(records, newSharingChangeRecord(c))
+	}
+	if dryRunFlag || len(changes) == 0 {
+		return printRecords(records)
+	}
+
+	if n := counts[drive.SharingRemove]; n > 0 && !yesFlag {
+		if !confirm(fmt.Sprintf("\nRemove %d permission(s) the manifest does not declare?", n)) {
+			color.Yellow("Cancelled")
+			return nil
+		}
+	}
+
+	fmt.Println()
+	var failed, denied int
+	for i, c := range changes {
+		if err := ds.ApplySharingChange(ctx, c, !notifyFlag); err != nil {
+			failed++
+			if errors.Is(err, drive.ErrPolicyDenied) {
+				denied++
+			}
+			records[i].Status, records[i].Detail = "error", err.Error()
+			color.Red("✗ %s %s on %s: %v", c.Action, c.Grant.Principal, c.Path, err)
+			continue
+		}
+		records[i].Status = "applied"
+	}
+	if err := printRecords(records); err != nil {
+		return err
+	}
+
+	if denied > 0 {
+		return explainPolicy(fmt.Errorf("%d of %d change(s) failed, %d %w", failed, len(changes), denied, drive.ErrPolicyDenied))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d change(s) failed", failed, len(changes))
+	}
+	color.Green("✓ Applied %d change(s)", len(changes))
+	return nil
+}
+
+func printSharingChange(c *drive.SharingChange) {
+	access := c.Grant.Role + until(c.Grant.Expires)
+	who := fmt.Sprintf("%s (%s)", c.Grant.Principal, c.Grant.Type)
+	switch c.Action {
+	case drive.SharingAdd:
+		color.Green("  + %-40s %-45s %s", c.Path, who, access)
+	case drive.SharingUpdate:
+		expires, _ := time.Parse(time.RFC3339, c.Permission.ExpirationTime)
+		previous := c.Permission.Role + until(expires)
+		color.Yellow("  ~ %-40s %-45s %s → %s", c.Path, who, previous, access)
+	case drive.SharingRemove:
+		color.Red("  - %-40s %-45s %s", c.Path, who, access)
+	}
+}
+
+// until describes when access expiring at t ends, or is "" for permanent
+// access.
+func until(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return " until " + t.Local().Format("2006-01-02 15:04")
+}
@@ -21,7 +21,8 @@ use the gdrive CLI and MCP server. The output is markdown with YAML
 frontmatter (name, description) and is the single source of truth for
 AI consumers; it is regenerated from the binary, so it always matches
 the installed version.`,
-		Args: cobra.NoArgs,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{outputNone: "true"},
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			if _, err := fmt.Fprint(cmd.OutOrStdout(), skillContent); err != nil {
 				return fmt.Errorf("write skill: %w", err)
@@ -0,0 +1,293 @@
+package cli
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	driveapi "google.golang.org/api/drive/v3"
+
+	"gdrive/internal/drive"
+)
+
+var olderThanFlag string
+
+// TrashCmd returns the trash command.
+func TrashCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trash",
+		Short: "List, restore and empty the Drive trash",
+		Long: `Commands for the Google Drive trash.
+
+'gdrive file delete' moves files to the trash. From there they can be
+restored to their original folder, or deleted permanently with 'empty'
+(everything) or 'purge' (items trashed more than a given age ago). Drive
+itself empties the trash of items older than 30 days.`,
+	}
+
+	cmd.AddCommand(trashListCmd())
+	cmd.AddCommand(trashRestoreCmd())
+	cmd.AddCommand(trashEmptyCmd())
+	cmd.AddCommand(trashPurgeCmd())
+
+	return cmd
+}
+
+func trashListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List items in the trash",
+		Long: `List items in the trash, most recently trashed first, with the IDs
+'gdrive trash restore --id' accepts.
+
+Examples:
+  gdrive trash list
+  gdrive trash list --days 7
+  gdrive trash list --max 500`,
+		Args: cobra.NoArgs,
+		RunE: runTrashList,
+	}
+
+	cmd.Flags().IntVar(&daysBackFlag, "days", 0, "Only show items trashed in the last N days (0 = all)")
+	cmd.Flags().Int64VarP(&maxResults, "max", "m", 100, "Maximum number of items to show")
+
+	return cmd
+}
+
+func trashRestoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore FILE",
+		Short: "Restore an item from the trash",
+		Long: `Take a file or folder out of the trash and put it back in its original
+folder. FILE is the path the item had before it was trashed, or its ID
+with --id. Restoring a folder restores its content.
+
+Examples:
+  gdrive trash restore Documents/report.pdf
+  gdrive trash restore 1a2b3c4d5e --id`,
+		Args: cobra.ExactArgs(1),
+		RunE: runTrashRestore,
+	}
+
+	cmd.Flags().BoolVar(&useIDFlag, "id", false, "Treat FILE as a Drive file ID")
+
+	return cmd
+}
+
+func trashEmptyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "empty",
+		Short: "Permanently delete everything in the trash",
+		Long: `Permanently delete every item in the trash. This cannot be undone and
+asks for confirmation.`,
+		Args: cobra.NoArgs,
+		RunE: runTrashEmpty,
+	}
+}
+
+func trashPurgeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "purge",
+		Short: "Permanently delete items trashed a while ago",
+		Long: `Permanently delete the items that were moved to the trash more than
+--older-than ago. Items trashed more recently stay restorable. This cannot
+be undone and asks for confirmation.
+
+--older-than accepts days (30d), weeks (2w) or a Go duration (36h).
+
+Examples:
+  gdrive trash purge --older-than 30d
+  gdrive trash purge --older-than 2w`,
+		Args: cobra.NoArgs,
+		RunE: runTrashPurge,
+	}
+
+	cmd.Flags().StringVar(&olderThanFlag, "older-than", "", "Age of the items to delete, e.g. 30d, 2w, 36h (required)")
+	_ = cmd.MarkFlagRequired("older-than")
+
+	return cmd
+}
+
+// parseAge parses a duration that may also be written in days ("30d") or
+// weeks ("2w").
+func parseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			v, err := strconv.Atoi(n)
+			if err != nil || v < 0 {
+				return 0, fmt.Errorf("invalid age %q", s)
+			}
+			return time.Duration(v) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q (use e.g. 30d, 2w or 36h)", s)
+	}
+	return d, nil
+}
+
+func runTrashList(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
+	if err != nil {
+		return err
+	}
+
+	files, err := ds.ListTrashedFiles(ctx, daysBackFlag, maxResults)
+	if err != nil {
+		return err
+	}
+	if structuredOutput() {
+		return printTrashedRecords(files)
+	}
+	if len(files) == 0 {
+		fmt.Println("The trash is empty")
+		return nil
+	}
+
+	color.Cyan("\n🗑  Trash:")
+	fmt.Printf("%-44s %-40s %-20s %12s\n", "ID", "Name", "Trashed", "Size")
+	fmt.Println(strings.Repeat("-", 120))
+	for _, f := range files {
+		name := trashedName(f)
+		if len(name) > 40 {
+			name = name[:37] + "..."
+		}
+		trashed := ""
+		if t, err := time.Parse(time.RFC3339, f.TrashedTime); err == nil {
+			trashed = t.Local().Format("2006-01-02 15:04")
+		}
+		size := "-"
+		if f.MimeType != drive.DriveFolderMimeType {
+			size = formatSize(f.Size)
+		}
+		fmt.Printf("%-44s %-40s %-20s %12s\n", f.Id, name, trashed, size)
+	}
+
+	fmt.Printf("\nTotal: %d item(s)\n", len(files))
+	return nil
+}
+
+func runTrashRestore(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
+	if err != nil {
+		return err
+	}
+
+	filePath := args[0]
+	fileID := filePath
+	if !useIDFlag {
+		parentID, err := ds.ResolvePath(ctx, path.Dir(strings.Trim(filePath, "/")), true)
+		if err != nil {
+			return fmt.Errorf("parent folder not found (if it is in the trash too, restore it first): %v", err)
+		}
+		file, err := ds.FindTrashedItem(ctx, path.Base(filePath), parentID)
+		if err != nil {
+			return err
+		}
+		if file == nil {
+			return fmt.Errorf("not found in the trash: %s", filePath)
+		}
+		fileID = file.Id
+	}
+
+	restored, err := ds.RestoreFile(ctx, fileID)
+	if err != nil {
+		return err
+	}
+
+	color.Green("✓ Restored: %s", restored.Name)
+	var restoredPath string
+	if components, err := ds.GetFilePath(ctx, restored.Id); err == nil && len(components) > 0 {
+		names := make([]string, len(components))
+		for i, c := range components {
+			names[i] = c.Name
+		}
+		fmt.Printf("  Path: %s\n", strings.Join(names, " / "))
+		restoredPath = joinPath(components)
+	}
+	return printRecord(newFileRecord(restored, restoredPath))
+}
+
+func runTrashEmpty(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !confirm("Permanently delete everything in the trash? This cannot be undone.") {
+		color.Yellow("Cancelled")
+		return nil
+	}
+
+	// Records list what was deleted, which Drive does not report
+	var files []*driveapi.File
+	if structuredOutput() {
+		if files, err = ds.TrashedBefore(ctx, time.Now()); err != nil {
+			return err
+		}
+	}
+	if err := ds.EmptyTrash(ctx); err != nil {
+		return err
+	}
+
+	color.Green("✓ Trash emptied")
+	return printTrashedRecords(files)
+}
+
+func runTrashPurge(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
+	if err != nil {
+		return err
+	}
+
+	age, err := parseAge(olderThanFlag)
+	if err != nil {
+		return fmt.Errorf("invalid --older-than: %w", err)
+	}
+	cutoff := time.Now().Add(-age)
+
+	files, err := ds.TrashedBefore(ctx, cutoff)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		fmt.Printf("Nothing in the trash is older than %s\n", olderThanFlag)
+		return printRecords([]trashedRecord{})
+	}
+
+	if !confirm(fmt.Sprintf("Permanently delete %d item(s) trashed before %s? This cannot be undone.",
+		len(files), cutoff.Local().Format("2006-01-02 15:04"))) {
+		color.Yellow("Cancelled")
+		return nil
+	}
+
+	deleted := 0
+	for _, f := range files {
+		if err := ds.DeleteFile(ctx, f.Id); err != nil {
+			return fmt.Errorf("unable to delete %s (%d of %d deleted): %w", f.Name, deleted, len(files), err)
+		}
+		deleted++
+		fmt.Printf("Deleted: %s\n", trashedName(f))
+	}
+
+	color.Green("✓ Purged %d item(s) from the trash", deleted)
+	return printTrashedRecords(files)
+}
+
+// trashedName returns a display name for a trashed item.
+func trashedName(f *driveapi.File) string {
+	if f.MimeType == drive.DriveFolderMimeType {
+		return f.Name + "/"
+	}
+	return f.Name
+}
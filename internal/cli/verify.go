@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"gdrive/internal/drive"
+)
+
+// VerifyCmd returns the verify command.
+func VerifyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify LOCAL REMOTE",
+		Short: "Check local files against their Drive checksums",
+		Long: `Compare a local file or directory tree with Google Drive using the
+MD5/SHA-1/SHA-256 checksums Drive stores for binary files.
+
+When LOCAL is a directory, REMOTE must be a folder and the comparison is
+recursive. Each file is reported as:
+  ok        content matches Drive
+  mismatch  content differs from Drive
+  missing   on Drive but not on disk
+  extra     on disk but not on Drive
+  skipped   Drive has no checksum (Google Workspace files, shortcuts)
+
+The command fails if any file is mismatched or missing.
+
+Examples:
+  gdrive verify ./backup/report.pdf Documents/report.pdf
+  gdrive verify ./backup Documents/Projects
+  gdrive verify ./backup 1a2b3c4d5e --id`,
+		Args: cobra.ExactArgs(2),
+		RunE: runVerify,
+	}
+
+	cmd.Flags().BoolVar(&useIDFlag, "id", false, "Treat REMOTE as a Drive file or folder ID")
+
+	return cmd
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	ds, err := getDriveService(ctx)
+	if err != nil {
+		return err
+	}
+
+	local, remote := args[0], args[1]
+	info, err := os.Stat(local)
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[drive.VerifyStatus]int)
+	var records []verifyRecord
+	report := func(res drive.VerifyResult) {
+		counts[res.Status]++
+		if structuredOutput() {
+			records = append(records, verifyRecord{Path: res.Path, Status: string(res.Status), Detail: res.Detail})
+			return
+		}
+		printVerifyResult(res)
+	}
+
+	if info.IsDir() {
+		folderID := remote
+		if !useIDFlag {
+			folderID, err = ds.ResolvePath(ctx, remote, true)
+			if err != nil {
+				return fmt.Errorf("remote folder not found: %v", err)
+			}
+		}
+		if err := ds.VerifyTree(ctx, local, folderID, report); err != nil {
+			return err
+		}
+	} else {
+		fileID := remote
+		if !useIDFlag {
+			fileID, err = resolveRemoteFile(ctx, ds, remote)
+			if err != nil {
+				return err
+			}
+		}
+		f, err := ds.GetFileChecksums(ctx, fileID)
+		if err != nil {
+			return fmt.Errorf("file not found: %v", err)
+		}
+		res := drive.VerifyFile(local, f)
+		report(res)
+	}
+
+	if err := printRecords(records); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%d ok, %d mismatched, %d missing, %d extra, %d skipped\n",
+		counts[drive.VerifyOK], counts[drive.VerifyMismatch], counts[drive.VerifyMissing],
+		counts[drive.VerifyExtra], counts[drive.VerifySkipped])
+
+	if failed := counts[drive.VerifyMismatch] + counts[drive.VerifyMissing] + counts[drive.VerifyError]; failed > 0 {
+		return fmt.Errorf("verification failed for %d file(s)", failed)
+	}
+	color.Green("✓ Verified against Drive")
+	return nil
+}
+
+// verifyRecord is the record of a file verify compared. Status is ok,
+// mismatch, missing, extra, skipped or error.
+type verifyRecord struct {
+	Path   string `json:"path"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+}
+
+func printVerifyResult(res drive.VerifyResult) {
+	status := fmt.Sprintf("%-9s", res.Status)
+	switch res.Status {
+	case drive.VerifyOK:
+		status = color.GreenString(status)
+	case drive.VerifyMismatch, drive.VerifyMissing, drive.VerifyError:
+		status = color.RedString(status)
+	default:
+		status = color.YellowString(status)
+	}
+	if res.Detail != "" {
+		fmt.Printf("%s %s (%s)\n", status, res.Path, res.Detail)
+	} else {
+		fmt.Printf("%s %s\n", status, res.Path)
+	}
+}
+
+// resolveRemoteFile returns the ID of the file at a remote path.
+func resolveRemoteFile(ctx context.Context, ds *drive.Service, remotePath string) (string, error) {
+	remotePath = strings.Trim(remotePath, "/")
+	parentID := "root"
+	if dir := path.Dir(remotePath); dir != "." {
+		var err error
+		parentID, err = ds.ResolvePath(ctx, dir, true)
+		if err != nil {
+			return "", err
+		}
+	}
+	f, err := ds.FindFile(ctx, path.Base(remotePath), parentID)
+	if err != nil {
+		return "", err
+	}
+	if f == nil {
+		return "", fmt.Errorf("file not found: %s", remotePath)
+	}
+	return f.Id, nil
+}
@@ -1,13 +1,14 @@
 package drive
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"strings"
 	"time"
 
 	"google.golang.org/api/drive/v3"
 	"google.golang.org/api/driveactivity/v2"
+
+	"gdrive/internal/query"
 )
 
 // ChangeInfo represents simplified change information.
@@ -22,18 +23,16 @@ type ChangeInfo struct {
 }
 
 // ListChanges lists recent changes to files in the Drive.
-func (ds *Service) ListChanges(pageSize int64) ([]*ChangeInfo, error) {
+func (ds *Service) ListChanges(ctx context.Context, pageSize int64) ([]*ChangeInfo, error) {
 	// Get the start page token
-	startToken, err := ds.API.Changes.GetStartPageToken().Do()
+	startToken, err := ds.Backend.GetStartPageToken(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("unable to get start page token: %v", err)
 	}
 
 	// List changes from the start token
-	changeList, err := ds.API.Changes.List(startToken.StartPageToken).
-		PageSize(pageSize).
-		Fields("changes(file(id, name, mimeType, modifiedTime, modifiedByMeTime, lastModifyingUser), fileId, removed, time)").
-		Do()
+	changeList, err := ds.Backend.ListChanges(ctx, startToken, pageSize,
+		"changes(file(id, name, mimeType, modifiedTime, modifiedByMeTime, lastModifyingUser), fileId, removed, time)")
 	if err != nil {
 		return nil, fmt.Errorf("unable to list changes: %v", err)
 	}
@@ -80,22 +79,21 @@ func (ds *Service) ListChanges(pageSize int64) ([]*ChangeInfo, error) {
 }
 
 // ListTrashedFiles lists files in the trash, optionally filtered by time.
-func (ds *Service) ListTrashedFiles(daysBack int, maxResults int64) ([]*drive.File, error) {
+func (ds *Service) ListTrashedFiles(ctx context.Context, daysBack int, maxResults int64) ([]*drive.File, error) {
 	// Build query for trashed files
-	query := "trashed = true"
+	q := query.Trashed(true)
 
 	// If days back is specified, add time filter
 	if daysBack > 0 {
-		cutoffTime := time.Now().AddDate(0, 0, -daysBack).Format(time.RFC3339)
-		query = fmt.Sprintf("trashed = true and trashedTime >= '%s'", cutoffTime)
+		q = query.And(q, query.TrashedTime(query.Ge, time.Now().AddDate(0, 0, -daysBack)))
 	}
 
-	fileList, err := ds.API.Files.List().
-		Q(query).
-		PageSize(maxResults).
-		Fields("files(id, name, mimeType, trashedTime, trashingUser, size, parents)").
-		OrderBy("trashedTime desc").
-		Do()
+	fileList, err := ds.Backend.ListFiles(ctx, ListOptions{
+		Query:    q.String(),
+		Fields:   "files(id, name, mimeType, trashedTime, trashingUser, size, parents, explicitlyTrashed)",
+		OrderBy:  "trashedTime desc",
+		PageSize: maxResults,
+	})
 
 	if err != nil {
 		return nil, fmt.Errorf("unable to list trashed files: %v", err)
@@ -115,53 +113,36 @@ type RevisionInfo struct {
 	Published    bool
 }
 
+// revisionInfoFields are the revision fields RevisionInfo is built from.
+const revisionInfoFields = "id, modifiedTime, size, mimeType, lastModifyingUser, keepForever, published"
+
 // ListRevisions lists all revisions for a specific file.
-func (ds *Service) ListRevisions(fileID string) ([]*RevisionInfo, error) {
-	revList, err := ds.API.Revisions.List(fileID).
-		Fields("revisions(id, modifiedTime, size, mimeType, lastModifyingUser, keepForever, published)").
-		Do()
+func (ds *Service) ListRevisions(ctx context.Context, fileID string) ([]*RevisionInfo, error) {
+	revList, err := ds.Backend.ListRevisions(ctx, fileID, revisionInfoFields)
 	if err != nil {
 		return nil, fmt.Errorf("unable to list revisions: %v", err)
 	}
 
 	var revisions []*RevisionInfo
-	for _, rev := range revList.Revisions {
-		revInfo := &RevisionInfo{
-			ID:          rev.Id,
-			Size:        rev.Size,
-			MimeType:    rev.MimeType,
-			KeepForever: rev.KeepForever,
-			Published:   rev.Published,
-		}
-
-		if rev.ModifiedTime != "" {
-			if t, err := time.Parse(time.RFC3339, rev.ModifiedTime); err == nil {
-				revInfo.ModifiedTime = t
-			}
-		}
-
-		if rev.LastModifyingUser != nil {
-			revInfo.ModifiedBy = rev.LastModifyingUser.DisplayName
-			if revInfo.ModifiedBy == "" {
-				revInfo.ModifiedBy = rev.LastModifyingUser.EmailAddress
-			}
-		}
-
-		revisions = append(revisions, revInfo)
+	for _, rev := range revList {
+		revisions = append(revisions, newRevisionInfo(rev))
 	}
 
 	return revisions, nil
 }
 
 // GetRevision gets a specific revision of a file.
-func (ds *Service) GetRevision(fileID, revisionID string) (*RevisionInfo, error) {
-	rev, err := ds.API.Revisions.Get(fileID, revisionID).
-		Fields("id, modifiedTime, size, mimeType, lastModifyingUser, keepForever, published").
-		Do()
+func (ds *Service) GetRevision(ctx context.Context, fileID, revisionID string) (*RevisionInfo, error) {
+	rev, err := ds.Backend.GetRevision(ctx, fileID, revisionID, revisionInfoFields)
 	if err != nil {
 		return nil, fmt.Errorf("unable to get revision: %v", err)
 	}
 
+	return newRevisionInfo(rev), nil
+}
+
+// newRevisionInfo converts a revision fetched with revisionInfoFields.
+func newRevisionInfo(rev *drive.Revision) *RevisionInfo {
 	revInfo := &RevisionInfo{
 		ID:          rev.Id,
 		Size:        rev.Size,
@@ -183,7 +164,7 @@ func (ds *Service) GetRevision(fileID, revisionID string) (*RevisionInfo, error)
 		}
 	}
 
-	return revInfo, nil
+	return revInfo
 }
 
 // DriveActivityInfo represents a drive activity event.
@@ -197,7 +178,7 @@ type DriveActivityInfo struct {
 }
 
 // QueryDriveActivity queries the Drive Activity API for recent activities.
-func QueryDriveActivity(activityService *driveactivity.Service, daysBack int, maxResults int64) ([]*DriveActivityInfo, error) {
+func QueryDriveActivity(ctx context.Context, activityService *driveactivity.Service, daysBack int, maxResults int64) ([]*DriveActivityInfo, error) {
 	// Build the request
 	pageSize := int64(100) // API max per page
 	if maxResults > 0 && maxResults < pageSize {
@@ -216,49 +197,20 @@ func QueryDriveActivity(activityService *driveactivity.Service, daysBack int, ma
 
 	var activities []*DriveActivityInfo
 	pageToken := ""
-	pageCount := 0
-	maxPagesPerBatch := 90 // Stay under 100 queries/minute limit
 
-	// Fetch all pages until we reach maxResults or no more pages
+	// Fetch all pages until we reach maxResults or no more pages.
+	// Rate limiting (429 / rateLimitExceeded) is retried by the client's
+	// transport, see internal/retry.
 	for {
 		if pageToken != "" {
 			req.PageToken = pageToken
 		}
 
-		// Query activities with retry logic for rate limiting
-		var resp *driveactivity.QueryDriveActivityResponse
-		var err error
-		maxRetries := 3
-		baseDelay := 2 * time.Second
-
-		for retry := 0; retry <= maxRetries; retry++ {
-			resp, err = activityService.Activity.Query(req).Do()
-			if err == nil {
-				break
-			}
-
-			// Check if it's a rate limit error
-			if strings.Contains(err.Error(), "429") || strings.Contains(err.Error(), "rateLimitExceeded") {
-				if retry < maxRetries {
-					// Exponential backoff: 2s, 4s, 8s
-					delay := baseDelay * time.Duration(1<<uint(retry))
-					fmt.Fprintf(os.Stderr, "⚠ Rate limit hit. Waiting %v before retry %d/%d...\n", delay, retry+1, maxRetries)
-					time.Sleep(delay)
-					continue
-				}
-			}
+		resp, err := activityService.Activity.Query(req).Context(ctx).Do()
+		if err != nil {
 			return nil, fmt.Errorf("unable to query drive activity: %v", err)
 		}
 
-		pageCount++
-
-		// Rate limiting: if we've made many requests, pause
-		if pageCount%maxPagesPerBatch == 0 {
-			fmt.Fprintf(os.Stderr, "⏸  Fetched %d pages (%d activities). Pausing 60s to respect rate limits...\n",
-				pageCount, len(activities))
-			time.Sleep(60 * time.Second)
-		}
-
 		// Process activities from this page
 		for _, activity := range resp.Activities {
 			activityInfo := &DriveActivityInfo{
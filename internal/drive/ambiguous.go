@@ -0,0 +1,83 @@
+package drive
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// AmbiguousPathError is returned when several items share a name in the same
+// folder and the Service has no Picker to choose between them.
+type AmbiguousPathError struct {
+	Name       string
+	ParentID   string
+	Candidates []*drive.File
+}
+
+func (e *AmbiguousPathError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "ambiguous name %q: %d items match in folder %s", e.Name, len(e.Candidates), e.ParentID)
+	for _, c := range e.Candidates {
+		fmt.Fprintf(&b, "\n  %s  modified %s  size %s  owner %s",
+			c.Id, orNA(c.ModifiedTime), candidateSize(c), orNA(CandidateOwner(c)))
+	}
+	return b.String()
+}
+
+func orNA(s string) string {
+	if s == "" {
+		return "N/A"
+	}
+	return s
+}
+
+func candidateSize(f *drive.File) string {
+	if f.MimeType == DriveFolderMimeType || f.Size == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d bytes", f.Size)
+}
+
+// CandidateOwner returns the email (or display name) of the first owner of f.
+func CandidateOwner(f *drive.File) string {
+	if len(f.Owners) == 0 {
+		return ""
+	}
+	if f.Owners[0].EmailAddress != "" {
+		return f.Owners[0].EmailAddress
+	}
+	return f.Owners[0].DisplayName
+}
+
+// Picker chooses one of the candidates of an ambiguous name. It may return
+// the error itself to refuse to choose.
+type Picker func(ctx context.Context, amb *AmbiguousPathError) (*drive.File, error)
+
+// PickNewest picks the most recently modified candidate.
+func PickNewest(_ context.Context, amb *AmbiguousPathError) (*drive.File, error) {
+	return pickByTime(amb, func(a, b time.Time) bool { return a.After(b) }), nil
+}
+
+// PickOldest picks the least recently modified candidate.
+func PickOldest(_ context.Context, amb *AmbiguousPathError) (*drive.File, error) {
+	return pickByTime(amb, func(a, b time.Time) bool { return a.Before(b) }), nil
+}
+
+// pickByTime returns the candidate whose modification time is preferred by
+// better. Ties keep the first candidate, in API order.
+func pickByTime(amb *AmbiguousPathError, better func(a, b time.Time) bool) *drive.File {
+	var (
+		best     *drive.File
+		bestTime time.Time
+	)
+	for _, c := range amb.Candidates {
+		t, _ := time.Parse(time.RFC3339, c.ModifiedTime)
+		if best == nil || better(t, bestTime) {
+			best, bestTime = c, t
+		}
+	}
+	return best
+}
@@ -0,0 +1,59 @@
+package drive
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/drive/v3"
+)
+
+func newAmbiguous() *AmbiguousPathError {
+	return &AmbiguousPathError{
+		Name:     "q3.pdf",
+		ParentID: "reports-id",
+		Candidates: []*drive.File{
+			{Id: "mid", ModifiedTime: "2025-02-01T10:00:00.000Z", Size: 200,
+				Owners: []*drive.User{{EmailAddress: "bob@example.com"}}},
+			{Id: "new", ModifiedTime: "2025-03-01T10:00:00.000Z", Size: 300},
+			{Id: "old", ModifiedTime: "2025-01-01T10:00:00.000Z", Size: 100,
+				Owners: []*drive.User{{DisplayName: "Alice"}}},
+		},
+	}
+}
+
+func TestAmbiguousPathErrorMessage(t *testing.T) {
+	msg := newAmbiguous().Error()
+	for _, want := range []string{
+		`ambiguous name "q3.pdf": 3 items match in folder reports-id`,
+		"mid  modified 2025-02-01T10:00:00.000Z  size 200 bytes  owner bob@example.com",
+		"new  modified 2025-03-01T10:00:00.000Z  size 300 bytes  owner N/A",
+		"owner Alice",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error message missing %q:\n%s", want, msg)
+		}
+	}
+}
+
+func TestPickers(t *testing.T) {
+	cases := []struct {
+		name   string
+		pick   Picker
+		wantID string
+	}{
+		{"newest", PickNewest, "new"},
+		{"oldest", PickOldest, "old"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.pick(context.Background(), newAmbiguous())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Id != tc.wantID {
+				t.Errorf("picked %s, want %s", got.Id, tc.wantID)
+			}
+		})
+	}
+}
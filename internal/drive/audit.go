@@ -0,0 +1,253 @@
+package drive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// auditPermissionFields are the permission fields a sharing audit needs:
+// permissionDetails tells inherited grants apart in Shared Drives.
+const auditPermissionFields = permissionFields + ", permissionDetails(inherited, inheritedFrom)"
+
+// SharingEntry is a permission found on an item of an audited folder tree.
+type SharingEntry struct {
+	// Path is the path of the item, starting with the name of the audited
+	// folder.
+	Path       string
+	FileID     string
+	MimeType   string
+	Permission *drive.Permission
+	// Principal is who the permission is for, as returned by Principal.
+	Principal string
+	// Inherited is set for grants the item gets from a folder above it,
+	// whose ID is InheritedFrom when known.
+	Inherited     bool
+	InheritedFrom string
+	// Public is set for links anyone can open.
+	Public bool
+	// External is set for users, groups and domains outside the user's
+	// domain.
+	External bool
+}
+
+// SharingAuditOptions holds options for AuditSharing.
+type SharingAuditOptions struct {
+	// Domain is the user's own domain, which external principals are not
+	// part of. Empty uses the domain of the user's email address.
+	Domain string
+	// Principal, when set, keeps only the entries of this principal.
+	Principal string
+}
+
+// AuditSharing walks the tree under folderID and calls fn for every
+// permission of every item, the folder included. Shortcuts are reported but
+// not followed.
+//
+// Drive only tells inherited grants apart in Shared Drives. Elsewhere a
+// grant is counted as inherited when the folder holding the item grants the
+// same role to the same principal, so grants on the audited folder itself
+// count as direct. Ownership is never inherited.
+func (ds *Service) AuditSharing(ctx context.Context, folderID string, opts SharingAuditOptions, fn func(*SharingEntry)) error {
+	if opts.Domain == "" {
+		about, err := ds.Backend.GetAbout(ctx, "user(emailAddress)")
+		if err != nil {
+			return fmt.Errorf("unable to get the user's domain: %w", err)
+		}
+		if about.User != nil {
+			opts.Domain = emailDomain(about.User.EmailAddress)
+		}
+	}
+
+	root, err := ds.Backend.GetFile(ctx, folderID, "id, name, mimeType")
+	if err != nil {
+		return err
+	}
+	return ds.auditItem(ctx, root, root.Name, nil, opts, fn)
+}
+
+// auditItem reports the permissions of item and, for folders, of the
+// items below it. sources maps the grants of the folder holding item, as
+// returned by grantKey, to the ID of the folder they come from.
+func (ds *Service) auditItem(ctx context.Context, item *drive.File, itemPath string, sources map[string]string, opts SharingAuditOptions, fn func(*SharingEntry)) error {
+	perms, err := ds.Backend.ListPermissions(ctx, item.Id, auditPermissionFields)
+	if err != nil {
+		return fmt.Errorf("unable to list permissions of %s: %w", itemPath, err)
+	}
+
+	grants := make(map[string]string, len(perms))
+	for _, p := range perms {
+		entry := &SharingEntry{
+			Path:       itemPath,
+			FileID:     item.Id,
+			MimeType:   item.MimeType,
+			Permission: p,
+			Principal:  Principal(p),
+			Public:     p.Type == "anyone",
+		}
+		if d := principalDomain(p); d != "" && opts.Domain != "" {
+			entry.External = !strings.EqualFold(d, opts.Domain)
+		}
+		entry.Inherited, entry.InheritedFrom = inheritance(p)
+		if from, ok := sources[grantKey(p)]; ok && p.PermissionDetails == nil && p.Role != "owner" {
+			entry.Inherited, entry.InheritedFrom = true, from
+		}
+
+		grants[grantKey(p)] = item.Id
+		if entry.Inherited && entry.InheritedFrom != "" {
+			grants[grantKey(p)] = entry.InheritedFrom
+		}
+		if opts.Principal == "" || MatchPrincipal(p, opts.Principal) {
+			fn(entry)
+		}
+	}
+
+	if item.MimeType != DriveFolderMimeType {
+		return nil
+	}
+	for child, err := range ds.FolderItems(ctx, item.Id) {
+		if err != nil {
+			return err
+		}
+		if err := ds.auditItem(ctx, child, path.Join(itemPath, child.Name), grants, opts, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AccessRemoval is the outcome of removing one permission in StripAccess.
+type AccessRemoval struct {
+	Path       string
+	FileID     string
+	Permission *drive.Permission
+	// Skipped is why the permission was kept, empty when it was removed
+	// or Err is set.
+	Skipped string
+	Err     error
+}
+
+// StripAccess removes the permissions of principal from the item folderID
+// and every item under it, calling fn for each permission it finds. It
+// goes on after failures, which are reported through fn. Owners cannot be
+// removed, and grants a Shared Drive item inherits from outside the tree
+// are kept; grants inherited from inside it go away with their source.
+func (ds *Service) StripAccess(ctx context.Context, folderID, principal string, fn func(AccessRemoval)) error {
+	root, err := ds.Backend.GetFile(ctx, folderID, "id, name, mimeType")
+	if err != nil {
+		return err
+	}
+	inTree := map[string]bool{}
+	return ds.stripItem(ctx, root, root.Name, principal, inTree, fn)
+}
+
+func (ds *Service) stripItem(ctx context.Context, item *drive.File, itemPath, principal string, inTree map[string]bool, fn func(AccessRemoval)) error {
+	inTree[item.Id] = true
+	perms, err := ds.Backend.ListPermissions(ctx, item.Id, auditPermissionFields)
+	if err != nil {
+		return fmt.Errorf("unable to list permissions of %s: %w", itemPath, err)
+	}
+
+	for _, p := range perms {
+		if !MatchPrincipal(p, principal) {
+			continue
+		}
+		res := AccessRemoval{Path: itemPath, FileID: item.Id, Permission: p}
+		inherited, from := inheritance(p)
+		switch {
+		case inherited && inTree[from]:
+			continue
+		case inherited:
+			res.Skipped = "inherited from outside the folder"
+		case p.Role == "owner":
+			res.Skipped = "owner, transfer the ownership first"
+		default:
+			err := ds.Backend.DeletePermission(ctx, item.Id, p.Id)
+			if isNotFound(err) {
+				// Removed along with the grant of a folder above
+				continue
+			}
+			res.Err = err
+		}
+		fn(res)
+	}
+
+	if item.MimeType != DriveFolderMimeType {
+		return nil
+	}
+	for child, err := range ds.FolderItems(ctx, item.Id) {
+		if err != nil {
+			return err
+		}
+		if err := ds.stripItem(ctx, child, path.Join(itemPath, child.Name), principal, inTree, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Principal returns who a permission is for: the email address of a user
+// or group, the name of a domain, or "anyone".
+func Principal(p *drive.Permission) string {
+	switch p.Type {
+	case "domain":
+		return p.Domain
+	case "anyone":
+		return "anyone"
+	}
+	return p.EmailAddress
+}
+
+// MatchPrincipal reports whether p is for principal, an email address, a
+// domain or "anyone", ignoring case.
+func MatchPrincipal(p *drive.Permission, principal string) bool {
+	return strings.EqualFold(Principal(p), principal)
+}
+
+// principalDomain returns the domain of the principal of p, or "" for
+// anyone.
+func principalDomain(p *drive.Permission) string {
+	switch p.Type {
+	case "domain":
+		return p.Domain
+	case "user", "group":
+		return emailDomain(p.EmailAddress)
+	}
+	return ""
+}
+
+func emailDomain(email string) string {
+	_, domain, _ := strings.Cut(email, "@")
+	return domain
+}
+
+// grantKey identifies the grant of a role to a principal.
+func grantKey(p *drive.Permission) string {
+	return p.Type + "\x00" + strings.ToLower(Principal(p)) + "\x00" + p.Role
+}
+
+// inheritance returns whether Drive reports p as inherited, and from
+// which item. A permission is inherited when none of its details is direct.
+func inheritance(p *drive.Permission) (bool, string) {
+	if len(p.PermissionDetails) == 0 {
+		return false, ""
+	}
+	for _, d := range p.PermissionDetails {
+		if !d.Inherited {
+			return false, ""
+		}
+	}
+	return true, p.PermissionDetails[0].InheritedFrom
+}
+
+// isNotFound reports whether err is Drive answering 404.
+func isNotFound(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound
+}
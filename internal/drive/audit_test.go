@@ -0,0 +1,110 @@
+package drive_test
+
+import (
+	"testing"
+
+	driveapi "google.golang.org/api/drive/v3"
+
+	"gdrive/internal/drive"
+	"gdrive/internal/drivetest"
+)
+
+func TestAuditSharing(t *testing.T) {
+	srv, ds := newFakeService(t)
+	ctx := t.Context()
+	projects := srv.AddFolder(drivetest.RootID, "Projects")
+	spec := srv.AddFile(projects, "spec.txt", []byte("spec"))
+	sub := srv.AddFolder(projects, "Sub")
+	notes := srv.AddFile(sub, "notes.txt", []byte("notes"))
+
+	alice := driveapi.Permission{Type: "user", Role: "writer", EmailAddress: "alice@example.com"}
+	for _, id := range []string{projects, spec, sub, notes} {
+		srv.Share(id, alice)
+	}
+	srv.Share(projects, driveapi.Permission{Type: "user", Role: "reader", EmailAddress: "bob@partner.com"})
+	srv.Share(spec, driveapi.Permission{Type: "user", Role: "commenter", EmailAddress: "bob@partner.com"})
+	srv.Share(spec, driveapi.Permission{Type: "anyone", Role: "reader"})
+
+	audit := func(opts drive.SharingAuditOptions) []*drive.SharingEntry {
+		t.Helper()
+		var entries []*drive.SharingEntry
+		if err := ds.AuditSharing(ctx, projects, opts, func(e *drive.SharingEntry) { entries = append(entries, e) }); err != nil {
+			t.Fatal(err)
+		}
+		return entries
+	}
+
+	all := audit(drive.SharingAuditOptions{})
+	if len(all) != 11 {
+		t.Fatalf("got %d entries, want 11", len(all))
+	}
+	for _, e := range all {
+		switch {
+		case e.Principal == "anyone":
+			if !e.Public || e.Inherited || e.Path != "Projects/spec.txt" {
+				t.Errorf("public entry = %+v", e)
+			}
+		case e.Principal == "bob@partner.com":
+			if !e.External || e.Inherited {
+				t.Errorf("external entry = %+v", e)
+			}
+		case e.Principal == "alice@example.com" && e.FileID == projects:
+			if e.Inherited || e.External || e.Path != "Projects" {
+				t.Errorf("direct entry = %+v", e)
+			}
+		case e.Principal == "alice@example.com":
+			if !e.Inherited || e.InheritedFrom != projects {
+				t.Errorf("inherited entry = %+v", e)
+			}
+		}
+	}
+
+	if bob := audit(drive.SharingAuditOptions{Principal: "BOB@partner.com"}); len(bob) != 2 {
+		t.Errorf("entries of bob = %d, want 2", len(bob))
+	}
+	if external := audit(drive.SharingAuditOptions{Domain: "partner.com", Principal: "alice@example.com"}); !external[0].External {
+		t.Errorf("alice should be external to partner.com: %+v", external[0])
+	}
+
+	var removed []drive.AccessRemoval
+	if err := ds.StripAccess(ctx, projects, "alice@example.com", func(r drive.AccessRemoval) { removed = append(removed, r) }); err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 4 {
+		t.Errorf("removed %d permissions, want 4", len(removed))
+	}
+	for _, r := range removed {
+		if r.Err != nil || r.Skipped != "" {
+			t.Errorf("removal = %+v", r)
+		}
+	}
+	if left := audit(drive.SharingAuditOptions{Principal: "alice@example.com"}); len(left) != 0 {
+		t.Errorf("alice still has %d permissions", len(left))
+	}
+
+	var owner []drive.AccessRemoval
+	if err := ds.StripAccess(ctx, spec, drivetest.Me.EmailAddress, func(r drive.AccessRemoval) { owner = append(owner, r) }); err != nil {
+		t.Fatal(err)
+	}
+	if len(owner) != 1 || owner[0].Skipped == "" {
+		t.Errorf("owner removal = %+v", owner)
+	}
+}
+
+func TestStripAccessKeepsSharedDriveMembership(t *testing.T) {
+	srv, ds := newFakeService(t)
+	team := srv.AddSharedDrive("Team")
+	id := srv.AddFile(team, "plan.txt", []byte("plan"))
+	srv.Share(id, driveapi.Permission{
+		Type: "user", Role: "writer", EmailAddress: "carol@example.com",
+		PermissionDetails: []*driveapi.PermissionPermissionDetails{{Inherited: true, InheritedFrom: team}},
+	})
+
+	var results []drive.AccessRemoval
+	if err := ds.StripAccess(t.Context(), id, "carol@example.com", func(r drive.AccessRemoval) { results = append(results, r) }); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Skipped == "" || len(srv.Permissions(id)) != 1 {
+		t.Errorf("results = %+v, permissions = %+v", results, srv.Permissions(id))
+	}
+}
@@ -0,0 +1,384 @@
+package drive
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// Backend is the storage API the Service is built on: files, exports,
+// permissions, revisions, comments, the changes feed and Shared Drives.
+// APIBackend implements it with the Drive v3 API; tests can run the Service
+// against the fake server in gdrive/internal/drivetest or substitute their
+// own implementation.
+//
+// Fields arguments are Drive partial-response selectors such as
+// "id, name, parents". Every call sees Shared Drive items.
+type Backend interface {
+	// ListFiles returns one page of files.
+	ListFiles(ctx context.Context, opts ListOptions) (*drive.FileList, error)
+	// GetFile returns the metadata of a file.
+	GetFile(ctx context.Context, fileID, fields string) (*drive.File, error)
+	// CreateFile creates a file, with content when media is non-nil.
+	CreateFile(ctx context.Context, meta *drive.File, media *Media, fields string) (*drive.File, error)
+	// UpdateFile patches the metadata, parents and optionally the content
+	// of a file.
+	UpdateFile(ctx context.Context, fileID string, meta *drive.File, opts UpdateOptions, fields string) (*drive.File, error)
+	// CopyFile copies a file; meta overrides the name and parents.
+	CopyFile(ctx context.Context, fileID string, meta *drive.File, fields string) (*drive.File, error)
+	// DeleteFile permanently deletes a file, bypassing the trash.
+	DeleteFile(ctx context.Context, fileID string) error
+	// EmptyTrash permanently deletes every file the user has trashed.
+	EmptyTrash(ctx context.Context) error
+	// DownloadFile returns the content of a binary file from byte offset
+	// on. A server that ignores the range answers 200 with the whole file.
+	DownloadFile(ctx context.Context, fileID string, offset int64) (*http.Response, error)
+	// ExportFile returns a Google Workspace file converted to mimeType.
+	ExportFile(ctx context.Context, fileID, mimeType string) (*http.Response, error)
+
+	// ListPermissions returns every permission of a file.
+	ListPermissions(ctx context.Context, fileID, fields string) ([]*drive.Permission, error)
+	// CreatePermission grants perm on a file.
+	CreatePermission(ctx context.Context, fileID string, perm *drive.Permission, opts PermissionOptions) (*drive.Permission, error)
+	// UpdatePermission changes the role, expiration or pending owner flag
+	// of a permission.
+	UpdatePermission(ctx context.Context, fileID, permissionID string, perm *drive.Permission, opts PermissionOptions) (*drive.Permission, error)
+	// DeletePermission revokes a permission.
+	DeletePermission(ctx context.Context, fileID, permissionID string) error
+
+	// ListRevisions returns every revision of a file, oldest first.
+	ListRevisions(ctx context.Context, fileID, fields string) ([]*drive.Revision, error)
+	// GetRevision returns the metadata of one revision.
+	GetRevision(ctx context.Context, fileID, revisionID, fields string) (*drive.Revision, error)
+	// DownloadRevision returns the content of a revision of a binary file.
+	DownloadRevision(ctx context.Context, fileID, revisionID string) (*http.Response, error)
+	// UpdateRevision changes the fields set in rev.
+	UpdateRevision(ctx context.Context, fileID, revisionID string, rev *drive.Revision, fields string) (*drive.Revision, error)
+	// DeleteRevision permanently deletes a revision of a binary file.
+	DeleteRevision(ctx context.Context, fileID, revisionID string) error
+
+	// ListComments returns every comment of a file with its replies,
+	// oldest first. Deleted comments are left out.
+	ListComments(ctx context.Context, fileID, fields string) ([]*drive.Comment, error)
+	// GetComment returns one comment with its replies.
+	GetComment(ctx context.Context, fileID, commentID, fields string) (*drive.Comment, error)
+	// CreateComment adds a comment to a file.
+	CreateComment(ctx context.Context, fileID string, comment *drive.Comment, fields string) (*drive.Comment, error)
+	// DeleteComment deletes a comment and its replies.
+	DeleteComment(ctx context.Context, fileID, commentID string) error
+	// CreateReply adds a reply to a comment. A reply whose Action is
+	// "resolve" or "reopen" also changes the state of the comment.
+	CreateReply(ctx context.Context, fileID, commentID string, reply *drive.Reply, fields string) (*drive.Reply, error)
+
+	// GetStartPageToken returns the token of the current end of the changes
+	// feed.
+	GetStartPageToken(ctx context.Context) (string, error)
+	// ListChanges returns the changes recorded since pageToken.
+	ListChanges(ctx context.Context, pageToken string, pageSize int64, fields string) (*drive.ChangeList, error)
+
+	// ListDrives returns one page of the Shared Drives the user belongs to.
+	ListDrives(ctx context.Context, pageToken string, pageSize int64, fields string) (*drive.DriveList, error)
+	// GetDrive returns the metadata of a Shared Drive.
+	GetDrive(ctx context.Context, driveID, fields string) (*drive.Drive, error)
+
+	// GetAbout returns information about the user and the capabilities of
+	// Drive, such as the formats files can be exported to.
+	GetAbout(ctx context.Context, fields string) (*drive.About, error)
+}
+
+// ListOptions selects the files returned by Backend.ListFiles.
+type ListOptions struct {
+	Query     string // Drive query, see gdrive/internal/query
+	Fields    string // e.g. "nextPageToken, files(id, name)"
+	OrderBy   string
+	PageSize  int64
+	PageToken string
+	DriveID   string // limit to one Shared Drive; empty spans all drives
+}
+
+// Media is the content of a created or updated file.
+type Media struct {
+	Body io.Reader
+	// ContentType is the MIME type of Body when it differs from the file's,
+	// as when converting to a Google Workspace type. Empty means the
+	// file's MIME type.
+	ContentType string
+}
+
+// UpdateOptions holds the optional parts of Backend.UpdateFile.
+type UpdateOptions struct {
+	Media         *Media
+	AddParents    string // comma-separated folder IDs
+	RemoveParents string // comma-separated folder IDs
+}
+
+// PermissionOptions holds the optional parts of Backend.CreatePermission
+// and Backend.UpdatePermission.
+type PermissionOptions struct {
+	Fields           string
+	Notify           bool // send a notification email (creation of users and groups only)
+	EmailMessage     string
+	RemoveExpiration bool // make the permission permanent (updates only)
+}
+
+// APIBackend implements Backend with the Drive v3 API.
+type APIBackend struct {
+	API *drive.Service
+}
+
+// NewAPIBackend returns a Backend calling the Drive API through api.
+func NewAPIBackend(api *drive.Service) *APIBackend {
+	return &APIBackend{API: api}
+}
+
+func mediaOptions(m *Media) []googleapi.MediaOption {
+	if m.ContentType == "" {
+		return nil
+	}
+	return []googleapi.MediaOption{googleapi.ContentType(m.ContentType)}
+}
+
+// ListFiles implements Backend.
+func (b *APIBackend) ListFiles(ctx context.Context, opts ListOptions) (*drive.FileList, error) {
+	call := b.API.Files.List().
+		SupportsAllDrives(true).
+		IncludeItemsFromAllDrives(true)
+	if opts.DriveID != "" {
+		call = call.Corpora("drive").DriveId(opts.DriveID)
+	} else {
+		call = call.Corpora("allDrives")
+	}
+	if opts.Query != "" {
+		call = call.Q(opts.Query)
+	}
+	if opts.Fields != "" {
+		call = call.Fields(googleapi.Field(opts.Fields))
+	}
+	if opts.OrderBy != "" {
+		call = call.OrderBy(opts.OrderBy)
+	}
+	if opts.PageSize > 0 {
+		call = call.PageSize(opts.PageSize)
+	}
+	if opts.PageToken != "" {
+		call = call.PageToken(opts.PageToken)
+	}
+	return call.Context(ctx).Do()
+}
+
+// GetFile implements Backend.
+func (b *APIBackend) GetFile(ctx context.Context, fileID, fields string) (*drive.File, error) {
+	return b.API.Files.Get(fileID).Fields(googleapi.Field(fields)).
+		SupportsAllDrives(true).Context(ctx).Do()
+}
+
+// CreateFile implements Backend.
+func (b *APIBackend) CreateFile(ctx context.Context, meta *drive.File, media *Media, fields string) (*drive.File, error) {
+	call := b.API.Files.Create(meta).Fields(googleapi.Field(fields)).SupportsAllDrives(true)
+	if media != nil {
+		call = call.Media(media.Body, mediaOptions(media)...)
+	}
+	return call.Context(ctx).Do()
+}
+
+// UpdateFile implements Backend.
+func (b *APIBackend) UpdateFile(ctx context.Context, fileID string, meta *drive.File, opts UpdateOptions, fields string) (*drive.File, error) {
+	call := b.API.Files.Update(fileID, meta).Fields(googleapi.Field(fields)).SupportsAllDrives(true)
+	if opts.AddParents != "" {
+		call = call.AddParents(opts.AddParents)
+	}
+	if opts.RemoveParents != "" {
+		call = call.RemoveParents(opts.RemoveParents)
+	}
+	if opts.Media != nil {
+		call = call.Media(opts.Media.Body, mediaOptions(opts.Media)...)
+	}
+	return call.Context(ctx).Do()
+}
+
+// CopyFile implements Backend.
+func (b *APIBackend) CopyFile(ctx context.Context, fileID string, meta *drive.File, fields string) (*drive.File, error) {
+	return b.API.Files.Copy(fileID, meta).Fields(googleapi.Field(fields)).
+		SupportsAllDrives(true).Context(ctx).Do()
+}
+
+// DeleteFile implements Backend.
+func (b *APIBackend) DeleteFile(ctx context.Context, fileID string) error {
+	return b.API.Files.Delete(fileID).SupportsAllDrives(true).Context(ctx).Do()
+}
+
+// EmptyTrash implements Backend.
+func (b *APIBackend) EmptyTrash(ctx context.Context) error {
+	return b.API.Files.EmptyTrash().Context(ctx).Do()
+}
+
+// DownloadFile implements Backend.
+func (b *APIBackend) DownloadFile(ctx context.Context, fileID string, offset int64) (*http.Response, error) {
+	call := b.API.Files.Get(fileID).SupportsAllDrives(true).Context(ctx)
+	if offset > 0 {
+		call.Header().Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	return call.Download()
+}
+
+// ExportFile implements Backend.
+func (b *APIBackend) ExportFile(ctx context.Context, fileID, mimeType string) (*http.Response, error) {
+	return b.API.Files.Export(fileID, mimeType).Context(ctx).Download()
+}
+
+// ListPermissions implements Backend.
+func (b *APIBackend) ListPermissions(ctx context.Context, fileID, fields string) ([]*drive.Permission, error) {
+	var perms []*drive.Permission
+	call := b.API.Permissions.List(fileID).
+		Fields(googleapi.Field("nextPageToken, permissions(" + fields + ")")).
+		SupportsAllDrives(true)
+	err := call.Pages(ctx, func(list *drive.PermissionList) error {
+		perms = append(perms, list.Permissions...)
+		return nil
+	})
+	return perms, err
+}
+
+// CreatePermission implements Backend.
+func (b *APIBackend) CreatePermission(ctx context.Context, fileID string, perm *drive.Permission, opts PermissionOptions) (*drive.Permission, error) {
+	call := b.API.Permissions.Create(fileID, perm).SupportsAllDrives(true)
+	if opts.Fields != "" {
+		call = call.Fields(googleapi.Field(opts.Fields))
+	}
+	if perm.Type == "user" || perm.Type == "group" {
+		call = call.SendNotificationEmail(opts.Notify)
+		if opts.EmailMessage != "" {
+			call = call.EmailMessage(opts.EmailMessage)
+		}
+	}
+	return call.Context(ctx).Do()
+}
+
+// UpdatePermission implements Backend.
+func (b *APIBackend) UpdatePermission(ctx context.Context, fileID, permissionID string, perm *drive.Permission, opts PermissionOptions) (*drive.Permission, error) {
+	call := b.API.Permissions.Update(fileID, permissionID, perm).SupportsAllDrives(true)
+	if opts.Fields != "" {
+		call = call.Fields(googleapi.Field(opts.Fields))
+	}
+	if opts.RemoveExpiration {
+		call = call.RemoveExpiration(true)
+	}
+	return call.Context(ctx).Do()
+}
+
+// DeletePermission implements Backend.
+func (b *APIBackend) DeletePermission(ctx context.Context, fileID, permissionID string) error {
+	return b.API.Permissions.Delete(fileID, permissionID).
+		SupportsAllDrives(true).Context(ctx).Do()
+}
+
+// ListRevisions implements Backend.
+func (b *APIBackend) ListRevisions(ctx context.Context, fileID, fields string) ([]*drive.Revision, error) {
+	var revs []*drive.Revision
+	call := b.API.Revisions.List(fileID).
+		Fields(googleapi.Field("nextPageToken, revisions(" + fields + ")"))
+	err := call.Pages(ctx, func(list *drive.RevisionList) error {
+		revs = append(revs, list.Revisions...)
+		return nil
+	})
+	return revs, err
+}
+
+// GetRevision implements Backend.
+func (b *APIBackend) GetRevision(ctx context.Context, fileID, revisionID, fields string) (*drive.Revision, error) {
+	return b.API.Revisions.Get(fileID, revisionID).Fields(googleapi.Field(fields)).Context(ctx).Do()
+}
+
+// DownloadRevision implements Backend.
+func (b *APIBackend) DownloadRevision(ctx context.Context, fileID, revisionID string) (*http.Response, error) {
+	return b.API.Revisions.Get(fileID, revisionID).Context(ctx).Download()
+}
+
+// UpdateRevision implements Backend.
+func (b *APIBackend) UpdateRevision(ctx context.Context, fileID, revisionID string, rev *drive.Revision, fields string) (*drive.Revision, error) {
+	return b.API.Revisions.Update(fileID, revisionID, rev).Fields(googleapi.Field(fields)).Context(ctx).Do()
+}
+
+// DeleteRevision implements Backend.
+func (b *APIBackend) DeleteRevision(ctx context.Context, fileID, revisionID string) error {
+	return b.API.Revisions.Delete(fileID, revisionID).Context(ctx).Do()
+}
+
+// ListComments implements Backend.
+func (b *APIBackend) ListComments(ctx context.Context, fileID, fields string) ([]*drive.Comment, error) {
+	var comments []*drive.Comment
+	call := b.API.Comments.List(fileID).PageSize(100).
+		Fields(googleapi.Field("nextPageToken, comments(" + fields + ")"))
+	err := call.Pages(ctx, func(list *drive.CommentList) error {
+		comments = append(comments, list.Comments...)
+		return nil
+	})
+	return comments, err
+}
+
+// GetComment implements Backend.
+func (b *APIBackend) GetComment(ctx context.Context, fileID, commentID, fields string) (*drive.Comment, error) {
+	return b.API.Comments.Get(fileID, commentID).Fields(googleapi.Field(fields)).Context(ctx).Do()
+}
+
+// CreateComment implements Backend.
+func (b *APIBackend) CreateComment(ctx context.Context, fileID string, comment *drive.Comment, fields string) (*drive.Comment, error) {
+	return b.API.Comments.Create(fileID, comment).Fields(googleapi.Field(fields)).Context(ctx).Do()
+}
+
+// DeleteComment implements Backend.
+func (b *APIBackend) DeleteComment(ctx context.Context, fileID, commentID string) error {
+	return b.API.Comments.Delete(fileID, commentID).Context(ctx).Do()
+}
+
+// CreateReply implements Backend.
+func (b *APIBackend) CreateReply(ctx context.Context, fileID, commentID string, reply *drive.Reply, fields string) (*drive.Reply, error) {
+	return b.API.Replies.Create(fileID, commentID, reply).Fields(googleapi.Field(fields)).Context(ctx).Do()
+}
+
+// GetStartPageToken implements Backend.
+func (b *APIBackend) GetStartPageToken(ctx context.Context) (string, error) {
+	token, err := b.API.Changes.GetStartPageToken().SupportsAllDrives(true).Context(ctx).Do()
+	if err != nil {
+		return "", err
+	}
+	return token.StartPageToken, nil
+}
+
+// ListChanges implements Backend.
+func (b *APIBackend) ListChanges(ctx context.Context, pageToken string, pageSize int64, fields string) (*drive.ChangeList, error) {
+	call := b.API.Changes.List(pageToken).
+		SupportsAllDrives(true).
+		IncludeItemsFromAllDrives(true).
+		Fields(googleapi.Field(fields))
+	if pageSize > 0 {
+		call = call.PageSize(pageSize)
+	}
+	return call.Context(ctx).Do()
+}
+
+// ListDrives implements Backend.
+func (b *APIBackend) ListDrives(ctx context.Context, pageToken string, pageSize int64, fields string) (*drive.DriveList, error) {
+	call := b.API.Drives.List().Fields(googleapi.Field(fields))
+	if pageSize > 0 {
+		call = call.PageSize(pageSize)
+	}
+	if pageToken != "" {
+		call = call.PageToken(pageToken)
+	}
+	return call.Context(ctx).Do()
+}
+
+// GetDrive implements Backend.
+func (b *APIBackend) GetDrive(ctx context.Context, driveID, fields string) (*drive.Drive, error) {
+	return b.API.Drives.Get(driveID).Fields(googleapi.Field(fields)).Context(ctx).Do()
+}
+
+// GetAbout implements Backend.
+func (b *APIBackend) GetAbout(ctx context.Context, fields string) (*drive.About, error) {
+	return b.API.About.Get().Fields(googleapi.Field(fields)).Context(ctx).Do()
+}
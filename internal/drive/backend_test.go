@@ -0,0 +1,214 @@
+package drive_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gdrive/internal/drive"
+	"gdrive/internal/drivetest"
+)
+
+func newFakeService(t *testing.T) (*drivetest.Server, *drive.Service) {
+	t.Helper()
+	srv := drivetest.NewServer(t)
+	return srv, drive.NewService(srv.API(t))
+}
+
+func TestServiceAgainstFakeServer(t *testing.T) {
+	srv, ds := newFakeService(t)
+	ctx := t.Context()
+
+	folderID, err := ds.CreateFolderPath(ctx, "Projects/2025")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ds.ResolvePath(ctx, "/Projects/2025/", true); err != nil || got != folderID {
+		t.Fatalf("ResolvePath = %q, %v; want %q", got, err, folderID)
+	}
+
+	local := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(local, []byte("first"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fileID, err := ds.UploadFile(ctx, local, folderID, "", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A second upload of the same name replaces the content in place
+	if err := os.WriteFile(local, []byte("second"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if id, err := ds.UploadFile(ctx, local, folderID, "", false, false); err != nil || id != fileID {
+		t.Fatalf("re-upload = %q, %v; want %q", id, err, fileID)
+	}
+	if got := string(srv.Content(fileID)); got != "second" {
+		t.Errorf("content = %q", got)
+	}
+	revs, err := ds.ListRevisions(ctx, fileID)
+	if err != nil || len(revs) != 2 {
+		t.Fatalf("ListRevisions = %d, %v; want 2", len(revs), err)
+	}
+
+	out := filepath.Join(t.TempDir(), "out.txt")
+	if err := ds.DownloadFile(ctx, fileID, out, "", true, false); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(out); string(data) != "second" {
+		t.Errorf("downloaded %q", data)
+	}
+
+	archive := srv.AddFolder(drivetest.RootID, "Archive")
+	if _, err := ds.MoveFile(ctx, fileID, archive); err != nil {
+		t.Fatal(err)
+	}
+	path, err := ds.GetFilePath(ctx, fileID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, c := range path {
+		names = append(names, c.Name)
+	}
+	if got := strings.Join(names, "/"); !strings.HasSuffix(got, "Archive/notes.txt") {
+		t.Errorf("path after move = %q", got)
+	}
+
+	if err := ds.DeleteFile(ctx, fileID); err != nil {
+		t.Fatal(err)
+	}
+	if srv.File(fileID) != nil {
+		t.Error("file still exists after delete")
+	}
+}
+
+func TestResumableUploadAgainstFakeServer(t *testing.T) {
+	srv, ds := newFakeService(t)
+	ds.HTTP = srv.Client()
+	ds.ChunkSize = drive.UploadChunkAlign
+
+	data := make([]byte, 2*drive.UploadChunkAlign+100)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	local := filepath.Join(t.TempDir(), "big.bin")
+	if err := os.WriteFile(local, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	id, err := ds.UploadFile(t.Context(), local, drivetest.RootID, "", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := srv.File(id); f.Size != int64(len(data)) || f.Name != "big.bin" {
+		t.Errorf("uploaded %s with %d bytes", f.Name, f.Size)
+	}
+}
+
+func TestSharingAgainstFakeServer(t *testing.T) {
+	srv, ds := newFakeService(t)
+	ctx := t.Context()
+	id := srv.AddFile(drivetest.RootID, "report.pdf", []byte("%PDF"))
+
+	if _, err := ds.ShareFile(ctx, id, drive.ShareOptions{Email: "bob@example.com", Role: "reader"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ds.ShareWithAnyone(ctx, id, "reader"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ds.RemovePublicAccess(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	perms, err := ds.ListPermissions(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range perms {
+		if p.Type == "anyone" {
+			t.Error("public permission not removed")
+		}
+	}
+	if len(perms) != 2 {
+		t.Errorf("got %d permissions, want owner and bob", len(perms))
+	}
+}
+
+func TestPermissionTypesAgainstFakeServer(t *testing.T) {
+	srv, ds := newFakeService(t)
+	ctx := t.Context()
+	id := srv.AddFile(drivetest.RootID, "report.pdf", []byte("%PDF"))
+	expires := srv.Now().Add(30 * 24 * time.Hour).Truncate(time.Second)
+
+	group, err := ds.ShareFile(ctx, id, drive.ShareOptions{Type: "group", Email: "team@example.com", Role: "commenter", Expires: expires})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if group.Id == "" || group.Type != "group" || group.ExpirationTime != expires.Format(time.RFC3339) {
+		t.Errorf("group permission = %+v", group)
+	}
+	domain, err := ds.ShareFile(ctx, id, drive.ShareOptions{Type: "domain", Domain: "example.com", Role: "reader", Discoverable: true})
+	if err != nil || domain.Domain != "example.com" || !domain.AllowFileDiscovery {
+		t.Fatalf("domain permission = %+v, %v", domain, err)
+	}
+
+	for _, bad := range []drive.ShareOptions{
+		{Type: "group", Role: "reader"},
+		{Type: "domain", Role: "reader"},
+		{Type: "robot", Email: "r@example.com", Role: "reader"},
+		{Email: "bob@example.com", Role: "owner"},
+		{Type: "domain", Domain: "example.com", Role: "reader", Expires: expires},
+		{Email: "bob@example.com", Role: "reader", Discoverable: true},
+	} {
+		if _, err := ds.ShareFile(ctx, id, bad); err == nil {
+			t.Errorf("ShareFile(%+v) should fail", bad)
+		}
+	}
+
+	updated, err := ds.UpdatePermission(ctx, id, group.Id, drive.PermissionUpdate{Role: "writer", RemoveExpiration: true})
+	if err != nil || updated.Role != "writer" || updated.ExpirationTime != "" {
+		t.Fatalf("update = %+v, %v", updated, err)
+	}
+	if _, err := ds.UpdatePermission(ctx, id, group.Id, drive.PermissionUpdate{Expires: srv.Now().Add(-time.Hour)}); err == nil {
+		t.Error("expiration in the past should be refused")
+	}
+	if _, err := ds.UpdatePermission(ctx, id, group.Id, drive.PermissionUpdate{}); err == nil {
+		t.Error("empty update should be refused")
+	}
+
+	// Ownership goes to a new user through a writer permission they have to
+	// accept; an existing reader is promoted to writer first.
+	pending, err := ds.TransferOwnership(ctx, id, "carol@example.com")
+	if err != nil || !pending.PendingOwner || pending.Role != "writer" || pending.EmailAddress != "carol@example.com" {
+		t.Fatalf("transfer to a new user = %+v, %v", pending, err)
+	}
+	if _, err := ds.ShareFile(ctx, id, drive.ShareOptions{Email: "dan@example.com", Role: "reader"}); err != nil {
+		t.Fatal(err)
+	}
+	if pending, err := ds.TransferOwnership(ctx, id, "Dan@example.com"); err != nil || !pending.PendingOwner || pending.Role != "writer" {
+		t.Errorf("transfer to a reader = %+v, %v", pending, err)
+	}
+	if _, err := ds.TransferOwnership(ctx, id, drivetest.Me.EmailAddress); err == nil {
+		t.Error("transfer to the owner should fail")
+	}
+
+	shared := srv.AddSharedDrive("Team")
+	inDrive := srv.AddFile(shared, "plan.txt", []byte("x"))
+	if _, err := ds.TransferOwnership(ctx, inDrive, "carol@example.com"); err == nil {
+		t.Error("items in Shared Drives have no owner to transfer")
+	}
+}
+
+func TestSharedDrivePathAgainstFakeServer(t *testing.T) {
+	srv, ds := newFakeService(t)
+	driveID := srv.AddSharedDrive("Team")
+	reports := srv.AddFolder(driveID, "Reports")
+
+	got, err := ds.ResolvePath(t.Context(), "@Team/Reports", true)
+	if err != nil || got != reports {
+		t.Fatalf("ResolvePath = %q, %v; want %q", got, err, reports)
+	}
+	if srv.File(reports).DriveId != driveID {
+		t.Error("folder not in the Shared Drive")
+	}
+}
@@ -0,0 +1,256 @@
+package drive
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultPathCacheTTL is how long a cached path segment stays valid.
+const DefaultPathCacheTTL = 5 * time.Minute
+
+// pathCacheFilePerm matches the token file: the cache reveals folder names.
+const pathCacheFilePerm = 0600
+
+// PathCache caches path segment lookups: (parent ID, name) → item ID.
+//
+// ResolvePath and CreateFolderPath consult it before issuing a Files.List per
+// segment. Entries expire after the TTL and are dropped when the item they
+// point to (or its parent) is renamed, moved or deleted through the Service.
+// When backed by a file, every change is written through so that separate
+// invocations share the cache.
+//
+// The cache trades ambiguity detection for speed: a hit is not checked
+// against Drive, so a duplicate folder name appearing after the entry was
+// cached is only detected once the entry expires. All methods are safe on a nil *PathCache,
+// which behaves as a disabled cache.
+type PathCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	file    string
+	entries map[string]pathCacheEntry
+	hits    int
+	misses  int
+	now     func() time.Time
+}
+
+type pathCacheEntry struct {
+	Parent  string    `json:"parent"`
+	Name    string    `json:"name"`
+	ID      string    `json:"id"`
+	Expires time.Time `json:"expires"`
+}
+
+// PathCacheStats describes the content of a PathCache.
+type PathCacheStats struct {
+	File      string
+	FileSize  int64
+	TTL       time.Duration
+	Entries   int
+	Hits      int
+	Misses    int
+	OldestExp time.Time
+	NewestExp time.Time
+}
+
+// NewPathCache creates an in-memory path cache.
+func NewPathCache(ttl time.Duration) *PathCache {
+	return &PathCache{
+		ttl:     ttl,
+		entries: make(map[string]pathCacheEntry),
+		now:     time.Now,
+	}
+}
+
+// OpenPathCache creates a path cache persisted in file, loading any entries
+// already stored there. A missing file is not an error.
+func OpenPathCache(file string, ttl time.Duration) (*PathCache, error) {
+	c := NewPathCache(ttl)
+	c.file = file
+
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read path cache: %w", err)
+	}
+
+	var stored []pathCacheEntry
+	if err := json.Unmarshal(data, &stored); err != nil {
+		// A corrupt cache is only a missed optimisation; start afresh.
+		return c, nil
+	}
+	now := c.now()
+	for _, e := range stored {
+		if now.Before(e.Expires) {
+			c.entries[pathCacheKey(e.Parent, e.Name)] = e
+		}
+	}
+	return c, nil
+}
+
+func pathCacheKey(parentID, name string) string {
+	return parentID + "/" + name
+}
+
+// Get returns the cached ID of the item called name inside parentID.
+func (c *PathCache) Get(parentID, name string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := pathCacheKey(parentID, name)
+	e, ok := c.entries[key]
+	if !ok || !c.now().Before(e.Expires) {
+		delete(c.entries, key)
+		c.misses++
+		return "", false
+	}
+	c.hits++
+	return e.ID, true
+}
+
+// Put records that the item called name inside parentID has the given ID.
+func (c *PathCache) Put(parentID, name, id string) {
+	if c == nil || c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[pathCacheKey(parentID, name)] = pathCacheEntry{
+		Parent:  parentID,
+		Name:    name,
+		ID:      id,
+		Expires: c.now().Add(c.ttl),
+	}
+	c.saveLocked()
+}
+
+// Invalidate drops every entry that resolves to id or lives directly inside
+// it. Call it after an item is renamed, moved or deleted.
+func (c *PathCache) Invalidate(id string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	changed := false
+	for key, e := range c.entries {
+		if e.ID == id || e.Parent == id {
+			delete(c.entries, key)
+			changed = true
+		}
+	}
+	if changed {
+		c.saveLocked()
+	}
+}
+
+// Clear drops all entries and removes the backing file, returning the
+// number of entries that were cached.
+func (c *PathCache) Clear() (int, error) {
+	if c == nil {
+		return 0, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := len(c.entries)
+	c.entries = make(map[string]pathCacheEntry)
+	if c.file != "" {
+		if err := os.Remove(c.file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return n, fmt.Errorf("unable to remove path cache: %w", err)
+		}
+	}
+	return n, nil
+}
+
+// Stats returns a snapshot of the cache content.
+func (c *PathCache) Stats() PathCacheStats {
+	if c == nil {
+		return PathCacheStats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := PathCacheStats{
+		File:   c.file,
+		TTL:    c.ttl,
+		Hits:   c.hits,
+		Misses: c.misses,
+	}
+	if c.file != "" {
+		if fi, err := os.Stat(c.file); err == nil {
+			stats.FileSize = fi.Size()
+		}
+	}
+	now := c.now()
+	for _, e := range c.entries {
+		if !now.Before(e.Expires) {
+			continue
+		}
+		stats.Entries++
+		if stats.OldestExp.IsZero() || e.Expires.Before(stats.OldestExp) {
+			stats.OldestExp = e.Expires
+		}
+		if e.Expires.After(stats.NewestExp) {
+			stats.NewestExp = e.Expires
+		}
+	}
+	return stats
+}
+
+// saveLocked writes the live entries to the backing file, if any. Errors are
+// ignored: a cache that cannot be persisted still works in memory.
+func (c *PathCache) saveLocked() {
+	if c.file == "" {
+		return
+	}
+	now := c.now()
+	stored := make([]pathCacheEntry, 0, len(c.entries))
+	for _, e := range c.entries {
+		if now.Before(e.Expires) {
+			stored = append(stored, e)
+		}
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return
+	}
+	_ = writeFileAtomic(c.file, data, pathCacheFilePerm)
+}
+
+// writeFileAtomic writes data to a temporary file next to file and renames
+// it into place, so readers never observe a partially written file.
+func writeFileAtomic(file string, data []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
@@ -0,0 +1,139 @@
+package drive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestCache returns a cache whose clock is driven by the returned pointer.
+func newTestCache(file string, ttl time.Duration) (*PathCache, *time.Time) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := NewPathCache(ttl)
+	c.file = file
+	c.now = func() time.Time { return now }
+	return c, &now
+}
+
+func TestPathCacheGetPut(t *testing.T) {
+	c, _ := newTestCache("", time.Minute)
+
+	if _, ok := c.Get("root", "Documents"); ok {
+		t.Fatal("expected miss on empty cache")
+	}
+	c.Put("root", "Documents", "doc-id")
+	if id, ok := c.Get("root", "Documents"); !ok || id != "doc-id" {
+		t.Fatalf("Get = (%q, %v), want (doc-id, true)", id, ok)
+	}
+	if _, ok := c.Get("other", "Documents"); ok {
+		t.Fatal("entry leaked across parents")
+	}
+
+	stats := c.Stats()
+	if stats.Hits != 1 || stats.Misses != 2 || stats.Entries != 1 {
+		t.Fatalf("stats = %+v, want 1 hit, 2 misses, 1 entry", stats)
+	}
+}
+
+func TestPathCacheExpiry(t *testing.T) {
+	c, now := newTestCache("", time.Minute)
+	c.Put("root", "Documents", "doc-id")
+
+	*now = now.Add(59 * time.Second)
+	if _, ok := c.Get("root", "Documents"); !ok {
+		t.Fatal("entry expired too early")
+	}
+	*now = now.Add(time.Second)
+	if _, ok := c.Get("root", "Documents"); ok {
+		t.Fatal("entry should have expired")
+	}
+}
+
+func TestPathCacheZeroTTLDisablesPut(t *testing.T) {
+	c, _ := newTestCache("", 0)
+	c.Put("root", "Documents", "doc-id")
+	if _, ok := c.Get("root", "Documents"); ok {
+		t.Fatal("zero TTL must not cache")
+	}
+}
+
+func TestPathCacheInvalidate(t *testing.T) {
+	c, _ := newTestCache("", time.Minute)
+	c.Put("root", "Documents", "doc-id")
+	c.Put("doc-id", "Projects", "proj-id")
+	c.Put("root", "Photos", "photo-id")
+
+	c.Invalidate("doc-id")
+
+	if _, ok := c.Get("root", "Documents"); ok {
+		t.Error("entry pointing to invalidated ID survived")
+	}
+	if _, ok := c.Get("doc-id", "Projects"); ok {
+		t.Error("child of invalidated ID survived")
+	}
+	if _, ok := c.Get("root", "Photos"); !ok {
+		t.Error("unrelated entry was dropped")
+	}
+}
+
+func TestPathCachePersistence(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "cache", "paths.json")
+
+	c, err := OpenPathCache(file, time.Minute)
+	if err != nil {
+		t.Fatalf("OpenPathCache on missing file: %v", err)
+	}
+	c.Put("root", "Documents", "doc-id")
+
+	fi, err := os.Stat(file)
+	if err != nil {
+		t.Fatalf("cache file not written: %v", err)
+	}
+	if perm := fi.Mode().Perm(); perm != pathCacheFilePerm {
+		t.Errorf("cache file mode = %o, want %o", perm, pathCacheFilePerm)
+	}
+
+	reopened, err := OpenPathCache(file, time.Minute)
+	if err != nil {
+		t.Fatalf("OpenPathCache: %v", err)
+	}
+	if id, ok := reopened.Get("root", "Documents"); !ok || id != "doc-id" {
+		t.Fatalf("reopened Get = (%q, %v), want (doc-id, true)", id, ok)
+	}
+
+	n, err := reopened.Clear()
+	if err != nil || n != 1 {
+		t.Fatalf("Clear = (%d, %v), want (1, nil)", n, err)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Fatalf("cache file still present after Clear: %v", err)
+	}
+}
+
+func TestPathCacheCorruptFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "paths.json")
+	if err := os.WriteFile(file, []byte("{not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := OpenPathCache(file, time.Minute)
+	if err != nil {
+		t.Fatalf("corrupt cache should be ignored, got %v", err)
+	}
+	if c.Stats().Entries != 0 {
+		t.Fatal("corrupt cache produced entries")
+	}
+}
+
+func TestPathCacheNil(t *testing.T) {
+	var c *PathCache
+	c.Put("root", "Documents", "doc-id")
+	c.Invalidate("doc-id")
+	if _, ok := c.Get("root", "Documents"); ok {
+		t.Fatal("nil cache returned a hit")
+	}
+	if n, err := c.Clear(); n != 0 || err != nil {
+		t.Fatalf("nil Clear = (%d, %v)", n, err)
+	}
+	_ = c.Stats()
+}
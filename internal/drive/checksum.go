@@ -0,0 +1,102 @@
+package drive
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// checksumFields are the file fields holding the content hashes Drive
+// computes for binary files. Google Workspace files have none.
+const checksumFields = "md5Checksum, sha1Checksum, sha256Checksum"
+
+// Checksums holds hex-encoded content hashes.
+type Checksums struct {
+	MD5    string
+	SHA1   string
+	SHA256 string
+}
+
+// RemoteChecksums returns the hashes Drive reported for f.
+func RemoteChecksums(f *drive.File) Checksums {
+	return Checksums{MD5: f.Md5Checksum, SHA1: f.Sha1Checksum, SHA256: f.Sha256Checksum}
+}
+
+// IsZero reports whether no hash is known.
+func (c Checksums) IsZero() bool {
+	return c == Checksums{}
+}
+
+// ChecksumMismatchError reports content that differs from what Drive holds.
+type ChecksumMismatchError struct {
+	Name      string
+	Algorithm string
+	Local     string
+	Remote    string
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("checksum mismatch for %s: local %s %s, Drive %s", e.Name, e.Algorithm, e.Local, e.Remote)
+}
+
+// CompareChecksums checks local against every hash in remote. Hashes Drive
+// did not report are skipped, so two zero Checksums compare equal.
+func CompareChecksums(name string, local, remote Checksums) error {
+	for _, c := range []struct{ alg, local, remote string }{
+		{"sha256", local.SHA256, remote.SHA256},
+		{"sha1", local.SHA1, remote.SHA1},
+		{"md5", local.MD5, remote.MD5},
+	} {
+		if c.remote != "" && c.local != c.remote {
+			return &ChecksumMismatchError{Name: name, Algorithm: c.alg, Local: c.local, Remote: c.remote}
+		}
+	}
+	return nil
+}
+
+// hasher computes all the hashes Drive reports in one pass.
+type hasher struct {
+	md5, sha1, sha256 hash.Hash
+}
+
+func newHasher() *hasher {
+	return &hasher{md5: md5.New(), sha1: sha1.New(), sha256: sha256.New()}
+}
+
+func (h *hasher) Write(p []byte) (int, error) {
+	h.md5.Write(p)
+	h.sha1.Write(p)
+	h.sha256.Write(p)
+	return len(p), nil
+}
+
+// Sums returns the hashes of everything written so far.
+func (h *hasher) Sums() Checksums {
+	return Checksums{
+		MD5:    hex.EncodeToString(h.md5.Sum(nil)),
+		SHA1:   hex.EncodeToString(h.sha1.Sum(nil)),
+		SHA256: hex.EncodeToString(h.sha256.Sum(nil)),
+	}
+}
+
+// HashFile computes the checksums of a local file.
+func HashFile(path string) (Checksums, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Checksums{}, err
+	}
+	defer f.Close()
+
+	h := newHasher()
+	if _, err := io.Copy(h, f); err != nil {
+		return Checksums{}, err
+	}
+	return h.Sums(), nil
+}
@@ -0,0 +1,82 @@
+package drive
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/api/drive/v3"
+)
+
+func TestHashFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hello.txt")
+	if err := os.WriteFile(path, []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := HashFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Checksums{
+		MD5:    "b1946ac92492d2347c6235b4d2611184",
+		SHA1:   "f572d396fae9206628714fb2ce00f72e94f2258f",
+		SHA256: "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03",
+	}
+	if got != want {
+		t.Errorf("HashFile = %+v, want %+v", got, want)
+	}
+}
+
+func TestCompareChecksums(t *testing.T) {
+	local := Checksums{MD5: "aa", SHA1: "bb", SHA256: "cc"}
+	cases := []struct {
+		name    string
+		remote  Checksums
+		wantAlg string
+	}{
+		{"all match", local, ""},
+		{"only md5 reported", Checksums{MD5: "aa"}, ""},
+		{"nothing reported", Checksums{}, ""},
+		{"md5 differs", Checksums{MD5: "xx"}, "md5"},
+		{"strongest hash reported first", Checksums{MD5: "xx", SHA256: "yy"}, "sha256"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := CompareChecksums("f", local, tc.remote)
+			var mismatch *ChecksumMismatchError
+			switch {
+			case tc.wantAlg == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tc.wantAlg != "" && (!errors.As(err, &mismatch) || mismatch.Algorithm != tc.wantAlg):
+				t.Errorf("got %v, want %s mismatch", err, tc.wantAlg)
+			}
+		})
+	}
+}
+
+func TestVerifyFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hello.txt")
+	if err := os.WriteFile(path, []byte("hello\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name  string
+		local string
+		file  *drive.File
+		want  VerifyStatus
+	}{
+		{"ok", path, &drive.File{Md5Checksum: "b1946ac92492d2347c6235b4d2611184"}, VerifyOK},
+		{"mismatch", path, &drive.File{Md5Checksum: "00"}, VerifyMismatch},
+		{"missing", filepath.Join(dir, "gone.txt"), &drive.File{Md5Checksum: "00"}, VerifyMissing},
+		{"workspace file", path, &drive.File{MimeType: DriveDocMimeType}, VerifySkipped},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := VerifyFile(tc.local, tc.file); got.Status != tc.want {
+				t.Errorf("status = %s (%s), want %s", got.Status, got.Detail, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package drive
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// commentFields are the comment fields Comment is built from.
+const commentFields = "id, author(displayName, emailAddress, me), content, quotedFileContent, anchor, resolved, createdTime, modifiedTime, " +
+	"replies(" + replyFields + ")"
+
+// replyFields are the reply fields Reply is built from.
+const replyFields = "id, author(displayName, emailAddress, me), content, action, deleted, createdTime"
+
+// Comment is a comment on a file with its replies. QuotedText is the text
+// of the file the comment is anchored to, and Anchor the position of that
+// text in the editor's own format; both are empty for comments on the whole
+// file. Drive does not always give the email address of authors, and Mine
+// tells which comments the user wrote.
+type Comment struct {
+	ID           string
+	Author       string
+	AuthorEmail  string
+	Mine         bool
+	Content      string
+	QuotedText   string
+	Anchor       string
+	Resolved     bool
+	CreatedTime  time.Time
+	ModifiedTime time.Time
+	Replies      []*Reply
+}
+
+// Reply is a reply to a comment. Action is "resolve" or "reopen" for the
+// replies that changed the state of the comment, whose Content may be
+// empty.
+type Reply struct {
+	ID          string
+	Author      string
+	AuthorEmail string
+	Mine        bool
+	Content     string
+	Action      string
+	CreatedTime time.Time
+}
+
+// CommentFilter selects the comments ListComments returns.
+type CommentFilter struct {
+	// Unresolved keeps the comments that are still open.
+	Unresolved bool
+	// Mine keeps the comments the user wrote.
+	Mine bool
+}
+
+// ListComments returns the comments of a file that match filter, oldest
+// first, with their replies.
+func (ds *Service) ListComments(ctx context.Context, fileID string, filter CommentFilter) ([]*Comment, error) {
+	list, err := ds.Backend.ListComments(ctx, fileID, commentFields)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list comments: %w", err)
+	}
+
+	var comments []*Comment
+	for _, c := range list {
+		comment := newComment(c)
+		if (filter.Unresolved && comment.Resolved) || (filter.Mine && !comment.Mine) {
+			continue
+		}
+		comments = append(comments, comment)
+	}
+	return comments, nil
+}
+
+// GetComment returns one comment of a file with its replies.
+func (ds *Service) GetComment(ctx context.Context, fileID, commentID string) (*Comment, error) {
+	c, err := ds.Backend.GetComment(ctx, fileID, commentID, commentFields)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get comment: %w", err)
+	}
+	return newComment(c), nil
+}
+
+// AddComment adds a comment to a file. A non-empty quote is the text of
+// the file the comment is about; Drive shows it with the comment, but the
+// Docs editors only highlight text for comments made in the editor.
+func (ds *Service) AddComment(ctx context.Context, fileID, content, quote string) (*Comment, error) {
+	comment := &drive.Comment{Content: content}
+	if quote != "" {
+		comment.QuotedFileContent = &drive.CommentQuotedFileContent{MimeType: "text/plain", Value: quote}
+	}
+	c, err := ds.Backend.CreateComment(ctx, fileID, comment, commentFields)
+	if err != nil {
+		return nil, fmt.Errorf("unable to add comment: %w", err)
+	}
+	return newComment(c), nil
+}
+
+// ReplyToComment adds a reply to a comment.
+func (ds *Service) ReplyToComment(ctx context.Context, fileID, commentID, content string) (*Reply, error) {
+	r, err := ds.Backend.CreateReply(ctx, fileID, commentID, &drive.Reply{Content: content}, replyFields)
+	if err != nil {
+		return nil, fmt.Errorf("unable to reply to comment: %w", err)
+	}
+	return newReply(r), nil
+}
+
+// ResolveComment marks a comment as resolved, or open again when reopen is
+// set, with a reply holding content, which may be empty. It returns the
+// comment afterwards.
+func (ds *Service) ResolveComment(ctx context.Context, fileID, commentID, content string, reopen bool) (*Comment, error) {
+	action := "resolve"
+	if reopen {
+		action = "reopen"
+	}
+	reply := &drive.Reply{Content: content, Action: action}
+	if _, err := ds.Backend.CreateReply(ctx, fileID, commentID, reply, replyFields); err != nil {
+		return nil, fmt.Errorf("unable to %s comment: %w", action, err)
+	}
+	return ds.GetComment(ctx, fileID, commentID)
+}
+
+// DeleteComment deletes a comment and its replies.
+func (ds *Service) DeleteComment(ctx context.Context, fileID, commentID string) error {
+	if err := ds.Backend.DeleteComment(ctx, fileID, commentID); err != nil {
+		return fmt.Errorf("unable to delete comment: %w", err)
+	}
+	return nil
+}
+
+// newComment converts a comment fetched with commentFields. Deleted
+// replies are left out.
+func newComment(c *drive.Comment) *Comment {
+	comment := &Comment{
+		ID:           c.Id,
+		Content:      c.Content,
+		Anchor:       c.Anchor,
+		Resolved:     c.Resolved,
+		CreatedTime:  parseTime(c.CreatedTime),
+		ModifiedTime: parseTime(c.ModifiedTime),
+	}
+	comment.Author, comment.AuthorEmail, comment.Mine = commentUser(c.Author)
+	if c.QuotedFileContent != nil {
+		comment.QuotedText = c.QuotedFileContent.Value
+	}
+	for _, r := range c.Replies {
+		if !r.Deleted {
+			comment.Replies = append(comment.Replies, newReply(r))
+		}
+	}
+	return comment
+}
+
+// newReply converts a reply fetched with replyFields.
+func newReply(r *drive.Reply) *Reply {
+	reply := &Reply{
+		ID:          r.Id,
+		Content:     r.Content,
+		Action:      r.Action,
+		CreatedTime: parseTime(r.CreatedTime),
+	}
+	reply.Author, reply.AuthorEmail, reply.Mine = commentUser(r.Author)
+	return reply
+}
+
+func commentUser(u *drive.User) (name, email string, me bool) {
+	if u == nil {
+		return "", "", false
+	}
+	return u.DisplayName, u.EmailAddress, u.Me
+}
+
+// parseTime parses a time returned by Drive, or returns the zero time.
+func parseTime(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339, s)
+	return t
+}
@@ -0,0 +1,72 @@
+package drive_test
+
+import (
+	"testing"
+
+	driveapi "google.golang.org/api/drive/v3"
+
+	"gdrive/internal/drive"
+	"gdrive/internal/drivetest"
+)
+
+func TestComments(t *testing.T) {
+	srv, ds := newFakeService(t)
+	ctx := t.Context()
+	id := srv.AddDoc(drivetest.RootID, "Plan", docMime, []byte("The launch is in May."))
+	alice := driveapi.User{DisplayName: "Alice", EmailAddress: "alice@example.com"}
+	review := srv.AddComment(id, alice, "Is May still realistic?", "launch is in May")
+
+	mine, err := ds.AddComment(ctx, id, "Add the budget section", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mine.Mine || mine.Author != drivetest.Me.DisplayName || mine.Resolved {
+		t.Errorf("added comment = %+v", mine)
+	}
+
+	reply, err := ds.ReplyToComment(ctx, id, review, "Yes, QA signed off")
+	if err != nil || reply.Content != "Yes, QA signed off" || !reply.Mine {
+		t.Fatalf("reply = %+v, %v", reply, err)
+	}
+	resolved, err := ds.ResolveComment(ctx, id, review, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resolved.Resolved || len(resolved.Replies) != 2 || resolved.Replies[1].Action != "resolve" {
+		t.Errorf("resolved comment = %+v", resolved)
+	}
+
+	comments, err := ds.ListComments(ctx, id, drive.CommentFilter{})
+	if err != nil || len(comments) != 2 {
+		t.Fatalf("ListComments = %v, %v", comments, err)
+	}
+	first := comments[0]
+	if first.ID != review || first.Author != "Alice" || first.Mine || first.QuotedText != "launch is in May" {
+		t.Errorf("first comment = %+v", first)
+	}
+
+	only := func(filter drive.CommentFilter, want string) {
+		t.Helper()
+		got, err := ds.ListComments(ctx, id, filter)
+		if err != nil || len(got) != 1 || got[0].ID != want {
+			t.Errorf("ListComments(%+v) = %v, %v, want %s", filter, got, err, want)
+		}
+	}
+	only(drive.CommentFilter{Unresolved: true}, mine.ID)
+	only(drive.CommentFilter{Mine: true}, mine.ID)
+
+	if reopened, err := ds.ResolveComment(ctx, id, review, "Reopening: the date moved", true); err != nil || reopened.Resolved {
+		t.Errorf("reopen = %+v, %v", reopened, err)
+	}
+
+	if err := ds.DeleteComment(ctx, id, mine.ID); err != nil {
+		t.Fatal(err)
+	}
+	only(drive.CommentFilter{}, review)
+	if _, err := ds.GetComment(ctx, id, mine.ID); err == nil {
+		t.Error("deleted comment should not be found")
+	}
+	if _, err := ds.ReplyToComment(ctx, id, review, ""); err == nil {
+		t.Error("empty reply should be refused")
+	}
+}
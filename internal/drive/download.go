@@ -0,0 +1,166 @@
+package drive
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/schollz/progressbar/v3"
+	"google.golang.org/api/drive/v3"
+)
+
+// PartSuffix is appended to the destination of a download while it is in
+// progress. The file is renamed into place only once it is complete, so an
+// interrupted download never leaves a truncated file under the final name.
+const PartSuffix = ".part"
+
+// partOffset returns how many bytes of f an existing partial download at
+// part already holds, or 0 when there is nothing usable to resume from.
+// A partial file last written before f was modified on Drive belongs to an
+// older revision and is discarded.
+func partOffset(part string, f *drive.File) int64 {
+	info, err := os.Stat(part)
+	if err != nil || !info.Mode().IsRegular() || info.Size() == 0 || info.Size() > f.Size {
+		return 0
+	}
+	modified, err := time.Parse(time.RFC3339, f.ModifiedTime)
+	if err != nil || info.ModTime().Before(modified) {
+		return 0
+	}
+	return info.Size()
+}
+
+// downloadBlob downloads a regular file to localPath through a partial
+// file, resuming a previous attempt with a Range request when possible.
+// The content is hashed as it arrives and must match the checksums in f
+// before it is renamed into place.
+func (ds *Service) downloadBlob(ctx context.Context, f *drive.File, localPath string, showProgress bool) error {
+	part := localPath + PartSuffix
+	offset := partOffset(part, f)
+	h := newHasher()
+
+	if offset < f.Size || f.Size == 0 {
+		resp, err := ds.Backend.DownloadFile(ctx, f.Id, offset)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		flags := os.O_WRONLY | os.O_CREATE
+		if resp.StatusCode == http.StatusPartialContent {
+			var start int64
+			if _, err := fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes %d-", &start); err != nil || start != offset {
+				return fmt.Errorf("unexpected Content-Range %q resuming at %d bytes", resp.Header.Get("Content-Range"), offset)
+			}
+			flags |= os.O_APPEND
+			if err := hashPrefix(h, part, offset); err != nil {
+				return err
+			}
+		} else {
+			// The server sent the whole file: start over
+			flags |= os.O_TRUNC
+			offset = 0
+		}
+
+		out, err := os.OpenFile(part, flags, downloadFilePerm)
+		if err != nil {
+			return err
+		}
+
+		size := f.Size
+		if size == 0 && resp.ContentLength > 0 {
+			size = resp.ContentLength
+		}
+		var w io.Writer = io.MultiWriter(out, h)
+		if showProgress {
+			if offset > 0 {
+				fmt.Printf("Resuming at %d of %d bytes\n", offset, size)
+			}
+			bar := progressbar.DefaultBytes(size, fmt.Sprintf("Downloading %s", f.Name))
+			_ = bar.Set64(offset)
+			w = io.MultiWriter(w, bar)
+		}
+
+		n, err := io.Copy(w, resp.Body)
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("download interrupted at %d of %d bytes, run the command again to resume: %w", offset+n, size, err)
+		}
+	} else if err := hashPrefix(h, part, offset); err != nil {
+		// A previous run fetched everything but stopped before the rename
+		return err
+	}
+
+	if err := CompareChecksums(f.Name, h.Sums(), RemoteChecksums(f)); err != nil {
+		_ = os.Remove(part)
+		return err
+	}
+	return os.Rename(part, localPath)
+}
+
+// hashPrefix feeds the first n bytes already downloaded to part into h.
+func hashPrefix(h io.Writer, part string, n int64) error {
+	in, err := os.Open(part)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	_, err = io.CopyN(h, in, n)
+	return err
+}
+
+// downloadExport exports a Google Workspace file to localPath. Exports are
+// generated on the fly and cannot be fetched by range, so an interrupted
+// export is discarded, but the result still only appears once complete.
+func (ds *Service) downloadExport(ctx context.Context, f *drive.File, exportMimeType, localPath string, showProgress bool) error {
+	resp, err := ds.openExport(ctx, f.Id, exportMimeType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// The export size differs from the source size, so the bar is
+	// indeterminate unless the response says how long it is, as export
+	// links usually do
+	size := int64(-1)
+	if resp.ContentLength > 0 {
+		size = resp.ContentLength
+	}
+	return saveStream(resp.Body, f.Name, localPath, size, showProgress, nil)
+}
+
+// saveStream writes body to localPath through a partial file that is
+// discarded if anything fails. size is only used for the progress bar and
+// may be -1 when unknown. check, when set, gets the hashes of everything
+// written and can reject the content before it is renamed into place.
+func saveStream(body io.Reader, name, localPath string, size int64, showProgress bool, check func(Checksums) error) error {
+	part := localPath + PartSuffix
+	out, err := os.OpenFile(part, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, downloadFilePerm)
+	if err != nil {
+		return err
+	}
+
+	h := newHasher()
+	var w io.Writer = io.MultiWriter(out, h)
+	if showProgress {
+		w = io.MultiWriter(w, progressbar.DefaultBytes(size, fmt.Sprintf("Downloading %s", name)))
+	}
+	_, err = io.Copy(w, body)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && check != nil {
+		err = check(h.Sums())
+	}
+	if err != nil {
+		_ = os.Remove(part)
+		return err
+	}
+
+	return os.Rename(part, localPath)
+}
@@ -0,0 +1,255 @@
+package drive
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+)
+
+// fakeDownloadServer serves one regular file, honouring Range requests, and
+// one Google Doc export.
+type fakeDownloadServer struct {
+	mu          sync.Mutex
+	content     []byte
+	modified    time.Time
+	ignoreRange bool  // answer Range requests with the whole file
+	cutAt       int64 // drop the connection after this many bytes once; -1 never
+	corrupt     int   // number of downloads to send with a flipped byte
+	ranges      []string
+}
+
+func newFakeDownloadServer(t *testing.T, content []byte) (*fakeDownloadServer, *Service) {
+	t.Helper()
+	f := &fakeDownloadServer{
+		content:  content,
+		modified: time.Now().Add(-time.Hour).UTC().Truncate(time.Second),
+		cutAt:    -1,
+	}
+	ts := httptest.NewServer(f)
+	t.Cleanup(ts.Close)
+
+	api, err := drive.NewService(context.Background(),
+		option.WithEndpoint(ts.URL+"/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f, NewService(api)
+}
+
+func (f *fakeDownloadServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/files/doc-id/export":
+		f.send(w, []byte("# exported\n"))
+
+	case r.URL.Path == "/files/doc-id":
+		fmt.Fprintf(w, `{"id":"doc-id","name":"Notes","mimeType":%q,"modifiedTime":%q}`,
+			DriveDocMimeType, f.modified.Format(time.RFC3339))
+
+	case r.URL.Path == "/files/file-id" && r.URL.Query().Get("alt") == "media":
+		body := f.content
+		rng := r.Header.Get("Range")
+		f.ranges = append(f.ranges, rng)
+		var start int64
+		if _, err := fmt.Sscanf(rng, "bytes=%d-", &start); err == nil && !f.ignoreRange {
+			body = body[start:]
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(f.content)-1, len(f.content)))
+			w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write(body)
+			return
+		}
+		if f.corrupt > 0 {
+			f.corrupt--
+			body = bytes.Clone(body)
+			body[len(body)/2] ^= 0xff
+		}
+		f.send(w, body)
+
+	case r.URL.Path == "/files/file-id":
+		fmt.Fprintf(w, `{"id":"file-id","name":"video.bin","mimeType":"application/octet-stream","size":"%d","modifiedTime":%q,"md5Checksum":"%x"}`,
+			len(f.content), f.modified.Format(time.RFC3339), md5.Sum(f.content))
+
+	default:
+		http.Error(w, "unexpected request "+r.Method+" "+r.URL.Path, http.StatusNotFound)
+	}
+}
+
+// send writes body, or only its first cutAt bytes followed by a dropped
+// connection when an interruption is scheduled.
+func (f *fakeDownloadServer) send(w http.ResponseWriter, body []byte) {
+	w.Header().Set("Content-Length", fmt.Sprint(len(body)))
+	if f.cutAt >= 0 {
+		_, _ = w.Write(body[:f.cutAt])
+		f.cutAt = -1
+		w.(http.Flusher).Flush()
+		panic(http.ErrAbortHandler)
+	}
+	_, _ = w.Write(body)
+}
+
+func TestDownloadFileAtomic(t *testing.T) {
+	_, data := writeTestFile(t, 5000)
+	_, ds := newFakeDownloadServer(t, data)
+	dest := filepath.Join(t.TempDir(), "video.bin")
+
+	if err := ds.DownloadFile(context.Background(), "file-id", dest, "", false, false); err != nil {
+		t.Fatalf("DownloadFile: %v", err)
+	}
+	got, _ := os.ReadFile(dest)
+	if !bytes.Equal(got, data) {
+		t.Errorf("downloaded %d bytes, want %d identical bytes", len(got), len(data))
+	}
+	if _, err := os.Stat(dest + PartSuffix); !os.IsNotExist(err) {
+		t.Errorf("partial file should be gone: %v", err)
+	}
+}
+
+func TestDownloadFileResumesAfterInterruption(t *testing.T) {
+	_, data := writeTestFile(t, 5000)
+	fake, ds := newFakeDownloadServer(t, data)
+	dest := filepath.Join(t.TempDir(), "video.bin")
+	fake.cutAt = 2000
+
+	err := ds.DownloadFile(context.Background(), "file-id", dest, "", false, false)
+	if err == nil || !strings.Contains(err.Error(), "run the command again to resume") {
+		t.Fatalf("expected interrupted download error, got %v", err)
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Errorf("destination must not exist after an interruption: %v", err)
+	}
+	if info, err := os.Stat(dest + PartSuffix); err != nil || info.Size() != 2000 {
+		t.Fatalf("partial file = %v, %v; want 2000 bytes", info, err)
+	}
+
+	if err := ds.DownloadFile(context.Background(), "file-id", dest, "", false, false); err != nil {
+		t.Fatalf("resumed DownloadFile: %v", err)
+	}
+	got, _ := os.ReadFile(dest)
+	if !bytes.Equal(got, data) {
+		t.Errorf("resumed download has %d bytes, want %d identical bytes", len(got), len(data))
+	}
+	if last := fake.ranges[len(fake.ranges)-1]; last != "bytes=2000-" {
+		t.Errorf("resume Range = %q, want bytes=2000-", last)
+	}
+}
+
+func TestDownloadFileServerIgnoresRange(t *testing.T) {
+	_, data := writeTestFile(t, 5000)
+	fake, ds := newFakeDownloadServer(t, data)
+	dest := filepath.Join(t.TempDir(), "video.bin")
+	if err := os.WriteFile(dest+PartSuffix, data[:1000], 0644); err != nil {
+		t.Fatal(err)
+	}
+	fake.ignoreRange = true
+
+	if err := ds.DownloadFile(context.Background(), "file-id", dest, "", false, false); err != nil {
+		t.Fatalf("DownloadFile: %v", err)
+	}
+	got, _ := os.ReadFile(dest)
+	if !bytes.Equal(got, data) {
+		t.Errorf("downloaded %d bytes, want the full %d bytes without duplication", len(got), len(data))
+	}
+}
+
+func TestDownloadFileDiscardsStalePart(t *testing.T) {
+	_, data := writeTestFile(t, 5000)
+	fake, ds := newFakeDownloadServer(t, data)
+	dest := filepath.Join(t.TempDir(), "video.bin")
+	part := dest + PartSuffix
+	if err := os.WriteFile(part, []byte("old revision"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// The partial file predates the remote modification.
+	old := fake.modified.Add(-time.Hour)
+	if err := os.Chtimes(part, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ds.DownloadFile(context.Background(), "file-id", dest, "", false, false); err != nil {
+		t.Fatalf("DownloadFile: %v", err)
+	}
+	if fake.ranges[0] != "" {
+		t.Errorf("stale partial file was resumed with Range %q", fake.ranges[0])
+	}
+	got, _ := os.ReadFile(dest)
+	if !bytes.Equal(got, data) {
+		t.Errorf("downloaded %d bytes, want %d identical bytes", len(got), len(data))
+	}
+}
+
+func TestDownloadExportAtomic(t *testing.T) {
+	fake, ds := newFakeDownloadServer(t, nil)
+	dir := t.TempDir()
+	dest := filepath.Join(dir, "Notes")
+
+	fake.cutAt = 3
+	if err := ds.DownloadFile(context.Background(), "doc-id", dest, "md", false, false); err == nil {
+		t.Fatal("expected interrupted export to fail")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("interrupted export left %d files behind", len(entries))
+	}
+
+	if err := ds.DownloadFile(context.Background(), "doc-id", dest, "md", false, false); err != nil {
+		t.Fatalf("DownloadFile: %v", err)
+	}
+	got, err := os.ReadFile(dest + ".md")
+	if err != nil || string(got) != "# exported\n" {
+		t.Errorf("export = %q, %v", got, err)
+	}
+	if _, err := os.Stat(dest + ".md" + PartSuffix); !os.IsNotExist(err) {
+		t.Errorf("partial export should be gone: %v", err)
+	}
+}
+
+func TestDownloadFileRetriesChecksumMismatch(t *testing.T) {
+	_, data := writeTestFile(t, 5000)
+	fake, ds := newFakeDownloadServer(t, data)
+	dest := filepath.Join(t.TempDir(), "video.bin")
+	fake.corrupt = 1
+
+	if err := ds.DownloadFile(context.Background(), "file-id", dest, "", false, false); err != nil {
+		t.Fatalf("DownloadFile: %v", err)
+	}
+	got, _ := os.ReadFile(dest)
+	if !bytes.Equal(got, data) {
+		t.Error("corrupted download was not replaced by a good copy")
+	}
+	if len(fake.ranges) != 2 {
+		t.Errorf("downloads = %d, want 2 (one retry)", len(fake.ranges))
+	}
+}
+
+func TestDownloadFileFailsPersistentMismatch(t *testing.T) {
+	_, data := writeTestFile(t, 5000)
+	fake, ds := newFakeDownloadServer(t, data)
+	dest := filepath.Join(t.TempDir(), "video.bin")
+	fake.corrupt = 2
+
+	err := ds.DownloadFile(context.Background(), "file-id", dest, "", false, false)
+	var mismatch *ChecksumMismatchError
+	if !errors.As(err, &mismatch) || mismatch.Algorithm != "md5" {
+		t.Fatalf("expected md5 mismatch, got %v", err)
+	}
+	for _, p := range []string{dest, dest + PartSuffix} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("%s should not exist after a failed verification: %v", p, err)
+		}
+	}
+}
@@ -0,0 +1,380 @@
+package drive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// ExportCatalogTTL is how long an export catalog cached on disk is used
+// before Drive is asked again. Drive rarely adds formats.
+const ExportCatalogTTL = 24 * time.Hour
+
+// exportCatalogFilePerm is the mode of the catalog cache, which holds
+// nothing specific to the user.
+const exportCatalogFilePerm = 0644
+
+// exportSizeLimitExceeded is the reason Drive gives when an export is over
+// the 10 MB Files.Export serves.
+const exportSizeLimitExceeded = "exportSizeLimitExceeded"
+
+// googleAppsPrefix starts the MIME type of every Google Workspace type.
+const googleAppsPrefix = "application/vnd.google-apps."
+
+// workspaceTypeNames maps the short names of Google Workspace types
+// accepted on the command line to their MIME types. Types not listed here
+// are named after their MIME type, e.g. "script" or "form".
+var workspaceTypeNames = map[string]string{
+	"doc":          "application/vnd.google-apps.document",
+	"docs":         "application/vnd.google-apps.document",
+	"document":     "application/vnd.google-apps.document",
+	"sheet":        "application/vnd.google-apps.spreadsheet",
+	"sheets":       "application/vnd.google-apps.spreadsheet",
+	"spreadsheet":  "application/vnd.google-apps.spreadsheet",
+	"slide":        "application/vnd.google-apps.presentation",
+	"slides":       "application/vnd.google-apps.presentation",
+	"presentation": "application/vnd.google-apps.presentation",
+	"drawing":      "application/vnd.google-apps.drawing",
+}
+
+// WorkspaceTypeName returns the short name of a Google Workspace type, as
+// accepted by ParseExportSpec.
+func WorkspaceTypeName(mimeType string) string {
+	switch mimeType {
+	case "application/vnd.google-apps.document":
+		return "doc"
+	case "application/vnd.google-apps.spreadsheet":
+		return "sheet"
+	case "application/vnd.google-apps.presentation":
+		return "slide"
+	}
+	return strings.TrimPrefix(mimeType, googleAppsPrefix)
+}
+
+// exportExtensions gives the extension of the export MIME types that
+// extensionMimeTypes does not know, or knows under several extensions.
+var exportExtensions = map[string]string{
+	"text/plain":                "txt",
+	"text/html":                 "html",
+	"image/jpeg":                "jpg",
+	"application/zip":           "zip",
+	"application/epub+zip":      "epub",
+	"text/tab-separated-values": "tsv",
+	"application/x-vnd.oasis.opendocument.spreadsheet": "ods",
+	"application/vnd.google-apps.script+json":          "json",
+}
+
+// FormatExtension returns the file extension, without the dot, of files of
+// mimeType. It is also the name export formats go by on the command line.
+func FormatExtension(mimeType string) string {
+	if ext, ok := exportExtensions[mimeType]; ok {
+		return ext
+	}
+	var best string
+	for ext, mt := range extensionMimeTypes {
+		if mt == mimeType && (best == "" || len(ext) < len(best) || len(ext) == len(best) && ext < best) {
+			best = ext
+		}
+	}
+	if best != "" {
+		return best[1:]
+	}
+	// Unknown type: the last word of the subtype, e.g. "x-foo+xml" → "x-foo"
+	_, sub, _ := strings.Cut(mimeType, "/")
+	sub, _, _ = strings.Cut(sub, "+")
+	return strings.ToLower(sub[strings.LastIndex(sub, ".")+1:])
+}
+
+// ExportFormat is one format a Google Workspace type can be exported to.
+type ExportFormat struct {
+	// Name is the format as given to --format, and the file extension.
+	Name     string
+	MimeType string
+}
+
+// ExportCatalog lists the formats Drive exports each Google Workspace type
+// to, and the formats it converts into Workspace types on upload, as
+// reported by the About API.
+type ExportCatalog struct {
+	// Exports maps Workspace MIME types to the MIME types they export to.
+	Exports map[string][]string `json:"exports"`
+	// Imports maps MIME types to the Workspace types they convert to.
+	Imports map[string][]string `json:"imports"`
+	// Fetched is when the catalog was read from Drive. It is zero for the
+	// built-in catalog.
+	Fetched time.Time `json:"fetched"`
+}
+
+// builtinExportCatalog returns the catalog used when Drive cannot be asked,
+// made from ExportFormats.
+func builtinExportCatalog() *ExportCatalog {
+	c := &ExportCatalog{Exports: make(map[string][]string)}
+	for mimeType, formats := range ExportFormats {
+		var mimeTypes []string
+		for _, mt := range formats {
+			if !slices.Contains(mimeTypes, mt) {
+				mimeTypes = append(mimeTypes, mt)
+			}
+		}
+		slices.Sort(mimeTypes)
+		c.Exports[mimeType] = mimeTypes
+	}
+	return c
+}
+
+// Types returns the Workspace MIME types that can be exported, sorted by
+// WorkspaceTypeName.
+func (c *ExportCatalog) Types() []string {
+	return slices.SortedFunc(maps.Keys(c.Exports), func(a, b string) int {
+		return strings.Compare(WorkspaceTypeName(a), WorkspaceTypeName(b))
+	})
+}
+
+// Formats returns the formats mimeType can be exported to, sorted by name.
+func (c *ExportCatalog) Formats(mimeType string) []ExportFormat {
+	var formats []ExportFormat
+	for _, mt := range c.Exports[mimeType] {
+		formats = append(formats, ExportFormat{Name: FormatExtension(mt), MimeType: mt})
+	}
+	slices.SortFunc(formats, func(a, b ExportFormat) int { return strings.Compare(a.Name, b.Name) })
+	return formats
+}
+
+// FormatNames returns the names of the formats mimeType can be exported to.
+func (c *ExportCatalog) FormatNames(mimeType string) []string {
+	var names []string
+	for _, f := range c.Formats(mimeType) {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// MimeType returns the MIME type mimeType is exported to for format, or ""
+// if it cannot be exported to it. format is a format name, or another
+// extension of the same type such as "jpeg" for "jpg".
+func (c *ExportCatalog) MimeType(mimeType, format string) string {
+	format = strings.ToLower(strings.TrimPrefix(format, "."))
+	if format == "" {
+		return ""
+	}
+	for _, mt := range c.Exports[mimeType] {
+		if FormatExtension(mt) == format || extensionMimeTypes["."+format] == mt {
+			return mt
+		}
+	}
+	return ""
+}
+
+// DefaultFormat returns the format mimeType is exported to when none is
+// given: the one GetDefaultExportFormat picks for the common types if Drive
+// offers it, otherwise the first one Drive lists. It is "" for types that
+// cannot be exported.
+func (c *ExportCatalog) DefaultFormat(mimeType string) string {
+	if f := defaultExportFormats[mimeType]; c.MimeType(mimeType, f) != "" {
+		return f
+	}
+	if mts := c.Exports[mimeType]; len(mts) > 0 {
+		return FormatExtension(mts[0])
+	}
+	return ""
+}
+
+// ImportSources returns, for each Workspace type, the extensions of the
+// files Drive converts to it on upload, sorted.
+func (c *ExportCatalog) ImportSources() map[string][]string {
+	sources := make(map[string][]string)
+	for from, targets := range c.Imports {
+		ext := FormatExtension(from)
+		for _, to := range targets {
+			if !slices.Contains(sources[to], ext) {
+				sources[to] = append(sources[to], ext)
+			}
+		}
+	}
+	for _, exts := range sources {
+		slices.Sort(exts)
+	}
+	return sources
+}
+
+// ExportCatalog returns the export formats Drive supports. They are read
+// from the About API once per Service, and kept in FormatsFile, when set,
+// for ExportCatalogTTL. If Drive cannot be asked, the built-in ExportFormats
+// are used instead.
+func (ds *Service) ExportCatalog(ctx context.Context) *ExportCatalog {
+	ds.catalogMu.Lock()
+	defer ds.catalogMu.Unlock()
+	if ds.catalog != nil {
+		return ds.catalog
+	}
+
+	if c, err := readExportCatalog(ds.FormatsFile); err == nil && time.Since(c.Fetched) < ExportCatalogTTL {
+		ds.catalog = c
+		return c
+	}
+	c, err := ds.fetchExportCatalog(ctx)
+	if err != nil {
+		c = builtinExportCatalog()
+	}
+	ds.catalog = c
+	return c
+}
+
+// RefreshExportCatalog reads the export formats from Drive, ignoring any
+// cached copy.
+func (ds *Service) RefreshExportCatalog(ctx context.Context) (*ExportCatalog, error) {
+	ds.catalogMu.Lock()
+	defer ds.catalogMu.Unlock()
+	c, err := ds.fetchExportCatalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ds.catalog = c
+	return c, nil
+}
+
+// fetchExportCatalog reads the catalog from Drive and caches it in
+// FormatsFile. Failing to write the cache only costs a request next time.
+func (ds *Service) fetchExportCatalog(ctx context.Context) (*ExportCatalog, error) {
+	about, err := ds.Backend.GetAbout(ctx, "exportFormats, importFormats")
+	if err != nil {
+		return nil, fmt.Errorf("unable to read export formats: %w", err)
+	}
+	c := &ExportCatalog{Exports: about.ExportFormats, Imports: about.ImportFormats, Fetched: time.Now()}
+	if ds.FormatsFile != "" {
+		if data, err := json.Marshal(c); err == nil {
+			_ = writeFileAtomic(ds.FormatsFile, data, exportCatalogFilePerm)
+		}
+	}
+	return c, nil
+}
+
+// readExportCatalog loads a catalog cached by fetchExportCatalog.
+func readExportCatalog(file string) (*ExportCatalog, error) {
+	if file == "" {
+		return nil, os.ErrNotExist
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var c ExportCatalog
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// ResolveExportFormat returns the format and MIME type a Workspace file of
+// mimeType is exported to for format, or for the default format of the type
+// when format is empty. The returned format is the canonical name, which is
+// also the file extension to use.
+func (ds *Service) ResolveExportFormat(ctx context.Context, mimeType, format string) (string, string, error) {
+	c := ds.ExportCatalog(ctx)
+	if format == "" {
+		if format = c.DefaultFormat(mimeType); format == "" {
+			return "", "", fmt.Errorf("cannot export file type: %s", mimeType)
+		}
+	}
+	exportMimeType := c.MimeType(mimeType, format)
+	if exportMimeType == "" {
+		return "", "", fmt.Errorf("export format %q is not supported for %s (available: %s)",
+			format, mimeType, strings.Join(c.FormatNames(mimeType), ", "))
+	}
+	return FormatExtension(exportMimeType), exportMimeType, nil
+}
+
+// openExport returns the export of a Workspace file to exportMimeType.
+// Files.Export refuses exports larger than 10 MB, which image-heavy decks
+// and long documents easily reach; those are streamed from the export link
+// of the file instead, which has no such limit but needs ds.HTTP.
+func (ds *Service) openExport(ctx context.Context, fileID, exportMimeType string) (*http.Response, error) {
+	resp, err := ds.Backend.ExportFile(ctx, fileID, exportMimeType)
+	if err == nil || !isExportSizeLimitExceeded(err) {
+		return resp, err
+	}
+
+	f, linkErr := ds.Backend.GetFile(ctx, fileID, "id, exportLinks")
+	if linkErr != nil {
+		return nil, fmt.Errorf("%w (unable to get export links: %v)", err, linkErr)
+	}
+	link, ok := f.ExportLinks[exportMimeType]
+	if !ok {
+		return nil, fmt.Errorf("%w (no export link for %s)", err, exportMimeType)
+	}
+	resp, linkErr = ds.fetchLink(ctx, link)
+	if linkErr != nil {
+		return nil, fmt.Errorf("export is over the 10 MB API limit and its export link failed: %w", linkErr)
+	}
+	return resp, nil
+}
+
+// isExportSizeLimitExceeded reports whether err is Drive refusing an export
+// for being too large.
+func isExportSizeLimitExceeded(err error) bool {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	for _, e := range apiErr.Errors {
+		if e.Reason == exportSizeLimitExceeded {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseExportSpec parses a comma-separated list of TYPE=FORMAT pairs, such
+// as "doc=md,sheet=xlsx,slide=pdf", into export formats keyed by Workspace
+// MIME type. TYPE is doc, sheet, slide, drawing or the name of any other
+// type Drive exports, as shown by WorkspaceTypeName; FORMAT must be one it
+// can be exported to.
+func (ds *Service) ParseExportSpec(ctx context.Context, spec string) (map[string]string, error) {
+	formats := make(map[string]string)
+	for pair := range strings.SplitSeq(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, format, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid export mapping %q (want TYPE=FORMAT, e.g. doc=md)", pair)
+		}
+		name, format = strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(format)
+
+		c := ds.ExportCatalog(ctx)
+		mimeType, ok := workspaceTypeNames[name]
+		if !ok {
+			mimeType = googleAppsPrefix + name
+			if _, ok := c.Exports[mimeType]; !ok {
+				return nil, fmt.Errorf("unknown Workspace type %q (use doc, sheet, slide or drawing, see 'gdrive formats')", name)
+			}
+		}
+		canonical, _, err := ds.ResolveExportFormat(ctx, mimeType, format)
+		if err != nil || format == "" {
+			return nil, fmt.Errorf("%s cannot be exported as %q (available: %s)", name, format, strings.Join(c.FormatNames(mimeType), ", "))
+		}
+		formats[mimeType] = canonical
+	}
+	return formats, nil
+}
+
+// ExportFilename returns the local name of a Workspace file exported to
+// format. Workspace files have no extension, so unlike AdjustFilename this
+// appends one and leaves dots in the name alone.
+func ExportFilename(name, format string) string {
+	if strings.EqualFold(filepath.Ext(name), "."+format) {
+		return name
+	}
+	return name + "." + format
+}
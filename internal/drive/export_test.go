@@ -0,0 +1,193 @@
+package drive_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"gdrive/internal/drive"
+	"gdrive/internal/drivetest"
+)
+
+const (
+	docMime   = "application/vnd.google-apps.document"
+	sheetMime = "application/vnd.google-apps.spreadsheet"
+	slideMime = "application/vnd.google-apps.presentation"
+)
+
+func TestParseExportSpec(t *testing.T) {
+	_, ds := newFakeService(t)
+	ctx := t.Context()
+
+	got, err := ds.ParseExportSpec(ctx, "doc=md, Sheet=ODS,slides=pdf,script=json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		docMime:                              "md",
+		sheetMime:                            "ods",
+		slideMime:                            "pdf",
+		"application/vnd.google-apps.script": "json",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ParseExportSpec = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("format for %s = %q, want %q", k, got[k], v)
+		}
+	}
+
+	if got, err := ds.ParseExportSpec(ctx, ""); err != nil || len(got) != 0 {
+		t.Errorf("empty spec = %v, %v", got, err)
+	}
+	for _, bad := range []string{"doc", "site=pdf", "sheet=md", "doc=md,slide", "doc="} {
+		if _, err := ds.ParseExportSpec(ctx, bad); err == nil {
+			t.Errorf("ParseExportSpec(%q) should fail", bad)
+		}
+	}
+}
+
+func TestExportCatalogFromAbout(t *testing.T) {
+	_, ds := newFakeService(t)
+	c := ds.ExportCatalog(t.Context())
+	if c.Fetched.IsZero() {
+		t.Fatal("catalog was not read from Drive")
+	}
+
+	if got := c.FormatNames(docMime); !slices.Contains(got, "odt") || !slices.Contains(got, "epub") || !slices.Contains(got, "rtf") {
+		t.Errorf("document formats = %v", got)
+	}
+	if got := c.FormatNames("application/vnd.google-apps.form"); !slices.Equal(got, []string{"zip"}) {
+		t.Errorf("form formats = %v", got)
+	}
+	if got := c.MimeType("application/vnd.google-apps.drawing", "jpeg"); got != "image/jpeg" {
+		t.Errorf("jpeg alias resolved to %q", got)
+	}
+	if got := c.MimeType(docMime, "xlsx"); got != "" {
+		t.Errorf("doc=xlsx resolved to %q", got)
+	}
+	for mimeType, want := range map[string]string{docMime: "pdf", "application/vnd.google-apps.script": "json"} {
+		if got := c.DefaultFormat(mimeType); got != want {
+			t.Errorf("default format of %s = %q, want %q", mimeType, got, want)
+		}
+	}
+	if got := c.ImportSources()[docMime]; !slices.Equal(got, []string{"md", "txt"}) {
+		t.Errorf("document import sources = %v", got)
+	}
+}
+
+func TestExportCatalogCache(t *testing.T) {
+	srv, ds := newFakeService(t)
+	ctx := t.Context()
+	// The config dir is created on the first write
+	ds.FormatsFile = filepath.Join(t.TempDir(), "config", "formats.json")
+
+	fetched := ds.ExportCatalog(ctx).Fetched
+	if _, err := os.Stat(ds.FormatsFile); err != nil {
+		t.Fatalf("catalog not cached: %v", err)
+	}
+
+	// A new service reads the cached copy without asking Drive.
+	other := drive.NewService(srv.API(t))
+	other.FormatsFile = ds.FormatsFile
+	srv.FailNext(500, "backendError")
+	if got := other.ExportCatalog(ctx).Fetched; !got.Equal(fetched) {
+		t.Errorf("cached catalog fetched at %v, want %v", got, fetched)
+	}
+
+	// Without Drive or a cache, the built-in formats are used.
+	fallback := drive.NewService(srv.API(t))
+	srv.FailNext(500, "backendError")
+	c := fallback.ExportCatalog(ctx)
+	if !c.Fetched.IsZero() || c.MimeType(docMime, "md") != "text/markdown" {
+		t.Errorf("fallback catalog = %+v", c)
+	}
+}
+
+func TestResolveExportFormat(t *testing.T) {
+	srv, ds := newFakeService(t)
+	ctx := t.Context()
+
+	if format, mimeType, err := ds.ResolveExportFormat(ctx, sheetMime, "TSV"); err != nil || format != "tsv" || mimeType != "text/tab-separated-values" {
+		t.Errorf("sheet=TSV = %q, %q, %v", format, mimeType, err)
+	}
+	if format, _, err := ds.ResolveExportFormat(ctx, slideMime, ""); err != nil || format != "pptx" {
+		t.Errorf("default slide format = %q, %v", format, err)
+	}
+	if _, _, err := ds.ResolveExportFormat(ctx, docMime, "bogus"); err == nil {
+		t.Error("bogus format should fail")
+	}
+	if _, _, err := ds.ResolveExportFormat(ctx, "application/vnd.google-apps.site", ""); err == nil {
+		t.Error("sites cannot be exported")
+	}
+
+	ds.HTTP = srv.Client()
+	id := srv.AddDoc(drivetest.RootID, "Plan", docMime, []byte("plan"))
+	dest := filepath.Join(t.TempDir(), "Plan")
+	if err := ds.DownloadFile(ctx, id, dest, "odt", false, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dest + ".odt"); err != nil {
+		t.Errorf("odt export not written: %v", err)
+	}
+}
+
+func TestFormatExtension(t *testing.T) {
+	cases := map[string]string{
+		"text/plain": "txt",
+		"image/jpeg": "jpg",
+		"application/vnd.openxmlformats-officedocument.wordprocessingml.document": "docx",
+		"application/x-vnd.oasis.opendocument.spreadsheet":                        "ods",
+		"application/vnd.google-apps.script+json":                                 "json",
+		"application/x-foo+xml":                                                   "x-foo",
+	}
+	for mimeType, want := range cases {
+		if got := drive.FormatExtension(mimeType); got != want {
+			t.Errorf("FormatExtension(%q) = %q, want %q", mimeType, got, want)
+		}
+	}
+}
+
+func TestExportFilename(t *testing.T) {
+	cases := []struct{ name, format, want string }{
+		{"Plan", "md", "Plan.md"},
+		{"Budget v1.2", "xlsx", "Budget v1.2.xlsx"},
+		{"report.PDF", "pdf", "report.PDF"},
+	}
+	for _, tc := range cases {
+		if got := drive.ExportFilename(tc.name, tc.format); got != tc.want {
+			t.Errorf("ExportFilename(%q, %q) = %q, want %q", tc.name, tc.format, got, tc.want)
+		}
+	}
+}
+
+func TestExportOverSizeLimit(t *testing.T) {
+	srv, ds := newFakeService(t)
+	ctx := t.Context()
+	id := srv.AddDoc(drivetest.RootID, "Deck", slideMime, []byte("many large images"))
+	srv.SetExportLimit(4)
+	dest := filepath.Join(t.TempDir(), "Deck")
+
+	if err := ds.DownloadFile(ctx, id, dest, "pdf", false, false); err == nil {
+		t.Fatal("export links need an HTTP client")
+	}
+
+	ds.HTTP = srv.Client()
+	if err := ds.DownloadFile(ctx, id, dest, "pdf", false, false); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(dest + ".pdf"); string(data) != "many large images" {
+		t.Errorf("exported %q", data)
+	}
+
+	data, _, err := ds.DownloadFileContent(ctx, id, "application/pdf")
+	if err != nil || string(data) != "many large images" {
+		t.Errorf("DownloadFileContent = %q, %v", data, err)
+	}
+	text, _, _, err := ds.ReadFileContent(ctx, id)
+	if err != nil || text != "many large images" {
+		t.Errorf("ReadFileContent = %q, %v", text, err)
+	}
+}
@@ -0,0 +1,307 @@
+package drive
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// SharingGrant is access a sharing manifest declares on an item. Principal
+// is an email address, a domain or "anyone"; Type is user, group, domain or
+// anyone. A zero Expires makes the access permanent.
+type SharingGrant struct {
+	Principal string
+	Type      string
+	Role      string
+	Expires   time.Time
+}
+
+// SharingTarget is the access a sharing manifest declares on the item at
+// Path. Grants may be empty, leaving only the owners with --prune.
+type SharingTarget struct {
+	Path   string
+	Grants []SharingGrant
+}
+
+// manifestGrant is a grant as written in a YAML manifest.
+type manifestGrant struct {
+	Principal string `yaml:"principal"`
+	Type      string `yaml:"type"`
+	Role      string `yaml:"role"`
+	Expires   string `yaml:"expires"`
+}
+
+// manifestTarget is a path as written in a YAML manifest.
+type manifestTarget struct {
+	Path   string          `yaml:"path"`
+	Grants []manifestGrant `yaml:"grants"`
+}
+
+// manifestRoles are the roles a manifest may grant. Ownership is
+// transferred, not granted.
+var manifestRoles = []string{"reader", "commenter", "writer", "fileOrganizer", "organizer"}
+
+// ParseSharingManifest reads a sharing manifest in format "yaml" or "csv".
+//
+// A YAML manifest is a list of entries with a path and its grants, such as
+// {path: Projects/ACME, grants: [{principal: alice@example.com, role:
+// writer}]}. Each grant has a principal and a role, and optionally a type
+// and an expires date.
+//
+// A CSV manifest has a header naming the path, principal and role columns,
+// and optionally type and expires, then one grant per row; a row with no
+// principal declares a path without grants.
+//
+// Type defaults to anyone for the principal "anyone", user for email
+// addresses and domain otherwise; groups must be given their type.
+func ParseSharingManifest(r io.Reader, format string) ([]SharingTarget, error) {
+	var raw []manifestTarget
+	switch format {
+	case "yaml":
+		dec := yaml.NewDecoder(r)
+		dec.KnownFields(true)
+		if err := dec.Decode(&raw); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("invalid manifest: %w", err)
+		}
+	case "csv":
+		var err error
+		if raw, err = readCSVManifest(r); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("invalid manifest format %q (use yaml or csv)", format)
+	}
+
+	targets := make([]SharingTarget, 0, len(raw))
+	seen := map[string]bool{}
+	for _, t := range raw {
+		path := strings.Trim(strings.TrimSpace(t.Path), "/")
+		if path == "" {
+			return nil, fmt.Errorf("invalid manifest: every entry needs a path")
+		}
+		if seen[path] {
+			return nil, fmt.Errorf("invalid manifest: %s is listed twice", path)
+		}
+		seen[path] = true
+
+		target := SharingTarget{Path: path}
+		for _, g := range t.Grants {
+			grant, err := parseGrant(g)
+			if err != nil {
+				return nil, fmt.Errorf("invalid manifest entry for %s: %w", path, err)
+			}
+			if slices.ContainsFunc(target.Grants, func(o SharingGrant) bool {
+				return o.Type == grant.Type && strings.EqualFold(o.Principal, grant.Principal)
+			}) {
+				return nil, fmt.Errorf("invalid manifest entry for %s: %s is listed twice", path, grant.Principal)
+			}
+			target.Grants = append(target.Grants, grant)
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// readCSVManifest reads the rows of a CSV manifest, grouping them by path
+// in the order paths first appear.
+func readCSVManifest(r io.Reader) ([]manifestTarget, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	columns := map[string]int{}
+	for i, name := range rows[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"path", "principal", "role"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("invalid manifest: the CSV header has no %s column", name)
+		}
+	}
+	cell := func(row []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	var targets []manifestTarget
+	index := map[string]int{}
+	for _, row := range rows[1:] {
+		path := cell(row, "path")
+		i, ok := index[path]
+		if !ok {
+			i = len(targets)
+			index[path] = i
+			targets = append(targets, manifestTarget{Path: path})
+		}
+		if cell(row, "principal") == "" && cell(row, "role") == "" {
+			continue
+		}
+		targets[i].Grants = append(targets[i].Grants, manifestGrant{
+			Principal: cell(row, "principal"),
+			Type:      cell(row, "type"),
+			Role:      cell(row, "role"),
+			Expires:   cell(row, "expires"),
+		})
+	}
+	return targets, nil
+}
+
+func parseGrant(g manifestGrant) (SharingGrant, error) {
+	grant := SharingGrant{Principal: strings.TrimSpace(g.Principal), Type: g.Type, Role: g.Role}
+	if grant.Principal == "" {
+		return grant, fmt.Errorf("a grant has no principal")
+	}
+	if grant.Type == "" {
+		switch {
+		case strings.EqualFold(grant.Principal, "anyone"):
+			grant.Type = "anyone"
+		case strings.Contains(grant.Principal, "@"):
+			grant.Type = "user"
+		default:
+			grant.Type = "domain"
+		}
+	}
+	switch grant.Type {
+	case "user", "group", "domain":
+	case "anyone":
+		grant.Principal = "anyone"
+	default:
+		return grant, fmt.Errorf("invalid type %q for %s (use user, group, domain or anyone)", grant.Type, grant.Principal)
+	}
+	if grant.Role == "owner" {
+		return grant, fmt.Errorf("ownership of %s cannot be declared, transfer it with 'gdrive file share --role owner'", grant.Principal)
+	}
+	if !slices.Contains(manifestRoles, grant.Role) {
+		return grant, fmt.Errorf("invalid role %q for %s (use %s)", grant.Role, grant.Principal, strings.Join(manifestRoles, ", "))
+	}
+	if g.Expires != "" {
+		if grant.Type != "user" && grant.Type != "group" {
+			return grant, fmt.Errorf("only user and group access can expire, not %s", grant.Principal)
+		}
+		t, err := ParseDate(g.Expires)
+		if err != nil {
+			return grant, err
+		}
+		grant.Expires = t
+	}
+	return grant, nil
+}
+
+// SharingAction is what applying a manifest does to a permission.
+type SharingAction string
+
+// Sharing actions.
+const (
+	SharingAdd    SharingAction = "add"
+	SharingUpdate SharingAction = "update" // change of role or expiration
+	SharingRemove SharingAction = "remove" // only with prune
+)
+
+// SharingChange is a change needed for an item to match its manifest
+// entry. Grant is the access the manifest declares, or the current access
+// for removals; Permission is the current permission for updates and
+// removals.
+type SharingChange struct {
+	Action     SharingAction
+	Path       string
+	FileID     string
+	Grant      SharingGrant
+	Permission *drive.Permission
+}
+
+// PlanSharing returns the changes that make the permissions of fileID
+// match target. With prune, permissions the manifest does not list are
+// removed, except owners and the access a Shared Drive item inherits.
+func (ds *Service) PlanSharing(ctx context.Context, fileID string, target SharingTarget, prune bool) ([]*SharingChange, error) {
+	perms, err := ds.Backend.ListPermissions(ctx, fileID, auditPermissionFields)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list permissions of %s: %w", target.Path, err)
+	}
+
+	var changes []*SharingChange
+	matched := map[*drive.Permission]bool{}
+	for _, grant := range target.Grants {
+		change := &SharingChange{Path: target.Path, FileID: fileID, Grant: grant}
+		i := slices.IndexFunc(perms, func(p *drive.Permission) bool {
+			return p.Type == grant.Type && MatchPrincipal(p, grant.Principal)
+		})
+		if i < 0 {
+			change.Action = SharingAdd
+			changes = append(changes, change)
+			continue
+		}
+
+		perm := perms[i]
+		matched[perm] = true
+		if perm.Role == "owner" {
+			return nil, fmt.Errorf("%s owns %s, their role cannot change", grant.Principal, target.Path)
+		}
+		expires, _ := time.Parse(time.RFC3339, perm.ExpirationTime)
+		if perm.Role != grant.Role || !expires.Equal(grant.Expires) {
+			change.Action, change.Permission = SharingUpdate, perm
+			changes = append(changes, change)
+		}
+	}
+
+	if !prune {
+		return changes, nil
+	}
+	for _, p := range perms {
+		if inherited, _ := inheritance(p); matched[p] || inherited || p.Role == "owner" {
+			continue
+		}
+		expires, _ := time.Parse(time.RFC3339, p.ExpirationTime)
+		changes = append(changes, &SharingChange{
+			Action:     SharingRemove,
+			Path:       target.Path,
+			FileID:     fileID,
+			Grant:      SharingGrant{Principal: Principal(p), Type: p.Type, Role: p.Role, Expires: expires},
+			Permission: p,
+		})
+	}
+	return changes, nil
+}
+
+// ApplySharingChange makes a change planned by PlanSharing. notify sends
+// users and groups given access the notification email.
+func (ds *Service) ApplySharingChange(ctx context.Context, c *SharingChange, notify bool) error {
+	switch c.Action {
+	case SharingAdd:
+		opts := ShareOptions{Type: c.Grant.Type, Role: c.Grant.Role, Expires: c.Grant.Expires, Notify: notify}
+		if c.Grant.Type == "domain" {
+			opts.Domain = c.Grant.Principal
+		} else {
+			opts.Email = c.Grant.Principal
+		}
+		_, err := ds.ShareFile(ctx, c.FileID, opts)
+		return err
+	case SharingUpdate:
+		u := PermissionUpdate{Expires: c.Grant.Expires}
+		if c.Grant.Role != c.Permission.Role {
+			u.Role = c.Grant.Role
+		}
+		u.RemoveExpiration = c.Grant.Expires.IsZero() && c.Permission.ExpirationTime != ""
+		_, err := ds.UpdatePermission(ctx, c.FileID, c.Permission.Id, u)
+		return err
+	case SharingRemove:
+		if err := ds.RemovePermission(ctx, c.FileID, c.Permission.Id); err != nil {
+			return fmt.Errorf("unable to remove permission: %w", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown sharing action %q", c.Action)
+}
@@ -0,0 +1,126 @@
+package drive_test
+
+import (
+	"strings"
+	"testing"
+
+	driveapi "google.golang.org/api/drive/v3"
+
+	"gdrive/internal/drive"
+	"gdrive/internal/drivetest"
+)
+
+func TestParseSharingManifest(t *testing.T) {
+	yamlManifest := `
+- path: /Projects/ACME/
+  grants:
+    - {principal: alice@example.com, role: writer}
+    - {principal: team@example.com, type: group, role: reader, expires: 2099-12-31}
+    - {principal: example.com, role: commenter}
+    - {principal: Anyone, role: reader}
+- path: Projects/Archive
+`
+	csvManifest := `path,principal,type,role,expires
+Projects/ACME,alice@example.com,,writer,
+Projects/ACME,team@example.com,group,reader,2099-12-31
+Projects/ACME,example.com,,commenter,
+Projects/ACME,anyone,,reader,
+Projects/Archive,,,,
+`
+	for format, text := range map[string]string{"yaml": yamlManifest, "csv": csvManifest} {
+		targets, err := drive.ParseSharingManifest(strings.NewReader(text), format)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if len(targets) != 2 || targets[0].Path != "Projects/ACME" || len(targets[0].Grants) != 4 || len(targets[1].Grants) != 0 {
+			t.Fatalf("%s: targets = %+v", format, targets)
+		}
+		var types []string
+		for _, g := range targets[0].Grants {
+			types = append(types, g.Type)
+		}
+		if got := strings.Join(types, ","); got != "user,group,domain,anyone" {
+			t.Errorf("%s: types = %s", format, got)
+		}
+		if g := targets[0].Grants[1]; g.Expires.Year() != 2099 {
+			t.Errorf("%s: group grant = %+v", format, g)
+		}
+	}
+
+	for name, text := range map[string]string{
+		"owner":          "- {path: A, grants: [{principal: a@example.com, role: owner}]}",
+		"bad role":       "- {path: A, grants: [{principal: a@example.com, role: editor}]}",
+		"domain expires": "- {path: A, grants: [{principal: example.com, role: reader, expires: 2099-01-01}]}",
+		"duplicate path": "- {path: A}\n- {path: /A}",
+		"duplicate user": "- {path: A, grants: [{principal: a@example.com, role: reader}, {principal: A@example.com, role: writer}]}",
+		"unknown field":  "- {path: A, grant: []}",
+		"no path":        "- {grants: []}",
+	} {
+		if _, err := drive.ParseSharingManifest(strings.NewReader(text), "yaml"); err == nil {
+			t.Errorf("%s: manifest should be refused", name)
+		}
+	}
+	if _, err := drive.ParseSharingManifest(strings.NewReader("path,role\nA,reader\n"), "csv"); err == nil {
+		t.Error("CSV without principal column should be refused")
+	}
+}
+
+func TestPlanAndApplySharing(t *testing.T) {
+	srv, ds := newFakeService(t)
+	ctx := t.Context()
+	id := srv.AddFolder(drivetest.RootID, "ACME")
+	srv.Share(id, driveapi.Permission{Type: "user", Role: "reader", EmailAddress: "alice@example.com"})
+	srv.Share(id, driveapi.Permission{Type: "user", Role: "writer", EmailAddress: "bob@example.com"})
+	srv.Share(id, driveapi.Permission{Type: "anyone", Role: "reader"})
+
+	targets, err := drive.ParseSharingManifest(strings.NewReader(`
+- path: ACME
+  grants:
+    - {principal: Alice@example.com, role: writer}
+    - {principal: bob@example.com, role: writer}
+    - {principal: team@example.com, type: group, role: commenter, expires: 2099-12-31}
+`), "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan := func(prune bool) []*drive.SharingChange {
+		t.Helper()
+		changes, err := ds.PlanSharing(ctx, id, targets[0], prune)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return changes
+	}
+	actions := func(changes []*drive.SharingChange) string {
+		var s []string
+		for _, c := range changes {
+			s = append(s, string(c.Action)+" "+c.Grant.Principal)
+		}
+		return strings.Join(s, ", ")
+	}
+
+	if got := actions(plan(false)); got != "update Alice@example.com, add team@example.com" {
+		t.Errorf("plan = %s", got)
+	}
+	changes := plan(true)
+	if got := actions(changes); got != "update Alice@example.com, add team@example.com, remove anyone" {
+		t.Fatalf("plan with prune = %s", got)
+	}
+	for _, c := range changes {
+		if err := ds.ApplySharingChange(ctx, c, false); err != nil {
+			t.Fatalf("%s %s: %v", c.Action, c.Grant.Principal, err)
+		}
+	}
+	if left := plan(true); len(left) != 0 {
+		t.Errorf("plan after apply = %s", actions(left))
+	}
+	if perms := srv.Permissions(id); len(perms) != 4 {
+		t.Errorf("permissions = %+v", perms)
+	}
+
+	targets[0].Grants = append(targets[0].Grants, drive.SharingGrant{Principal: drivetest.Me.EmailAddress, Type: "user", Role: "reader"})
+	if _, err := ds.PlanSharing(ctx, id, targets[0], false); err == nil {
+		t.Error("changing the role of the owner should be refused")
+	}
+}
@@ -0,0 +1,132 @@
+package drive
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// maxPropertySize is the number of bytes Drive allows for the key and value
+// of one custom property together.
+const maxPropertySize = 124
+
+// metadataFields selects the editable metadata of a file.
+const metadataFields = "id, name, description, starred, properties, appProperties"
+
+// Metadata holds the metadata of a file that users can edit besides its
+// name. Properties are visible to every app with access to the file;
+// AppProperties are private to the OAuth client that set them. Both can be
+// searched with query.Property and query.AppProperty.
+type Metadata struct {
+	ID            string
+	Name          string
+	Description   string
+	Starred       bool
+	Properties    map[string]string
+	AppProperties map[string]string
+}
+
+// MetadataUpdate describes a change to the metadata of a file. Nil fields
+// are left as they are. Keys listed in Unset and UnsetApp are removed from
+// the properties and appProperties of the file.
+type MetadataUpdate struct {
+	Description   *string
+	Starred       *bool
+	Properties    map[string]string
+	AppProperties map[string]string
+	Unset         []string
+	UnsetApp      []string
+}
+
+// IsEmpty reports whether u changes nothing.
+func (u MetadataUpdate) IsEmpty() bool {
+	return u.Description == nil && u.Starred == nil &&
+		len(u.Properties) == 0 && len(u.AppProperties) == 0 && len(u.Unset) == 0 && len(u.UnsetApp) == 0
+}
+
+// ParseProperties parses key=value pairs into a property map. Values may
+// contain '=' and be empty; keys may not.
+func ParseProperties(pairs []string) (map[string]string, error) {
+	props := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid property %q: expected key=value", pair)
+		}
+		props[key] = value
+	}
+	return props, nil
+}
+
+// GetMetadata returns the description, starred flag and custom properties
+// of a file.
+func (ds *Service) GetMetadata(ctx context.Context, fileID string) (*Metadata, error) {
+	file, err := ds.Backend.GetFile(ctx, fileID, metadataFields)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get metadata: %w", err)
+	}
+	return newMetadata(file), nil
+}
+
+// UpdateMetadata applies u to a file and returns its metadata afterwards.
+// Drive limits each property to 124 bytes of key and value together.
+func (ds *Service) UpdateMetadata(ctx context.Context, fileID string, u MetadataUpdate) (*Metadata, error) {
+	meta := &drive.File{}
+	if u.Description != nil {
+		meta.Description = *u.Description
+		meta.ForceSendFields = append(meta.ForceSendFields, "Description")
+	}
+	if u.Starred != nil {
+		meta.Starred = *u.Starred
+		meta.ForceSendFields = append(meta.ForceSendFields, "Starred")
+	}
+
+	for _, ns := range []struct {
+		field string
+		dst   *map[string]string
+		set   map[string]string
+		unset []string
+	}{
+		{"Properties", &meta.Properties, u.Properties, u.Unset},
+		{"AppProperties", &meta.AppProperties, u.AppProperties, u.UnsetApp},
+	} {
+		if len(ns.set) == 0 && len(ns.unset) == 0 {
+			continue
+		}
+		*ns.dst = make(map[string]string, len(ns.set))
+		for k, v := range ns.set {
+			if len(k)+len(v) > maxPropertySize {
+				return nil, fmt.Errorf("property %q is too long: key and value must fit in %d bytes", k, maxPropertySize)
+			}
+			(*ns.dst)[k] = v
+		}
+		// Drive deletes the keys sent with a null value.
+		for _, k := range ns.unset {
+			if _, ok := ns.set[k]; ok {
+				return nil, fmt.Errorf("property %q is both set and unset", k)
+			}
+			meta.NullFields = append(meta.NullFields, ns.field+"."+k)
+		}
+		meta.ForceSendFields = append(meta.ForceSendFields, ns.field)
+	}
+
+	file, err := ds.Backend.UpdateFile(ctx, fileID, meta, UpdateOptions{}, metadataFields)
+	if err != nil {
+		return nil, fmt.Errorf("unable to update metadata: %w", err)
+	}
+	return newMetadata(file), nil
+}
+
+func newMetadata(file *drive.File) *Metadata {
+	return &Metadata{
+		ID:            file.Id,
+		Name:          file.Name,
+		Description:   file.Description,
+		Starred:       file.Starred,
+		Properties:    file.Properties,
+		AppProperties: file.AppProperties,
+	}
+}
@@ -0,0 +1,79 @@
+package drive_test
+
+import (
+	"maps"
+	"strings"
+	"testing"
+
+	"gdrive/internal/drive"
+	"gdrive/internal/drivetest"
+)
+
+func TestUpdateMetadata(t *testing.T) {
+	srv, ds := newFakeService(t)
+	ctx := t.Context()
+	id := srv.AddFile(drivetest.RootID, "q3.pdf", []byte("%PDF"))
+
+	desc, starred := "Q3 figures", true
+	meta, err := ds.UpdateMetadata(ctx, id, drive.MetadataUpdate{
+		Description:   &desc,
+		Starred:       &starred,
+		Properties:    map[string]string{"project": "ACME-42", "state": "draft"},
+		AppProperties: map[string]string{"pipeline": "ingested"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Description != desc || !meta.Starred || meta.Properties["state"] != "draft" || meta.AppProperties["pipeline"] != "ingested" {
+		t.Errorf("after set: %+v", meta)
+	}
+
+	// Unset keys are removed, other keys are kept, and false unstars.
+	unstar := false
+	if _, err := ds.UpdateMetadata(ctx, id, drive.MetadataUpdate{
+		Starred:    &unstar,
+		Properties: map[string]string{"reviewer": "ana"},
+		Unset:      []string{"state", "missing"},
+		UnsetApp:   []string{"pipeline"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	meta, err = ds.GetMetadata(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"project": "ACME-42", "reviewer": "ana"}; !maps.Equal(meta.Properties, want) {
+		t.Errorf("properties = %v, want %v", meta.Properties, want)
+	}
+	if meta.Starred || len(meta.AppProperties) != 0 || meta.Description != desc {
+		t.Errorf("after unset: %+v", meta)
+	}
+
+	files, err := ds.SearchFiles(ctx, "", drive.SearchOptions{Properties: map[string]string{"project": "ACME-42", "reviewer": "ana"}})
+	if err != nil || len(files) != 1 || files[0].Id != id {
+		t.Errorf("search by properties = %v, %v", files, err)
+	}
+	if files, _ := ds.SearchFiles(ctx, "", drive.SearchOptions{Properties: map[string]string{"project": "other"}}); len(files) != 0 {
+		t.Errorf("search by another value = %v", files)
+	}
+
+	long := map[string]string{"note": strings.Repeat("x", 121)}
+	if _, err := ds.UpdateMetadata(ctx, id, drive.MetadataUpdate{Properties: long}); err == nil {
+		t.Error("property over 124 bytes should be refused")
+	}
+}
+
+func TestParseProperties(t *testing.T) {
+	got, err := drive.ParseProperties([]string{"project=ACME-42", "query=a=b", "empty="})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"project": "ACME-42", "query": "a=b", "empty": ""}; !maps.Equal(got, want) {
+		t.Errorf("ParseProperties = %v, want %v", got, want)
+	}
+	for _, bad := range []string{"project", "=value"} {
+		if _, err := drive.ParseProperties([]string{bad}); err == nil {
+			t.Errorf("ParseProperties(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package drive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrPolicyDenied is wrapped by the errors of sharing the policy forbids.
+var ErrPolicyDenied = errors.New("denied by the sharing policy")
+
+// SharingPolicy is the organization's rules for sharing, which the
+// sharing methods of a Service enforce when its Policy is set. Zero fields
+// impose nothing. It is read from YAML by LoadSharingPolicy:
+//
+//	allowedDomains: [example.com, partner.com]
+//	forbiddenAnyoneRoles: [writer, commenter]
+//	protectedFolderIds: [1a2b3c4d5e]
+//	protectedPaths: [Confidential, "@Legal/Contracts"]
+//	maxExpirationDays: 90
+type SharingPolicy struct {
+	// AllowedDomains are the only domains users, groups and domains may
+	// belong to.
+	AllowedDomains []string `yaml:"allowedDomains"`
+	// ForbiddenAnyoneRoles are the roles anyone with the link may not get.
+	ForbiddenAnyoneRoles []string `yaml:"forbiddenAnyoneRoles"`
+	// ProtectedFolderIDs and ProtectedPaths are folders whose items, at
+	// any depth, may not be shared at all.
+	ProtectedFolderIDs []string `yaml:"protectedFolderIds"`
+	ProtectedPaths     []string `yaml:"protectedPaths"`
+	// MaxExpirationDays, when positive, makes the access of users and
+	// groups expire within that many days.
+	MaxExpirationDays int `yaml:"maxExpirationDays"`
+}
+
+// LoadSharingPolicy reads a sharing policy from a YAML file. It returns
+// nil, and no error, when the file does not exist.
+func LoadSharingPolicy(path string) (*SharingPolicy, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	policy := &SharingPolicy{}
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(policy); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid sharing policy %s: %w", path, err)
+	}
+	if policy.MaxExpirationDays < 0 {
+		return nil, fmt.Errorf("invalid sharing policy %s: maxExpirationDays must not be negative", path)
+	}
+	return policy, nil
+}
+
+// policyDenied returns the error of sharing the policy forbids.
+func policyDenied(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrPolicyDenied, fmt.Sprintf(format, args...))
+}
+
+// checkShare checks the permission p about to be created on fileID against
+// ds.Policy. Owners, who get the item rather than access to it, do not
+// expire.
+func (ds *Service) checkShare(ctx context.Context, fileID string, p *drive.Permission) error {
+	policy := ds.Policy
+	if policy == nil {
+		return nil
+	}
+
+	if p.Type == "anyone" && slices.Contains(policy.ForbiddenAnyoneRoles, p.Role) {
+		return policyDenied("anyone with the link may not be %s", p.Role)
+	}
+	if err := policy.checkDomain(p); err != nil {
+		return err
+	}
+	if p.Role != "owner" {
+		if err := policy.checkExpiration(p); err != nil {
+			return err
+		}
+	}
+	return ds.checkProtected(ctx, fileID)
+}
+
+// roleRanks orders roles by the access they give.
+var roleRanks = map[string]int{"reader": 1, "commenter": 2, "writer": 3, "fileOrganizer": 4, "organizer": 5, "owner": 6}
+
+// checkUpdate checks the permission permissionID of fileID, as u would
+// leave it, against ds.Policy. Only the rules on what u changes apply, so
+// that access granted before the policy can still be reduced: a higher role
+// is checked like a new share, expiration included, other role changes only
+// against the roles anyone may get, and a new expiration against the maximum.
+func (ds *Service) checkUpdate(ctx context.Context, fileID, permissionID string, u PermissionUpdate) error {
+	policy := ds.Policy
+	if policy == nil {
+		return nil
+	}
+	perms, err := ds.ListPermissions(ctx, fileID)
+	if err != nil {
+		return fmt.Errorf("unable to list permissions: %w", err)
+	}
+	i := slices.IndexFunc(perms, func(p *drive.Permission) bool { return p.Id == permissionID })
+	if i < 0 {
+		return fmt.Errorf("permission %s not found", permissionID)
+	}
+
+	current := perms[i]
+	updated := *current
+	if u.Role != "" {
+		updated.Role = u.Role
+	}
+	switch {
+	case !u.Expires.IsZero():
+		updated.ExpirationTime = u.Expires.UTC().Format(time.RFC3339)
+	case u.RemoveExpiration:
+		updated.ExpirationTime = ""
+	}
+	if u.Role != "" && updated.Type == "anyone" && slices.Contains(policy.ForbiddenAnyoneRoles, updated.Role) {
+		return policyDenied("anyone with the link may not be %s", updated.Role)
+	}
+	raised := roleRanks[updated.Role] > roleRanks[current.Role]
+	if raised {
+		if err := policy.checkDomain(&updated); err != nil {
+			return err
+		}
+		if err := ds.checkProtected(ctx, fileID); err != nil {
+			return err
+		}
+	}
+	if updated.Role == "owner" || (!raised && u.Expires.IsZero() && !u.RemoveExpiration) {
+		return nil
+	}
+	return policy.checkExpiration(&updated)
+}
+
+// checkDomain checks that users, groups and domains belong to the allowed
+// domains.
+func (policy *SharingPolicy) checkDomain(p *drive.Permission) error {
+	d := principalDomain(p)
+	if d == "" || len(policy.AllowedDomains) == 0 ||
+		slices.ContainsFunc(policy.AllowedDomains, func(a string) bool { return strings.EqualFold(a, d) }) {
+		return nil
+	}
+	return policyDenied("%s is outside the allowed domains (%s)", Principal(p), strings.Join(policy.AllowedDomains, ", "))
+}
+
+// checkExpiration checks that the access of a user or group ends within
+// MaxExpirationDays.
+func (policy *SharingPolicy) checkExpiration(p *drive.Permission) error {
+	if policy.MaxExpirationDays <= 0 || (p.Type != "user" && p.Type != "group") {
+		return nil
+	}
+	if p.ExpirationTime == "" {
+		return policyDenied("access of users and groups must expire within %d days", policy.MaxExpirationDays)
+	}
+	t, err := time.Parse(time.RFC3339, p.ExpirationTime)
+	if err != nil {
+		return err
+	}
+	if limit := time.Now().AddDate(0, 0, policy.MaxExpirationDays); t.After(limit) {
+		return policyDenied("access of users and groups must expire within %d days, by %s", policy.MaxExpirationDays, limit.Format(time.DateOnly))
+	}
+	return nil
+}
+
+// checkProtected fails for items that are, or are under, a protected
+// folder. Protected paths are resolved from Drive, without the path cache
+// or the Picker, so that neither a stale entry nor the choice of the user
+// between folders of the same name leaves one unprotected. Protected paths
+// that cannot be resolved, or resolve to several folders, and parents that
+// cannot be read, deny sharing, so that a mistake in the policy or a failed
+// lookup does not lift the protection.
+func (ds *Service) checkProtected(ctx context.Context, fileID string) error {
+	policy := ds.Policy
+	if len(policy.ProtectedFolderIDs) == 0 && len(policy.ProtectedPaths) == 0 {
+		return nil
+	}
+
+	protected := map[string]bool{}
+	for _, id := range policy.ProtectedFolderIDs {
+		protected[id] = true
+	}
+	resolver := &Service{Backend: ds.Backend, API: ds.API, NoFollowShortcuts: ds.NoFollowShortcuts}
+	for _, p := range policy.ProtectedPaths {
+		id, err := resolver.ResolvePath(ctx, p, true)
+		if err != nil {
+			return policyDenied("protected folder %s cannot be found: %v", p, err)
+		}
+		protected[id] = true
+	}
+
+	seen := map[string]bool{}
+	for id := fileID; id != "" && !seen[id]; {
+		seen[id] = true
+		f, err := ds.Backend.GetFile(ctx, id, "id, name, parents")
+		if err != nil {
+			return policyDenied("unable to check for protected folders above %s: %v", fileID, err)
+		}
+		if protected[f.Id] {
+			return policyDenied("items in the protected folder %s (%s) cannot be shared", f.Name, f.Id)
+		}
+		id = ""
+		if len(f.Parents) > 0 {
+			id = f.Parents[0]
+		}
+	}
+	return nil
+}
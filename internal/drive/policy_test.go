@@ -0,0 +1,192 @@
+package drive_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	driveapi "google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+
+	"gdrive/internal/drive"
+	"gdrive/internal/drivetest"
+)
+
+func TestLoadSharingPolicy(t *testing.T) {
+	dir := t.TempDir()
+	if policy, err := drive.LoadSharingPolicy(filepath.Join(dir, "missing.yaml")); policy != nil || err != nil {
+		t.Fatalf("missing policy = %+v, %v", policy, err)
+	}
+
+	path := filepath.Join(dir, "policy.yaml")
+	write := func(text string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(text), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("allowedDomains: [example.com]\nforbiddenAnyoneRoles: [writer]\nprotectedPaths: [Confidential]\nmaxExpirationDays: 30\n")
+	policy, err := drive.LoadSharingPolicy(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(policy.AllowedDomains) != 1 || len(policy.ForbiddenAnyoneRoles) != 1 || len(policy.ProtectedPaths) != 1 || policy.MaxExpirationDays != 30 {
+		t.Errorf("policy = %+v", policy)
+	}
+
+	for _, text := range []string{"allowedDomain: [example.com]\n", "maxExpirationDays: -1\n"} {
+		write(text)
+		if _, err := drive.LoadSharingPolicy(path); err == nil {
+			t.Errorf("policy %q should be refused", text)
+		}
+	}
+}
+
+func TestSharingPolicy(t *testing.T) {
+	srv, ds := newFakeService(t)
+	ctx := t.Context()
+	confidential := srv.AddFolder(drivetest.RootID, "Confidential")
+	secret := srv.AddFile(srv.AddFolder(confidential, "HR"), "salaries.xlsx", []byte("salaries"))
+	public := srv.AddFile(drivetest.RootID, "flyer.pdf", []byte("flyer"))
+	ds.Policy = &drive.SharingPolicy{
+		AllowedDomains:       []string{"example.com"},
+		ForbiddenAnyoneRoles: []string{"writer", "commenter"},
+		ProtectedPaths:       []string{"Confidential"},
+		MaxExpirationDays:    30,
+	}
+	soon := time.Now().AddDate(0, 0, 7)
+
+	denied := map[string]drive.ShareOptions{
+		"personal account":   {Email: "someone@gmail.com", Role: "writer", Expires: soon},
+		"outside domain":     {Type: "domain", Domain: "partner.com", Role: "reader"},
+		"anyone writer":      {Type: "anyone", Role: "writer"},
+		"no expiration":      {Email: "alice@example.com", Role: "reader"},
+		"too late to expire": {Email: "alice@example.com", Role: "reader", Expires: time.Now().AddDate(0, 0, 60)},
+	}
+	for name, opts := range denied {
+		if _, err := ds.ShareFile(ctx, public, opts); !errors.Is(err, drive.ErrPolicyDenied) {
+			t.Errorf("%s: err = %v, want a policy denial", name, err)
+		}
+	}
+	if _, err := ds.ShareWithAnyone(ctx, secret, "reader"); !errors.Is(err, drive.ErrPolicyDenied) {
+		t.Errorf("sharing a protected item: err = %v", err)
+	}
+	if _, err := ds.TransferOwnership(ctx, public, "someone@gmail.com"); !errors.Is(err, drive.ErrPolicyDenied) {
+		t.Errorf("transfer outside the domain: err = %v", err)
+	}
+	if n := len(srv.Permissions(public)) + len(srv.Permissions(secret)); n != 2 {
+		t.Errorf("denied sharing created permissions: %d left", n)
+	}
+
+	if _, err := ds.ShareWithAnyone(ctx, public, "reader"); err != nil {
+		t.Errorf("anyone reader: %v", err)
+	}
+	perm, err := ds.ShareFile(ctx, public, drive.ShareOptions{Email: "Alice@Example.com", Role: "writer", Expires: soon})
+	if err != nil {
+		t.Fatalf("expiring share within the domain: %v", err)
+	}
+	if _, err := ds.UpdatePermission(ctx, public, perm.Id, drive.PermissionUpdate{RemoveExpiration: true}); !errors.Is(err, drive.ErrPolicyDenied) {
+		t.Errorf("removing the expiration: err = %v", err)
+	}
+	if _, err := ds.UpdatePermission(ctx, public, perm.Id, drive.PermissionUpdate{Role: "reader"}); err != nil {
+		t.Errorf("reducing the role: %v", err)
+	}
+
+	gmail := srv.Share(public, driveapi.Permission{Type: "user", Role: "reader", EmailAddress: "someone@gmail.com"})
+	if _, err := ds.UpdatePermission(ctx, public, gmail, drive.PermissionUpdate{Role: "writer"}); !errors.Is(err, drive.ErrPolicyDenied) {
+		t.Errorf("raising an out-of-domain reader: err = %v", err)
+	}
+	inside := srv.Share(secret, driveapi.Permission{Type: "user", Role: "reader", EmailAddress: "bob@example.com"})
+	if _, err := ds.UpdatePermission(ctx, secret, inside, drive.PermissionUpdate{Role: "writer"}); !errors.Is(err, drive.ErrPolicyDenied) {
+		t.Errorf("raising a role in a protected folder: err = %v", err)
+	}
+	if _, err := ds.UpdatePermission(ctx, secret, inside, drive.PermissionUpdate{Role: "reader"}); err != nil {
+		t.Errorf("keeping a role in a protected folder: %v", err)
+	}
+	permanent := srv.Share(public, driveapi.Permission{Type: "user", Role: "reader", EmailAddress: "carol@example.com"})
+	for _, role := range []string{"writer", "organizer"} {
+		if _, err := ds.UpdatePermission(ctx, public, permanent, drive.PermissionUpdate{Role: role}); !errors.Is(err, drive.ErrPolicyDenied) {
+			t.Errorf("raising a permanent reader to %s: err = %v", role, err)
+		}
+	}
+	if _, err := ds.UpdatePermission(ctx, public, permanent, drive.PermissionUpdate{Role: "writer", Expires: soon}); err != nil {
+		t.Errorf("raising a permanent reader with an expiration: %v", err)
+	}
+
+	ds.Policy.ProtectedPaths = []string{"Missing"}
+	if _, err := ds.ShareWithAnyone(ctx, public, "reader"); !errors.Is(err, drive.ErrPolicyDenied) {
+		t.Errorf("unresolved protected path: err = %v", err)
+	}
+
+	ds.Policy = nil
+	if _, err := ds.ShareWithAnyone(ctx, secret, "writer"); err != nil {
+		t.Errorf("sharing without a policy: %v", err)
+	}
+}
+
+// failingBackend fails every GetFile of one item.
+type failingBackend struct {
+	drive.Backend
+	id  string
+	err error
+}
+
+func (b failingBackend) GetFile(ctx context.Context, fileID, fields string) (*driveapi.File, error) {
+	if fileID == b.id {
+		return nil, b.err
+	}
+	return b.Backend.GetFile(ctx, fileID, fields)
+}
+
+func TestSharingPolicyUnreadableParent(t *testing.T) {
+	srv, ds := newFakeService(t)
+	confidential := srv.AddFolder(drivetest.RootID, "Confidential")
+	hr := srv.AddFolder(confidential, "HR")
+	secret := srv.AddFile(hr, "salaries.xlsx", []byte("salaries"))
+	ds.Policy = &drive.SharingPolicy{ProtectedFolderIDs: []string{confidential}}
+
+	backend := ds.Backend
+	for _, code := range []int{http.StatusNotFound, http.StatusInternalServerError} {
+		ds.Backend = failingBackend{Backend: backend, id: hr, err: &googleapi.Error{Code: code}}
+		if _, err := ds.ShareWithAnyone(t.Context(), secret, "reader"); !errors.Is(err, drive.ErrPolicyDenied) {
+			t.Errorf("%d on a parent: err = %v, want a policy denial", code, err)
+		}
+	}
+	if perms := srv.Permissions(secret); len(perms) != 1 {
+		t.Errorf("permissions = %+v", perms)
+	}
+}
+
+func TestSharingPolicyProtectedPathResolution(t *testing.T) {
+	srv, ds := newFakeService(t)
+	ctx := t.Context()
+	confidential := srv.AddFolder(drivetest.RootID, "Confidential")
+	secret := srv.AddFile(confidential, "salaries.xlsx", []byte("salaries"))
+	public := srv.AddFolder(drivetest.RootID, "Public")
+	flyer := srv.AddFile(public, "flyer.pdf", []byte("flyer"))
+	ds.Policy = &drive.SharingPolicy{ProtectedPaths: []string{"Confidential"}}
+
+	// A stale entry does not move the protection
+	ds.Cache = drive.NewPathCache(time.Hour)
+	ds.Cache.Put(drivetest.RootID, "Confidential", public)
+	if _, err := ds.ShareWithAnyone(ctx, secret, "reader"); !errors.Is(err, drive.ErrPolicyDenied) {
+		t.Errorf("stale path cache: err = %v, want a policy denial", err)
+	}
+	if _, err := ds.ShareWithAnyone(ctx, flyer, "reader"); err != nil {
+		t.Errorf("stale path cache: sharing an unprotected item: %v", err)
+	}
+
+	// Nor does the Picker choose which folder of a name is protected
+	ds.Pick = drive.PickNewest
+	srv.AddFolder(drivetest.RootID, "Confidential")
+	if _, err := ds.ShareWithAnyone(ctx, secret, "reader"); !errors.Is(err, drive.ErrPolicyDenied) {
+		t.Errorf("ambiguous protected path: err = %v, want a policy denial", err)
+	}
+	if perms := srv.Permissions(secret); len(perms) != 1 {
+		t.Errorf("protected item permissions = %+v", perms)
+	}
+}
@@ -0,0 +1,248 @@
+package drive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+)
+
+// revisionContentFields are the fields needed to fetch the content of a
+// revision. Drive stores the bytes of binary revisions only; revisions of
+// Google Workspace files have export links instead.
+const revisionContentFields = "id, mimeType, modifiedTime, size, md5Checksum, exportLinks"
+
+// restoreFormats are the formats Workspace revisions go through when they
+// are restored. Drive converts them back to the Workspace type on upload,
+// so each is the one it imports with the least loss.
+var restoreFormats = map[string]string{
+	"application/vnd.google-apps.document":     "docx",
+	"application/vnd.google-apps.spreadsheet":  "xlsx",
+	"application/vnd.google-apps.presentation": "pptx",
+}
+
+// DownloadRevision downloads one revision of a file to localPath and
+// returns the path written. Workspace revisions are exported to format, or
+// to the default format of their type, and the extension of localPath is
+// adjusted to match. The file gets the modification time of the revision.
+func (ds *Service) DownloadRevision(ctx context.Context, fileID, revisionID, localPath, format string, showProgress bool) (string, error) {
+	rev, err := ds.Backend.GetRevision(ctx, fileID, revisionID, revisionContentFields)
+	if err != nil {
+		return "", fmt.Errorf("unable to get revision: %v", err)
+	}
+
+	contentType := rev.MimeType
+	size := rev.Size
+	var check func(Checksums) error
+	if len(rev.ExportLinks) > 0 {
+		format, contentType, err = ds.ResolveExportFormat(ctx, rev.MimeType, format)
+		if err != nil {
+			return "", err
+		}
+		localPath = ds.AdjustFilename(localPath, format)
+		size = -1
+	} else {
+		check = func(sums Checksums) error {
+			return CompareChecksums(filepath.Base(localPath), sums, Checksums{MD5: rev.Md5Checksum})
+		}
+	}
+
+	body, err := ds.openRevision(ctx, fileID, rev, contentType)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return "", err
+	}
+	if err := saveStream(body, filepath.Base(localPath), localPath, size, showProgress, check); err != nil {
+		return "", err
+	}
+
+	if modTime, err := time.Parse(time.RFC3339, rev.ModifiedTime); err == nil {
+		_ = os.Chtimes(localPath, modTime, modTime)
+	}
+	return localPath, nil
+}
+
+// RevisionContent returns the content of one revision and its MIME type.
+// Like DownloadFileContent, Workspace revisions are exported to
+// exportMimeType, which defaults to the text format of their type.
+func (ds *Service) RevisionContent(ctx context.Context, fileID, revisionID, exportMimeType string) ([]byte, string, error) {
+	rev, err := ds.Backend.GetRevision(ctx, fileID, revisionID, revisionContentFields)
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to get revision: %v", err)
+	}
+
+	contentType := rev.MimeType
+	if len(rev.ExportLinks) > 0 {
+		contentType = exportMimeType
+		if contentType == "" {
+			contentType = TextExportFormats[rev.MimeType]
+		}
+	}
+
+	body, err := ds.openRevision(ctx, fileID, rev, contentType)
+	if err != nil {
+		return nil, contentType, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, contentType, fmt.Errorf("read failed: %w", err)
+	}
+	return data, contentType, nil
+}
+
+// RestoreRevision makes an old revision the current content of a file by
+// uploading it again as a new revision, so the revisions in between are
+// kept. Workspace revisions are exported to an Office format that Drive
+// converts back on upload, which may lose formatting the Office format
+// cannot represent. Binary revisions are downloaded and checked against
+// their MD5 before they replace the content of the file.
+func (ds *Service) RestoreRevision(ctx context.Context, fileID, revisionID string) (*drive.File, error) {
+	rev, err := ds.Backend.GetRevision(ctx, fileID, revisionID, revisionContentFields)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get revision: %v", err)
+	}
+
+	contentType := rev.MimeType
+	if len(rev.ExportLinks) > 0 {
+		format, ok := restoreFormats[rev.MimeType]
+		if !ok {
+			return nil, fmt.Errorf("revisions of %s files cannot be restored", rev.MimeType)
+		}
+		if _, contentType, err = ds.ResolveExportFormat(ctx, rev.MimeType, format); err != nil {
+			return nil, err
+		}
+	}
+
+	var check func(Checksums) error
+	if len(rev.ExportLinks) == 0 {
+		check = func(sums Checksums) error {
+			if err := CompareChecksums("revision "+revisionID, sums, Checksums{MD5: rev.Md5Checksum}); err != nil {
+				return fmt.Errorf("revision %s was corrupted in transit, restore it again: %w", revisionID, err)
+			}
+			return nil
+		}
+	}
+
+	tmpDir, err := os.MkdirTemp("", "gdrive-restore-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+	local, err := ds.saveRevision(ctx, fileID, rev, contentType, filepath.Join(tmpDir, "revision"), check)
+	if err != nil {
+		return nil, err
+	}
+	defer local.Close()
+
+	h := newHasher()
+	media := &Media{Body: io.TeeReader(local, h), ContentType: contentType}
+	file, err := ds.Backend.UpdateFile(ctx, fileID, &drive.File{}, UpdateOptions{Media: media}, "id, name, mimeType, headRevisionId, "+checksumFields)
+	if err != nil {
+		return nil, fmt.Errorf("unable to restore revision: %w", err)
+	}
+
+	if len(rev.ExportLinks) == 0 {
+		if err := verifyUpload(file.Name, h, file); err != nil {
+			return nil, err
+		}
+	}
+	return file, nil
+}
+
+// saveRevision downloads rev to localPath, rejecting it if check fails, and
+// opens the result.
+func (ds *Service) saveRevision(ctx context.Context, fileID string, rev *drive.Revision, contentType, localPath string, check func(Checksums) error) (*os.File, error) {
+	body, err := ds.openRevision(ctx, fileID, rev, contentType)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	if err := saveStream(body, filepath.Base(localPath), localPath, -1, false, check); err != nil {
+		return nil, err
+	}
+	return os.Open(localPath)
+}
+
+// SetRevisionKeepForever pins or unpins a revision. Drive purges unpinned
+// revisions of binary files after 30 days or once a file has more than 100
+// of them; pinned revisions are kept until deleted. Revisions of Workspace
+// files cannot be pinned.
+func (ds *Service) SetRevisionKeepForever(ctx context.Context, fileID, revisionID string, keep bool) (*RevisionInfo, error) {
+	rev, err := ds.Backend.UpdateRevision(ctx, fileID, revisionID,
+		&drive.Revision{KeepForever: keep, ForceSendFields: []string{"KeepForever"}}, revisionInfoFields)
+	if err != nil {
+		return nil, fmt.Errorf("unable to update revision: %w", err)
+	}
+	return newRevisionInfo(rev), nil
+}
+
+// DeleteRevision permanently deletes a revision of a binary file. Drive
+// refuses to delete the only revision of a file and revisions of Workspace
+// files. Deleting the current revision reverts the file to the previous one.
+func (ds *Service) DeleteRevision(ctx context.Context, fileID, revisionID string) error {
+	if err := ds.Backend.DeleteRevision(ctx, fileID, revisionID); err != nil {
+		return fmt.Errorf("unable to delete revision: %w", err)
+	}
+	return nil
+}
+
+// openRevision returns the content of rev: its stored bytes for a binary
+// file, or its export to exportMimeType for a Workspace file.
+func (ds *Service) openRevision(ctx context.Context, fileID string, rev *drive.Revision, exportMimeType string) (io.ReadCloser, error) {
+	if len(rev.ExportLinks) == 0 {
+		resp, err := ds.Backend.DownloadRevision(ctx, fileID, rev.Id)
+		if err != nil {
+			return nil, fmt.Errorf("unable to download revision: %w", err)
+		}
+		return resp.Body, nil
+	}
+
+	link, ok := rev.ExportLinks[exportMimeType]
+	if !ok {
+		return nil, fmt.Errorf("revision %s cannot be exported to %q (available: %s)",
+			rev.Id, exportMimeType, strings.Join(slices.Sorted(maps.Keys(rev.ExportLinks)), ", "))
+	}
+	resp, err := ds.fetchLink(ctx, link)
+	if err != nil {
+		return nil, fmt.Errorf("unable to export revision: %w", err)
+	}
+	return resp.Body, nil
+}
+
+// fetchLink downloads one of the export links Drive hands out. They are
+// plain URLs rather than API calls, so they go through ds.HTTP directly.
+func (ds *Service) fetchLink(ctx context.Context, link string) (*http.Response, error) {
+	if ds.HTTP == nil {
+		return nil, errors.New("export links need an authenticated HTTP client")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := ds.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := googleapi.CheckResponse(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
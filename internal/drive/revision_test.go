@@ -0,0 +1,136 @@
+package drive_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	driveapi "google.golang.org/api/drive/v3"
+
+	"gdrive/internal/drive"
+	"gdrive/internal/drivetest"
+)
+
+func TestRevisionsOfBinaryFile(t *testing.T) {
+	srv, ds := newFakeService(t)
+	ctx := t.Context()
+	id := srv.AddFile(drivetest.RootID, "notes.txt", []byte("v1"))
+	srv.Update(id, []byte("v2"))
+	first := srv.Revisions(id)[0]
+
+	out, err := ds.DownloadRevision(ctx, id, first.Id, filepath.Join(t.TempDir(), "notes.txt"), "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(out); string(data) != "v1" {
+		t.Errorf("downloaded %q, want v1", data)
+	}
+	if info, err := os.Stat(out); err != nil || info.ModTime().UTC().Format("2006-01-02T15:04:05Z") != first.ModifiedTime {
+		t.Errorf("mtime of %s does not match revision time %s", out, first.ModifiedTime)
+	}
+
+	if _, err := ds.RestoreRevision(ctx, id, first.Id); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(srv.Content(id)); got != "v1" {
+		t.Errorf("content after restore = %q", got)
+	}
+	if n := len(srv.Revisions(id)); n != 3 {
+		t.Errorf("got %d revisions after restore, want 3", n)
+	}
+
+	info, err := ds.SetRevisionKeepForever(ctx, id, first.Id, true)
+	if err != nil || !info.KeepForever || !srv.Revisions(id)[0].KeepForever {
+		t.Fatalf("pin = %+v, %v", info, err)
+	}
+	if _, err := ds.SetRevisionKeepForever(ctx, id, first.Id, false); err != nil || srv.Revisions(id)[0].KeepForever {
+		t.Fatalf("unpin failed: %v", err)
+	}
+
+	if err := ds.DeleteRevision(ctx, id, first.Id); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(srv.Revisions(id)); n != 2 {
+		t.Errorf("got %d revisions after delete, want 2", n)
+	}
+}
+
+// corruptingBackend reports a wrong MD5 for every revision, as if its
+// content had been corrupted in transit.
+type corruptingBackend struct {
+	drive.Backend
+}
+
+func (b corruptingBackend) GetRevision(ctx context.Context, fileID, revisionID, fields string) (*driveapi.Revision, error) {
+	rev, err := b.Backend.GetRevision(ctx, fileID, revisionID, fields)
+	if err == nil {
+		rev.Md5Checksum = "0123456789abcdef0123456789abcdef"
+	}
+	return rev, err
+}
+
+func TestRestoreCorruptedRevision(t *testing.T) {
+	srv, ds := newFakeService(t)
+	id := srv.AddFile(drivetest.RootID, "notes.txt", []byte("v1"))
+	srv.Update(id, []byte("v2"))
+	first := srv.Revisions(id)[0]
+
+	ds.Backend = corruptingBackend{ds.Backend}
+	if _, err := ds.RestoreRevision(t.Context(), id, first.Id); err == nil {
+		t.Fatal("restoring a corrupted revision should fail")
+	}
+	if got := string(srv.Content(id)); got != "v2" {
+		t.Errorf("content after a failed restore = %q, want v2", got)
+	}
+	if n := len(srv.Revisions(id)); n != 2 {
+		t.Errorf("got %d revisions after a failed restore, want 2", n)
+	}
+}
+
+func TestRevisionsOfWorkspaceFile(t *testing.T) {
+	srv, ds := newFakeService(t)
+	ctx := t.Context()
+	id := srv.AddDoc(drivetest.RootID, "Plan", "application/vnd.google-apps.document", []byte("draft"))
+	srv.Update(id, []byte("final"))
+	first := srv.Revisions(id)[0].Id
+
+	dest := filepath.Join(t.TempDir(), "Plan")
+	if _, err := ds.DownloadRevision(ctx, id, first, dest, "md", false); err == nil {
+		t.Fatal("exporting without an HTTP client should fail")
+	}
+
+	ds.HTTP = srv.Client()
+	out, err := ds.DownloadRevision(ctx, id, first, dest, "md", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Ext(out) != ".md" {
+		t.Errorf("exported to %s, want a .md file", out)
+	}
+	if data, _ := os.ReadFile(out); string(data) != "draft" {
+		t.Errorf("exported %q, want draft", data)
+	}
+	if _, err := ds.DownloadRevision(ctx, id, first, dest, "xlsx", false); err == nil {
+		t.Error("exporting a document as xlsx should fail")
+	}
+
+	data, mimeType, err := ds.RevisionContent(ctx, id, first, "")
+	if err != nil || string(data) != "draft" || mimeType != "text/markdown" {
+		t.Errorf("RevisionContent = %q, %q, %v", data, mimeType, err)
+	}
+
+	if _, err := ds.RestoreRevision(ctx, id, first); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(srv.Content(id)); got != "draft" {
+		t.Errorf("content after restore = %q", got)
+	}
+	if f := srv.File(id); f.MimeType != "application/vnd.google-apps.document" {
+		t.Errorf("restore changed the type to %s", f.MimeType)
+	}
+
+	if err := ds.DeleteRevision(ctx, id, first); err == nil {
+		t.Error("deleting a revision of a Google Doc should fail")
+	}
+}
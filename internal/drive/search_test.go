@@ -0,0 +1,103 @@
+package drive_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	driveapi "google.golang.org/api/drive/v3"
+
+	"gdrive/internal/drive"
+	"gdrive/internal/drivetest"
+)
+
+func TestSearchFilters(t *testing.T) {
+	srv, ds := newFakeService(t)
+	ctx := t.Context()
+	reports := srv.AddFolder(drivetest.RootID, "Reports")
+	jan := srv.AddFile(reports, "invoice-jan.pdf", []byte("purchase order 42"))
+	feb := srv.AddFile(reports, "invoice-feb.pdf", []byte(strings.Repeat("x", 4096)))
+	plan := srv.AddDoc(reports, "Plan", docMime, []byte("plan"))
+	alice := driveapi.User{DisplayName: "Alice", EmailAddress: "alice@example.com"}
+	shared := srv.AddFile(drivetest.RootID, "from-alice.txt", []byte("hi"))
+	srv.ShareWithMe(shared, alice)
+	srv.Share(feb, driveapi.Permission{Type: "anyone", Role: "reader"})
+	starred := true
+	if _, err := ds.UpdateMetadata(ctx, plan, drive.MetadataUpdate{Starred: &starred}); err != nil {
+		t.Fatal(err)
+	}
+	srv.Advance(72 * time.Hour)
+	srv.Update(jan, []byte("purchase order 43"))
+
+	ids := func(opts drive.SearchOptions) []string {
+		t.Helper()
+		files, err := ds.SearchFiles(ctx, "", opts)
+		if err != nil {
+			t.Fatalf("SearchFiles(%+v): %v", opts, err)
+		}
+		var got []string
+		for _, f := range files {
+			got = append(got, f.Id)
+		}
+		return got
+	}
+	cases := []struct {
+		name string
+		opts drive.SearchOptions
+		want []string
+	}{
+		{"full text", drive.SearchOptions{FullText: "purchase order"}, []string{jan}},
+		{"modified after", drive.SearchOptions{ModifiedAfter: srv.Now().Add(-time.Hour)}, []string{jan}},
+		{"created before", drive.SearchOptions{CreatedBefore: srv.Now().Add(-time.Hour), FileTypes: []string{"pdf"}}, []string{jan, feb}},
+		{"owner", drive.SearchOptions{Owner: "alice@example.com"}, []string{shared}},
+		{"shared with me", drive.SearchOptions{SharedWithMe: true}, []string{shared}},
+		{"shared by", drive.SearchOptions{SharedBy: "Alice@example.com"}, []string{shared}},
+		{"shared by someone else", drive.SearchOptions{SharedBy: "bob@example.com"}, nil},
+		{"starred", drive.SearchOptions{Starred: true}, []string{plan}},
+		{"visibility", drive.SearchOptions{Visibility: "anyoneWithLink"}, []string{feb}},
+		{"min size", drive.SearchOptions{MinSize: 1024}, []string{feb}},
+		{"max size", drive.SearchOptions{MaxSize: 100, ParentID: reports}, []string{jan}},
+		{"sort by size", drive.SearchOptions{Sort: "size", ParentID: reports, FileTypes: []string{"pdf"}}, []string{feb, jan}},
+		{"sort by name reversed", drive.SearchOptions{Sort: "name", Reverse: true, ParentID: reports}, []string{plan, jan, feb}},
+		{"max results after filtering", drive.SearchOptions{MinSize: 1, MaxResults: 1, Sort: "size"}, []string{feb}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ids(tc.opts)
+			if tc.opts.Sort == "" {
+				slices.Sort(got)
+				slices.Sort(tc.want)
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	for _, bad := range []drive.SearchOptions{
+		{Sort: "owner"},
+		{Sort: "name", FullText: "order"},
+		{Visibility: "public"},
+	} {
+		if _, err := ds.SearchFiles(ctx, "", bad); err == nil {
+			t.Errorf("SearchFiles(%+v) should fail", bad)
+		}
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	got, err := drive.ParseDate("2026-01-31")
+	if err != nil || !got.Equal(time.Date(2026, 1, 31, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("ParseDate(date) = %v, %v", got, err)
+	}
+	got, err = drive.ParseDate("2026-01-31T09:00:00Z")
+	if err != nil || !got.Equal(time.Date(2026, 1, 31, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("ParseDate(RFC 3339) = %v, %v", got, err)
+	}
+	for _, bad := range []string{"", "31/01/2026", "yesterday"} {
+		if _, err := drive.ParseDate(bad); err == nil {
+			t.Errorf("ParseDate(%q) should fail", bad)
+		}
+	}
+}
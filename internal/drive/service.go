@@ -2,17 +2,24 @@
 package drive
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"iter"
+	"maps"
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/schollz/progressbar/v3"
 	"google.golang.org/api/drive/v3"
-	"google.golang.org/api/googleapi"
+
+	"gdrive/internal/query"
 )
 
 const (
@@ -78,7 +85,8 @@ var TextExportFormats = map[string]string{
 	"application/vnd.google-apps.presentation": "text/plain",
 }
 
-// ExportFormats maps Google Workspace MIME types to export formats.
+// ExportFormats maps Google Workspace MIME types to export formats. Drive
+// supports more, see ExportCatalog; these are used when it cannot be asked.
 var ExportFormats = map[string]map[string]string{
 	"application/vnd.google-apps.document": {
 		"pdf":  "application/pdf",
@@ -107,12 +115,38 @@ var ExportFormats = map[string]map[string]string{
 
 // Service wraps the Google Drive service.
 type Service struct {
+	// Backend performs all Drive storage operations.
+	Backend Backend
+	// API is the Drive client behind Backend, used directly only by the
+	// resumable upload protocol.
 	API *drive.Service
+	// Cache, when set, memoizes folder lookups made while resolving paths.
+	Cache *PathCache
+	// Pick chooses between items sharing a name in one folder. When nil,
+	// such lookups fail with *AmbiguousPathError.
+	Pick Picker
+	// HTTP is the authenticated client behind API. When set, UploadFile uses
+	// resumable uploads in chunks of ChunkSize (DefaultUploadChunkSize if
+	// zero), recording sessions in Uploads so they survive a restart.
+	HTTP      *http.Client
+	ChunkSize int64
+	Uploads   *UploadStateStore
+	// FormatsFile, when set, caches the export formats read from Drive
+	// between runs, see ExportCatalog.
+	FormatsFile string
+	// NoFollowShortcuts stops paths from going through shortcuts to folders
+	// and downloads from fetching the target of shortcuts.
+	NoFollowShortcuts bool
+	// Policy, when set, is enforced by the sharing methods.
+	Policy *SharingPolicy
+
+	catalogMu sync.Mutex
+	catalog   *ExportCatalog
 }
 
 // NewService creates a new DriveService.
 func NewService(service *drive.Service) *Service {
-	return &Service{API: service}
+	return &Service{Backend: NewAPIBackend(service), API: service}
 }
 
 // ParseRemotePath parses a remote path into folder components.
@@ -125,65 +159,110 @@ func (ds *Service) ParseRemotePath(remotePath string) []string {
 }
 
 // FindItemByName finds an item by name in a parent folder.
-func (ds *Service) FindItemByName(name, parentID, mimeType string) (*drive.File, error) {
-	query := fmt.Sprintf("name = '%s' and '%s' in parents and trashed = false", name, parentID)
+// If several items match, ds.Pick chooses one; without a Picker the lookup
+// fails with *AmbiguousPathError listing the candidates.
+func (ds *Service) FindItemByName(ctx context.Context, name, parentID, mimeType string) (*drive.File, error) {
+	q := query.And(query.Name(name), query.InParents(parentID), query.Trashed(false))
 	if mimeType != "" {
-		query += fmt.Sprintf(" and mimeType = '%s'", mimeType)
+		q = query.And(q, query.MimeType(mimeType))
 	}
 
-	fileList, err := ds.API.Files.List().Q(query).
-		Fields("files(id, name, mimeType, modifiedTime, size)").Do()
+	fileList, err := ds.Backend.ListFiles(ctx, ListOptions{
+		Query:  q.String(),
+		Fields: "files(id, name, mimeType, modifiedTime, size, owners(displayName, emailAddress), " + shortcutFields + ")",
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if len(fileList.Files) == 0 {
+	switch len(fileList.Files) {
+	case 0:
 		return nil, nil
+	case 1:
+		return fileList.Files[0], nil
+	}
+
+	amb := &AmbiguousPathError{Name: name, ParentID: parentID, Candidates: fileList.Files}
+	if ds.Pick == nil {
+		return nil, amb
 	}
-	return fileList.Files[0], nil
+	return ds.Pick(ctx, amb)
 }
 
 // ResolvePath resolves a human-readable path to a folder ID.
-func (ds *Service) ResolvePath(remotePath string, mustExist bool) (string, error) {
+// A leading "@Name" segment resolves relative to the root of the Shared
+// Drive called Name instead of My Drive.
+func (ds *Service) ResolvePath(ctx context.Context, remotePath string, mustExist bool) (string, error) {
 	parts := ds.ParseRemotePath(remotePath)
 	if len(parts) == 0 {
 		return DriveRootID, nil
 	}
 
-	currentID := DriveRootID
+	currentID, parts, err := ds.resolveRoot(ctx, parts)
+	if err != nil {
+		return "", err
+	}
 	for _, part := range parts {
-		item, err := ds.FindItemByName(part, currentID, DriveFolderMimeType)
+		folderID, err := ds.findFolder(ctx, part, currentID)
 		if err != nil {
 			return "", err
 		}
-		if item == nil {
+		if folderID == "" {
 			if mustExist {
 				return "", fmt.Errorf("path not found: %s", remotePath)
 			}
 			return "", nil
 		}
-		currentID = item.Id
+		currentID = folderID
 	}
 
 	return currentID, nil
 }
 
+// findFolder returns the ID of the folder called name inside parentID, or ""
+// if there is none. Unless ds.NoFollowShortcuts is set, a shortcut to a
+// folder stands for its target. Hits are served from the path cache, which
+// only holds real folders, without asking Drive: a folder with the same name
+// created after the entry was cached goes unnoticed until it expires, even
+// without a Picker.
+func (ds *Service) findFolder(ctx context.Context, name, parentID string) (string, error) {
+	if id, ok := ds.Cache.Get(parentID, name); ok {
+		return id, nil
+	}
+	item, err := ds.FindItemByName(ctx, name, parentID, DriveFolderMimeType)
+	if err != nil {
+		return "", err
+	}
+	if item == nil {
+		if ds.NoFollowShortcuts {
+			return "", nil
+		}
+		return ds.findFolderShortcut(ctx, name, parentID)
+	}
+	ds.Cache.Put(parentID, name, item.Id)
+	return item.Id, nil
+}
+
 // CreateFolderPath creates a folder path (like mkdir -p).
-func (ds *Service) CreateFolderPath(remotePath string) (string, error) {
+// Paths starting with "@Name" are created inside that Shared Drive.
+func (ds *Service) CreateFolderPath(ctx context.Context, remotePath string) (string, error) {
 	parts := ds.ParseRemotePath(remotePath)
 	if len(parts) == 0 {
 		return "root", nil
 	}
 
-	currentID := "root"
+	currentID, parts, err := ds.resolveRoot(ctx, parts)
+	if err != nil {
+		return "", err
+	}
 	for _, part := range parts {
-		item, err := ds.FindItemByName(part, currentID, "application/vnd.google-apps.folder")
+		folderID, err := ds.findFolder(ctx, part, currentID)
 		if err != nil {
 			return "", err
 		}
 
-		if item != nil {
-			currentID = item.Id
+		if folderID != "" {
+			currentID = folderID
 		} else {
 			// Create folder
 			fileMetadata := &drive.File{
@@ -191,10 +270,11 @@ func (ds *Service) CreateFolderPath(remotePath string) (string, error) {
 				MimeType: "application/vnd.google-apps.folder",
 				Parents:  []string{currentID},
 			}
-			folder, err := ds.API.Files.Create(fileMetadata).Fields("id").Do()
+			folder, err := ds.Backend.CreateFile(ctx, fileMetadata, nil, "id")
 			if err != nil {
 				return "", err
 			}
+			ds.Cache.Put(currentID, part, folder.Id)
 			currentID = folder.Id
 			fmt.Printf("Created folder: %s\n", part)
 		}
@@ -204,8 +284,8 @@ func (ds *Service) CreateFolderPath(remotePath string) (string, error) {
 }
 
 // FindFile finds a file by name in a parent folder.
-func (ds *Service) FindFile(filename, parentID string) (*drive.File, error) {
-	return ds.FindItemByName(filename, parentID, "")
+func (ds *Service) FindFile(ctx context.Context, filename, parentID string) (*drive.File, error) {
+	return ds.FindItemByName(ctx, filename, parentID, "")
 }
 
 // UploadFile uploads a file to Google Drive.
@@ -223,9 +303,9 @@ func (ds *Service) FindFile(filename, parentID string) (*drive.File, error) {
 // content. Convert is rejected when the extension has no conversion target,
 // or when an existing file with the same name has a different Workspace
 // type (the caller should rename or delete first).
-func (ds *Service) UploadFile(localPath, parentID, mimeType string, convert, showProgress bool) (string, error) {
+func (ds *Service) UploadFile(ctx context.Context, localPath, parentID, mimeType string, convert, showProgress bool) (string, error) {
 	filename := filepath.Base(localPath)
-	existingFile, err := ds.FindFile(filename, parentID)
+	existingFile, err := ds.FindFile(ctx, filename, parentID)
 	if err != nil {
 		return "", err
 	}
@@ -236,17 +316,6 @@ func (ds *Service) UploadFile(localPath, parentID, mimeType string, convert, sho
 	}
 	defer file.Close()
 
-	stat, err := file.Stat()
-	if err != nil {
-		return "", err
-	}
-
-	var reader io.Reader = file
-	if showProgress {
-		bar := progressbar.DefaultBytes(stat.Size(), fmt.Sprintf("Uploading %s", filename))
-		reader = io.TeeReader(file, bar)
-	}
-
 	sourceMime := mimeType
 	if sourceMime == "" {
 		sourceMime = DetectMimeType(filename)
@@ -273,20 +342,24 @@ func (ds *Service) UploadFile(localPath, parentID, mimeType string, convert, sho
 		if showProgress {
 			fmt.Printf("Updating: %s\n", filename)
 		}
-		var updateMeta *drive.File
-		updateCall := ds.API.Files.Update(existingFile.Id, &drive.File{})
+		updateMeta := &drive.File{}
+		if !convert {
+			updateMeta.MimeType = sourceMime
+		}
+		if ds.HTTP != nil {
+			return ds.uploadResumable(ctx, file, parentID, existingFile.Id, updateMeta, sourceMime, showProgress)
+		}
+
+		h := newHasher()
+		media := &Media{Body: io.TeeReader(ds.uploadReader(file, filename, showProgress), h)}
 		if convert {
-			updateCall = ds.API.Files.Update(existingFile.Id, &drive.File{}).
-				Media(reader, googleapi.ContentType(sourceMime))
-		} else {
-			updateMeta = &drive.File{MimeType: sourceMime}
-			updateCall = ds.API.Files.Update(existingFile.Id, updateMeta).Media(reader)
+			media.ContentType = sourceMime
 		}
-		updatedFile, err := updateCall.Do()
+		updatedFile, err := ds.Backend.UpdateFile(ctx, existingFile.Id, updateMeta, UpdateOptions{Media: media}, "id, "+checksumFields)
 		if err != nil {
 			return "", err
 		}
-		return updatedFile.Id, nil
+		return updatedFile.Id, verifyUpload(filename, h, updatedFile)
 	}
 
 	// Create new file
@@ -294,75 +367,87 @@ func (ds *Service) UploadFile(localPath, parentID, mimeType string, convert, sho
 		fmt.Printf("Uploading: %s\n", filename)
 	}
 	createMeta := &drive.File{
-		Name:    filename,
-		Parents: []string{parentID},
+		Name:     filename,
+		Parents:  []string{parentID},
+		MimeType: sourceMime,
 	}
-	var createCall *drive.FilesCreateCall
 	if convert {
 		// metadata.MimeType = target Workspace type, media ContentType = source
 		createMeta.MimeType = targetMime
-		createCall = ds.API.Files.Create(createMeta).
-			Media(reader, googleapi.ContentType(sourceMime)).
-			Fields("id")
-	} else {
-		createMeta.MimeType = sourceMime
-		createCall = ds.API.Files.Create(createMeta).Media(reader).Fields("id")
 	}
-	createdFile, err := createCall.Do()
+	if ds.HTTP != nil {
+		return ds.uploadResumable(ctx, file, parentID, "", createMeta, sourceMime, showProgress)
+	}
+
+	h := newHasher()
+	media := &Media{Body: io.TeeReader(ds.uploadReader(file, filename, showProgress), h)}
+	if convert {
+		media.ContentType = sourceMime
+	}
+	createdFile, err := ds.Backend.CreateFile(ctx, createMeta, media, "id, "+checksumFields)
 	if err != nil {
 		return "", err
 	}
-	return createdFile.Id, nil
+	return createdFile.Id, verifyUpload(filename, h, createdFile)
+}
+
+// verifyUpload compares the hashes of the bytes sent with those Drive
+// computed for the stored file.
+func verifyUpload(name string, h *hasher, uploaded *drive.File) error {
+	if err := CompareChecksums(name, h.Sums(), RemoteChecksums(uploaded)); err != nil {
+		return fmt.Errorf("uploaded file %s is corrupt, upload it again: %w", uploaded.Id, err)
+	}
+	return nil
+}
+
+// uploadReader returns the body for a single-request upload of file,
+// reporting progress if requested.
+func (ds *Service) uploadReader(file *os.File, filename string, showProgress bool) io.Reader {
+	if !showProgress {
+		return file
+	}
+	var size int64
+	if stat, err := file.Stat(); err == nil {
+		size = stat.Size()
+	}
+	bar := progressbar.DefaultBytes(size, fmt.Sprintf("Uploading %s", filename))
+	return io.TeeReader(file, bar)
 }
 
 // DownloadFile downloads a file from Google Drive.
 // For Google Workspace files, it exports them to standard formats.
+// Shortcuts are downloaded as their target unless ds.NoFollowShortcuts is set.
 // If formatOverride is non-empty, it forces the export format (e.g. "md", "pdf",
 // "docx", "txt", "html" for Docs; "xlsx", "csv", "pdf" for Sheets;
 // "pptx", "pdf" for Slides). It is ignored for non-Workspace files.
-func (ds *Service) DownloadFile(fileID, localPath, formatOverride string, preserveTimestamp, showProgress bool) error {
+func (ds *Service) DownloadFile(ctx context.Context, fileID, localPath, formatOverride string, preserveTimestamp, showProgress bool) error {
 	// Get file metadata
-	fileMetadata, err := ds.API.Files.Get(fileID).Fields("name, modifiedTime, size, mimeType").Do()
+	fields := "id, name, modifiedTime, size, mimeType, " + checksumFields
+	fileMetadata, err := ds.Backend.GetFile(ctx, fileID, fields+", "+shortcutFields)
 	if err != nil {
 		return err
 	}
+	if fileMetadata, err = ds.followShortcut(ctx, fileMetadata, fields); err != nil {
+		return err
+	}
+	if ds.IsFolder(fileMetadata) {
+		return fmt.Errorf("%s is a folder, download it with folder download", fileMetadata.Name)
+	}
 
-	var resp *http.Response
-	var exportFormat string
+	var exportMimeType string
 
 	// Check if it's a Google Workspace file
 	if ds.IsGoogleWorkspaceFile(fileMetadata) {
-		// Determine export format
-		exportFormat = formatOverride
-		if exportFormat == "" {
-			exportFormat = ds.GetDefaultExportFormat(fileMetadata.MimeType)
-		}
-		if exportFormat == "" {
-			return fmt.Errorf("cannot export file type: %s", fileMetadata.MimeType)
-		}
-
-		// Get export MIME type
-		exportMimeType := ds.GetExportMimeType(fileMetadata.MimeType, exportFormat)
-		if exportMimeType == "" {
-			return fmt.Errorf("export format %q is not supported for %s", exportFormat, fileMetadata.MimeType)
+		// Determine export format and MIME type from those Drive offers
+		var exportFormat string
+		exportFormat, exportMimeType, err = ds.ResolveExportFormat(ctx, fileMetadata.MimeType, formatOverride)
+		if err != nil {
+			return err
 		}
 
 		// Adjust filename extension
 		localPath = ds.AdjustFilename(localPath, exportFormat)
-
-		// Export file
-		resp, err = ds.API.Files.Export(fileID, exportMimeType).Download()
-		if err != nil {
-			return err
-		}
-	} else {
-		// Download regular file
-		resp, err = ds.API.Files.Get(fileID).Download()
-		if err != nil {
-			return err
-		}
 	}
-	defer resp.Body.Close()
 
 	// Create local directory if needed
 	dir := filepath.Dir(localPath)
@@ -370,29 +455,19 @@ func (ds *Service) DownloadFile(fileID, localPath, formatOverride string, preser
 		return err
 	}
 
-	// Create local file
-	localFile, err := os.Create(localPath)
-	if err != nil {
-		return err
-	}
-	defer localFile.Close()
-
-	// Copy with progress
-	if showProgress {
-		// For exported files, the size from metadata is often inaccurate
-		// Use -1 for indeterminate progress bar to avoid "current number exceeds max" error
-		size := fileMetadata.Size
-		if exportFormat != "" {
-			// Exported files: use indeterminate progress (-1) since the export size differs from source size
-			size = -1
-		} else if size == 0 && resp.ContentLength > 0 {
-			// Regular files with missing size: use ContentLength from response
-			size = resp.ContentLength
-		}
-		bar := progressbar.DefaultBytes(size, fmt.Sprintf("Downloading %s", fileMetadata.Name))
-		_, err = io.Copy(io.MultiWriter(localFile, bar), resp.Body)
+	// Download through a partial file that is renamed into place when complete
+	if exportMimeType != "" {
+		err = ds.downloadExport(ctx, fileMetadata, exportMimeType, localPath, showProgress)
 	} else {
-		_, err = io.Copy(localFile, resp.Body)
+		err = ds.downloadBlob(ctx, fileMetadata, localPath, showProgress)
+		var mismatch *ChecksumMismatchError
+		if errors.As(err, &mismatch) {
+			// Corrupted in transit: fetch it once more from scratch
+			if showProgress {
+				fmt.Printf("%v, downloading again\n", err)
+			}
+			err = ds.downloadBlob(ctx, fileMetadata, localPath, showProgress)
+		}
 	}
 	if err != nil {
 		return err
@@ -409,16 +484,79 @@ func (ds *Service) DownloadFile(fileID, localPath, formatOverride string, preser
 	return nil
 }
 
-// ListFolder lists all items in a folder.
-func (ds *Service) ListFolder(folderID string) ([]*drive.File, error) {
-	query := fmt.Sprintf("'%s' in parents and trashed = false", folderID)
-	fileList, err := ds.API.Files.List().Q(query).
-		Fields("files(id, name, mimeType, modifiedTime, size)").
-		PageSize(1000).Do()
-	if err != nil {
-		return nil, err
+// maxPageSize is the largest PageSize the Drive API accepts for Files.List.
+const maxPageSize int64 = 1000
+
+// listFolderOptions returns the list options for the items of a folder,
+// folders first then by name, so that pages are stable.
+func listFolderOptions(folderID string) ListOptions {
+	q := query.And(query.InParents(folderID), query.Trashed(false))
+	return ListOptions{
+		Query:   q.String(),
+		Fields:  "nextPageToken, files(id, name, mimeType, modifiedTime, size, " + checksumFields + ", " + shortcutFields + ")",
+		OrderBy: "folder,name",
+	}
+}
+
+// FolderItems streams the items of a folder, fetching one page at a time.
+// Iteration stops at the first error, which is yielded with a nil file.
+func (ds *Service) FolderItems(ctx context.Context, folderID string) iter.Seq2[*drive.File, error] {
+	return func(yield func(*drive.File, error) bool) {
+		opts := listFolderOptions(folderID)
+		opts.PageSize = maxPageSize
+		for {
+			fileList, err := ds.Backend.ListFiles(ctx, opts)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, f := range fileList.Files {
+				if !yield(f, nil) {
+					return
+				}
+			}
+			if fileList.NextPageToken == "" {
+				return
+			}
+			opts.PageToken = fileList.NextPageToken
+		}
+	}
+}
+
+// ListFolder lists all items in a folder, following every page.
+func (ds *Service) ListFolder(ctx context.Context, folderID string) ([]*drive.File, error) {
+	var items []*drive.File
+	for item, err := range ds.FolderItems(ctx, folderID) {
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// ListFolderPage lists up to limit items of a folder, starting at pageToken.
+// It returns the token to pass to get the next items, or "" when the folder
+// has been listed completely. A limit <= 0 lists everything.
+func (ds *Service) ListFolderPage(ctx context.Context, folderID string, limit int64, pageToken string) ([]*drive.File, string, error) {
+	opts := listFolderOptions(folderID)
+	var items []*drive.File
+	for {
+		opts.PageSize = maxPageSize
+		if remaining := limit - int64(len(items)); limit > 0 && remaining < opts.PageSize {
+			opts.PageSize = remaining
+		}
+		opts.PageToken = pageToken
+		fileList, err := ds.Backend.ListFiles(ctx, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		items = append(items, fileList.Files...)
+		pageToken = fileList.NextPageToken
+		if pageToken == "" || (limit > 0 && int64(len(items)) >= limit) {
+			return items, pageToken, nil
+		}
 	}
-	return fileList.Files, nil
 }
 
 // IsFolder checks if an item is a folder.
@@ -436,6 +574,7 @@ func (ds *Service) IsGoogleWorkspaceFile(item *drive.File) bool {
 		"application/vnd.google-apps.drawing",
 		"application/vnd.google-apps.map",
 		"application/vnd.google-apps.site",
+		"application/vnd.google-apps.script",
 	}
 
 	for _, wsType := range workspaceTypes {
@@ -474,78 +613,259 @@ func (ds *Service) ExpandFileTypes(fileTypes []string) []string {
 	return mimeTypes
 }
 
-// SearchFiles searches for files and folders on Google Drive.
-// If parentID is non-empty, results are restricted to direct children of that folder.
-func (ds *Service) SearchFiles(query string, fileTypes []string, parentID string, maxResults int64) ([]*drive.File, error) {
-	searchQuery := fmt.Sprintf("name contains '%s' and trashed = false", query)
+// SearchOptions narrows down SearchFiles.
+type SearchOptions struct {
+	// FileTypes are type shortcuts or MIME types, see ExpandFileTypes.
+	FileTypes []string
+	// ParentID restricts results to direct children of a folder.
+	ParentID string
+	// DriveID restricts results to a Shared Drive; otherwise My Drive and
+	// all Shared Drives are searched.
+	DriveID string
+	// MaxResults caps the number of results; 0 means no limit.
+	MaxResults int64
+	// Properties and AppProperties keep only files that have every
+	// key=value pair given.
+	Properties    map[string]string
+	AppProperties map[string]string
+	// FullText keeps files whose content, name or description contains
+	// the text.
+	FullText string
+	// ModifiedAfter, ModifiedBefore, CreatedAfter and CreatedBefore bound
+	// the modification and creation times. Zero times are ignored.
+	ModifiedAfter  time.Time
+	ModifiedBefore time.Time
+	CreatedAfter   time.Time
+	CreatedBefore  time.Time
+	// Owner keeps files owned by this email address, or by the user for
+	// "me".
+	Owner string
+	// SharedBy keeps files that the user with this email address shared
+	// with the user. Drive cannot search by sharer, so this and the size
+	// bounds are applied to the results as they arrive.
+	SharedBy string
+	// Starred keeps starred files only; SharedWithMe keeps files shared
+	// with the user, as listed under "Shared with me".
+	Starred      bool
+	SharedWithMe bool
+	// Visibility keeps files with this visibility, one of Visibilities.
+	Visibility string
+	// MinSize and MaxSize bound the size in bytes. When either is set,
+	// folders and Google Workspace files, which have no size, are left out.
+	// Zero means no bound.
+	MinSize int64
+	MaxSize int64
+	// Sort orders the results by one of the keys of SortKeys; empty keeps
+	// the order of Drive. Reverse flips the order.
+	Sort    string
+	Reverse bool
+}
+
+// Visibilities are the visibility levels Drive can search by, from the
+// most to the least open.
+var Visibilities = []string{"anyoneCanFind", "anyoneWithLink", "domainCanFind", "domainWithLink", "limited"}
+
+// SortKeys maps the sort keys of SearchOptions to the Drive orderBy they
+// stand for. Times and sizes come newest and largest first, names from A
+// to Z.
+var SortKeys = map[string]string{
+	"modified": "modifiedTime desc",
+	"created":  "createdTime desc",
+	"name":     "name_natural",
+	"size":     "quotaBytesUsed desc",
+}
+
+// ParseDate parses a date such as 2026-01-31, taken as midnight local
+// time, or an RFC 3339 time.
+func ParseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.ParseInLocation(time.DateOnly, s, time.Local); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (use e.g. 2026-01-31 or 2026-01-31T09:00:00Z)", s)
+	}
+	return t, nil
+}
+
+// orderBy returns the Drive orderBy for opts.
+func (opts SearchOptions) orderBy() (string, error) {
+	if opts.Sort == "" {
+		return "", nil
+	}
+	order, ok := SortKeys[opts.Sort]
+	if !ok {
+		return "", fmt.Errorf("invalid sort key %q (use %s)", opts.Sort, strings.Join(slices.Sorted(maps.Keys(SortKeys)), ", "))
+	}
+	if opts.FullText != "" {
+		return "", errors.New("full-text results are ordered by relevance and cannot be sorted")
+	}
+	if opts.Reverse {
+		if field, ok := strings.CutSuffix(order, " desc"); ok {
+			return field, nil
+		}
+		return order + " desc", nil
+	}
+	return order, nil
+}
+
+// query returns the Drive query for term and opts.
+func (opts SearchOptions) query(ds *Service, term string) (query.Clause, error) {
+	q := query.Trashed(false)
+	if term != "" {
+		q = query.And(query.NameContains(term), q)
+	}
+	if opts.FullText != "" {
+		q = query.And(q, query.FullText(opts.FullText))
+	}
 
-	if parentID != "" {
-		searchQuery += fmt.Sprintf(" and '%s' in parents", parentID)
+	if opts.ParentID != "" {
+		q = query.And(q, query.InParents(opts.ParentID))
 	}
 
 	// Add MIME type filters if specified
-	if len(fileTypes) > 0 {
-		mimeTypes := ds.ExpandFileTypes(fileTypes)
-		if len(mimeTypes) > 0 {
-			var mimeConditions []string
-			for _, mime := range mimeTypes {
-				mimeConditions = append(mimeConditions, fmt.Sprintf("mimeType = '%s'", mime))
-			}
-			searchQuery += fmt.Sprintf(" and (%s)", strings.Join(mimeConditions, " or "))
+	if len(opts.FileTypes) > 0 {
+		q = query.And(q, query.MimeTypes(ds.ExpandFileTypes(opts.FileTypes)...))
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(opts.Properties)) {
+		q = query.And(q, query.Property(key, opts.Properties[key]))
+	}
+	for _, key := range slices.Sorted(maps.Keys(opts.AppProperties)) {
+		q = query.And(q, query.AppProperty(key, opts.AppProperties[key]))
+	}
+
+	for _, bound := range []struct {
+		t      time.Time
+		clause func(query.Op, time.Time) query.Clause
+		op     query.Op
+	}{
+		{opts.ModifiedAfter, query.ModifiedTime, query.Ge},
+		{opts.ModifiedBefore, query.ModifiedTime, query.Lt},
+		{opts.CreatedAfter, query.CreatedTime, query.Ge},
+		{opts.CreatedBefore, query.CreatedTime, query.Lt},
+	} {
+		if !bound.t.IsZero() {
+			q = query.And(q, bound.clause(bound.op, bound.t))
 		}
 	}
 
+	if opts.Owner != "" {
+		q = query.And(q, query.Owner(opts.Owner))
+	}
+	if opts.Starred {
+		q = query.And(q, query.Starred(true))
+	}
+	if opts.SharedWithMe {
+		q = query.And(q, query.SharedWithMe())
+	}
+	if opts.Visibility != "" {
+		if !slices.Contains(Visibilities, opts.Visibility) {
+			return query.Clause{}, fmt.Errorf("invalid visibility %q (use %s)", opts.Visibility, strings.Join(Visibilities, ", "))
+		}
+		q = query.And(q, query.Visibility(opts.Visibility))
+	}
+	return q, nil
+}
+
+// keep applies the filters of opts that Drive cannot search by.
+func (opts SearchOptions) keep(item *drive.File) bool {
+	if opts.SharedBy != "" && (item.SharingUser == nil || !strings.EqualFold(item.SharingUser.EmailAddress, opts.SharedBy)) {
+		return false
+	}
+	if opts.MinSize > 0 || opts.MaxSize > 0 {
+		if strings.HasPrefix(item.MimeType, googleAppsPrefix) {
+			return false
+		}
+		if item.Size < opts.MinSize || (opts.MaxSize > 0 && item.Size > opts.MaxSize) {
+			return false
+		}
+	}
+	return true
+}
+
+// SearchFiles searches for files and folders on Google Drive whose name
+// contains term. An empty term matches every name.
+func (ds *Service) SearchFiles(ctx context.Context, term string, opts SearchOptions) ([]*drive.File, error) {
+	q, err := opts.query(ds, term)
+	if err != nil {
+		return nil, err
+	}
+	orderBy, err := opts.orderBy()
+	if err != nil {
+		return nil, err
+	}
+	filtered := opts.SharedBy != "" || opts.MinSize > 0 || opts.MaxSize > 0
+
 	// Drive API caps PageSize at 1000. Paginate when maxResults > 1000 (or unbounded with <= 0).
-	const apiPageMax int64 = 1000
 	var (
 		results   []*drive.File
 		pageToken string
 	)
+	maxResults := opts.MaxResults
 	for {
 		remaining := maxResults - int64(len(results))
 		if maxResults > 0 && remaining <= 0 {
 			break
 		}
-		pageSize := apiPageMax
-		if maxResults > 0 && remaining < pageSize {
+		pageSize := maxPageSize
+		if maxResults > 0 && remaining < pageSize && !filtered {
 			pageSize = remaining
 		}
-		call := ds.API.Files.List().Q(searchQuery).
-			Fields("nextPageToken, files(id, name, mimeType, modifiedTime, size)").
-			PageSize(pageSize)
-		if pageToken != "" {
-			call = call.PageToken(pageToken)
-		}
-		fileList, err := call.Do()
+		fileList, err := ds.Backend.ListFiles(ctx, ListOptions{
+			Query:     q.String(),
+			Fields:    "nextPageToken, files(id, name, mimeType, modifiedTime, size, sharingUser(emailAddress))",
+			OrderBy:   orderBy,
+			PageSize:  pageSize,
+			PageToken: pageToken,
+			DriveID:   opts.DriveID,
+		})
 		if err != nil {
 			return nil, err
 		}
-		results = append(results, fileList.Files...)
+		for _, item := range fileList.Files {
+			if opts.keep(item) {
+				results = append(results, item)
+			}
+		}
 		if fileList.NextPageToken == "" {
 			break
 		}
 		pageToken = fileList.NextPageToken
 	}
 
+	if maxResults > 0 && int64(len(results)) > maxResults {
+		results = results[:maxResults]
+	}
 	return results, nil
 }
 
-// DeleteFile deletes a file or folder from Google Drive.
-func (ds *Service) DeleteFile(fileID string) error {
-	return ds.API.Files.Delete(fileID).Do()
+// DeleteFile permanently deletes a file or folder from Google Drive,
+// bypassing the trash. TrashFile is the recoverable alternative.
+func (ds *Service) DeleteFile(ctx context.Context, fileID string) error {
+	if err := ds.Backend.DeleteFile(ctx, fileID); err != nil {
+		return err
+	}
+	ds.Cache.Invalidate(fileID)
+	return nil
 }
 
 // RenameFile renames a file or folder.
-func (ds *Service) RenameFile(fileID, newName string) (*drive.File, error) {
+func (ds *Service) RenameFile(ctx context.Context, fileID, newName string) (*drive.File, error) {
 	fileMetadata := &drive.File{Name: newName}
-	return ds.API.Files.Update(fileID, fileMetadata).
-		Fields("id, name, webViewLink").Do()
+	file, err := ds.Backend.UpdateFile(ctx, fileID, fileMetadata, UpdateOptions{}, "id, name, webViewLink")
+	if err != nil {
+		return nil, err
+	}
+	ds.Cache.Invalidate(fileID)
+	return file, nil
 }
 
 // MoveFile moves a file to a different folder.
-func (ds *Service) MoveFile(fileID, targetFolderID string) (*drive.File, error) {
+func (ds *Service) MoveFile(ctx context.Context, fileID, targetFolderID string) (*drive.File, error) {
 	// Get current parents
-	file, err := ds.API.Files.Get(fileID).Fields("parents").Do()
+	file, err := ds.Backend.GetFile(ctx, fileID, "parents")
 	if err != nil {
 		return nil, err
 	}
@@ -554,10 +874,15 @@ func (ds *Service) MoveFile(fileID, targetFolderID string) (*drive.File, error)
 	previousParents := strings.Join(file.Parents, ",")
 
 	// Move file
-	return ds.API.Files.Update(fileID, &drive.File{}).
-		AddParents(targetFolderID).
-		RemoveParents(previousParents).
-		Fields("id, name, parents").Do()
+	moved, err := ds.Backend.UpdateFile(ctx, fileID, &drive.File{}, UpdateOptions{
+		AddParents:    targetFolderID,
+		RemoveParents: previousParents,
+	}, "id, name, parents")
+	if err != nil {
+		return nil, err
+	}
+	ds.Cache.Invalidate(fileID)
+	return moved, nil
 }
 
 // CopyOptions holds options for copying a file.
@@ -567,7 +892,7 @@ type CopyOptions struct {
 }
 
 // CopyFile copies a file in Google Drive.
-func (ds *Service) CopyFile(fileID string, opts CopyOptions) (*drive.File, error) {
+func (ds *Service) CopyFile(ctx context.Context, fileID string, opts CopyOptions) (*drive.File, error) {
 	body := &drive.File{}
 	if opts.NewName != "" {
 		body.Name = opts.NewName
@@ -576,8 +901,7 @@ func (ds *Service) CopyFile(fileID string, opts CopyOptions) (*drive.File, error
 		body.Parents = []string{opts.ParentFolderID}
 	}
 
-	return ds.API.Files.Copy(fileID, body).
-		Fields("id, name, webViewLink").Do()
+	return ds.Backend.CopyFile(ctx, fileID, body, "id, name, webViewLink")
 }
 
 // PathComponent represents a component in a file path.
@@ -588,18 +912,22 @@ type PathComponent struct {
 }
 
 // GetFilePath reconstructs the full path of a file by traversing parent folders.
-func (ds *Service) GetFilePath(fileID string) ([]PathComponent, error) {
+func (ds *Service) GetFilePath(ctx context.Context, fileID string) ([]PathComponent, error) {
 	var path []PathComponent
 	currentID := fileID
 
 	for currentID != "" {
-		file, err := ds.API.Files.Get(currentID).
-			Fields("id, name, parents, mimeType, sharedWithMeTime").
-			SupportsAllDrives(true).Do()
+		file, err := ds.Backend.GetFile(ctx, currentID, "id, name, parents, mimeType, sharedWithMeTime, driveId")
 		if err != nil {
 			break
 		}
 
+		// Root of a Shared Drive reached
+		if file.DriveId != "" && file.Id == file.DriveId {
+			path = append([]PathComponent{ds.sharedDriveComponent(ctx, file.DriveId)}, path...)
+			break
+		}
+
 		// Add to beginning of path
 		path = append([]PathComponent{{
 			ID:       file.Id,
@@ -643,104 +971,241 @@ type FileInfo struct {
 	CreatedTime  string
 	ModifiedTime string
 	WebViewLink  string
+	DriveID      string
 	Owners       []*drive.User
 	Path         []PathComponent
+	Description  string
+	Starred      bool
+	// Properties and AppProperties are the custom properties of the file,
+	// see Metadata.
+	Properties    map[string]string
+	AppProperties map[string]string
+	// Target is the path of the item a shortcut points to, ending with
+	// the item itself. It is nil for other files, and holds just the ID
+	// if the target cannot be read.
+	Target []PathComponent
 }
 
 // GetFileInfo retrieves detailed information about a file.
-func (ds *Service) GetFileInfo(fileID string) (*FileInfo, error) {
-	file, err := ds.API.Files.Get(fileID).
-		Fields("id, name, mimeType, size, createdTime, modifiedTime, webViewLink, owners").
-		Do()
+func (ds *Service) GetFileInfo(ctx context.Context, fileID string) (*FileInfo, error) {
+	file, err := ds.Backend.GetFile(ctx, fileID, "id, name, mimeType, size, createdTime, modifiedTime, webViewLink, owners, driveId, description, starred, properties, appProperties, "+shortcutFields)
 	if err != nil {
 		return nil, err
 	}
 
-	path, _ := ds.GetFilePath(fileID)
+	path, _ := ds.GetFilePath(ctx, fileID)
+
+	var target []PathComponent
+	if ds.IsShortcut(file) {
+		if target, _ = ds.GetFilePath(ctx, file.ShortcutDetails.TargetId); len(target) == 0 {
+			target = []PathComponent{{ID: file.ShortcutDetails.TargetId, MimeType: file.ShortcutDetails.TargetMimeType}}
+		}
+	}
 
 	return &FileInfo{
-		ID:           file.Id,
-		Name:         file.Name,
-		MimeType:     file.MimeType,
-		Size:         file.Size,
-		CreatedTime:  file.CreatedTime,
-		ModifiedTime: file.ModifiedTime,
-		WebViewLink:  file.WebViewLink,
-		Owners:       file.Owners,
-		Path:         path,
+		ID:            file.Id,
+		Name:          file.Name,
+		MimeType:      file.MimeType,
+		Size:          file.Size,
+		CreatedTime:   file.CreatedTime,
+		ModifiedTime:  file.ModifiedTime,
+		WebViewLink:   file.WebViewLink,
+		DriveID:       file.DriveId,
+		Owners:        file.Owners,
+		Path:          path,
+		Description:   file.Description,
+		Starred:       file.Starred,
+		Properties:    file.Properties,
+		AppProperties: file.AppProperties,
+		Target:        target,
 	}, nil
 }
 
-// ShareOptions holds options for sharing a file.
+// permissionFields are the permission fields returned by the sharing
+// methods.
+const permissionFields = "id, type, role, emailAddress, displayName, domain, expirationTime, pendingOwner, allowFileDiscovery"
+
+// ShareOptions holds options for sharing a file. Type is user (the
+// default), group, domain or anyone; Email names the user or group and
+// Domain the domain. Sharing again with the same user, group or domain
+// changes the role of their permission.
 type ShareOptions struct {
+	Type    string
 	Email   string
+	Domain  string
 	Role    string
 	Notify  bool
 	Message string
+	// Expires, when set, is when the permission ends. Drive only lets
+	// permissions of users and groups expire.
+	Expires time.Time
+	// Discoverable lets domain and anyone permissions be found by search
+	// instead of only through the link.
+	Discoverable bool
 }
 
-// ShareFile shares a file with a user.
-func (ds *Service) ShareFile(fileID string, opts ShareOptions) error {
+// ShareFile shares a file and returns the permission Drive created. The
+// owner role is refused: use TransferOwnership. Sharing that ds.Policy
+// forbids fails with an error wrapping ErrPolicyDenied.
+func (ds *Service) ShareFile(ctx context.Context, fileID string, opts ShareOptions) (*drive.Permission, error) {
 	permission := &drive.Permission{
-		Type:         "user",
-		Role:         opts.Role,
-		EmailAddress: opts.Email,
+		Type: opts.Type,
+		Role: opts.Role,
+	}
+	if permission.Type == "" {
+		permission.Type = "user"
+	}
+	switch permission.Type {
+	case "user", "group":
+		if opts.Email == "" {
+			return nil, fmt.Errorf("an email address is required to share with a %s", permission.Type)
+		}
+		permission.EmailAddress = opts.Email
+	case "domain":
+		if opts.Domain == "" {
+			return nil, fmt.Errorf("a domain is required to share with a domain")
+		}
+		permission.Domain = opts.Domain
+	case "anyone":
+	default:
+		return nil, fmt.Errorf("invalid permission type %q (use user, group, domain or anyone)", opts.Type)
+	}
+	if opts.Role == "owner" {
+		return nil, fmt.Errorf("ownership cannot be granted by sharing, transfer it instead")
+	}
+	if !opts.Expires.IsZero() {
+		if permission.Type != "user" && permission.Type != "group" {
+			return nil, fmt.Errorf("only user and group permissions can expire")
+		}
+		permission.ExpirationTime = opts.Expires.UTC().Format(time.RFC3339)
+	}
+	if opts.Discoverable {
+		if permission.Type != "domain" && permission.Type != "anyone" {
+			return nil, fmt.Errorf("only domain and anyone permissions can be discoverable")
+		}
+		permission.AllowFileDiscovery = true
 	}
 
-	createCall := ds.API.Permissions.Create(fileID, permission).
-		Fields("id").
-		SendNotificationEmail(opts.Notify).
-		SupportsAllDrives(true)
-
-	if opts.Message != "" {
-		createCall = createCall.EmailMessage(opts.Message)
+	if err := ds.checkShare(ctx, fileID, permission); err != nil {
+		return nil, err
 	}
+	return ds.createPermission(ctx, fileID, permission, opts)
+}
 
-	_, err := createCall.Do()
-	return err
+func (ds *Service) createPermission(ctx context.Context, fileID string, permission *drive.Permission, opts ShareOptions) (*drive.Permission, error) {
+	perm, err := ds.Backend.CreatePermission(ctx, fileID, permission, PermissionOptions{
+		Fields:       permissionFields,
+		Notify:       opts.Notify,
+		EmailMessage: opts.Message,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to share file: %w", err)
+	}
+	return perm, nil
 }
 
 // ShareWithAnyone shares a file with anyone who has the link.
-func (ds *Service) ShareWithAnyone(fileID, role string) error {
-	permission := &drive.Permission{
-		Type: "anyone",
-		Role: role,
-	}
+func (ds *Service) ShareWithAnyone(ctx context.Context, fileID, role string) (*drive.Permission, error) {
+	return ds.ShareFile(ctx, fileID, ShareOptions{Type: "anyone", Role: role})
+}
 
-	_, err := ds.API.Permissions.Create(fileID, permission).
-		Fields("id").
-		SupportsAllDrives(true).Do()
-	return err
+// PermissionUpdate describes a change to an existing permission. An empty
+// Role and a zero Expires leave them as they are; RemoveExpiration makes
+// the permission permanent.
+type PermissionUpdate struct {
+	Role             string
+	Expires          time.Time
+	RemoveExpiration bool
 }
 
-// ListPermissions lists all permissions for a file.
-func (ds *Service) ListPermissions(fileID string) ([]*drive.Permission, error) {
-	perms, err := ds.API.Permissions.List(fileID).
-		Fields("permissions(id, type, role, emailAddress, displayName, domain)").
-		SupportsAllDrives(true).Do()
+// UpdatePermission changes the role or expiration of a permission and
+// returns it afterwards. ds.Policy applies its rules on the roles of anyone
+// and on expirations to the updated permission.
+func (ds *Service) UpdatePermission(ctx context.Context, fileID, permissionID string, u PermissionUpdate) (*drive.Permission, error) {
+	switch {
+	case u.Role == "" && u.Expires.IsZero() && !u.RemoveExpiration:
+		return nil, fmt.Errorf("nothing to change: give a role or an expiration")
+	case u.Role == "owner":
+		return nil, fmt.Errorf("ownership cannot be granted by a role update, transfer it instead")
+	case !u.Expires.IsZero() && u.RemoveExpiration:
+		return nil, fmt.Errorf("cannot set and remove the expiration at once")
+	}
+	if err := ds.checkUpdate(ctx, fileID, permissionID, u); err != nil {
+		return nil, err
+	}
+
+	update := &drive.Permission{Role: u.Role}
+	if !u.Expires.IsZero() {
+		update.ExpirationTime = u.Expires.UTC().Format(time.RFC3339)
+	}
+	perm, err := ds.Backend.UpdatePermission(ctx, fileID, permissionID, update, PermissionOptions{
+		Fields:           permissionFields,
+		RemoveExpiration: u.RemoveExpiration,
+	})
 	if err != nil {
+		return nil, fmt.Errorf("unable to update permission: %w", err)
+	}
+	return perm, nil
+}
+
+// TransferOwnership offers the ownership of a file to the user with email
+// and returns their permission. Drive makes them a pending owner, sharing
+// the file with them as writer first if needed, and they become owner when
+// they accept. The current owner then keeps writer access. Items in Shared
+// Drives belong to the drive and have no owner to transfer. ds.Policy
+// applies to the new owner, except for the expiration of their access.
+func (ds *Service) TransferOwnership(ctx context.Context, fileID, email string) (*drive.Permission, error) {
+	if err := ds.checkShare(ctx, fileID, &drive.Permission{Type: "user", Role: "owner", EmailAddress: email}); err != nil {
 		return nil, err
 	}
+	perms, err := ds.ListPermissions(ctx, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list permissions: %w", err)
+	}
+	var perm *drive.Permission
+	for _, p := range perms {
+		if p.Type == "user" && strings.EqualFold(p.EmailAddress, email) {
+			perm = p
+		}
+	}
+	if perm != nil && perm.Role == "owner" {
+		return nil, fmt.Errorf("%s already owns the file", email)
+	}
+	if perm == nil {
+		writer := &drive.Permission{Type: "user", Role: "writer", EmailAddress: email}
+		if perm, err = ds.createPermission(ctx, fileID, writer, ShareOptions{Notify: true}); err != nil {
+			return nil, err
+		}
+	}
+
+	perm, err = ds.Backend.UpdatePermission(ctx, fileID, perm.Id,
+		&drive.Permission{Role: "writer", PendingOwner: true}, PermissionOptions{Fields: permissionFields})
+	if err != nil {
+		return nil, fmt.Errorf("unable to transfer ownership: %w", err)
+	}
+	return perm, nil
+}
 
-	return perms.Permissions, nil
+// ListPermissions lists all permissions for a file.
+func (ds *Service) ListPermissions(ctx context.Context, fileID string) ([]*drive.Permission, error) {
+	return ds.Backend.ListPermissions(ctx, fileID, permissionFields)
 }
 
 // RemovePermission removes a specific permission from a file.
-func (ds *Service) RemovePermission(fileID, permissionID string) error {
-	return ds.API.Permissions.Delete(fileID, permissionID).
-		SupportsAllDrives(true).Do()
+func (ds *Service) RemovePermission(ctx context.Context, fileID, permissionID string) error {
+	return ds.Backend.DeletePermission(ctx, fileID, permissionID)
 }
 
 // RemovePublicAccess removes public access (anyone with the link) from a file.
-func (ds *Service) RemovePublicAccess(fileID string) error {
-	perms, err := ds.ListPermissions(fileID)
+func (ds *Service) RemovePublicAccess(ctx context.Context, fileID string) error {
+	perms, err := ds.ListPermissions(ctx, fileID)
 	if err != nil {
 		return err
 	}
 
 	for _, perm := range perms {
 		if perm.Type == "anyone" {
-			if err := ds.RemovePermission(fileID, perm.Id); err != nil {
+			if err := ds.RemovePermission(ctx, fileID, perm.Id); err != nil {
 				return err
 			}
 		}
@@ -759,20 +1224,18 @@ func (ds *Service) GetExportMimeType(workspaceMimeType, format string) string {
 	return ""
 }
 
+// defaultExportFormats are the formats the common Workspace types are
+// exported to when none is given.
+var defaultExportFormats = map[string]string{
+	"application/vnd.google-apps.document":     "pdf",
+	"application/vnd.google-apps.spreadsheet":  "xlsx",
+	"application/vnd.google-apps.presentation": "pptx",
+	"application/vnd.google-apps.drawing":      "pdf",
+}
+
 // GetDefaultExportFormat returns the default export format for a Google Workspace file.
 func (ds *Service) GetDefaultExportFormat(mimeType string) string {
-	switch mimeType {
-	case "application/vnd.google-apps.document":
-		return "pdf"
-	case "application/vnd.google-apps.spreadsheet":
-		return "xlsx"
-	case "application/vnd.google-apps.presentation":
-		return "pptx"
-	case "application/vnd.google-apps.drawing":
-		return "pdf"
-	default:
-		return ""
-	}
+	return defaultExportFormats[mimeType]
 }
 
 // AdjustFilename adjusts the filename extension based on export format.
@@ -794,21 +1257,23 @@ const maxReadContentSize = 1 << 20 // 1MB
 // ReadFileContent reads file content as text from Google Drive.
 // For Google Workspace files, exports to text-friendly formats.
 // Content is capped at 1MB to avoid memory issues.
-func (ds *Service) ReadFileContent(fileID string) (content string, mimeType string, truncated bool, err error) {
-	file, err := ds.API.Files.Get(fileID).
-		Fields("id, name, mimeType, size").
-		SupportsAllDrives(true).Do()
+func (ds *Service) ReadFileContent(ctx context.Context, fileID string) (content string, mimeType string, truncated bool, err error) {
+	file, err := ds.Backend.GetFile(ctx, fileID, "id, name, mimeType, size, "+shortcutFields)
 	if err != nil {
 		return "", "", false, err
 	}
+	if file, err = ds.followShortcut(ctx, file, "id, name, mimeType, size"); err != nil {
+		return "", "", false, err
+	}
+	fileID = file.Id
 
 	mimeType = file.MimeType
 
 	var resp *http.Response
 	if exportMime, ok := TextExportFormats[file.MimeType]; ok {
-		resp, err = ds.API.Files.Export(fileID, exportMime).Download()
+		resp, err = ds.openExport(ctx, fileID, exportMime)
 	} else {
-		resp, err = ds.API.Files.Get(fileID).Download()
+		resp, err = ds.Backend.DownloadFile(ctx, fileID, 0)
 	}
 	if err != nil {
 		return "", mimeType, false, fmt.Errorf("download failed: %w", err)
@@ -831,7 +1296,7 @@ func (ds *Service) ReadFileContent(fileID string) (content string, mimeType stri
 
 // ListRecentFiles lists recent files sorted by the specified order.
 // Valid orderBy values: "recency", "lastModified", "lastModifiedByMe", or "" (defaults to recency).
-func (ds *Service) ListRecentFiles(orderBy string, pageSize int64, pageToken string) ([]*drive.File, string, error) {
+func (ds *Service) ListRecentFiles(ctx context.Context, orderBy string, pageSize int64, pageToken string) ([]*drive.File, string, error) {
 	orderMap := map[string]string{
 		"recency":          "recency desc",
 		"lastModified":     "modifiedTime desc",
@@ -848,17 +1313,13 @@ func (ds *Service) ListRecentFiles(orderBy string, pageSize int64, pageToken str
 		pageSize = 10
 	}
 
-	call := ds.API.Files.List().
-		Q("trashed = false").
-		OrderBy(apiOrder).
-		PageSize(pageSize).
-		Fields("nextPageToken, files(id, name, mimeType, modifiedTime, size, owners, webViewLink)")
-
-	if pageToken != "" {
-		call = call.PageToken(pageToken)
-	}
-
-	result, err := call.Do()
+	result, err := ds.Backend.ListFiles(ctx, ListOptions{
+		Query:     query.Trashed(false).String(),
+		Fields:    "nextPageToken, files(id, name, mimeType, modifiedTime, size, owners, webViewLink)",
+		OrderBy:   apiOrder,
+		PageSize:  pageSize,
+		PageToken: pageToken,
+	})
 	if err != nil {
 		return nil, "", err
 	}
@@ -868,13 +1329,15 @@ func (ds *Service) ListRecentFiles(orderBy string, pageSize int64, pageToken str
 
 // DownloadFileContent downloads raw binary content of a file.
 // For Google Workspace files, exportMimeType determines the export format (defaults to text/plain).
-func (ds *Service) DownloadFileContent(fileID string, exportMimeType string) ([]byte, string, error) {
-	file, err := ds.API.Files.Get(fileID).
-		Fields("id, name, mimeType, size").
-		SupportsAllDrives(true).Do()
+func (ds *Service) DownloadFileContent(ctx context.Context, fileID string, exportMimeType string) ([]byte, string, error) {
+	file, err := ds.Backend.GetFile(ctx, fileID, "id, name, mimeType, size, "+shortcutFields)
 	if err != nil {
 		return nil, "", err
 	}
+	if file, err = ds.followShortcut(ctx, file, "id, name, mimeType, size"); err != nil {
+		return nil, "", err
+	}
+	fileID = file.Id
 
 	var resp *http.Response
 	effectiveMime := file.MimeType
@@ -884,9 +1347,9 @@ func (ds *Service) DownloadFileContent(fileID string, exportMimeType string) ([]
 			exportMimeType = "text/plain"
 		}
 		effectiveMime = exportMimeType
-		resp, err = ds.API.Files.Export(fileID, exportMimeType).Download()
+		resp, err = ds.openExport(ctx, fileID, exportMimeType)
 	} else {
-		resp, err = ds.API.Files.Get(fileID).Download()
+		resp, err = ds.Backend.DownloadFile(ctx, fileID, 0)
 	}
 	if err != nil {
 		return nil, effectiveMime, fmt.Errorf("download failed: %w", err)
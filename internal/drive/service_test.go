@@ -1,6 +1,9 @@
 package drive
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 // newTestService returns a Service with a nil *drive.Service. The pure helpers
 // under test never dereference it.
@@ -35,6 +38,31 @@ func TestParseRemotePath(t *testing.T) {
 	}
 }
 
+func TestSplitSharedDrive(t *testing.T) {
+	cases := []struct {
+		in        []string
+		wantDrive string
+		wantRest  []string
+	}{
+		{nil, "", nil},
+		{[]string{"Documents", "a.txt"}, "", []string{"Documents", "a.txt"}},
+		{[]string{"@Engineering"}, "Engineering", []string{}},
+		{[]string{"@Engineering", "Specs", "design.pdf"}, "Engineering", []string{"Specs", "design.pdf"}},
+		{[]string{"Notes", "@home"}, "", []string{"Notes", "@home"}},
+	}
+	for _, tc := range cases {
+		t.Run(strings.Join(tc.in, "/"), func(t *testing.T) {
+			gotDrive, gotRest := splitSharedDrive(tc.in)
+			if gotDrive != tc.wantDrive {
+				t.Fatalf("splitSharedDrive(%v) drive = %q, want %q", tc.in, gotDrive, tc.wantDrive)
+			}
+			if strings.Join(gotRest, "/") != strings.Join(tc.wantRest, "/") {
+				t.Fatalf("splitSharedDrive(%v) rest = %v, want %v", tc.in, gotRest, tc.wantRest)
+			}
+		})
+	}
+}
+
 func TestGetDefaultExportFormat(t *testing.T) {
 	ds := newTestService()
 	cases := []struct {
@@ -171,6 +199,7 @@ func (ds *Service) isGoogleWorkspaceFileMime(mime string) bool {
 		"application/vnd.google-apps.drawing",
 		"application/vnd.google-apps.map",
 		"application/vnd.google-apps.site",
+		"application/vnd.google-apps.script",
 	}
 	for _, t := range workspaceTypes {
 		if mime == t {
@@ -0,0 +1,93 @@
+package drive
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// SharedDrivePrefix marks the first segment of a remote path as a Shared
+// Drive name rather than a My Drive folder: "@Team/Reports/q3.pdf".
+const SharedDrivePrefix = "@"
+
+// splitSharedDrive extracts the Shared Drive name from parsed path parts.
+// When the first part starts with SharedDrivePrefix, it returns the drive
+// name (without the prefix) and the remaining parts; otherwise it returns
+// an empty name and the parts unchanged.
+func splitSharedDrive(parts []string) (string, []string) {
+	if len(parts) == 0 || !strings.HasPrefix(parts[0], SharedDrivePrefix) {
+		return "", parts
+	}
+	return strings.TrimPrefix(parts[0], SharedDrivePrefix), parts[1:]
+}
+
+// ListSharedDrives lists all Shared Drives the user is a member of.
+func (ds *Service) ListSharedDrives(ctx context.Context) ([]*drive.Drive, error) {
+	var (
+		drives    []*drive.Drive
+		pageToken string
+	)
+	for {
+		list, err := ds.Backend.ListDrives(ctx, pageToken, 100, "nextPageToken, drives(id, name, createdTime, hidden)")
+		if err != nil {
+			return nil, fmt.Errorf("unable to list shared drives: %w", err)
+		}
+		drives = append(drives, list.Drives...)
+		if list.NextPageToken == "" {
+			break
+		}
+		pageToken = list.NextPageToken
+	}
+	return drives, nil
+}
+
+// FindSharedDrive returns the Shared Drive with the given name.
+// The match is exact; an error is returned when no drive has that name.
+func (ds *Service) FindSharedDrive(ctx context.Context, name string) (*drive.Drive, error) {
+	drives, err := ds.ListSharedDrives(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range drives {
+		if d.Name == name {
+			return d, nil
+		}
+	}
+	return nil, fmt.Errorf("shared drive not found: %s%s", SharedDrivePrefix, name)
+}
+
+// resolveRoot returns the folder ID that a parsed path starts from and the
+// path parts left to walk. Paths beginning with "@Name" start at the root
+// of that Shared Drive; everything else starts at My Drive.
+func (ds *Service) resolveRoot(ctx context.Context, parts []string) (string, []string, error) {
+	driveName, rest := splitSharedDrive(parts)
+	if driveName == "" {
+		return DriveRootID, rest, nil
+	}
+	// Shared Drives are cached under an empty parent ID
+	if id, ok := ds.Cache.Get("", parts[0]); ok {
+		return id, rest, nil
+	}
+	sd, err := ds.FindSharedDrive(ctx, driveName)
+	if err != nil {
+		return "", nil, err
+	}
+	ds.Cache.Put("", parts[0], sd.Id)
+	return sd.Id, rest, nil
+}
+
+// sharedDriveComponent returns the path component for the root of a Shared
+// Drive. If the drive name cannot be fetched, the ID is used instead.
+func (ds *Service) sharedDriveComponent(ctx context.Context, driveID string) PathComponent {
+	name := driveID
+	if sd, err := ds.Backend.GetDrive(ctx, driveID, "id, name"); err == nil {
+		name = sd.Name
+	}
+	return PathComponent{
+		ID:       driveID,
+		Name:     SharedDrivePrefix + name,
+		MimeType: "special",
+	}
+}
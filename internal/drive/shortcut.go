@@ -0,0 +1,96 @@
+package drive
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// DriveShortcutMimeType is the MIME type of Drive shortcuts, items that
+// point to another file or folder through shortcutDetails.targetId.
+const DriveShortcutMimeType = "application/vnd.google-apps.shortcut"
+
+// shortcutFields selects the target of a shortcut in file listings.
+const shortcutFields = "shortcutDetails(targetId, targetMimeType)"
+
+// IsShortcut checks if an item is a shortcut.
+func (ds *Service) IsShortcut(item *drive.File) bool {
+	return item.MimeType == DriveShortcutMimeType && item.ShortcutDetails != nil
+}
+
+// IsFolderShortcut checks if an item is a shortcut to a folder.
+func (ds *Service) IsFolderShortcut(item *drive.File) bool {
+	return ds.IsShortcut(item) && item.ShortcutDetails.TargetMimeType == DriveFolderMimeType
+}
+
+// ShortcutTarget returns the item a shortcut points to, with fields. It
+// fails if the target was deleted or is not shared with the user.
+func (ds *Service) ShortcutTarget(ctx context.Context, shortcut *drive.File, fields string) (*drive.File, error) {
+	target, err := ds.Backend.GetFile(ctx, shortcut.ShortcutDetails.TargetId, fields)
+	if err != nil {
+		return nil, fmt.Errorf("shortcut %s points to an inaccessible item: %w", shortcut.Name, err)
+	}
+	return target, nil
+}
+
+// followShortcut returns the target of item, with fields, when item is a
+// shortcut and ds follows shortcuts, and item itself otherwise.
+func (ds *Service) followShortcut(ctx context.Context, item *drive.File, fields string) (*drive.File, error) {
+	if !ds.IsShortcut(item) {
+		return item, nil
+	}
+	if ds.NoFollowShortcuts {
+		return nil, fmt.Errorf("%s is a shortcut to %s, which is not followed", item.Name, item.ShortcutDetails.TargetId)
+	}
+	return ds.ShortcutTarget(ctx, item, fields)
+}
+
+// ResolveShortcut returns the item a shortcut found in a folder listing
+// stands for: its target with the fields of a listing, under the name of the
+// shortcut. Other items are returned as is.
+func (ds *Service) ResolveShortcut(ctx context.Context, item *drive.File) (*drive.File, error) {
+	if !ds.IsShortcut(item) {
+		return item, nil
+	}
+	target, err := ds.ShortcutTarget(ctx, item, "id, name, mimeType, modifiedTime, size, "+checksumFields)
+	if err != nil {
+		return nil, err
+	}
+	target.Name = item.Name
+	return target, nil
+}
+
+// findFolderShortcut returns the ID of the folder that the shortcut called
+// name inside parentID points to, or "" if there is no such shortcut.
+func (ds *Service) findFolderShortcut(ctx context.Context, name, parentID string) (string, error) {
+	item, err := ds.FindItemByName(ctx, name, parentID, DriveShortcutMimeType)
+	if err != nil || item == nil || !ds.IsFolderShortcut(item) {
+		return "", err
+	}
+	return item.ShortcutDetails.TargetId, nil
+}
+
+// CreateShortcut creates a shortcut to targetID in folderID. The shortcut
+// is named after the target unless name is set.
+func (ds *Service) CreateShortcut(ctx context.Context, targetID, folderID, name string) (*drive.File, error) {
+	if name == "" {
+		target, err := ds.Backend.GetFile(ctx, targetID, "id, name")
+		if err != nil {
+			return nil, fmt.Errorf("shortcut target not found: %w", err)
+		}
+		name = target.Name
+	}
+
+	meta := &drive.File{
+		Name:            name,
+		MimeType:        DriveShortcutMimeType,
+		Parents:         []string{folderID},
+		ShortcutDetails: &drive.FileShortcutDetails{TargetId: targetID},
+	}
+	shortcut, err := ds.Backend.CreateFile(ctx, meta, nil, "id, name, webViewLink, "+shortcutFields)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create shortcut: %w", err)
+	}
+	return shortcut, nil
+}
@@ -0,0 +1,72 @@
+package drive_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gdrive/internal/drivetest"
+)
+
+func TestShortcutsAreFollowed(t *testing.T) {
+	srv, ds := newFakeService(t)
+	ctx := t.Context()
+	team := srv.AddFolder(drivetest.RootID, "Team")
+	specs := srv.AddFolder(team, "Specs")
+	design := srv.AddFile(specs, "design.txt", []byte("design"))
+	srv.AddShortcut(drivetest.RootID, "Specs", specs)
+	link := srv.AddShortcut(drivetest.RootID, "design link", design)
+
+	if got, err := ds.ResolvePath(ctx, "Specs", true); err != nil || got != specs {
+		t.Errorf("ResolvePath through a shortcut = %q, %v; want %q", got, err, specs)
+	}
+
+	dest := filepath.Join(t.TempDir(), "design.txt")
+	if err := ds.DownloadFile(ctx, link, dest, "", false, false); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(dest); string(data) != "design" {
+		t.Errorf("downloaded %q through the shortcut", data)
+	}
+
+	info, err := ds.GetFileInfo(ctx, link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(info.Target); n == 0 || info.Target[n-1].ID != design || info.Target[n-2].Name != "Specs" {
+		t.Errorf("shortcut target = %+v", info.Target)
+	}
+
+	ds.NoFollowShortcuts = true
+	if _, err := ds.ResolvePath(ctx, "Specs", true); err == nil {
+		t.Error("ResolvePath went through a shortcut that should not be followed")
+	}
+	if err := ds.DownloadFile(ctx, link, dest, "", false, false); err == nil {
+		t.Error("downloading a shortcut that should not be followed succeeded")
+	}
+}
+
+func TestCreateShortcut(t *testing.T) {
+	srv, ds := newFakeService(t)
+	ctx := t.Context()
+	folder := srv.AddFolder(drivetest.RootID, "Inbox")
+	target := srv.AddFile(drivetest.RootID, "report.pdf", []byte("%PDF"))
+
+	shortcut, err := ds.CreateShortcut(ctx, target, folder, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := srv.File(shortcut.Id)
+	if f.Name != "report.pdf" || f.ShortcutDetails == nil || f.ShortcutDetails.TargetId != target || f.Parents[0] != folder {
+		t.Errorf("created %+v", f)
+	}
+
+	items, err := ds.ListFolder(ctx, folder)
+	if err != nil || len(items) != 1 || !ds.IsShortcut(items[0]) {
+		t.Fatalf("ListFolder = %v, %v", items, err)
+	}
+
+	if _, err := ds.CreateShortcut(ctx, "missing", folder, ""); err == nil {
+		t.Error("shortcut to a missing target should fail")
+	}
+}
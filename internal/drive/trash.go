@@ -0,0 +1,125 @@
+package drive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+
+	"gdrive/internal/query"
+)
+
+// ErrNotTrashed is returned by RestoreFile for a file that is not in the
+// trash.
+var ErrNotTrashed = errors.New("file is not in the trash")
+
+// trashFields are the fields fetched for items in the trash.
+const trashFields = "id, name, mimeType, size, parents, trashed, explicitlyTrashed, trashedTime, trashingUser"
+
+// TrashFile moves a file or folder to the trash. Items in a trashed folder
+// go with it. Trashed items can be restored with RestoreFile until the
+// trash is emptied; Drive empties it automatically after 30 days.
+func (ds *Service) TrashFile(ctx context.Context, fileID string) (*drive.File, error) {
+	file, err := ds.Backend.UpdateFile(ctx, fileID, &drive.File{Trashed: true}, UpdateOptions{}, "id, name, trashed")
+	if err != nil {
+		return nil, err
+	}
+	ds.Cache.Invalidate(fileID)
+	return file, nil
+}
+
+// RestoreFile takes a file or folder out of the trash. Drive keeps the
+// parents of trashed items, so it reappears in its original folder.
+//
+// An item that is only in the trash because its folder was trashed cannot
+// be restored on its own: the folder has to be restored instead.
+func (ds *Service) RestoreFile(ctx context.Context, fileID string) (*drive.File, error) {
+	file, err := ds.Backend.GetFile(ctx, fileID, trashFields)
+	if err != nil {
+		return nil, err
+	}
+	if !file.Trashed {
+		return nil, fmt.Errorf("%s: %w", file.Name, ErrNotTrashed)
+	}
+	if !file.ExplicitlyTrashed {
+		return nil, fmt.Errorf("%s was trashed with its folder; restore the folder instead", file.Name)
+	}
+
+	restored, err := ds.Backend.UpdateFile(ctx, fileID,
+		&drive.File{Trashed: false, ForceSendFields: []string{"Trashed"}},
+		UpdateOptions{}, "id, name, mimeType, parents, trashed")
+	if err != nil {
+		return nil, err
+	}
+	ds.Cache.Invalidate(fileID)
+	return restored, nil
+}
+
+// FindTrashedItem finds a trashed item by name in a parent folder. Like
+// FindItemByName, it defers to ds.Pick when several items match.
+func (ds *Service) FindTrashedItem(ctx context.Context, name, parentID string) (*drive.File, error) {
+	q := query.And(query.Name(name), query.InParents(parentID), query.Trashed(true))
+	fileList, err := ds.Backend.ListFiles(ctx, ListOptions{
+		Query:   q.String(),
+		Fields:  "files(id, name, mimeType, modifiedTime, size, owners(displayName, emailAddress))",
+		OrderBy: "trashedTime desc",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(fileList.Files) {
+	case 0:
+		return nil, nil
+	case 1:
+		return fileList.Files[0], nil
+	}
+
+	amb := &AmbiguousPathError{Name: name, ParentID: parentID, Candidates: fileList.Files}
+	if ds.Pick == nil {
+		return nil, amb
+	}
+	return ds.Pick(ctx, amb)
+}
+
+// EmptyTrash permanently deletes everything in the trash.
+func (ds *Service) EmptyTrash(ctx context.Context) error {
+	if err := ds.Backend.EmptyTrash(ctx); err != nil {
+		return fmt.Errorf("unable to empty trash: %w", err)
+	}
+	return nil
+}
+
+// TrashedBefore returns the items explicitly trashed before cutoff, oldest
+// first. Items trashed along with a folder are left out: they go with it.
+func (ds *Service) TrashedBefore(ctx context.Context, cutoff time.Time) ([]*drive.File, error) {
+	q := query.And(query.Trashed(true), query.TrashedTime(query.Lt, cutoff))
+
+	var (
+		files     []*drive.File
+		pageToken string
+	)
+	for {
+		list, err := ds.Backend.ListFiles(ctx, ListOptions{
+			Query:     q.String(),
+			Fields:    "nextPageToken, files(" + trashFields + ")",
+			OrderBy:   "trashedTime",
+			PageSize:  1000,
+			PageToken: pageToken,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to list trashed files: %w", err)
+		}
+		for _, f := range list.Files {
+			if f.ExplicitlyTrashed {
+				files = append(files, f)
+			}
+		}
+		if list.NextPageToken == "" {
+			return files, nil
+		}
+		pageToken = list.NextPageToken
+	}
+}
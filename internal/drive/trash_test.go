@@ -0,0 +1,91 @@
+package drive_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gdrive/internal/drive"
+	"gdrive/internal/drivetest"
+)
+
+func TestTrashAndRestore(t *testing.T) {
+	srv, ds := newFakeService(t)
+	ctx := t.Context()
+	dir := srv.AddFolder(drivetest.RootID, "Projects")
+	child := srv.AddFile(dir, "plan.txt", []byte("plan"))
+
+	if _, err := ds.TrashFile(ctx, dir); err != nil {
+		t.Fatal(err)
+	}
+	if !srv.File(child).Trashed {
+		t.Fatal("child of a trashed folder is not trashed")
+	}
+
+	if _, err := ds.RestoreFile(ctx, child); err == nil {
+		t.Error("restoring an item trashed with its folder should fail")
+	}
+	restored, err := ds.RestoreFile(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restored.Parents) != 1 || restored.Parents[0] != drivetest.RootID {
+		t.Errorf("restored parents = %v", restored.Parents)
+	}
+	if srv.File(dir).Trashed || srv.File(child).Trashed {
+		t.Error("folder or child still trashed after restore")
+	}
+
+	if _, err := ds.RestoreFile(ctx, dir); !errors.Is(err, drive.ErrNotTrashed) {
+		t.Errorf("restoring an untrashed file: %v", err)
+	}
+}
+
+func TestFindTrashedItem(t *testing.T) {
+	srv, ds := newFakeService(t)
+	ctx := t.Context()
+	id := srv.AddFile(drivetest.RootID, "old.txt", []byte("x"))
+	srv.AddFile(drivetest.RootID, "kept.txt", []byte("y"))
+	if _, err := ds.TrashFile(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+
+	if f, err := ds.FindTrashedItem(ctx, "old.txt", drivetest.RootID); err != nil || f == nil || f.Id != id {
+		t.Errorf("FindTrashedItem(old.txt) = %v, %v", f, err)
+	}
+	if f, err := ds.FindTrashedItem(ctx, "kept.txt", drivetest.RootID); err != nil || f != nil {
+		t.Errorf("FindTrashedItem(kept.txt) = %v, %v; want nothing", f, err)
+	}
+}
+
+func TestTrashedBeforeAndEmptyTrash(t *testing.T) {
+	srv, ds := newFakeService(t)
+	ctx := t.Context()
+	dir := srv.AddFolder(drivetest.RootID, "Old")
+	srv.AddFile(dir, "inside.txt", []byte("a"))
+	recent := srv.AddFile(drivetest.RootID, "recent.txt", []byte("b"))
+
+	if _, err := ds.TrashFile(ctx, dir); err != nil {
+		t.Fatal(err)
+	}
+	srv.Advance(40 * 24 * time.Hour)
+	if _, err := ds.TrashFile(ctx, recent); err != nil {
+		t.Fatal(err)
+	}
+
+	old, err := ds.TrashedBefore(ctx, srv.Now().Add(-30*24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The folder's content goes with it and is not listed separately
+	if len(old) != 1 || old[0].Id != dir {
+		t.Fatalf("TrashedBefore = %v; want only the folder", old)
+	}
+
+	if err := ds.EmptyTrash(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if srv.File(dir) != nil || srv.File(recent) != nil {
+		t.Error("trash not emptied")
+	}
+}
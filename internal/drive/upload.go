@@ -0,0 +1,393 @@
+package drive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/schollz/progressbar/v3"
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+)
+
+const (
+	// DefaultUploadChunkSize is the size of each request of a resumable upload.
+	DefaultUploadChunkSize int64 = 8 << 20
+	// UploadChunkAlign is the granularity Drive requires for chunk sizes.
+	UploadChunkAlign int64 = 256 << 10
+
+	// uploadSessionTTL is how long Drive keeps a resumable session open.
+	uploadSessionTTL = 7 * 24 * time.Hour
+	// uploadStateFilePerm keeps session URIs private: they grant upload access.
+	uploadStateFilePerm = 0600
+)
+
+// errUploadSessionExpired reports that Drive no longer knows a session.
+var errUploadSessionExpired = errors.New("upload session expired")
+
+// UploadSession is the persisted state of a resumable upload.
+type UploadSession struct {
+	LocalPath  string    `json:"localPath"`
+	ParentID   string    `json:"parentId"`
+	FileID     string    `json:"fileId,omitempty"` // set when uploading a new version
+	SessionURI string    `json:"sessionUri"`
+	Size       int64     `json:"size"`
+	ModTime    time.Time `json:"modTime"`
+	Offset     int64     `json:"offset"`
+	Started    time.Time `json:"started"`
+}
+
+// UploadStateStore persists resumable upload sessions so that an upload
+// interrupted by a crash or Ctrl-C continues where it stopped on the next
+// run. Sessions are keyed by local file and destination folder and are
+// discarded once Drive would have expired them. All methods are safe on a
+// nil *UploadStateStore, which remembers nothing.
+type UploadStateStore struct {
+	mu       sync.Mutex
+	file     string
+	sessions map[string]UploadSession
+	now      func() time.Time
+}
+
+// OpenUploadStateStore loads the upload state stored in file. A missing or
+// unreadable state file yields an empty store.
+func OpenUploadStateStore(file string) (*UploadStateStore, error) {
+	s := &UploadStateStore{
+		file:     file,
+		sessions: make(map[string]UploadSession),
+		now:      time.Now,
+	}
+
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read upload state: %w", err)
+	}
+
+	var stored []UploadSession
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return s, nil
+	}
+	now := s.now()
+	for _, sess := range stored {
+		if now.Sub(sess.Started) < uploadSessionTTL {
+			s.sessions[uploadKey(sess.LocalPath, sess.ParentID)] = sess
+		}
+	}
+	return s, nil
+}
+
+func uploadKey(localPath, parentID string) string {
+	return parentID + "/" + localPath
+}
+
+// Get returns the session for uploading localPath into parentID.
+func (s *UploadStateStore) Get(localPath, parentID string) (UploadSession, bool) {
+	if s == nil {
+		return UploadSession{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[uploadKey(localPath, parentID)]
+	return sess, ok
+}
+
+// Put records sess and writes the store to disk.
+func (s *UploadStateStore) Put(sess UploadSession) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessions[uploadKey(sess.LocalPath, sess.ParentID)] = sess
+	s.saveLocked()
+}
+
+// Delete forgets the session for uploading localPath into parentID.
+func (s *UploadStateStore) Delete(localPath, parentID string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := uploadKey(localPath, parentID)
+	if _, ok := s.sessions[key]; ok {
+		delete(s.sessions, key)
+		s.saveLocked()
+	}
+}
+
+// saveLocked writes the sessions to the state file; an empty store removes
+// it. Errors are ignored: the upload itself does not depend on the file.
+func (s *UploadStateStore) saveLocked() {
+	if s.file == "" {
+		return
+	}
+	if len(s.sessions) == 0 {
+		_ = os.Remove(s.file)
+		return
+	}
+	stored := make([]UploadSession, 0, len(s.sessions))
+	for _, sess := range s.sessions {
+		stored = append(stored, sess)
+	}
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return
+	}
+	_ = writeFileAtomic(s.file, data, uploadStateFilePerm)
+}
+
+// chunkSize returns the configured chunk size rounded up to the granularity
+// Drive accepts.
+func (ds *Service) chunkSize() int64 {
+	size := ds.ChunkSize
+	if size <= 0 {
+		size = DefaultUploadChunkSize
+	}
+	if rem := size % UploadChunkAlign; rem != 0 {
+		size += UploadChunkAlign - rem
+	}
+	return size
+}
+
+// uploadResumable uploads f with Drive's resumable protocol, in chunks of
+// ds.ChunkSize. If ds.Uploads holds a session for the same unchanged local
+// file and destination, the upload continues from the offset Drive reports.
+// fileID is the file to update, or "" to create a new one described by meta.
+// The bytes Drive acknowledges are hashed and must match the checksums it
+// reports for the finished file.
+func (ds *Service) uploadResumable(ctx context.Context, f *os.File, parentID, fileID string, meta *drive.File, contentType string, showProgress bool) (string, error) {
+	stat, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	localPath, err := filepath.Abs(f.Name())
+	if err != nil {
+		return "", err
+	}
+	size := stat.Size()
+
+	var offset int64
+	sess, resumed := ds.Uploads.Get(localPath, parentID)
+	if resumed && (sess.Size != size || !sess.ModTime.Equal(stat.ModTime()) || sess.FileID != fileID) {
+		// The local file or the destination changed: the session is stale.
+		ds.Uploads.Delete(localPath, parentID)
+		resumed = false
+	}
+	h := newHasher()
+	finish := func(done *drive.File) (string, error) {
+		ds.Uploads.Delete(localPath, parentID)
+		return done.Id, verifyUpload(filepath.Base(localPath), h, done)
+	}
+
+	if resumed {
+		var done *drive.File
+		offset, done, err = ds.queryUploadOffset(ctx, sess.SessionURI, size)
+		switch {
+		case errors.Is(err, errUploadSessionExpired):
+			ds.Uploads.Delete(localPath, parentID)
+			resumed = false
+		case err != nil:
+			return "", fmt.Errorf("unable to resume upload: %w", err)
+		case done != nil:
+			offset = size
+		}
+		if resumed {
+			// Bytes sent by the previous run count towards the checksum
+			if _, err := io.Copy(h, io.NewSectionReader(f, 0, offset)); err != nil {
+				return "", err
+			}
+		}
+		if done != nil {
+			return finish(done)
+		}
+	}
+	if !resumed {
+		offset = 0
+		uri, err := ds.startUploadSession(ctx, fileID, meta, contentType, size)
+		if err != nil {
+			return "", err
+		}
+		sess = UploadSession{
+			LocalPath:  localPath,
+			ParentID:   parentID,
+			FileID:     fileID,
+			SessionURI: uri,
+			Size:       size,
+			ModTime:    stat.ModTime(),
+			Started:    time.Now(),
+		}
+		ds.Uploads.Put(sess)
+	} else if showProgress {
+		fmt.Printf("Resuming at %d of %d bytes\n", offset, size)
+	}
+
+	var bar *progressbar.ProgressBar
+	if showProgress {
+		bar = progressbar.DefaultBytes(size, fmt.Sprintf("Uploading %s", filepath.Base(localPath)))
+		_ = bar.Set64(offset)
+	}
+
+	buf := make([]byte, min(ds.chunkSize(), max(size-offset, 0)))
+	for {
+		n, err := f.ReadAt(buf[:min(int64(len(buf)), size-offset)], offset)
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		next, done, err := ds.putUploadChunk(ctx, sess.SessionURI, buf[:n], offset, size)
+		if err != nil {
+			if ds.Uploads != nil {
+				return "", fmt.Errorf("upload interrupted at %d of %d bytes, run the command again to resume: %w", offset, size, err)
+			}
+			return "", err
+		}
+		if done != nil {
+			if bar != nil {
+				_ = bar.Finish()
+			}
+			h.Write(buf[:n])
+			return finish(done)
+		}
+		if next == offset {
+			return "", fmt.Errorf("upload made no progress at %d of %d bytes", offset, size)
+		}
+		h.Write(buf[:min(next-offset, int64(n))])
+		if bar != nil {
+			_ = bar.Set64(next)
+		}
+		offset = next
+		sess.Offset = offset
+		ds.Uploads.Put(sess)
+	}
+}
+
+// startUploadSession opens a resumable upload session and returns its URI.
+func (ds *Service) startUploadSession(ctx context.Context, fileID string, meta *drive.File, contentType string, size int64) (string, error) {
+	method := http.MethodPost
+	endpoint := googleapi.ResolveRelative(ds.API.BasePath, "/upload/drive/v3/files")
+	if fileID != "" {
+		method = http.MethodPatch
+		endpoint += "/" + url.PathEscape(fileID)
+	}
+	params := url.Values{
+		"uploadType":        {"resumable"},
+		"supportsAllDrives": {"true"},
+		"fields":            {"id, " + checksumFields},
+	}
+
+	body, err := json.Marshal(meta)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint+"?"+params.Encode(), bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	req.Header.Set("X-Upload-Content-Type", contentType)
+	req.Header.Set("X-Upload-Content-Length", strconv.FormatInt(size, 10))
+
+	resp, err := ds.HTTP.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if err := googleapi.CheckResponse(resp); err != nil {
+		return "", err
+	}
+	uri := resp.Header.Get("Location")
+	if uri == "" {
+		return "", fmt.Errorf("upload session not created: no Location header")
+	}
+	return uri, nil
+}
+
+// queryUploadOffset asks Drive how much of a session it has received. It
+// returns the next offset to send, or the file if the upload completed.
+func (ds *Service) queryUploadOffset(ctx context.Context, sessionURI string, size int64) (int64, *drive.File, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, sessionURI, http.NoBody)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+
+	resp, err := ds.HTTP.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return 0, nil, errUploadSessionExpired
+	}
+	return uploadProgress(resp)
+}
+
+// putUploadChunk sends chunk, which starts at offset of a size-byte upload.
+func (ds *Service) putUploadChunk(ctx context.Context, sessionURI string, chunk []byte, offset, size int64) (int64, *drive.File, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, sessionURI, bytes.NewReader(chunk))
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(chunk) == 0 {
+		req.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+	} else {
+		req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, offset+int64(len(chunk))-1, size))
+	}
+
+	resp, err := ds.HTTP.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	return uploadProgress(resp)
+}
+
+// uploadProgress interprets a resumable upload response: 308 carries the
+// received byte range, 200/201 the created or updated file.
+func uploadProgress(resp *http.Response) (int64, *drive.File, error) {
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+		var file drive.File
+		if err := json.NewDecoder(resp.Body).Decode(&file); err != nil {
+			return 0, nil, fmt.Errorf("unable to decode upload response: %w", err)
+		}
+		return 0, &file, nil
+	case http.StatusPermanentRedirect:
+		// "Range: bytes=0-N" — N is the last byte received. No header means
+		// nothing has been received yet.
+		r := resp.Header.Get("Range")
+		if r == "" {
+			return 0, nil, nil
+		}
+		_, last, ok := strings.Cut(strings.TrimPrefix(r, "bytes="), "-")
+		if !ok {
+			return 0, nil, fmt.Errorf("invalid Range header in upload response: %q", r)
+		}
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil {
+			return 0, nil, fmt.Errorf("invalid Range header in upload response: %q", r)
+		}
+		return n + 1, nil, nil
+	}
+	if err := googleapi.CheckResponse(resp); err != nil {
+		return 0, nil, err
+	}
+	return 0, nil, fmt.Errorf("unexpected upload response: %s", resp.Status)
+}
@@ -0,0 +1,281 @@
+package drive
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+)
+
+// fakeUploadServer implements enough of Drive's resumable upload protocol
+// to exercise UploadFile: an empty file search, session creation and
+// chunked PUTs.
+type fakeUploadServer struct {
+	mu       sync.Mutex
+	received []byte
+	done     bool
+	sessions int
+	failAt   int64 // fail the chunk starting at this offset once; -1 never
+	expired  bool  // answer 404 to the next status query
+	badSum   bool  // report a checksum that does not match the received bytes
+}
+
+func newFakeUploadServer(t *testing.T) (*fakeUploadServer, *Service) {
+	t.Helper()
+	f := &fakeUploadServer{failAt: -1}
+	ts := httptest.NewServer(f)
+	t.Cleanup(ts.Close)
+
+	api, err := drive.NewService(context.Background(),
+		option.WithEndpoint(ts.URL+"/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds := NewService(api)
+	ds.HTTP = ts.Client()
+	ds.ChunkSize = UploadChunkAlign
+	return f, ds
+}
+
+func (f *fakeUploadServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/files":
+		io.WriteString(w, `{"files":[]}`)
+
+	case r.Method == http.MethodPost && r.URL.Path == "/upload/drive/v3/files":
+		if r.URL.Query().Get("uploadType") != "resumable" {
+			http.Error(w, "expected resumable upload", http.StatusBadRequest)
+			return
+		}
+		f.sessions++
+		f.received, f.done = nil, false
+		w.Header().Set("Location", fmt.Sprintf("http://%s/session/%d", r.Host, f.sessions))
+
+	case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/session/"):
+		f.handleChunk(w, r)
+
+	default:
+		http.Error(w, "unexpected request "+r.Method+" "+r.URL.Path, http.StatusNotFound)
+	}
+}
+
+func (f *fakeUploadServer) handleChunk(w http.ResponseWriter, r *http.Request) {
+	var start, end, total int64
+	contentRange := r.Header.Get("Content-Range")
+	if _, err := fmt.Sscanf(contentRange, "bytes */%d", &total); err == nil {
+		// Status query; it also finalizes an upload whose bytes all arrived
+		if f.expired {
+			f.expired = false
+			http.Error(w, "session expired", http.StatusNotFound)
+			return
+		}
+		f.done = int64(len(f.received)) == total
+		f.progress(w)
+		return
+	}
+	if _, err := fmt.Sscanf(contentRange, "bytes %d-%d/%d", &start, &end, &total); err != nil {
+		http.Error(w, "bad Content-Range "+contentRange, http.StatusBadRequest)
+		return
+	}
+	if start == f.failAt {
+		f.failAt = -1
+		http.Error(w, "connection lost", http.StatusInternalServerError)
+		return
+	}
+	if start != int64(len(f.received)) {
+		http.Error(w, fmt.Sprintf("chunk at %d, expected %d", start, len(f.received)), http.StatusBadRequest)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	f.received = append(f.received, body...)
+	f.done = int64(len(f.received)) == total
+	f.progress(w)
+}
+
+func (f *fakeUploadServer) progress(w http.ResponseWriter) {
+	if f.done {
+		sum := fmt.Sprintf("%x", md5.Sum(f.received))
+		if f.badSum {
+			sum = strings.Repeat("0", len(sum))
+		}
+		fmt.Fprintf(w, `{"id":"uploaded-id","md5Checksum":%q}`, sum)
+		return
+	}
+	if len(f.received) > 0 {
+		w.Header().Set("Range", fmt.Sprintf("bytes=0-%d", len(f.received)-1))
+	}
+	w.WriteHeader(http.StatusPermanentRedirect)
+}
+
+// writeTestFile creates a file of n pseudo-random bytes.
+func writeTestFile(t *testing.T, n int) (string, []byte) {
+	t.Helper()
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(i * 31)
+	}
+	path := filepath.Join(t.TempDir(), "video.bin")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path, data
+}
+
+func TestUploadFileChunked(t *testing.T) {
+	fake, ds := newFakeUploadServer(t)
+	path, data := writeTestFile(t, int(2*UploadChunkAlign+1000))
+
+	id, err := ds.UploadFile(context.Background(), path, "parent-id", "", false, false)
+	if err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	if id != "uploaded-id" {
+		t.Errorf("id = %q, want uploaded-id", id)
+	}
+	if !bytes.Equal(fake.received, data) {
+		t.Errorf("server received %d bytes, want %d identical bytes", len(fake.received), len(data))
+	}
+}
+
+func TestUploadFileResumesAfterInterruption(t *testing.T) {
+	fake, ds := newFakeUploadServer(t)
+	stateFile := filepath.Join(t.TempDir(), "uploads.json")
+	path, data := writeTestFile(t, int(3*UploadChunkAlign+1))
+
+	uploads, err := OpenUploadStateStore(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ds.Uploads = uploads
+	fake.failAt = 2 * UploadChunkAlign
+
+	_, err = ds.UploadFile(context.Background(), path, "parent-id", "", false, false)
+	if err == nil || !strings.Contains(err.Error(), "run the command again to resume") {
+		t.Fatalf("expected interrupted upload error, got %v", err)
+	}
+
+	// A new process picks the session up from the state file.
+	reopened, err := OpenUploadStateStore(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	abs, _ := filepath.Abs(path)
+	sess, ok := reopened.Get(abs, "parent-id")
+	if !ok || sess.Offset != 2*UploadChunkAlign {
+		t.Fatalf("persisted session = %+v (found %v), want offset %d", sess, ok, 2*UploadChunkAlign)
+	}
+	ds.Uploads = reopened
+
+	id, err := ds.UploadFile(context.Background(), path, "parent-id", "", false, false)
+	if err != nil {
+		t.Fatalf("resumed UploadFile: %v", err)
+	}
+	if id != "uploaded-id" || !bytes.Equal(fake.received, data) {
+		t.Errorf("resumed upload produced id %q and %d bytes, want uploaded-id and %d", id, len(fake.received), len(data))
+	}
+	if fake.sessions != 1 {
+		t.Errorf("sessions = %d, want 1 (resumed, not restarted)", fake.sessions)
+	}
+	if _, err := os.Stat(stateFile); !os.IsNotExist(err) {
+		t.Errorf("state file should be removed after completion: %v", err)
+	}
+}
+
+func TestUploadFileRestartsExpiredSession(t *testing.T) {
+	fake, ds := newFakeUploadServer(t)
+	path, data := writeTestFile(t, int(2*UploadChunkAlign))
+	uploads, _ := OpenUploadStateStore(filepath.Join(t.TempDir(), "uploads.json"))
+	ds.Uploads = uploads
+	fake.failAt = UploadChunkAlign
+
+	if _, err := ds.UploadFile(context.Background(), path, "parent-id", "", false, false); err == nil {
+		t.Fatal("expected interrupted upload")
+	}
+	fake.expired = true
+
+	if _, err := ds.UploadFile(context.Background(), path, "parent-id", "", false, false); err != nil {
+		t.Fatalf("UploadFile after expiry: %v", err)
+	}
+	if fake.sessions != 2 || !bytes.Equal(fake.received, data) {
+		t.Errorf("sessions = %d, received %d bytes; want a fresh session with all %d bytes", fake.sessions, len(fake.received), len(data))
+	}
+}
+
+func TestUploadFileIgnoresStaleSession(t *testing.T) {
+	fake, ds := newFakeUploadServer(t)
+	path, _ := writeTestFile(t, int(2*UploadChunkAlign))
+	uploads, _ := OpenUploadStateStore(filepath.Join(t.TempDir(), "uploads.json"))
+	ds.Uploads = uploads
+	fake.failAt = UploadChunkAlign
+
+	if _, err := ds.UploadFile(context.Background(), path, "parent-id", "", false, false); err == nil {
+		t.Fatal("expected interrupted upload")
+	}
+
+	// The local file changes before the retry: its session must not be reused.
+	changed := bytes.Repeat([]byte("x"), 1000)
+	if err := os.WriteFile(path, changed, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ds.UploadFile(context.Background(), path, "parent-id", "", false, false); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	if fake.sessions != 2 || !bytes.Equal(fake.received, changed) {
+		t.Errorf("sessions = %d, received %q...; want a fresh session with the new content", fake.sessions, fake.received[:min(10, len(fake.received))])
+	}
+}
+
+func TestUploadFileChecksumMismatch(t *testing.T) {
+	fake, ds := newFakeUploadServer(t)
+	path, _ := writeTestFile(t, int(UploadChunkAlign+10))
+	fake.badSum = true
+
+	_, err := ds.UploadFile(context.Background(), path, "parent-id", "", false, false)
+	var mismatch *ChecksumMismatchError
+	if !errors.As(err, &mismatch) || !strings.Contains(err.Error(), "upload it again") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+}
+
+func TestUploadFileEmpty(t *testing.T) {
+	fake, ds := newFakeUploadServer(t)
+	path, _ := writeTestFile(t, 0)
+
+	id, err := ds.UploadFile(context.Background(), path, "parent-id", "", false, false)
+	if err != nil || id != "uploaded-id" {
+		t.Fatalf("UploadFile = (%q, %v), want uploaded-id", id, err)
+	}
+	if !fake.done {
+		t.Error("empty upload was not finalized")
+	}
+}
+
+func TestChunkSizeAlignment(t *testing.T) {
+	cases := []struct{ in, want int64 }{
+		{0, DefaultUploadChunkSize},
+		{UploadChunkAlign, UploadChunkAlign},
+		{UploadChunkAlign + 1, 2 * UploadChunkAlign},
+		{10 << 20, 10 << 20},
+	}
+	for _, tc := range cases {
+		ds := &Service{ChunkSize: tc.in}
+		if got := ds.chunkSize(); got != tc.want {
+			t.Errorf("chunkSize(%d) = %d, want %d", tc.in, got, tc.want)
+		}
+	}
+}
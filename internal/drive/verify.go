@@ -0,0 +1,115 @@
+package drive
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// VerifyStatus is the outcome of comparing one local file with Drive.
+type VerifyStatus string
+
+// Verification outcomes.
+const (
+	VerifyOK       VerifyStatus = "ok"
+	VerifyMismatch VerifyStatus = "mismatch"
+	VerifyMissing  VerifyStatus = "missing" // on Drive but not on disk
+	VerifyExtra    VerifyStatus = "extra"   // on disk but not on Drive
+	VerifySkipped  VerifyStatus = "skipped" // Drive has no checksum for it
+	VerifyError    VerifyStatus = "error"
+)
+
+// VerifyResult describes the verification of one file. Path is relative to
+// the local root being verified.
+type VerifyResult struct {
+	Path   string
+	Status VerifyStatus
+	Detail string
+}
+
+// VerifyFile compares the local file at localPath with f, which must have
+// been fetched with its checksum fields.
+func VerifyFile(localPath string, f *drive.File) VerifyResult {
+	res := VerifyResult{Path: localPath}
+	remote := RemoteChecksums(f)
+	if remote.IsZero() {
+		res.Status, res.Detail = VerifySkipped, "no checksum on Drive"
+		return res
+	}
+
+	local, err := HashFile(localPath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		res.Status = VerifyMissing
+		return res
+	case err != nil:
+		res.Status, res.Detail = VerifyError, err.Error()
+		return res
+	}
+
+	var mismatch *ChecksumMismatchError
+	if err := CompareChecksums(f.Name, local, remote); errors.As(err, &mismatch) {
+		res.Status = VerifyMismatch
+		res.Detail = mismatch.Algorithm + " " + mismatch.Local + " != " + mismatch.Remote
+		return res
+	}
+	res.Status = VerifyOK
+	return res
+}
+
+// GetFileChecksums returns the metadata of a file, including its checksums.
+func (ds *Service) GetFileChecksums(ctx context.Context, fileID string) (*drive.File, error) {
+	return ds.Backend.GetFile(ctx, fileID, "id, name, mimeType, size, "+checksumFields)
+}
+
+// VerifyTree compares the local directory localRoot with the Drive folder
+// folderID recursively, calling fn with the result for every file. Local
+// files with no counterpart on Drive are reported as VerifyExtra; local
+// exports of Google Workspace files are recognised by their extension.
+func (ds *Service) VerifyTree(ctx context.Context, localRoot, folderID string, fn func(VerifyResult)) error {
+	return ds.verifyDir(ctx, ds.ExportCatalog(ctx), localRoot, "", folderID, fn)
+}
+
+func (ds *Service) verifyDir(ctx context.Context, catalog *ExportCatalog, localRoot, rel, folderID string, fn func(VerifyResult)) error {
+	seen := make(map[string]bool)
+	for item, err := range ds.FolderItems(ctx, folderID) {
+		if err != nil {
+			return err
+		}
+		itemRel := filepath.Join(rel, item.Name)
+		seen[item.Name] = true
+
+		if item.MimeType == DriveFolderMimeType {
+			if err := ds.verifyDir(ctx, catalog, localRoot, itemRel, item.Id, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if ds.IsGoogleWorkspaceFile(item) {
+			for _, format := range catalog.FormatNames(item.MimeType) {
+				seen[ExportFilename(item.Name, format)] = true
+			}
+		}
+
+		res := VerifyFile(filepath.Join(localRoot, itemRel), item)
+		res.Path = itemRel
+		fn(res)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(localRoot, rel))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if !seen[e.Name()] {
+			fn(VerifyResult{Path: filepath.Join(rel, e.Name()), Status: VerifyExtra})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,454 @@
+package drivetest
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"google.golang.org/api/drive/v3"
+)
+
+// This file implements the subset of the Drive query language
+// (https://developers.google.com/drive/api/guides/ref-search-terms) that
+// gdrive generates: and/or/not, parentheses, comparisons on names, MIME
+// types, booleans and times, "contains", "in" collections and
+// "has { key=... and value=... }" on properties.
+
+// predicate reports whether a file matches a query term.
+type predicate func(s *Server, f *file) bool
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+	tokLBrace
+	tokRBrace
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func tokenize(q string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(q); {
+		c := q[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(':
+			toks = append(toks, token{tokLParen, "("})
+			i++
+		case c == ')':
+			toks = append(toks, token{tokRParen, ")"})
+			i++
+		case c == '{':
+			toks = append(toks, token{tokLBrace, "{"})
+			i++
+		case c == '}':
+			toks = append(toks, token{tokRBrace, "}"})
+			i++
+		case c == '\'':
+			var b strings.Builder
+			i++
+			for ; i < len(q) && q[i] != '\''; i++ {
+				if q[i] == '\\' && i+1 < len(q) {
+					i++
+				}
+				b.WriteByte(q[i])
+			}
+			if i >= len(q) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			i++
+			toks = append(toks, token{tokString, b.String()})
+		case strings.ContainsRune("=!<>", rune(c)):
+			op := string(c)
+			if i+1 < len(q) && q[i+1] == '=' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, fmt.Errorf("unexpected '!'")
+			}
+			toks = append(toks, token{tokOp, op})
+			i += len(op)
+		case unicode.IsLetter(rune(c)) || c == '_':
+			j := i
+			for j < len(q) && (unicode.IsLetter(rune(q[j])) || unicode.IsDigit(rune(q[j])) || q[j] == '_' || q[j] == '.') {
+				j++
+			}
+			toks = append(toks, token{tokIdent, q[i:j]})
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q", c)
+		}
+	}
+	return append(toks, token{kind: tokEOF}), nil
+}
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+// parseQuery compiles a Drive query. An empty query matches everything.
+func parseQuery(q string) (predicate, error) {
+	if strings.TrimSpace(q) == "" {
+		return func(*Server, *file) bool { return true }, nil
+	}
+	toks, err := tokenize(q)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query %q: %w", q, err)
+	}
+	p := &parser{toks: toks}
+	pred, err := p.or()
+	if err == nil && p.peek().kind != tokEOF {
+		err = fmt.Errorf("unexpected %q", p.peek().text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid query %q: %w", q, err)
+	}
+	return pred, nil
+}
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) keyword(word string) bool {
+	if t := p.peek(); t.kind == tokIdent && t.text == word {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(kind tokenKind, what string) (token, error) {
+	t := p.next()
+	if t.kind != kind {
+		return t, fmt.Errorf("expected %s, got %q", what, t.text)
+	}
+	return t, nil
+}
+
+func (p *parser) or() (predicate, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(s *Server, f *file) bool { return l(s, f) || right(s, f) }
+	}
+	return left, nil
+}
+
+func (p *parser) and() (predicate, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(s *Server, f *file) bool { return l(s, f) && right(s, f) }
+	}
+	return left, nil
+}
+
+func (p *parser) unary() (predicate, error) {
+	if p.keyword("not") {
+		inner, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(s *Server, f *file) bool { return !inner(s, f) }, nil
+	}
+	if p.peek().kind == tokLParen {
+		p.next()
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokRParen, "')'"); err != nil {
+			return nil, err
+		}
+		return inner, nil
+	}
+	return p.term()
+}
+
+// term parses "'value' in collection", "field contains 'value'",
+// "field op value" and "field has { key='k' and value='v' }".
+func (p *parser) term() (predicate, error) {
+	if t := p.peek(); t.kind == tokString {
+		p.next()
+		if !p.keyword("in") {
+			return nil, fmt.Errorf("expected 'in' after %q", t.text)
+		}
+		coll, err := p.expect(tokIdent, "collection")
+		if err != nil {
+			return nil, err
+		}
+		return inCollection(t.text, coll.text)
+	}
+
+	field, err := p.expect(tokIdent, "field")
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case p.keyword("contains"):
+		v, err := p.expect(tokString, "string")
+		if err != nil {
+			return nil, err
+		}
+		return contains(field.text, v.text)
+	case p.keyword("has"):
+		return p.has(field.text)
+	}
+
+	op, err := p.expect(tokOp, "operator")
+	if err != nil {
+		return nil, err
+	}
+	v := p.next()
+	switch v.kind {
+	case tokString, tokIdent:
+	default:
+		return nil, fmt.Errorf("expected value after %s %s", field.text, op.text)
+	}
+	return compare(field.text, op.text, v)
+}
+
+func (p *parser) has(field string) (predicate, error) {
+	if field != "properties" && field != "appProperties" {
+		return nil, fmt.Errorf("%s does not support has", field)
+	}
+	if _, err := p.expect(tokLBrace, "'{'"); err != nil {
+		return nil, err
+	}
+	var key, value string
+	for _, part := range []struct {
+		name string
+		dst  *string
+	}{{"key", &key}, {"value", &value}} {
+		if !p.keyword(part.name) {
+			return nil, fmt.Errorf("expected %s in has clause", part.name)
+		}
+		if op, err := p.expect(tokOp, "'='"); err != nil || op.text != "=" {
+			return nil, fmt.Errorf("expected = after %s", part.name)
+		}
+		v, err := p.expect(tokString, "string")
+		if err != nil {
+			return nil, err
+		}
+		*part.dst = v.text
+		if part.name == "key" && !p.keyword("and") {
+			return nil, fmt.Errorf("expected and in has clause")
+		}
+	}
+	if _, err := p.expect(tokRBrace, "'}'"); err != nil {
+		return nil, err
+	}
+	return func(_ *Server, f *file) bool {
+		props := f.meta.Properties
+		if field == "appProperties" {
+			props = f.meta.AppProperties
+		}
+		v, ok := props[key]
+		return ok && v == value
+	}, nil
+}
+
+func inCollection(value, coll string) (predicate, error) {
+	switch coll {
+	case "parents":
+		return func(_ *Server, f *file) bool { return slices.Contains(f.meta.Parents, value) }, nil
+	case "owners":
+		return func(_ *Server, f *file) bool {
+			for _, o := range f.meta.Owners {
+				if o.EmailAddress == value || (value == "me" && o.EmailAddress == Me.EmailAddress) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case "writers", "readers":
+		roles := map[string]bool{"owner": true, "organizer": true, "fileOrganizer": true, "writer": true}
+		if coll == "readers" {
+			roles["commenter"], roles["reader"] = true, true
+		}
+		return func(s *Server, f *file) bool {
+			for _, perm := range f.perms {
+				if roles[perm.Role] && (perm.EmailAddress == value || perm.Domain == value) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported collection %q", coll)
+}
+
+func contains(field, value string) (predicate, error) {
+	value = strings.ToLower(value)
+	switch field {
+	case "name", "mimeType":
+		return func(_ *Server, f *file) bool {
+			return strings.Contains(strings.ToLower(stringField(f, field)), value)
+		}, nil
+	case "fullText":
+		return func(_ *Server, f *file) bool {
+			for _, text := range []string{f.meta.Name, f.meta.Description, string(f.content)} {
+				if strings.Contains(strings.ToLower(text), value) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	}
+	return nil, fmt.Errorf("%s does not support contains", field)
+}
+
+func stringField(f *file, field string) string {
+	switch field {
+	case "name":
+		return f.meta.Name
+	case "mimeType":
+		return f.meta.MimeType
+	case "visibility":
+		return visibility(f)
+	case "shortcutDetails.targetId":
+		if f.meta.ShortcutDetails != nil {
+			return f.meta.ShortcutDetails.TargetId
+		}
+	}
+	return ""
+}
+
+// visibility derives the Drive visibility of a file from its permissions.
+func visibility(f *file) string {
+	vis := "limited"
+	for _, p := range f.perms {
+		switch {
+		case p.Type == "anyone" && p.AllowFileDiscovery:
+			return "anyoneCanFind"
+		case p.Type == "anyone":
+			vis = "anyoneWithLink"
+		case p.Type == "domain" && vis == "limited":
+			if p.AllowFileDiscovery {
+				vis = "domainCanFind"
+			} else {
+				vis = "domainWithLink"
+			}
+		}
+	}
+	return vis
+}
+
+func boolField(f *file, field string) (bool, bool) {
+	switch field {
+	case "trashed":
+		return f.meta.Trashed, true
+	case "starred":
+		return f.meta.Starred, true
+	case "sharedWithMe":
+		return f.meta.SharedWithMeTime != "", true
+	}
+	return false, false
+}
+
+func timeField(f *file, field string) (string, bool) {
+	switch field {
+	case "modifiedTime":
+		return f.meta.ModifiedTime, true
+	case "createdTime":
+		return f.meta.CreatedTime, true
+	case "viewedByMeTime":
+		return f.meta.ViewedByMeTime, true
+	case "trashedTime":
+		return f.meta.TrashedTime, true
+	case "sharedWithMeTime":
+		return f.meta.SharedWithMeTime, true
+	}
+	return "", false
+}
+
+func compare(field, op string, v token) (predicate, error) {
+	if _, ok := boolField(&file{meta: &drive.File{}}, field); ok {
+		if v.kind != tokIdent || (v.text != "true" && v.text != "false") || (op != "=" && op != "!=") {
+			return nil, fmt.Errorf("%s only supports = true|false", field)
+		}
+		want := v.text == "true"
+		return func(_ *Server, f *file) bool {
+			got, _ := boolField(f, field)
+			return (got == want) == (op == "=")
+		}, nil
+	}
+
+	if _, ok := timeField(&file{meta: &drive.File{}}, field); ok {
+		want, err := time.Parse(time.RFC3339, v.text)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid time %q", field, v.text)
+		}
+		return func(_ *Server, f *file) bool {
+			raw, _ := timeField(f, field)
+			got, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return false
+			}
+			return compareOrdered(got.Compare(want), op)
+		}, nil
+	}
+
+	switch field {
+	case "name", "mimeType", "visibility", "shortcutDetails.targetId":
+		if v.kind != tokString || (op != "=" && op != "!=") {
+			return nil, fmt.Errorf("%s only supports = and != with a string", field)
+		}
+		return func(_ *Server, f *file) bool {
+			return (stringField(f, field) == v.text) == (op == "=")
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported field %q", field)
+}
+
+func compareOrdered(cmp int, op string) bool {
+	switch op {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
@@ -0,0 +1,1599 @@
+// Package drivetest provides an in-memory fake of the Google Drive v3 API
+// for offline tests.
+//
+// The fake is stateful: it models the folder tree through parents, the
+// trash, file content with revisions and checksums, permissions, comments,
+// Shared Drives and the changes feed, and accepts the simple, multipart and
+// resumable upload protocols. Point a Drive client at it with API:
+//
+//	srv := drivetest.NewServer(t)
+//	folder := srv.AddFolder("root", "Reports")
+//	srv.AddFile(folder, "q3.pdf", []byte("%PDF"))
+//	ds := drive.NewService(srv.API(t))
+//	ds.HTTP = srv.Client()
+//
+// Field selectors are ignored: responses always carry every known field.
+package drivetest
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/option"
+)
+
+const (
+	// RootID is the ID of the My Drive root folder. Drive also accepts the
+	// alias "root", which the fake uses as the real ID.
+	RootID = "root"
+
+	folderMimeType = "application/vnd.google-apps.folder"
+	googleAppsMime = "application/vnd.google-apps."
+)
+
+// Me is the authenticated user: the owner of every file the fake creates.
+var Me = drive.User{DisplayName: "Test User", EmailAddress: "me@example.com", Me: true, Kind: "drive#user"}
+
+// Epoch is the fake clock's initial time. The clock advances by a second
+// on every mutation so that modification times are strictly ordered.
+var Epoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Server is a fake Drive API server.
+type Server struct {
+	// URL is the base URL of the server, e.g. "http://127.0.0.1:1234".
+	URL string
+
+	ts       *httptest.Server
+	mu       sync.Mutex
+	now      time.Time
+	nextID   int
+	files    map[string]*file
+	drives   map[string]*drive.Drive
+	changes  []*drive.Change
+	sessions map[string]*uploadSession
+	failures []failure
+	// exportLimit, when positive, is the largest export Files.Export serves.
+	exportLimit int
+}
+
+// file is the stored state of one Drive item.
+type file struct {
+	meta      *drive.File
+	content   []byte
+	revisions []*revision
+	perms     []*drive.Permission
+	comments  []*drive.Comment
+}
+
+type revision struct {
+	meta    *drive.Revision
+	content []byte
+}
+
+type uploadSession struct {
+	fileID      string // set for updates
+	meta        *drive.File
+	contentType string
+	data        []byte
+}
+
+type failure struct {
+	code   int
+	reason string
+}
+
+// NewServer starts a fake Drive server holding an empty My Drive. It is
+// closed when the test ends.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	s := &Server{
+		now:      Epoch,
+		files:    make(map[string]*file),
+		drives:   make(map[string]*drive.Drive),
+		sessions: make(map[string]*uploadSession),
+	}
+	s.files[RootID] = &file{meta: &drive.File{
+		Id:           RootID,
+		Name:         "My Drive",
+		MimeType:     folderMimeType,
+		CreatedTime:  stamp(Epoch),
+		ModifiedTime: stamp(Epoch),
+		Owners:       []*drive.User{&Me},
+	}}
+	s.ts = httptest.NewServer(s)
+	s.URL = s.ts.URL
+	t.Cleanup(s.ts.Close)
+	return s
+}
+
+// Client returns an HTTP client for the server.
+func (s *Server) Client() *http.Client {
+	return s.ts.Client()
+}
+
+// API returns a Drive API client talking to the server.
+func (s *Server) API(t testing.TB) *drive.Service {
+	t.Helper()
+	api, err := drive.NewService(t.Context(),
+		option.WithEndpoint(s.URL+"/"),
+		option.WithHTTPClient(s.Client()))
+	if err != nil {
+		t.Fatalf("drivetest: %v", err)
+	}
+	return api
+}
+
+// FailNext makes the next request fail with the given HTTP status and
+// Drive error reason, e.g. 429 "rateLimitExceeded".
+func (s *Server) FailNext(code int, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, failure{code, reason})
+}
+
+// SetExportLimit makes Files.Export refuse exports larger than n bytes with
+// exportSizeLimitExceeded, as Drive does above 10 MB. Export links are not
+// limited. Zero removes the limit.
+func (s *Server) SetExportLimit(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.exportLimit = n
+}
+
+// Advance moves the fake clock forward.
+func (s *Server) Advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = s.now.Add(d)
+}
+
+// Now returns the current time of the fake clock.
+func (s *Server) Now() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now
+}
+
+// AddFolder creates a folder and returns its ID.
+func (s *Server) AddFolder(parentID, name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.create(&drive.File{Name: name, MimeType: folderMimeType, Parents: []string{parentID}}, nil).meta.Id
+}
+
+// AddFile creates a binary file with the given content and returns its
+// ID. The MIME type is derived from the name's extension.
+func (s *Server) AddFile(parentID, name string, content []byte) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	meta := &drive.File{Name: name, MimeType: mimeTypeFor(name), Parents: []string{parentID}}
+	return s.create(meta, content).meta.Id
+}
+
+// AddDoc creates a Google Workspace file of mimeType, e.g. a Google Doc.
+// Exports return text in whatever format is requested.
+func (s *Server) AddDoc(parentID, name, mimeType string, text []byte) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.create(&drive.File{Name: name, MimeType: mimeType, Parents: []string{parentID}}, text).meta.Id
+}
+
+// AddShortcut creates a shortcut to targetID and returns its ID.
+func (s *Server) AddShortcut(parentID, name, targetID string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	meta := &drive.File{Name: name, MimeType: "application/vnd.google-apps.shortcut", Parents: []string{parentID},
+		ShortcutDetails: &drive.FileShortcutDetails{TargetId: targetID, TargetMimeType: s.files[targetID].meta.MimeType}}
+	return s.create(meta, nil).meta.Id
+}
+
+// AddSharedDrive creates a Shared Drive and returns its ID, which is also
+// the ID of its root folder.
+func (s *Server) AddSharedDrive(name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.newID("drive")
+	s.drives[id] = &drive.Drive{Id: id, Name: name, CreatedTime: stamp(s.now), Kind: "drive#drive"}
+	s.files[id] = &file{meta: &drive.File{
+		Id: id, Name: name, MimeType: folderMimeType, DriveId: id,
+		CreatedTime: stamp(s.now), ModifiedTime: stamp(s.now),
+	}}
+	return id
+}
+
+// Update replaces the content of a file, adding a revision.
+func (s *Server) Update(fileID string, content []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if f := s.files[fileID]; f != nil {
+		s.setContent(f, content)
+		s.recordChange(f, false)
+	}
+}
+
+// Share adds a permission to a file, as if another user had shared it.
+func (s *Server) Share(fileID string, perm drive.Permission) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f := s.files[fileID]
+	if f == nil {
+		return ""
+	}
+	return s.addPermission(f, &perm).Id
+}
+
+// ShareWithMe makes a file look like owner created it and shared it with
+// the user: owner becomes its owner and sharing user, and the file shows up
+// in sharedWithMe searches.
+func (s *Server) ShareWithMe(fileID string, owner drive.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f := s.files[fileID]
+	if f == nil {
+		return
+	}
+	f.meta.Owners = []*drive.User{&owner}
+	f.meta.SharingUser = &owner
+	f.meta.SharedWithMeTime = stamp(s.now)
+}
+
+// AddComment adds a comment to a file, as if author had written it, and
+// returns its ID. quote is the text the comment is anchored to, if any.
+func (s *Server) AddComment(fileID string, author drive.User, content, quote string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f := s.files[fileID]
+	if f == nil {
+		return ""
+	}
+	c := &drive.Comment{Content: content}
+	if quote != "" {
+		c.QuotedFileContent = &drive.CommentQuotedFileContent{MimeType: "text/plain", Value: quote}
+	}
+	return s.addComment(f, c, &author).Id
+}
+
+// File returns a copy of the metadata of a file, or nil if it does not
+// exist (or was permanently deleted).
+func (s *Server) File(id string) *drive.File {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if f := s.files[id]; f != nil {
+		return s.view(f)
+	}
+	return nil
+}
+
+// Content returns the current content of a file.
+func (s *Server) Content(id string) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if f := s.files[id]; f != nil {
+		return bytes.Clone(f.content)
+	}
+	return nil
+}
+
+// Children returns the IDs of the items in a folder, trashed or not.
+func (s *Server) Children(folderID string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ids []string
+	for id, f := range s.files {
+		if slices.Contains(f.meta.Parents, folderID) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Revisions returns copies of the revisions of a file, oldest first.
+func (s *Server) Revisions(id string) []*drive.Revision {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var revs []*drive.Revision
+	if f := s.files[id]; f != nil {
+		for _, r := range f.revisions {
+			c := *r.meta
+			revs = append(revs, &c)
+		}
+	}
+	return revs
+}
+
+// Permissions returns copies of the permissions of a file.
+func (s *Server) Permissions(id string) []*drive.Permission {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var perms []*drive.Permission
+	if f := s.files[id]; f != nil {
+		for _, p := range f.perms {
+			c := *p
+			perms = append(perms, &c)
+		}
+	}
+	return perms
+}
+
+// --- state helpers; the caller holds s.mu ---
+
+func stamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func (s *Server) tick() string {
+	s.now = s.now.Add(time.Second)
+	return stamp(s.now)
+}
+
+func (s *Server) newID(prefix string) string {
+	s.nextID++
+	return fmt.Sprintf("%s-%d", prefix, s.nextID)
+}
+
+func mimeTypeFor(name string) string {
+	if t := mime.TypeByExtension(path.Ext(name)); t != "" {
+		mt, _, _ := mime.ParseMediaType(t)
+		return mt
+	}
+	return "application/octet-stream"
+}
+
+func isGoogleApps(mimeType string) bool {
+	return strings.HasPrefix(mimeType, googleAppsMime)
+}
+
+// view returns the metadata a client sees for f.
+func (s *Server) view(f *file) *drive.File {
+	c := *f.meta
+	c.Kind = "drive#file"
+	c.WebViewLink = "https://drive.google.com/file/d/" + c.Id + "/view"
+	if c.MimeType == folderMimeType {
+		c.WebViewLink = "https://drive.google.com/drive/folders/" + c.Id
+	}
+	if len(f.perms) > 1 {
+		c.Shared = true
+	}
+	if isGoogleApps(c.MimeType) && c.MimeType != folderMimeType {
+		c.ExportLinks = map[string]string{}
+		for _, mt := range exportFormats[c.MimeType] {
+			c.ExportLinks[mt] = fmt.Sprintf("%s/feeds/download/%s?mimeType=%s", s.URL, c.Id, mt)
+		}
+	}
+	return &c
+}
+
+// exportFormats lists the export MIME types offered per Workspace type.
+var exportFormats = map[string][]string{
+	"application/vnd.google-apps.document": {
+		"application/pdf", "text/plain", "text/markdown", "text/html",
+		"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+		"application/vnd.oasis.opendocument.text", "application/rtf",
+		"application/epub+zip", "application/zip",
+	},
+	"application/vnd.google-apps.spreadsheet": {
+		"application/pdf", "text/csv", "text/tab-separated-values",
+		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		"application/x-vnd.oasis.opendocument.spreadsheet", "application/zip",
+	},
+	"application/vnd.google-apps.presentation": {
+		"application/pdf", "text/plain",
+		"application/vnd.openxmlformats-officedocument.presentationml.presentation",
+		"application/vnd.oasis.opendocument.presentation",
+	},
+	"application/vnd.google-apps.drawing": {
+		"application/pdf", "image/png", "image/jpeg", "image/svg+xml",
+	},
+	"application/vnd.google-apps.script": {"application/vnd.google-apps.script+json"},
+	"application/vnd.google-apps.form":   {"application/zip"},
+}
+
+// create stores a new item described by meta.
+func (s *Server) create(meta *drive.File, content []byte) *file {
+	now := s.tick()
+	m := *meta
+	m.Id = s.newID("file")
+	if m.MimeType == "" {
+		m.MimeType = mimeTypeFor(m.Name)
+	}
+	if len(m.Parents) == 0 {
+		m.Parents = []string{RootID}
+	}
+	if parent := s.files[m.Parents[0]]; parent != nil {
+		m.DriveId = parent.meta.DriveId
+	}
+	if m.CreatedTime == "" {
+		m.CreatedTime = now
+	}
+	m.ModifiedTime = now
+	m.LastModifyingUser = &Me
+	if m.DriveId == "" {
+		m.Owners = []*drive.User{&Me}
+	}
+	f := &file{meta: &m}
+	s.files[m.Id] = f
+	if m.DriveId == "" {
+		s.addPermission(f, &drive.Permission{Type: "user", Role: "owner", EmailAddress: Me.EmailAddress, DisplayName: Me.DisplayName})
+	}
+	if m.MimeType != folderMimeType {
+		s.setContent(f, content)
+	}
+	s.recordChange(f, false)
+	return f
+}
+
+// setContent replaces the content of f and records a revision.
+func (s *Server) setContent(f *file, content []byte) {
+	f.content = bytes.Clone(content)
+	m := f.meta
+	m.ModifiedTime = s.tick()
+	m.Version++
+	if !isGoogleApps(m.MimeType) {
+		m.Size = int64(len(content))
+		md5sum, sha1sum, sha256sum := md5.Sum(content), sha1.Sum(content), sha256.Sum256(content)
+		m.Md5Checksum = hex.EncodeToString(md5sum[:])
+		m.Sha1Checksum = hex.EncodeToString(sha1sum[:])
+		m.Sha256Checksum = hex.EncodeToString(sha256sum[:])
+	}
+	rev := &drive.Revision{
+		Id:                strconv.Itoa(len(f.revisions) + 1),
+		Kind:              "drive#revision",
+		MimeType:          m.MimeType,
+		ModifiedTime:      m.ModifiedTime,
+		LastModifyingUser: &Me,
+	}
+	if !isGoogleApps(m.MimeType) {
+		rev.Size = m.Size
+		rev.Md5Checksum = m.Md5Checksum
+		rev.OriginalFilename = m.Name
+	} else {
+		rev.ExportLinks = map[string]string{}
+		for _, mt := range exportFormats[m.MimeType] {
+			rev.ExportLinks[mt] = fmt.Sprintf("%s/files/%s/revisions/%s/export?mimeType=%s", s.URL, m.Id, rev.Id, mt)
+		}
+	}
+	f.revisions = append(f.revisions, &revision{meta: rev, content: f.content})
+	m.HeadRevisionId = rev.Id
+}
+
+func (s *Server) addPermission(f *file, p *drive.Permission) *drive.Permission {
+	c := *p
+	c.Kind = "drive#permission"
+	switch {
+	case c.Id != "":
+	case c.Type == "anyone":
+		c.Id = "anyoneWithLink"
+	default:
+		c.Id = s.newID("perm")
+	}
+	f.perms = append(f.perms, &c)
+	return &c
+}
+
+func (s *Server) addComment(f *file, c *drive.Comment, author *drive.User) *drive.Comment {
+	now := s.tick()
+	c.Id = s.newID("comment")
+	c.Kind = "drive#comment"
+	c.Author = author
+	c.HtmlContent = html.EscapeString(c.Content)
+	c.CreatedTime, c.ModifiedTime = now, now
+	c.Replies = []*drive.Reply{}
+	f.comments = append(f.comments, c)
+	return c
+}
+
+// commentAuthor is how comments show the user: Drive leaves out the email
+// address of comment authors.
+func commentAuthor() *drive.User {
+	return &drive.User{Kind: Me.Kind, DisplayName: Me.DisplayName, Me: true}
+}
+
+// recordChange appends f to the changes feed.
+func (s *Server) recordChange(f *file, removed bool) {
+	ch := &drive.Change{
+		Kind:       "drive#change",
+		ChangeType: "file",
+		FileId:     f.meta.Id,
+		Removed:    removed,
+		Time:       stamp(s.now),
+		DriveId:    f.meta.DriveId,
+	}
+	if !removed {
+		ch.File = s.view(f)
+	}
+	s.changes = append(s.changes, ch)
+}
+
+// descendants returns the items below folderID, depth first.
+func (s *Server) descendants(folderID string) []*file {
+	var out []*file
+	for _, f := range s.files {
+		if slices.Contains(f.meta.Parents, folderID) {
+			out = append(out, f)
+			if f.meta.MimeType == folderMimeType {
+				out = append(out, s.descendants(f.meta.Id)...)
+			}
+		}
+	}
+	return out
+}
+
+// setTrashed moves f, and everything below it, to or out of the trash.
+func (s *Server) setTrashed(f *file, trashed bool) {
+	now := s.tick()
+	apply := func(c *file, explicit bool) {
+		if trashed {
+			if c.meta.Trashed {
+				return
+			}
+			c.meta.Trashed, c.meta.ExplicitlyTrashed = true, explicit
+			c.meta.TrashedTime, c.meta.TrashingUser = now, &Me
+		} else {
+			if !explicit && c.meta.ExplicitlyTrashed {
+				return // trashed on its own before its folder
+			}
+			c.meta.Trashed, c.meta.ExplicitlyTrashed = false, false
+			c.meta.TrashedTime, c.meta.TrashingUser = "", nil
+		}
+		s.recordChange(c, false)
+	}
+	apply(f, true)
+	for _, c := range s.descendants(f.meta.Id) {
+		apply(c, false)
+	}
+}
+
+// remove permanently deletes f and everything below it.
+func (s *Server) remove(f *file) {
+	for _, c := range s.descendants(f.meta.Id) {
+		delete(s.files, c.meta.Id)
+		s.recordChange(c, true)
+	}
+	delete(s.files, f.meta.Id)
+	s.recordChange(f, true)
+}
+
+// --- HTTP ---
+
+// apiError is the JSON error body of the Drive API.
+type apiError struct {
+	code    int
+	reason  string
+	message string
+}
+
+func errNotFound(kind, id string) *apiError {
+	return &apiError{http.StatusNotFound, "notFound", fmt.Sprintf("%s not found: %s.", kind, id)}
+}
+
+func errBadRequest(reason, format string, args ...any) *apiError {
+	return &apiError{http.StatusBadRequest, reason, fmt.Sprintf(format, args...)}
+}
+
+func writeError(w http.ResponseWriter, e *apiError) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(e.code)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"error": map[string]any{
+			"code":    e.code,
+			"message": e.message,
+			"errors":  []map[string]string{{"domain": "global", "reason": e.reason, "message": e.message}},
+		},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.failures) > 0 {
+		f := s.failures[0]
+		s.failures = s.failures[1:]
+		writeError(w, &apiError{f.code, f.reason, "injected failure"})
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if e := s.route(w, r, parts); e != nil {
+		writeError(w, e)
+	}
+}
+
+func (s *Server) route(w http.ResponseWriter, r *http.Request, p []string) *apiError {
+	m := r.Method
+	switch {
+	case len(p) >= 3 && p[0] == "upload" && p[1] == "drive" && p[2] == "v3":
+		return s.routeUpload(w, r, p[3:])
+	case len(p) == 2 && p[0] == "upload" && p[1] != "" && m == http.MethodPut:
+		return s.putSession(w, r, p[1])
+
+	case len(p) == 1 && p[0] == "files" && m == http.MethodGet:
+		return s.listFiles(w, r)
+	case len(p) == 1 && p[0] == "files" && m == http.MethodPost:
+		return s.createFile(w, r, nil, "")
+	case len(p) == 2 && p[0] == "files" && m == http.MethodGet:
+		return s.getFile(w, r, p[1])
+	case len(p) == 2 && p[0] == "files" && m == http.MethodPatch:
+		return s.updateFile(w, r, p[1], nil, "")
+	case len(p) == 2 && p[0] == "files" && p[1] == "trash" && m == http.MethodDelete:
+		return s.emptyTrash(w)
+	case len(p) == 2 && p[0] == "files" && m == http.MethodDelete:
+		return s.deleteFile(w, p[1])
+	case len(p) == 3 && p[0] == "files" && p[2] == "copy" && m == http.MethodPost:
+		return s.copyFile(w, r, p[1])
+	case len(p) == 3 && p[0] == "files" && p[2] == "export" && m == http.MethodGet:
+		return s.exportFile(w, r, p[1], "", s.exportLimit)
+	case len(p) == 3 && p[0] == "feeds" && p[1] == "download" && m == http.MethodGet:
+		return s.exportFile(w, r, p[2], "", 0)
+
+	case len(p) >= 3 && p[0] == "files" && p[2] == "permissions":
+		return s.routePermissions(w, r, p[1], p[3:])
+	case len(p) >= 3 && p[0] == "files" && p[2] == "revisions":
+		return s.routeRevisions(w, r, p[1], p[3:])
+	case len(p) >= 3 && p[0] == "files" && p[2] == "comments":
+		return s.routeComments(w, r, p[1], p[3:])
+
+	case len(p) == 2 && p[0] == "changes" && p[1] == "startPageToken":
+		writeJSON(w, http.StatusOK, &drive.StartPageToken{Kind: "drive#startPageToken", StartPageToken: strconv.Itoa(len(s.changes) + 1)})
+		return nil
+	case len(p) == 1 && p[0] == "changes":
+		return s.listChanges(w, r)
+
+	case len(p) == 1 && p[0] == "drives" && m == http.MethodGet:
+		return s.listDrives(w, r)
+	case len(p) == 2 && p[0] == "drives" && m == http.MethodGet:
+		d := s.drives[p[1]]
+		if d == nil {
+			return errNotFound("Shared drive", p[1])
+		}
+		writeJSON(w, http.StatusOK, d)
+		return nil
+
+	case len(p) == 1 && p[0] == "about":
+		writeJSON(w, http.StatusOK, s.about())
+		return nil
+	}
+	return &apiError{http.StatusNotFound, "notFound", "unsupported request " + m + " " + r.URL.Path}
+}
+
+// lookup returns a stored file, accepting the "root" alias.
+func (s *Server) lookup(id string) (*file, *apiError) {
+	f := s.files[id]
+	if f == nil {
+		return nil, errNotFound("File", id)
+	}
+	return f, nil
+}
+
+func (s *Server) about() *drive.About {
+	exports := map[string][]string{}
+	for k, v := range exportFormats {
+		exports[k] = v
+	}
+	return &drive.About{
+		Kind:          "drive#about",
+		User:          &Me,
+		ExportFormats: exports,
+		ImportFormats: map[string][]string{
+			"text/markdown": {"application/vnd.google-apps.document"},
+			"text/plain":    {"application/vnd.google-apps.document"},
+			"text/csv":      {"application/vnd.google-apps.spreadsheet"},
+		},
+	}
+}
+
+func (s *Server) listFiles(w http.ResponseWriter, r *http.Request) *apiError {
+	q := r.URL.Query()
+	pred, err := parseQuery(q.Get("q"))
+	if err != nil {
+		return errBadRequest("invalid", "%v", err)
+	}
+	driveID := q.Get("driveId")
+
+	var matches []*file
+	for _, f := range s.files {
+		if f.meta.Id == RootID || (driveID != "" && f.meta.DriveId != driveID) || s.drives[f.meta.Id] != nil {
+			continue
+		}
+		if pred(s, f) {
+			matches = append(matches, f)
+		}
+	}
+	if err := sortFiles(matches, q.Get("orderBy")); err != nil {
+		return err
+	}
+
+	page, next, e := paginate(len(matches), q.Get("pageSize"), q.Get("pageToken"), 100, 1000)
+	if e != nil {
+		return e
+	}
+	list := &drive.FileList{Kind: "drive#fileList", Files: []*drive.File{}, NextPageToken: next}
+	for _, f := range matches[page.start:page.end] {
+		list.Files = append(list.Files, s.view(f))
+	}
+	writeJSON(w, http.StatusOK, list)
+	return nil
+}
+
+// sortFiles orders files by a Drive orderBy clause: comma-separated keys,
+// each optionally followed by "desc". Ties are broken by ID.
+func sortFiles(files []*file, orderBy string) *apiError {
+	type key struct {
+		name string
+		desc bool
+	}
+	var keys []key
+	for _, part := range strings.Split(orderBy, ",") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		k := key{name: fields[0], desc: len(fields) > 1 && fields[1] == "desc"}
+		switch k.name {
+		case "folder", "name", "name_natural", "modifiedTime", "createdTime", "recency",
+			"modifiedByMeTime", "viewedByMeTime", "trashedTime", "quotaBytesUsed", "starred", "sharedWithMeTime":
+		default:
+			return errBadRequest("invalid", "invalid orderBy key %q", k.name)
+		}
+		keys = append(keys, k)
+	}
+	value := func(f *file, k string) string {
+		m := f.meta
+		switch k {
+		case "folder":
+			if m.MimeType == folderMimeType {
+				return "0"
+			}
+			return "1"
+		case "name", "name_natural":
+			return strings.ToLower(m.Name)
+		case "createdTime":
+			return m.CreatedTime
+		case "trashedTime":
+			return m.TrashedTime
+		case "viewedByMeTime":
+			return m.ViewedByMeTime
+		case "sharedWithMeTime":
+			return m.SharedWithMeTime
+		case "quotaBytesUsed":
+			return fmt.Sprintf("%020d", m.Size)
+		case "starred":
+			return strconv.FormatBool(m.Starred)
+		}
+		return m.ModifiedTime
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		for _, k := range keys {
+			a, b := value(files[i], k.name), value(files[j], k.name)
+			if a != b {
+				return (a < b) != k.desc
+			}
+		}
+		return files[i].meta.Id < files[j].meta.Id
+	})
+	return nil
+}
+
+type pageRange struct{ start, end int }
+
+// paginate returns the window of n items selected by a numeric page token.
+func paginate(n int, sizeParam, token string, def, maxSize int) (pageRange, string, *apiError) {
+	size := def
+	if sizeParam != "" {
+		v, err := strconv.Atoi(sizeParam)
+		if err != nil || v < 1 || v > maxSize {
+			return pageRange{}, "", errBadRequest("invalid", "invalid pageSize %q", sizeParam)
+		}
+		size = v
+	}
+	start := 0
+	if token != "" {
+		v, err := strconv.Atoi(token)
+		if err != nil || v < 0 || v > n {
+			return pageRange{}, "", errBadRequest("invalid", "invalid pageToken %q", token)
+		}
+		start = v
+	}
+	end := min(start+size, n)
+	next := ""
+	if end < n {
+		next = strconv.Itoa(end)
+	}
+	return pageRange{start, end}, next, nil
+}
+
+func (s *Server) getFile(w http.ResponseWriter, r *http.Request, id string) *apiError {
+	f, e := s.lookup(id)
+	if e != nil {
+		return e
+	}
+	if r.URL.Query().Get("alt") == "media" {
+		if isGoogleApps(f.meta.MimeType) {
+			return &apiError{http.StatusForbidden, "fileNotDownloadable", "Only files with binary content can be downloaded. Use Export with Docs Editors files."}
+		}
+		serveContent(w, r, f.meta.MimeType, f.content)
+		return nil
+	}
+	writeJSON(w, http.StatusOK, s.view(f))
+	return nil
+}
+
+// serveContent writes content, honouring a "bytes=N-" Range header.
+func serveContent(w http.ResponseWriter, r *http.Request, mimeType string, content []byte) {
+	w.Header().Set("Content-Type", mimeType)
+	var start int
+	if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &start); err == nil && start > 0 {
+		if start >= len(content) {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", len(content)))
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(content)-1, len(content)))
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)-start))
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write(content[start:])
+		return
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	_, _ = w.Write(content)
+}
+
+// readMeta decodes a JSON file body, also returning the set of keys present
+// so that explicit false or empty values can be told apart from absence.
+func readMeta(body []byte) (*drive.File, map[string]json.RawMessage, *apiError) {
+	meta := &drive.File{}
+	keys := map[string]json.RawMessage{}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return meta, keys, nil
+	}
+	if err := json.Unmarshal(body, meta); err != nil {
+		return nil, nil, errBadRequest("parseError", "invalid JSON body: %v", err)
+	}
+	_ = json.Unmarshal(body, &keys)
+	return meta, keys, nil
+}
+
+func (s *Server) createFile(w http.ResponseWriter, r *http.Request, content []byte, contentType string) *apiError {
+	var (
+		meta *drive.File
+		e    *apiError
+	)
+	if content == nil {
+		body, _ := io.ReadAll(r.Body)
+		if meta, _, e = readMeta(body); e != nil {
+			return e
+		}
+	} else {
+		meta = &drive.File{}
+	}
+	return s.finishCreate(w, meta, content, contentType)
+}
+
+func (s *Server) finishCreate(w http.ResponseWriter, meta *drive.File, content []byte, contentType string) *apiError {
+	for _, p := range meta.Parents {
+		parent := s.files[p]
+		if parent == nil {
+			return errNotFound("File", p)
+		}
+		if parent.meta.MimeType != folderMimeType {
+			return errBadRequest("invalidParent", "parent %s is not a folder", p)
+		}
+	}
+	if meta.MimeType == "" && contentType != "" && content != nil {
+		meta.MimeType = contentType
+	}
+	if meta.MimeType == "application/vnd.google-apps.shortcut" {
+		if meta.ShortcutDetails == nil || s.files[meta.ShortcutDetails.TargetId] == nil {
+			return errBadRequest("invalid", "shortcut target not found")
+		}
+		meta.ShortcutDetails.TargetMimeType = s.files[meta.ShortcutDetails.TargetId].meta.MimeType
+	}
+	f := s.create(meta, content)
+	writeJSON(w, http.StatusOK, s.view(f))
+	return nil
+}
+
+func (s *Server) updateFile(w http.ResponseWriter, r *http.Request, id string, content []byte, contentType string) *apiError {
+	f, e := s.lookup(id)
+	if e != nil {
+		return e
+	}
+	var (
+		meta *drive.File
+		keys map[string]json.RawMessage
+	)
+	if content == nil {
+		body, _ := io.ReadAll(r.Body)
+		if meta, keys, e = readMeta(body); e != nil {
+			return e
+		}
+	} else {
+		meta, keys = &drive.File{}, map[string]json.RawMessage{}
+	}
+	return s.finishUpdate(w, r.URL.Query(), f, meta, keys, content, contentType)
+}
+
+func (s *Server) finishUpdate(w http.ResponseWriter, q map[string][]string, f *file, meta *drive.File, keys map[string]json.RawMessage, content []byte, contentType string) *apiError {
+	query := func(k string) string {
+		if v := q[k]; len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	if _, ok := keys["parents"]; ok {
+		return &apiError{http.StatusForbidden, "fieldNotWritable", "The resource body includes fields which are not directly writable."}
+	}
+	m := f.meta
+	for _, p := range splitIDs(query("addParents")) {
+		if parent := s.files[p]; parent == nil || parent.meta.MimeType != folderMimeType {
+			return errNotFound("File", p)
+		}
+	}
+	if rm := splitIDs(query("removeParents")); len(rm) > 0 {
+		var kept []string
+		for _, p := range m.Parents {
+			if !slices.Contains(rm, p) {
+				kept = append(kept, p)
+			}
+		}
+		m.Parents = kept
+	}
+	for _, p := range splitIDs(query("addParents")) {
+		if !slices.Contains(m.Parents, p) {
+			m.Parents = append(m.Parents, p)
+		}
+	}
+
+	if _, ok := keys["name"]; ok {
+		m.Name = meta.Name
+	}
+	if _, ok := keys["description"]; ok {
+		m.Description = meta.Description
+	}
+	if _, ok := keys["starred"]; ok {
+		m.Starred = meta.Starred
+	}
+	if _, ok := keys["modifiedTime"]; ok {
+		m.ModifiedTime = meta.ModifiedTime
+	}
+	for _, prop := range []struct {
+		key string
+		dst *map[string]string
+		src map[string]string
+	}{{"properties", &m.Properties, meta.Properties}, {"appProperties", &m.AppProperties, meta.AppProperties}} {
+		raw, ok := keys[prop.key]
+		if !ok {
+			continue
+		}
+		// null values delete keys
+		var values map[string]*string
+		_ = json.Unmarshal(raw, &values)
+		if *prop.dst == nil {
+			*prop.dst = map[string]string{}
+		}
+		for k, v := range values {
+			if v == nil {
+				delete(*prop.dst, k)
+			} else {
+				(*prop.dst)[k] = *v
+			}
+		}
+	}
+	if meta.MimeType != "" && !isGoogleApps(m.MimeType) {
+		m.MimeType = meta.MimeType
+	}
+	if content != nil {
+		if m.MimeType == "" && contentType != "" {
+			m.MimeType = contentType
+		}
+		s.setContent(f, content)
+	} else if _, ok := keys["trashed"]; ok && meta.Trashed != m.Trashed {
+		s.setTrashed(f, meta.Trashed)
+	} else {
+		m.ModifiedTime = s.tick()
+	}
+	s.recordChange(f, false)
+	writeJSON(w, http.StatusOK, s.view(f))
+	return nil
+}
+
+func splitIDs(s string) []string {
+	var ids []string
+	for _, id := range strings.Split(s, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func (s *Server) deleteFile(w http.ResponseWriter, id string) *apiError {
+	f, e := s.lookup(id)
+	if e != nil {
+		return e
+	}
+	if id == RootID {
+		return &apiError{http.StatusForbidden, "cannotDeleteRoot", "The root folder cannot be deleted."}
+	}
+	s.remove(f)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *Server) emptyTrash(w http.ResponseWriter) *apiError {
+	for _, f := range s.files {
+		if f.meta.Trashed && f.meta.ExplicitlyTrashed && s.files[f.meta.Id] != nil {
+			s.remove(f)
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (s *Server) copyFile(w http.ResponseWriter, r *http.Request, id string) *apiError {
+	src, e := s.lookup(id)
+	if e != nil {
+		return e
+	}
+	if src.meta.MimeType == folderMimeType {
+		return &apiError{http.StatusForbidden, "cannotCopyFile", "Folders cannot be copied."}
+	}
+	body, _ := io.ReadAll(r.Body)
+	override, _, e := readMeta(body)
+	if e != nil {
+		return e
+	}
+	meta := &drive.File{
+		Name:        "Copy of " + src.meta.Name,
+		MimeType:    src.meta.MimeType,
+		Parents:     src.meta.Parents,
+		Description: src.meta.Description,
+		Properties:  src.meta.Properties,
+	}
+	if override.Name != "" {
+		meta.Name = override.Name
+	}
+	if len(override.Parents) > 0 {
+		meta.Parents = override.Parents
+	}
+	return s.finishCreate(w, meta, src.content, "")
+}
+
+// exportFile serves an export through Files.Export or an export link. Only
+// the former has a size limit.
+func (s *Server) exportFile(w http.ResponseWriter, r *http.Request, id, revisionID string, limit int) *apiError {
+	f, e := s.lookup(id)
+	if e != nil {
+		return e
+	}
+	mimeType := r.URL.Query().Get("mimeType")
+	if !slices.Contains(exportFormats[f.meta.MimeType], mimeType) {
+		return errBadRequest("badRequest", "Export of %s to %q is not supported.", f.meta.MimeType, mimeType)
+	}
+	content := f.content
+	if revisionID != "" {
+		rev := findRevision(f, revisionID)
+		if rev == nil {
+			return errNotFound("Revision", revisionID)
+		}
+		content = rev.content
+	}
+	if limit > 0 && len(content) > limit {
+		return &apiError{http.StatusForbidden, "exportSizeLimitExceeded", "This file is too large to be exported."}
+	}
+	w.Header().Set("Content-Type", mimeType)
+	_, _ = w.Write(content)
+	return nil
+}
+
+// --- uploads ---
+
+func (s *Server) routeUpload(w http.ResponseWriter, r *http.Request, p []string) *apiError {
+	if len(p) == 0 || p[0] != "files" || len(p) > 2 {
+		return &apiError{http.StatusNotFound, "notFound", "unsupported upload " + r.URL.Path}
+	}
+	var target *file
+	if len(p) == 2 {
+		f, e := s.lookup(p[1])
+		if e != nil {
+			return e
+		}
+		target = f
+	}
+
+	switch r.URL.Query().Get("uploadType") {
+	case "media":
+		content, _ := io.ReadAll(r.Body)
+		ct := r.Header.Get("Content-Type")
+		if target != nil {
+			return s.finishUpdate(w, r.URL.Query(), target, &drive.File{}, map[string]json.RawMessage{}, content, ct)
+		}
+		return s.finishCreate(w, &drive.File{}, content, ct)
+
+	case "multipart":
+		meta, keys, content, ct, e := readMultipart(r)
+		if e != nil {
+			return e
+		}
+		if target != nil {
+			return s.finishUpdate(w, r.URL.Query(), target, meta, keys, content, ct)
+		}
+		return s.finishCreate(w, meta, content, ct)
+
+	case "resumable":
+		body, _ := io.ReadAll(r.Body)
+		meta, _, e := readMeta(body)
+		if e != nil {
+			return e
+		}
+		sess := &uploadSession{meta: meta, contentType: r.Header.Get("X-Upload-Content-Type")}
+		if target != nil {
+			sess.fileID = target.meta.Id
+		}
+		id := s.newID("session")
+		s.sessions[id] = sess
+		w.Header().Set("Location", s.URL+"/upload/"+id+"?"+r.URL.RawQuery)
+		w.WriteHeader(http.StatusOK)
+		return nil
+	}
+	return errBadRequest("badRequest", "unsupported uploadType %q", r.URL.Query().Get("uploadType"))
+}
+
+func readMultipart(r *http.Request) (*drive.File, map[string]json.RawMessage, []byte, string, *apiError) {
+	mt, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || !strings.HasPrefix(mt, "multipart/") {
+		return nil, nil, nil, "", errBadRequest("badContent", "expected a multipart body")
+	}
+	mr := multipart.NewReader(r.Body, params["boundary"])
+	metaPart, err := mr.NextPart()
+	if err != nil {
+		return nil, nil, nil, "", errBadRequest("badContent", "missing metadata part")
+	}
+	metaBody, _ := io.ReadAll(metaPart)
+	meta, keys, e := readMeta(metaBody)
+	if e != nil {
+		return nil, nil, nil, "", e
+	}
+	mediaPart, err := mr.NextPart()
+	if err != nil {
+		return nil, nil, nil, "", errBadRequest("badContent", "missing media part")
+	}
+	content, _ := io.ReadAll(mediaPart)
+	return meta, keys, content, mediaPart.Header.Get("Content-Type"), nil
+}
+
+// putSession receives a chunk, or a status query, of a resumable upload.
+func (s *Server) putSession(w http.ResponseWriter, r *http.Request, id string) *apiError {
+	sess := s.sessions[id]
+	if sess == nil {
+		return errNotFound("Upload session", id)
+	}
+	chunk, _ := io.ReadAll(r.Body)
+	total := -1
+	cr := r.Header.Get("Content-Range")
+	var start, end int
+	switch {
+	case cr == "":
+		// Single request holding the whole content
+		sess.data = chunk
+		total = len(chunk)
+	case strings.HasPrefix(cr, "bytes */"):
+		if v, err := strconv.Atoi(strings.TrimPrefix(cr, "bytes */")); err == nil {
+			total = v
+		}
+	default:
+		var totalStr string
+		if _, err := fmt.Sscanf(cr, "bytes %d-%d/%s", &start, &end, &totalStr); err != nil {
+			return errBadRequest("badContent", "invalid Content-Range %q", cr)
+		}
+		if start != len(sess.data) {
+			return errBadRequest("badContent", "chunk starts at %d, expected %d", start, len(sess.data))
+		}
+		sess.data = append(sess.data, chunk...)
+		if totalStr != "*" {
+			total, _ = strconv.Atoi(totalStr)
+		}
+	}
+
+	if total < 0 || len(sess.data) < total {
+		if len(sess.data) > 0 {
+			w.Header().Set("Range", fmt.Sprintf("bytes=0-%d", len(sess.data)-1))
+		}
+		w.WriteHeader(http.StatusPermanentRedirect)
+		return nil
+	}
+
+	delete(s.sessions, id)
+	if sess.fileID != "" {
+		f, e := s.lookup(sess.fileID)
+		if e != nil {
+			return e
+		}
+		keys := map[string]json.RawMessage{}
+		if sess.meta.Name != "" {
+			keys["name"] = nil
+		}
+		return s.finishUpdate(w, r.URL.Query(), f, sess.meta, keys, sess.data, sess.contentType)
+	}
+	return s.finishCreate(w, sess.meta, sess.data, sess.contentType)
+}
+
+// --- permissions ---
+
+func (s *Server) routePermissions(w http.ResponseWriter, r *http.Request, fileID string, rest []string) *apiError {
+	f, e := s.lookup(fileID)
+	if e != nil {
+		return e
+	}
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		list := &drive.PermissionList{Kind: "drive#permissionList", Permissions: []*drive.Permission{}}
+		list.Permissions = append(list.Permissions, f.perms...)
+		writeJSON(w, http.StatusOK, list)
+		return nil
+
+	case len(rest) == 0 && r.Method == http.MethodPost:
+		perm := &drive.Permission{}
+		if err := json.NewDecoder(r.Body).Decode(perm); err != nil {
+			return errBadRequest("parseError", "invalid permission: %v", err)
+		}
+		if e := validatePermission(perm); e != nil {
+			return e
+		}
+		if e := s.validateExpiration(perm); e != nil {
+			return e
+		}
+		if perm.Role == "owner" && r.URL.Query().Get("transferOwnership") != "true" {
+			return &apiError{http.StatusForbidden, "consentRequiredForOwnershipTransfer", "transferOwnership must be set to make a user owner."}
+		}
+		for _, existing := range f.perms {
+			if existing.Type == perm.Type && existing.EmailAddress == perm.EmailAddress && existing.Domain == perm.Domain {
+				existing.Role = perm.Role
+				existing.ExpirationTime = perm.ExpirationTime
+				s.recordChange(f, false)
+				writeJSON(w, http.StatusOK, existing)
+				return nil
+			}
+		}
+		created := s.addPermission(f, perm)
+		s.recordChange(f, false)
+		writeJSON(w, http.StatusOK, created)
+		return nil
+
+	case len(rest) == 1:
+		var perm *drive.Permission
+		idx := -1
+		for i, p := range f.perms {
+			if p.Id == rest[0] {
+				perm, idx = p, i
+			}
+		}
+		if perm == nil {
+			return errNotFound("Permission", rest[0])
+		}
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, perm)
+		case http.MethodPatch:
+			update := &drive.Permission{}
+			if err := json.NewDecoder(r.Body).Decode(update); err != nil {
+				return errBadRequest("parseError", "invalid permission: %v", err)
+			}
+			if update.Role == "owner" && r.URL.Query().Get("transferOwnership") != "true" {
+				return &apiError{http.StatusForbidden, "consentRequiredForOwnershipTransfer", "transferOwnership must be set to make a user owner."}
+			}
+			c := *perm
+			if update.Role != "" {
+				c.Role = update.Role
+			}
+			if update.ExpirationTime != "" {
+				c.ExpirationTime = update.ExpirationTime
+			}
+			if r.URL.Query().Get("removeExpiration") == "true" {
+				c.ExpirationTime = ""
+			}
+			if update.PendingOwner {
+				if c.Type != "user" || c.Role != "writer" || f.meta.DriveId != "" {
+					return errBadRequest("invalidSharingRequest", "Only writers of files outside Shared Drives can be made pending owners.")
+				}
+				c.PendingOwner = true
+			}
+			if e := validatePermission(&c); e != nil {
+				return e
+			}
+			if e := s.validateExpiration(&c); e != nil {
+				return e
+			}
+			*perm = c
+			s.recordChange(f, false)
+			writeJSON(w, http.StatusOK, perm)
+		case http.MethodDelete:
+			if perm.Role == "owner" {
+				return &apiError{http.StatusForbidden, "cannotRemoveOwner", "The owner of a file cannot be removed."}
+			}
+			f.perms = append(f.perms[:idx], f.perms[idx+1:]...)
+			s.recordChange(f, false)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			return &apiError{http.StatusMethodNotAllowed, "badRequest", "unsupported method"}
+		}
+		return nil
+	}
+	return &apiError{http.StatusNotFound, "notFound", "unsupported request " + r.Method + " " + r.URL.Path}
+}
+
+func validatePermission(p *drive.Permission) *apiError {
+	switch p.Role {
+	case "owner", "organizer", "fileOrganizer", "writer", "commenter", "reader":
+	default:
+		return errBadRequest("invalid", "Invalid role: %q", p.Role)
+	}
+	switch p.Type {
+	case "user", "group":
+		if p.EmailAddress == "" {
+			return errBadRequest("required", "emailAddress is required for %s permissions", p.Type)
+		}
+	case "domain":
+		if p.Domain == "" {
+			return errBadRequest("required", "domain is required for domain permissions")
+		}
+	case "anyone":
+	default:
+		return errBadRequest("invalid", "Invalid permission type: %q", p.Type)
+	}
+	return nil
+}
+
+// validateExpiration checks the expiration time of p as Drive does: only
+// user and group permissions below owner may expire, and not in the past.
+func (s *Server) validateExpiration(p *drive.Permission) *apiError {
+	if p.ExpirationTime == "" {
+		return nil
+	}
+	if (p.Type != "user" && p.Type != "group") || p.Role == "owner" {
+		return errBadRequest("expirationDatesOnlyForUsersAndGroups", "Expiration dates can only be set on user and group permissions.")
+	}
+	t, err := time.Parse(time.RFC3339, p.ExpirationTime)
+	if err != nil {
+		return errBadRequest("invalid", "Invalid expirationTime: %q", p.ExpirationTime)
+	}
+	if !t.After(s.now) {
+		return errBadRequest("expirationDateInPast", "The expiration date must be in the future.")
+	}
+	return nil
+}
+
+// --- revisions ---
+
+func findRevision(f *file, id string) *revision {
+	if id == "head" && len(f.revisions) > 0 {
+		return f.revisions[len(f.revisions)-1]
+	}
+	for _, r := range f.revisions {
+		if r.meta.Id == id {
+			return r
+		}
+	}
+	return nil
+}
+
+func (s *Server) routeRevisions(w http.ResponseWriter, r *http.Request, fileID string, rest []string) *apiError {
+	f, e := s.lookup(fileID)
+	if e != nil {
+		return e
+	}
+	if len(rest) == 0 && r.Method == http.MethodGet {
+		list := &drive.RevisionList{Kind: "drive#revisionList", Revisions: []*drive.Revision{}}
+		for _, rev := range f.revisions {
+			list.Revisions = append(list.Revisions, rev.meta)
+		}
+		writeJSON(w, http.StatusOK, list)
+		return nil
+	}
+	if len(rest) == 2 && rest[1] == "export" && r.Method == http.MethodGet {
+		return s.exportFile(w, r, fileID, rest[0], 0)
+	}
+	if len(rest) != 1 {
+		return &apiError{http.StatusNotFound, "notFound", "unsupported request " + r.Method + " " + r.URL.Path}
+	}
+	rev := findRevision(f, rest[0])
+	if rev == nil {
+		return errNotFound("Revision", rest[0])
+	}
+	switch r.Method {
+	case http.MethodGet:
+		if r.URL.Query().Get("alt") == "media" {
+			if isGoogleApps(f.meta.MimeType) {
+				return &apiError{http.StatusForbidden, "fileNotDownloadable", "Only files with binary content can be downloaded."}
+			}
+			serveContent(w, r, rev.meta.MimeType, rev.content)
+			return nil
+		}
+		writeJSON(w, http.StatusOK, rev.meta)
+	case http.MethodPatch:
+		var keys map[string]json.RawMessage
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &keys); err != nil {
+			return errBadRequest("parseError", "invalid revision: %v", err)
+		}
+		update := &drive.Revision{}
+		_ = json.Unmarshal(body, update)
+		if _, ok := keys["keepForever"]; ok {
+			rev.meta.KeepForever = update.KeepForever
+		}
+		if _, ok := keys["published"]; ok {
+			rev.meta.Published = update.Published
+		}
+		writeJSON(w, http.StatusOK, rev.meta)
+	case http.MethodDelete:
+		if len(f.revisions) == 1 {
+			return &apiError{http.StatusBadRequest, "cannotDeleteOnlyRevision", "The last remaining revision of a file cannot be deleted."}
+		}
+		if isGoogleApps(f.meta.MimeType) {
+			return &apiError{http.StatusForbidden, "revisionNotDeletable", "Revisions of Google Docs Editors files cannot be deleted."}
+		}
+		for i, other := range f.revisions {
+			if other == rev {
+				f.revisions = append(f.revisions[:i], f.revisions[i+1:]...)
+				break
+			}
+		}
+		if head := f.revisions[len(f.revisions)-1]; head.meta.Id != f.meta.HeadRevisionId {
+			// Deleting the head revision reverts the file to the previous one
+			f.content = head.content
+			f.meta.HeadRevisionId = head.meta.Id
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		return &apiError{http.StatusMethodNotAllowed, "badRequest", "unsupported method"}
+	}
+	return nil
+}
+
+// --- comments ---
+
+func (s *Server) routeComments(w http.ResponseWriter, r *http.Request, fileID string, rest []string) *apiError {
+	f, e := s.lookup(fileID)
+	if e != nil {
+		return e
+	}
+	if r.Method != http.MethodDelete && r.URL.Query().Get("fields") == "" {
+		return errBadRequest("required", "The 'fields' parameter is required for this method.")
+	}
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		q := r.URL.Query()
+		page, next, e := paginate(len(f.comments), q.Get("pageSize"), q.Get("pageToken"), 20, 100)
+		if e != nil {
+			return e
+		}
+		list := &drive.CommentList{Kind: "drive#commentList", Comments: []*drive.Comment{}, NextPageToken: next}
+		list.Comments = append(list.Comments, f.comments[page.start:page.end]...)
+		writeJSON(w, http.StatusOK, list)
+		return nil
+
+	case len(rest) == 0 && r.Method == http.MethodPost:
+		c := &drive.Comment{}
+		if err := json.NewDecoder(r.Body).Decode(c); err != nil {
+			return errBadRequest("parseError", "invalid comment: %v", err)
+		}
+		if c.Content == "" {
+			return errBadRequest("required", "Required: content")
+		}
+		writeJSON(w, http.StatusOK, s.addComment(f, c, commentAuthor()))
+		return nil
+	}
+
+	if len(rest) == 0 || len(rest) > 2 || (len(rest) == 2 && rest[1] != "replies") {
+		return &apiError{http.StatusNotFound, "notFound", "unsupported request " + r.Method + " " + r.URL.Path}
+	}
+	i := slices.IndexFunc(f.comments, func(c *drive.Comment) bool { return c.Id == rest[0] })
+	if i < 0 {
+		return errNotFound("Comment", rest[0])
+	}
+	c := f.comments[i]
+	switch {
+	case len(rest) == 1 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, c)
+	case len(rest) == 1 && r.Method == http.MethodDelete:
+		f.comments = slices.Delete(f.comments, i, i+1)
+		w.WriteHeader(http.StatusNoContent)
+	case len(rest) == 2 && r.Method == http.MethodPost:
+		reply := &drive.Reply{}
+		if err := json.NewDecoder(r.Body).Decode(reply); err != nil {
+			return errBadRequest("parseError", "invalid reply: %v", err)
+		}
+		switch reply.Action {
+		case "":
+			if reply.Content == "" {
+				return errBadRequest("required", "Required: content")
+			}
+		case "resolve", "reopen":
+			c.Resolved = reply.Action == "resolve"
+		default:
+			return errBadRequest("invalid", "Invalid value for: action")
+		}
+		now := s.tick()
+		reply.Id = s.newID("reply")
+		reply.Kind = "drive#reply"
+		reply.Author = commentAuthor()
+		reply.HtmlContent = html.EscapeString(reply.Content)
+		reply.CreatedTime, reply.ModifiedTime = now, now
+		c.Replies = append(c.Replies, reply)
+		c.ModifiedTime = now
+		writeJSON(w, http.StatusOK, reply)
+	default:
+		return &apiError{http.StatusMethodNotAllowed, "badRequest", "unsupported method"}
+	}
+	return nil
+}
+
+// --- changes and drives ---
+
+func (s *Server) listChanges(w http.ResponseWriter, r *http.Request) *apiError {
+	q := r.URL.Query()
+	token, err := strconv.Atoi(q.Get("pageToken"))
+	if err != nil || token < 1 || token > len(s.changes)+1 {
+		return errBadRequest("invalid", "invalid pageToken %q", q.Get("pageToken"))
+	}
+	page, next, e := paginate(len(s.changes), q.Get("pageSize"), strconv.Itoa(token-1), 100, 1000)
+	if e != nil {
+		return e
+	}
+	list := &drive.ChangeList{Kind: "drive#changeList", Changes: []*drive.Change{}}
+	list.Changes = append(list.Changes, s.changes[page.start:page.end]...)
+	if next != "" {
+		n, _ := strconv.Atoi(next)
+		list.NextPageToken = strconv.Itoa(n + 1)
+	} else {
+		list.NewStartPageToken = strconv.Itoa(len(s.changes) + 1)
+	}
+	writeJSON(w, http.StatusOK, list)
+	return nil
+}
+
+func (s *Server) listDrives(w http.ResponseWriter, r *http.Request) *apiError {
+	var ids []string
+	for id := range s.drives {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	page, next, e := paginate(len(ids), r.URL.Query().Get("pageSize"), r.URL.Query().Get("pageToken"), 10, 100)
+	if e != nil {
+		return e
+	}
+	list := &drive.DriveList{Kind: "drive#driveList", Drives: []*drive.Drive{}, NextPageToken: next}
+	for _, id := range ids[page.start:page.end] {
+		list.Drives = append(list.Drives, s.drives[id])
+	}
+	writeJSON(w, http.StatusOK, list)
+	return nil
+}
@@ -0,0 +1,263 @@
+package drivetest
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/drive/v3"
+	"google.golang.org/api/googleapi"
+)
+
+func TestListFilesQuery(t *testing.T) {
+	srv := NewServer(t)
+	api := srv.API(t)
+	docs := srv.AddFolder(RootID, "Docs")
+	a := srv.AddFile(docs, "a.txt", []byte("alpha"))
+	srv.AddFile(docs, "b.pdf", []byte("%PDF"))
+	srv.AddFolder(docs, "Sub")
+
+	tests := []struct {
+		q    string
+		want int
+	}{
+		{"'" + docs + "' in parents and trashed=false", 3},
+		{"'" + docs + "' in parents and mimeType = 'application/vnd.google-apps.folder'", 1},
+		{"name = 'a.txt' and '" + docs + "' in parents", 1},
+		{"name contains 'A' or name = 'Sub'", 2},
+		{"not name contains '.' and trashed = false", 2}, // Docs, Sub
+		{"fullText contains 'alpha'", 1},
+		{"modifiedTime > '2025-01-01T00:00:03Z'", 2},
+	}
+	for _, tt := range tests {
+		list, err := api.Files.List().Q(tt.q).Do()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.q, err)
+		}
+		if len(list.Files) != tt.want {
+			t.Errorf("%s: got %d files, want %d", tt.q, len(list.Files), tt.want)
+		}
+	}
+
+	if _, err := api.Files.List().Q("name ~ 'x'").Do(); !isReason(err, "invalid") {
+		t.Errorf("invalid query: got %v", err)
+	}
+
+	got, err := api.Files.Get(a).Do()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Md5Checksum != "2c1743a391305fbf367df8e4f069f9f9" || got.Size != 5 {
+		t.Errorf("checksum/size = %s/%d", got.Md5Checksum, got.Size)
+	}
+}
+
+func TestListFilesPagination(t *testing.T) {
+	srv := NewServer(t)
+	for _, name := range []string{"c", "a", "b"} {
+		srv.AddFile(RootID, name, nil)
+	}
+	var names []string
+	err := srv.API(t).Files.List().OrderBy("name").PageSize(2).Pages(t.Context(), func(l *drive.FileList) error {
+		for _, f := range l.Files {
+			names = append(names, f.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(names, ",") != "a,b,c" {
+		t.Errorf("names = %v", names)
+	}
+}
+
+func TestTrashCascadesAndDelete(t *testing.T) {
+	srv := NewServer(t)
+	api := srv.API(t)
+	dir := srv.AddFolder(RootID, "Dir")
+	child := srv.AddFile(dir, "f.txt", []byte("x"))
+	loose := srv.AddFile(dir, "g.txt", []byte("y"))
+
+	if _, err := api.Files.Update(loose, &drive.File{Trashed: true}).Do(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.Files.Update(dir, &drive.File{Trashed: true}).Do(); err != nil {
+		t.Fatal(err)
+	}
+	if f := srv.File(child); !f.Trashed || f.ExplicitlyTrashed {
+		t.Errorf("child trashed=%v explicit=%v", f.Trashed, f.ExplicitlyTrashed)
+	}
+
+	// Restoring the folder keeps the separately trashed file in the trash
+	if _, err := api.Files.Update(dir, &drive.File{Trashed: false, ForceSendFields: []string{"Trashed"}}).Do(); err != nil {
+		t.Fatal(err)
+	}
+	if srv.File(child).Trashed || !srv.File(loose).Trashed {
+		t.Errorf("restore: child=%v loose=%v", srv.File(child).Trashed, srv.File(loose).Trashed)
+	}
+
+	if err := api.Files.Delete(dir).Do(); err != nil {
+		t.Fatal(err)
+	}
+	if srv.File(dir) != nil || srv.File(child) != nil {
+		t.Error("delete did not remove the folder tree")
+	}
+	if _, err := api.Files.Get(child).Do(); !isReason(err, "notFound") {
+		t.Errorf("get deleted: %v", err)
+	}
+}
+
+func TestUploadsAndRevisions(t *testing.T) {
+	srv := NewServer(t)
+	api := srv.API(t)
+
+	created, err := api.Files.Create(&drive.File{Name: "n.txt"}).Media(strings.NewReader("one")).Do()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.Files.Update(created.Id, &drive.File{}).Media(strings.NewReader("two")).Do(); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(srv.Content(created.Id)); got != "two" {
+		t.Errorf("content = %q", got)
+	}
+	revs := srv.Revisions(created.Id)
+	if len(revs) != 2 {
+		t.Fatalf("got %d revisions, want 2", len(revs))
+	}
+
+	resp, err := api.Revisions.Get(created.Id, revs[0].Id).Download()
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(body) != "one" {
+		t.Errorf("first revision = %q", body)
+	}
+
+	if err := api.Revisions.Delete(created.Id, revs[1].Id).Do(); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(srv.Content(created.Id)); got != "one" {
+		t.Errorf("after deleting head, content = %q", got)
+	}
+	if err := api.Revisions.Delete(created.Id, revs[0].Id).Do(); !isReason(err, "cannotDeleteOnlyRevision") {
+		t.Errorf("delete last revision: %v", err)
+	}
+}
+
+func TestDownloadRange(t *testing.T) {
+	srv := NewServer(t)
+	id := srv.AddFile(RootID, "r.bin", []byte("0123456789"))
+
+	call := srv.API(t).Files.Get(id)
+	call.Header().Set("Range", "bytes=4-")
+	resp, err := call.Download()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusPartialContent || string(body) != "456789" {
+		t.Errorf("status %d body %q", resp.StatusCode, body)
+	}
+	if cr := resp.Header.Get("Content-Range"); cr != "bytes 4-9/10" {
+		t.Errorf("Content-Range = %q", cr)
+	}
+}
+
+func TestExportAndPermissions(t *testing.T) {
+	srv := NewServer(t)
+	api := srv.API(t)
+	doc := srv.AddDoc(RootID, "Notes", "application/vnd.google-apps.document", []byte("# Notes"))
+
+	if _, err := api.Files.Get(doc).Download(); !isReason(err, "fileNotDownloadable") {
+		t.Errorf("download doc: %v", err)
+	}
+	resp, err := api.Files.Export(doc, "text/markdown").Download()
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if !bytes.Equal(body, []byte("# Notes")) {
+		t.Errorf("export = %q", body)
+	}
+	if _, err := api.Files.Export(doc, "image/png").Download(); !isReason(err, "badRequest") {
+		t.Errorf("unsupported export: %v", err)
+	}
+
+	if _, err := api.Permissions.Create(doc, &drive.Permission{Type: "anyone", Role: "reader"}).Do(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := api.Permissions.Create(doc, &drive.Permission{Type: "user", Role: "writer"}).Do(); !isReason(err, "required") {
+		t.Errorf("permission without email: %v", err)
+	}
+	list, err := api.Files.List().Q("visibility = 'anyoneWithLink'").Do()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Files) != 1 || !list.Files[0].Shared {
+		t.Errorf("visibility query: %+v", list.Files)
+	}
+	if err := api.Permissions.Delete(doc, "anyoneWithLink").Do(); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(srv.Permissions(doc)); n != 1 {
+		t.Errorf("got %d permissions, want only the owner", n)
+	}
+}
+
+func TestChangesFeed(t *testing.T) {
+	srv := NewServer(t)
+	api := srv.API(t)
+	start, err := api.Changes.GetStartPageToken().Do()
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := srv.AddFile(RootID, "c.txt", []byte("c"))
+	if err := api.Files.Delete(id).Do(); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := api.Changes.List(start.StartPageToken).Do()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Changes) != 2 || list.Changes[0].File.Name != "c.txt" || !list.Changes[1].Removed {
+		t.Errorf("changes = %+v", list.Changes)
+	}
+	if list.NewStartPageToken == "" {
+		t.Error("missing newStartPageToken")
+	}
+}
+
+func TestFailNext(t *testing.T) {
+	srv := NewServer(t)
+	srv.FailNext(http.StatusTooManyRequests, "rateLimitExceeded")
+	api := srv.API(t)
+	if _, err := api.Files.Get(RootID).Do(); !isReason(err, "rateLimitExceeded") {
+		t.Errorf("first call: %v", err)
+	}
+	if _, err := api.Files.Get(RootID).Do(); err != nil {
+		t.Errorf("second call: %v", err)
+	}
+}
+
+func isReason(err error, reason string) bool {
+	var gerr *googleapi.Error
+	if !errors.As(err, &gerr) {
+		return false
+	}
+	for _, item := range gerr.Errors {
+		if item.Reason == reason {
+			return true
+		}
+	}
+	return false
+}
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"testing"
 )
@@ -21,9 +24,17 @@ type mockFile struct {
 	WebViewLink  string   `json:"webViewLink"`
 	Trashed      bool     `json:"trashed"`
 	TrashedTime  string   `json:"trashedTime,omitempty"`
+	DriveID      string   `json:"driveId,omitempty"`
 	Content      []byte   `json:"-"` // raw file content
 }
 
+// mockSharedDrive represents a Shared Drive.
+type mockSharedDrive struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	CreatedTime string `json:"createdTime"`
+}
+
 // mockPermission represents a permission.
 type mockPermission struct {
 	ID           string `json:"id"`
@@ -46,6 +57,7 @@ type mockRevision struct {
 // mockDriveData holds all test data for the mock server.
 type mockDriveData struct {
 	files       map[string]*mockFile
+	drives      []*mockSharedDrive
 	permissions map[string][]*mockPermission
 	revisions   map[string][]*mockRevision
 }
@@ -107,6 +119,35 @@ func newMockDriveData() *mockDriveData {
 				ModifiedTime: "2026-04-05T10:00:00Z", CreatedTime: "2026-04-05T10:00:00Z",
 				Parents: []string{"root"}, WebViewLink: "https://drive.google.com/drive/folders/empty-folder",
 			},
+			"dup-folder": {
+				ID: "dup-folder", Name: "Duplicates",
+				MimeType: "application/vnd.google-apps.folder", Size: 0,
+				ModifiedTime: "2026-04-05T10:00:00Z", CreatedTime: "2026-04-05T10:00:00Z",
+				Parents: []string{"root"}, WebViewLink: "https://drive.google.com/drive/folders/dup-folder",
+			},
+			"minutes-old": {
+				ID: "minutes-old", Name: "Minutes.txt",
+				MimeType: "text/plain", Size: 10,
+				ModifiedTime: "2026-04-01T10:00:00Z", CreatedTime: "2026-04-01T10:00:00Z",
+				Parents: []string{"dup-folder"}, WebViewLink: "https://drive.google.com/file/d/minutes-old",
+			},
+			"minutes-new": {
+				ID: "minutes-new", Name: "Minutes.txt",
+				MimeType: "text/plain", Size: 20,
+				ModifiedTime: "2026-04-02T10:00:00Z", CreatedTime: "2026-04-02T10:00:00Z",
+				Parents: []string{"dup-folder"}, WebViewLink: "https://drive.google.com/file/d/minutes-new",
+			},
+			"shared-doc": {
+				ID: "shared-doc", Name: "Team Plan",
+				MimeType: "application/vnd.google-apps.document", Size: 0,
+				ModifiedTime: "2026-04-08T10:00:00Z", CreatedTime: "2026-04-08T09:00:00Z",
+				Parents: []string{"shared-drive-1"}, DriveID: "shared-drive-1",
+				WebViewLink: "https://docs.google.com/document/d/shared-doc",
+				Content:     []byte("Team plan content."),
+			},
+		},
+		drives: []*mockSharedDrive{
+			{ID: "shared-drive-1", Name: "Engineering", CreatedTime: "2026-01-01T00:00:00Z"},
 		},
 		permissions: map[string][]*mockPermission{
 			"doc-1": {
@@ -127,6 +168,19 @@ func newMockDriveData() *mockDriveData {
 }
 
 // newMockDriveServer creates a mock Google Drive API server.
+// Patterns for the query clauses the mock understands; quoted values may
+// contain escaped quotes.
+var (
+	mockNameContainsRe = regexp.MustCompile(`name contains '((?:[^'\\]|\\.)*)'`)
+	mockNameEqualsRe   = regexp.MustCompile(`name = '((?:[^'\\]|\\.)*)'`)
+	mockInParentsRe    = regexp.MustCompile(`'((?:[^'\\]|\\.)*)' in parents`)
+)
+
+// mockUnescape reverses query.Escape.
+func mockUnescape(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\'`, `'`).Replace(s)
+}
+
 func newMockDriveServer(t *testing.T) (*httptest.Server, *mockDriveData) {
 	t.Helper()
 	data := newMockDriveData()
@@ -138,6 +192,7 @@ func newMockDriveServer(t *testing.T) (*httptest.Server, *mockDriveData) {
 		q := r.URL.Query().Get("q")
 		orderBy := r.URL.Query().Get("orderBy")
 		_ = orderBy
+		driveID := r.URL.Query().Get("driveId")
 
 		var files []map[string]interface{}
 		for _, f := range data.files {
@@ -149,35 +204,35 @@ func newMockDriveServer(t *testing.T) (*httptest.Server, *mockDriveData) {
 				continue
 			}
 
-			// Simple name search filter
-			if strings.Contains(q, "name contains") {
-				nameStart := strings.Index(q, "'")
-				nameEnd := strings.LastIndex(q, "'")
-				if nameStart >= 0 && nameEnd > nameStart {
-					searchTerm := q[nameStart+1 : nameEnd]
-					if !strings.Contains(strings.ToLower(f.Name), strings.ToLower(searchTerm)) {
-						continue
-					}
+			// Shared Drive corpus
+			if driveID != "" && f.DriveID != driveID {
+				continue
+			}
+
+			// Simple name filters
+			if m := mockNameContainsRe.FindStringSubmatch(q); m != nil {
+				searchTerm := mockUnescape(m[1])
+				if !strings.Contains(strings.ToLower(f.Name), strings.ToLower(searchTerm)) {
+					continue
 				}
 			}
+			if m := mockNameEqualsRe.FindStringSubmatch(q); m != nil && f.Name != mockUnescape(m[1]) {
+				continue
+			}
 
 			// Filter by parent
-			if strings.Contains(q, "in parents") {
-				parentStart := strings.Index(q, "'")
-				parentEnd := strings.Index(q[parentStart+1:], "'") + parentStart + 1
-				if parentStart >= 0 && parentEnd > parentStart {
-					parentID := q[parentStart+1 : parentEnd]
-					found := false
-					for _, p := range f.Parents {
-						if p == parentID {
-							found = true
-							break
-						}
-					}
-					if !found {
-						continue
+			if m := mockInParentsRe.FindStringSubmatch(q); m != nil {
+				parentID := mockUnescape(m[1])
+				found := false
+				for _, p := range f.Parents {
+					if p == parentID {
+						found = true
+						break
 					}
 				}
+				if !found {
+					continue
+				}
 			}
 
 			files = append(files, map[string]interface{}{
@@ -188,9 +243,21 @@ func newMockDriveServer(t *testing.T) (*httptest.Server, *mockDriveData) {
 			})
 		}
 
+		// Paginate in a stable order; the page token is the offset.
+		sort.Slice(files, func(i, j int) bool { return files[i]["id"].(string) < files[j]["id"].(string) })
+		nextPageToken := ""
+		if offset, err := strconv.Atoi(r.URL.Query().Get("pageToken")); err == nil && offset < len(files) {
+			files = files[offset:]
+		}
+		if pageSize, err := strconv.Atoi(r.URL.Query().Get("pageSize")); err == nil && pageSize > 0 && pageSize < len(files) {
+			offset, _ := strconv.Atoi(r.URL.Query().Get("pageToken"))
+			nextPageToken = strconv.Itoa(offset + pageSize)
+			files = files[:pageSize]
+		}
+
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"files":         files,
-			"nextPageToken": "",
+			"nextPageToken": nextPageToken,
 		})
 	})
 
@@ -378,6 +445,14 @@ func newMockDriveServer(t *testing.T) (*httptest.Server, *mockDriveData) {
 		json.NewEncoder(w).Encode(map[string]interface{}{"id": "uploaded-file"})
 	})
 
+	// GET /drives - List Shared Drives
+	mux.HandleFunc("GET /drives", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"drives":        data.drives,
+			"nextPageToken": "",
+		})
+	})
+
 	// Activity API endpoints
 	mux.HandleFunc("GET /changes/startPageToken", func(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(map[string]string{"startPageToken": "100"})
@@ -2,16 +2,16 @@ package mcp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
-	"os/signal"
 	"strings"
-	"syscall"
 	"time"
 
 	"gdrive/internal/auth"
+	"gdrive/internal/drive"
 	"gdrive/internal/telemetry"
 
 	"github.com/mark3labs/mcp-go/mcp"
@@ -30,6 +30,24 @@ type ServerConfig struct {
 	VaultToken      string
 	VaultSecretPath string
 	CredentialFile  string
+	// ToolTimeout bounds a single tool call. Zero means DefaultToolTimeout.
+	ToolTimeout time.Duration
+	// SharingPolicyFile is the sharing policy the permission tools enforce.
+	// A missing file means no policy, unless SharingPolicyRequired is set.
+	SharingPolicyFile     string
+	SharingPolicyRequired bool
+}
+
+// DefaultToolTimeout is the deadline applied to a tool call when
+// ServerConfig.ToolTimeout is not set.
+const DefaultToolTimeout = 60 * time.Second
+
+// slowToolTimeouts gives tools that page through large result sets or
+// transfer file content a longer deadline than the default.
+var slowToolTimeouts = map[string]time.Duration{
+	"drive_activity_history": 5 * time.Minute,
+	"drive_download_content": 5 * time.Minute,
+	"drive_read_content":     2 * time.Minute,
 }
 
 // Server is the MCP HTTP Streamable server for Google Drive.
@@ -38,6 +56,8 @@ type Server struct {
 	mcpServer  *server.MCPServer
 	oauth2     *OAuth2Server
 	httpServer *http.Server
+	// policy is the sharing policy, which tools cannot override.
+	policy *drive.SharingPolicy
 }
 
 // NewServer creates and configures the MCP server.
@@ -50,6 +70,16 @@ func NewServer(ctx context.Context, cfg *ServerConfig) (*Server, error) {
 		return nil, fmt.Errorf("load OAuth credentials: %w", err)
 	}
 
+	var policy *drive.SharingPolicy
+	if cfg.SharingPolicyFile != "" {
+		if policy, err = drive.LoadSharingPolicy(cfg.SharingPolicyFile); err != nil {
+			return nil, fmt.Errorf("load sharing policy: %w", err)
+		}
+	}
+	if policy == nil && cfg.SharingPolicyRequired {
+		return nil, fmt.Errorf("load sharing policy: %s not found", cfg.SharingPolicyFile)
+	}
+
 	// Create OAuth2 server
 	oauth2Srv := NewOAuth2Server(cfg.BaseURL, creds)
 
@@ -58,12 +88,14 @@ func NewServer(ctx context.Context, cfg *ServerConfig) (*Server, error) {
 		"gdrive-mcp-server",
 		"1.0.0",
 		server.WithToolCapabilities(true),
+		server.WithToolHandlerMiddleware(toolTimeoutMiddleware(cfg.ToolTimeout)),
 	)
 
 	s := &Server{
 		config:    cfg,
 		mcpServer: mcpSrv,
 		oauth2:    oauth2Srv,
+		policy:    policy,
 	}
 
 	// Register tools
@@ -84,8 +116,8 @@ func (s *Server) GetOAuth2Server() *OAuth2Server {
 	return s.oauth2
 }
 
-// Start starts the MCP server with graceful shutdown.
-func (s *Server) Start() error {
+// Start starts the MCP server and shuts it down gracefully once ctx is done.
+func (s *Server) Start(ctx context.Context) error {
 	addr := fmt.Sprintf("%s:%d", s.config.Host, s.config.Port)
 
 	// Create the StreamableHTTP server with auth context injection
@@ -117,15 +149,12 @@ func (s *Server) Start() error {
 	}
 
 	// Graceful shutdown
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-
 	go func() {
-		<-sigChan
+		<-ctx.Done()
 		slog.Info("shutdown signal received, shutting down gracefully")
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
-		if err := s.httpServer.Shutdown(ctx); err != nil {
+		if err := s.httpServer.Shutdown(shutdownCtx); err != nil {
 			slog.Error("server shutdown error", "error", err)
 		}
 	}()
@@ -139,6 +168,38 @@ func (s *Server) Start() error {
 	return nil
 }
 
+// toolTimeout returns the deadline for the named tool. A configured timeout
+// replaces the default, but never shortens the allowance of a slow tool.
+func toolTimeout(name string, configured time.Duration) time.Duration {
+	timeout := configured
+	if timeout <= 0 {
+		timeout = DefaultToolTimeout
+	}
+	if slow, ok := slowToolTimeouts[name]; ok && slow > timeout {
+		timeout = slow
+	}
+	return timeout
+}
+
+// toolTimeoutMiddleware bounds every tool call with a per-tool deadline.
+// The deadline is attached to the call context, so in-flight Drive API
+// requests are cancelled when it expires or when the client goes away.
+func toolTimeoutMiddleware(configured time.Duration) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			timeout := toolTimeout(req.Params.Name, configured)
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			result, err := next(ctx, req)
+			if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, fmt.Errorf("%s timed out after %s: %w", req.Params.Name, timeout, err)
+			}
+			return result, err
+		}
+	}
+}
+
 // httpContextFunc injects auth context from the HTTP request into the MCP context.
 // This is called by the mcp-go SDK for each request to the /mcp endpoint.
 // Tracing is handled by tracingMiddleware around the HTTP handler, so the
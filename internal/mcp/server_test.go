@@ -1,10 +1,15 @@
 package mcp
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func TestHandleHealth(t *testing.T) {
@@ -102,6 +107,47 @@ func TestAuthMiddleware(t *testing.T) {
 	})
 }
 
+func TestToolTimeout(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured time.Duration
+		want       time.Duration
+	}{
+		{"drive_search", 0, DefaultToolTimeout},
+		{"drive_search", 10 * time.Second, 10 * time.Second},
+		{"drive_activity_history", 0, 5 * time.Minute},
+		{"drive_activity_history", 10 * time.Second, 5 * time.Minute},
+		{"drive_activity_history", 10 * time.Minute, 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+"/"+tt.configured.String(), func(t *testing.T) {
+			if got := toolTimeout(tt.name, tt.configured); got != tt.want {
+				t.Errorf("toolTimeout(%q, %s) = %s, want %s", tt.name, tt.configured, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToolTimeoutMiddleware(t *testing.T) {
+	slow := func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	handler := toolTimeoutMiddleware(10 * time.Millisecond)(slow)
+
+	req := mcp.CallToolRequest{}
+	req.Params.Name = "drive_search"
+
+	_, err := handler(t.Context(), req)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if !contains(err.Error(), "drive_search timed out") {
+		t.Errorf("error should name the tool, got: %v", err)
+	}
+}
+
 func TestParseLogLevel(t *testing.T) {
 	tests := []struct {
 		input string
@@ -183,6 +229,33 @@ func TestNewServerMissingCredentials(t *testing.T) {
 	}
 }
 
+func TestNewServerSharingPolicy(t *testing.T) {
+	dir := t.TempDir()
+	broken := dir + "/policy.yaml"
+	os.WriteFile(broken, []byte("allowedDomain: [example.com]\n"), 0600)
+
+	for name, set := range map[string]func(*ServerConfig){
+		"invalid":          func(cfg *ServerConfig) { cfg.SharingPolicyFile = broken },
+		"missing required": func(cfg *ServerConfig) { cfg.SharingPolicyFile, cfg.SharingPolicyRequired = dir+"/missing.yaml", true },
+	} {
+		cfg := testServerConfig(t)
+		set(cfg)
+		if _, err := NewServer(t.Context(), cfg); err == nil {
+			t.Errorf("%s policy: expected a startup error", name)
+		}
+	}
+
+	cfg := testServerConfig(t)
+	cfg.SharingPolicyFile = dir + "/missing.yaml"
+	srv, err := NewServer(t.Context(), cfg)
+	if err != nil {
+		t.Fatalf("optional missing policy: unexpected error: %v", err)
+	}
+	if srv.policy != nil {
+		t.Error("policy should be nil when the file does not exist")
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsSubstr(s, substr))
 }
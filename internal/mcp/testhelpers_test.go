@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"gdrive/internal/drive"
+	"gdrive/internal/drivetest"
 
 	mcplib "github.com/mark3labs/mcp-go/mcp"
 	driveapi "google.golang.org/api/drive/v3"
@@ -49,24 +50,53 @@ func setupToolTest(t *testing.T) *Server {
 	}
 	t.Cleanup(func() { activityServiceOverride = origActivity })
 
-	// Create real MCP server with test credentials
+	return newToolTestServer(t)
+}
+
+// setupFakeDriveTest creates a test MCP server backed by the stateful fake
+// Drive server from drivetest, for tests that check the effect of tools.
+func setupFakeDriveTest(t *testing.T) (*Server, *drivetest.Server) {
+	t.Helper()
+
+	fake := drivetest.NewServer(t)
+	origDrive := driveServiceOverride
+	driveServiceOverride = func(ctx context.Context) (*drive.Service, error) {
+		ds := drive.NewService(fake.API(t))
+		ds.HTTP = fake.Client()
+		return ds, nil
+	}
+	t.Cleanup(func() { driveServiceOverride = origDrive })
+
+	return newToolTestServer(t), fake
+}
+
+// newToolTestServer creates a real MCP server with test credentials.
+func newToolTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	srv, err := NewServer(t.Context(), testServerConfig(t))
+	if err != nil {
+		t.Fatalf("failed to create test server: %v", err)
+	}
+
+	return srv
+}
+
+// testServerConfig returns the configuration of a test server with test
+// credentials.
+func testServerConfig(t *testing.T) *ServerConfig {
+	t.Helper()
+
 	tmpDir := t.TempDir()
 	credFile := tmpDir + "/creds.json"
 	os.WriteFile(credFile, []byte(`{"client_id":"test-id","client_secret":"test-secret"}`), 0600)
 
-	cfg := &ServerConfig{
+	return &ServerConfig{
 		Host:           "127.0.0.1",
 		Port:           0,
 		BaseURL:        "http://localhost:8080",
 		CredentialFile: credFile,
 	}
-
-	srv, err := NewServer(t.Context(), cfg)
-	if err != nil {
-		t.Fatalf("failed to create test server: %v", err)
-	}
-
-	return srv
 }
 
 // callTool invokes an MCP tool via JSON-RPC HandleMessage.
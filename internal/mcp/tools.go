@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
 	"time"
 
@@ -20,14 +22,19 @@ import (
 // RegisterReadTools registers all read-only MCP tools on the server.
 func RegisterReadTools(s *Server) {
 	registerSearchTool(s)
+	registerSharedDrivesListTool(s)
 	registerFolderListTool(s)
 	registerFileInfoTool(s)
+	registerMetadataGetTool(s)
 	registerDownloadURLTool(s)
 	registerExportURLTool(s)
 	registerActivityChangesTool(s)
 	registerActivityDeletedTool(s)
+	registerTrashListTool(s)
 	registerActivityHistoryTool(s)
 	registerFileRevisionsTool(s)
+	registerRevisionDownloadTool(s)
+	registerCommentsListTool(s)
 	registerReadContentTool(s)
 	registerListRecentTool(s)
 	registerDownloadContentTool(s)
@@ -36,10 +43,18 @@ func RegisterReadTools(s *Server) {
 // RegisterWriteTools registers all write MCP tools on the server.
 func RegisterWriteTools(s *Server) {
 	registerDeleteTool(s)
+	registerRestoreTool(s)
+	registerRevisionRestoreTool(s)
+	registerRevisionPinTool(s)
+	registerRevisionDeleteTool(s)
 	registerRenameTool(s)
 	registerMoveTool(s)
 	registerCopyTool(s)
 	registerFolderCreateTool(s)
+	registerShortcutCreateTool(s)
+	registerMetadataUpdateTool(s)
+	registerCommentAddTool(s)
+	registerCommentReplyTool(s)
 	registerPermissionsListTool(s)
 	registerPermissionsUpdateTool(s)
 	registerCreateUploadURLTool(s)
@@ -57,11 +72,13 @@ func getDriveService(ctx context.Context) (*drive.Service, error) {
 		return driveServiceOverride(ctx)
 	}
 	cfg := auth.NewConfig("", "")
-	srv, err := auth.GetAuthenticatedService(ctx, cfg)
+	srv, client, err := auth.GetAuthenticatedServiceAndClient(ctx, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("authentication failed: %w", err)
 	}
-	return drive.NewService(srv), nil
+	ds := drive.NewService(srv)
+	ds.HTTP = client
+	return ds, nil
 }
 
 // getActivityService creates an authenticated Drive Activity service from context.
@@ -101,19 +118,41 @@ func logToolCall(toolName string, start time.Time, result *mcp.CallToolResult, e
 
 func registerSearchTool(s *Server) {
 	tool := mcp.NewTool("drive_search",
-		mcp.WithDescription("Search for files and folders in Google Drive by name. Use type shortcuts (image, audio, video, prez, doc, spreadsheet, txt, pdf, folder) or explicit MIME types to filter results."),
-		mcp.WithString("query", mcp.Required(), mcp.Description("Search query (file name)")),
+		mcp.WithDescription("Search for files and folders in Google Drive by name, content, dates, owner, sharing, size and properties. Use type shortcuts (image, audio, video, prez, doc, spreadsheet, txt, pdf, folder) or explicit MIME types to filter results. Dates are YYYY-MM-DD or RFC 3339."),
+		mcp.WithString("query", mcp.Description("Text the file name contains; omit to rely on the other filters")),
+		mcp.WithString("fullText", mcp.Description("Text the content, name or description contains")),
 		mcp.WithString("fileTypes", mcp.Description("Comma-separated file type shortcuts or MIME types (e.g., 'image,pdf' or 'application/pdf')")),
 		mcp.WithString("parentId", mcp.Description("Restrict search to direct children of this folder ID")),
+		mcp.WithString("driveId", mcp.Description("Restrict search to this Shared Drive ID (default: My Drive and all Shared Drives)")),
 		mcp.WithNumber("maxResults", mcp.Description("Maximum number of results (default: 50)")),
+		mcp.WithObject("properties", mcp.Description("Only files whose custom properties include all these key/value pairs, e.g. {\"project\": \"ACME-42\"}"),
+			mcp.AdditionalProperties(map[string]any{"type": "string"})),
+		mcp.WithObject("appProperties", mcp.Description("Only files whose appProperties include all these key/value pairs"),
+			mcp.AdditionalProperties(map[string]any{"type": "string"})),
+		mcp.WithString("modifiedAfter", mcp.Description("Only files modified at or after this date")),
+		mcp.WithString("modifiedBefore", mcp.Description("Only files modified before this date")),
+		mcp.WithString("createdAfter", mcp.Description("Only files created at or after this date")),
+		mcp.WithString("createdBefore", mcp.Description("Only files created before this date")),
+		mcp.WithString("owner", mcp.Description("Only files owned by this email address ('me' for the user's own files)")),
+		mcp.WithString("sharedBy", mcp.Description("Only files shared with the user by this email address")),
+		mcp.WithBoolean("starred", mcp.Description("Only starred files")),
+		mcp.WithBoolean("sharedWithMe", mcp.Description("Only files in 'Shared with me'")),
+		mcp.WithString("visibility", mcp.Description("Only files with this visibility"), mcp.Enum(drive.Visibilities...)),
+		mcp.WithNumber("minSize", mcp.Description("Only files of at least this many bytes (excludes folders and Workspace files)")),
+		mcp.WithNumber("maxSize", mcp.Description("Only files of at most this many bytes (excludes folders and Workspace files)")),
+		mcp.WithString("orderBy", mcp.Description("Sort by modified or created (newest first), name (A-Z) or size (largest first); not with fullText"),
+			mcp.Enum("modified", "created", "name", "size")),
+		mcp.WithBoolean("reverse", mcp.Description("Reverse the order given by orderBy")),
 	)
 
 	s.mcpServer.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		start := time.Now()
 
-		query, _ := req.GetArguments()["query"].(string)
+		args := req.GetArguments()
+		query, _ := args["query"].(string)
 		fileTypesStr, _ := req.GetArguments()["fileTypes"].(string)
 		parentID, _ := req.GetArguments()["parentId"].(string)
+		driveID, _ := req.GetArguments()["driveId"].(string)
 		maxResults := int64(50)
 		if mr, ok := req.GetArguments()["maxResults"].(float64); ok && mr > 0 {
 			maxResults = int64(mr)
@@ -132,7 +171,50 @@ func registerSearchTool(s *Server) {
 			}
 		}
 
-		files, err := driveSrv.SearchFiles(query, fileTypes, parentID, maxResults)
+		opts := drive.SearchOptions{
+			FileTypes:  fileTypes,
+			ParentID:   parentID,
+			DriveID:    driveID,
+			MaxResults: maxResults,
+		}
+		opts.FullText, _ = args["fullText"].(string)
+		opts.Owner, _ = args["owner"].(string)
+		opts.SharedBy, _ = args["sharedBy"].(string)
+		opts.Starred, _ = args["starred"].(bool)
+		opts.SharedWithMe, _ = args["sharedWithMe"].(bool)
+		opts.Visibility, _ = args["visibility"].(string)
+		opts.Sort, _ = args["orderBy"].(string)
+		opts.Reverse, _ = args["reverse"].(bool)
+		if n, ok := args["minSize"].(float64); ok {
+			opts.MinSize = int64(n)
+		}
+		if n, ok := args["maxSize"].(float64); ok {
+			opts.MaxSize = int64(n)
+		}
+		for name, dst := range map[string]*time.Time{
+			"modifiedAfter": &opts.ModifiedAfter, "modifiedBefore": &opts.ModifiedBefore,
+			"createdAfter": &opts.CreatedAfter, "createdBefore": &opts.CreatedBefore,
+		} {
+			if value, _ := args[name].(string); value != "" {
+				t, err := drive.ParseDate(value)
+				if err != nil {
+					return logToolCall("drive_search", start, nil, fmt.Errorf("%s: %w", name, err))
+				}
+				*dst = t
+			}
+		}
+		for name, dst := range map[string]*map[string]string{"properties": &opts.Properties, "appProperties": &opts.AppProperties} {
+			props, unset, err := propertyArgs(args, name)
+			if err == nil && len(unset) > 0 {
+				err = fmt.Errorf("%s filter %q has no value", name, unset[0])
+			}
+			if err != nil {
+				return logToolCall("drive_search", start, nil, err)
+			}
+			*dst = props
+		}
+
+		files, err := driveSrv.SearchFiles(ctx, query, opts)
 		if err != nil {
 			return logToolCall("drive_search", start, nil, fmt.Errorf("search failed: %w", err))
 		}
@@ -153,36 +235,90 @@ func registerSearchTool(s *Server) {
 	})
 }
 
+func registerSharedDrivesListTool(s *Server) {
+	tool := mcp.NewTool("drive_shared_drives_list",
+		mcp.WithDescription("List the Shared Drives the user is a member of. Use a drive ID as folderId in drive_folder_list or as driveId in drive_search."),
+	)
+
+	s.mcpServer.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+
+		driveSrv, err := getDriveService(ctx)
+		if err != nil {
+			return logToolCall("drive_shared_drives_list", start, nil, err)
+		}
+
+		drives, err := driveSrv.ListSharedDrives(ctx)
+		if err != nil {
+			return logToolCall("drive_shared_drives_list", start, nil, err)
+		}
+
+		results := make([]map[string]interface{}, 0, len(drives))
+		for _, d := range drives {
+			results = append(results, map[string]interface{}{
+				"id":          d.Id,
+				"name":        d.Name,
+				"createdTime": d.CreatedTime,
+				"hidden":      d.Hidden,
+			})
+		}
+
+		result, err := toolResult(results)
+		return logToolCall("drive_shared_drives_list", start, result, err)
+	})
+}
+
 func registerFolderListTool(s *Server) {
 	tool := mcp.NewTool("drive_folder_list",
-		mcp.WithDescription("List contents of a Google Drive folder. Returns files and subfolders sorted by type (folders first) then alphabetically."),
+		mcp.WithDescription("List contents of a Google Drive folder. Returns files and subfolders sorted by type (folders first) then alphabetically; shortcuts carry the ID and type of their target. Without pageSize/pageToken, returns every item as an array; with either, returns {files, nextPageToken} for one page."),
 		mcp.WithString("folderId", mcp.Required(), mcp.Description("Google Drive folder ID (use 'root' for My Drive root)")),
+		mcp.WithNumber("pageSize", mcp.Description("Maximum number of items to return in this page")),
+		mcp.WithString("pageToken", mcp.Description("Page token for pagination from a previous response")),
 	)
 
 	s.mcpServer.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		start := time.Now()
 
 		folderID, _ := req.GetArguments()["folderId"].(string)
+		pageToken, _ := req.GetArguments()["pageToken"].(string)
+		var pageSize int64
+		if ps, ok := req.GetArguments()["pageSize"].(float64); ok && ps > 0 {
+			pageSize = int64(ps)
+		}
+		paged := pageSize > 0 || pageToken != ""
 
 		driveSrv, err := getDriveService(ctx)
 		if err != nil {
 			return logToolCall("drive_folder_list", start, nil, err)
 		}
 
-		files, err := driveSrv.ListFolder(folderID)
+		files, nextPageToken, err := driveSrv.ListFolderPage(ctx, folderID, pageSize, pageToken)
 		if err != nil {
 			return logToolCall("drive_folder_list", start, nil, fmt.Errorf("list folder failed: %w", err))
 		}
 
 		results := make([]map[string]interface{}, 0, len(files))
 		for _, f := range files {
-			results = append(results, map[string]interface{}{
+			item := map[string]interface{}{
 				"id":           f.Id,
 				"name":         f.Name,
 				"mimeType":     f.MimeType,
 				"modifiedTime": f.ModifiedTime,
 				"size":         f.Size,
+			}
+			if driveSrv.IsShortcut(f) {
+				item["targetId"] = f.ShortcutDetails.TargetId
+				item["targetMimeType"] = f.ShortcutDetails.TargetMimeType
+			}
+			results = append(results, item)
+		}
+
+		if paged {
+			result, err := toolResult(map[string]interface{}{
+				"files":         results,
+				"nextPageToken": nextPageToken,
 			})
+			return logToolCall("drive_folder_list", start, result, err)
 		}
 
 		result, err := toolResult(results)
@@ -192,7 +328,7 @@ func registerFolderListTool(s *Server) {
 
 func registerFileInfoTool(s *Server) {
 	tool := mcp.NewTool("drive_file_info",
-		mcp.WithDescription("Get detailed metadata for a Google Drive file including full path from root, owners, timestamps, and web link."),
+		mcp.WithDescription("Get detailed metadata for a Google Drive file including full path from root, owners, timestamps, web link, description, starred flag and custom properties. Google Workspace files also list the formats they can be exported to; shortcuts give the ID and path of their target."),
 		mcp.WithString("fileId", mcp.Required(), mcp.Description("Google Drive file ID")),
 	)
 
@@ -206,7 +342,7 @@ func registerFileInfoTool(s *Server) {
 			return logToolCall("drive_file_info", start, nil, err)
 		}
 
-		info, err := driveSrv.GetFileInfo(fileID)
+		info, err := driveSrv.GetFileInfo(ctx, fileID)
 		if err != nil {
 			return logToolCall("drive_file_info", start, nil, fmt.Errorf("get file info failed: %w", err))
 		}
@@ -237,12 +373,112 @@ func registerFileInfoTool(s *Server) {
 			"owners":       owners,
 			"path":         pathParts,
 		}
+		if len(info.Target) > 0 {
+			targetPath := make([]string, 0, len(info.Target))
+			for _, p := range info.Target {
+				targetPath = append(targetPath, p.Name)
+			}
+			target := info.Target[len(info.Target)-1]
+			data["shortcutTarget"] = map[string]interface{}{
+				"id":       target.ID,
+				"mimeType": target.MimeType,
+				"path":     targetPath,
+			}
+		}
+		if info.Description != "" {
+			data["description"] = info.Description
+		}
+		if info.Starred {
+			data["starred"] = true
+		}
+		if len(info.Properties) > 0 {
+			data["properties"] = info.Properties
+		}
+		if len(info.AppProperties) > 0 {
+			data["appProperties"] = info.AppProperties
+		}
+		if catalog := driveSrv.ExportCatalog(ctx); len(catalog.Exports[info.MimeType]) > 0 {
+			data["exportFormats"] = catalog.FormatNames(info.MimeType)
+			data["defaultExportFormat"] = catalog.DefaultFormat(info.MimeType)
+		}
 
 		result, err := toolResult(data)
 		return logToolCall("drive_file_info", start, result, err)
 	})
 }
 
+// propertyArgs reads the object argument name as a property map. Keys with a
+// null value are returned in unset.
+func propertyArgs(args map[string]any, name string) (map[string]string, []string, error) {
+	raw, ok := args[name]
+	if !ok || raw == nil {
+		return nil, nil, nil
+	}
+	obj, ok := raw.(map[string]any)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s must be an object of key/value pairs", name)
+	}
+	props := make(map[string]string, len(obj))
+	var unset []string
+	for k, v := range obj {
+		switch v := v.(type) {
+		case nil:
+			unset = append(unset, k)
+		case string:
+			props[k] = v
+		default:
+			return nil, nil, fmt.Errorf("%s.%s must be a string or null", name, k)
+		}
+	}
+	slices.Sort(unset)
+	return props, unset, nil
+}
+
+// metadataResult is the tool result for the metadata of a file.
+func metadataResult(meta *drive.Metadata) map[string]interface{} {
+	props, appProps := meta.Properties, meta.AppProperties
+	if props == nil {
+		props = map[string]string{}
+	}
+	if appProps == nil {
+		appProps = map[string]string{}
+	}
+	return map[string]interface{}{
+		"id":            meta.ID,
+		"name":          meta.Name,
+		"description":   meta.Description,
+		"starred":       meta.Starred,
+		"properties":    props,
+		"appProperties": appProps,
+	}
+}
+
+func registerMetadataGetTool(s *Server) {
+	tool := mcp.NewTool("drive_metadata_get",
+		mcp.WithDescription("Get the description, starred flag and custom properties of a Google Drive file. properties are visible to every app; appProperties are private to gdrive's OAuth client."),
+		mcp.WithString("fileId", mcp.Required(), mcp.Description("Google Drive file ID")),
+	)
+
+	s.mcpServer.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+
+		fileID, _ := req.GetArguments()["fileId"].(string)
+
+		driveSrv, err := getDriveService(ctx)
+		if err != nil {
+			return logToolCall("drive_metadata_get", start, nil, err)
+		}
+
+		meta, err := driveSrv.GetMetadata(ctx, fileID)
+		if err != nil {
+			return logToolCall("drive_metadata_get", start, nil, err)
+		}
+
+		result, err := toolResult(metadataResult(meta))
+		return logToolCall("drive_metadata_get", start, result, err)
+	})
+}
+
 func registerDownloadURLTool(s *Server) {
 	tool := mcp.NewTool("drive_download_url",
 		mcp.WithDescription("Get an authenticated download URL for a Google Drive file. For Google Workspace files (Docs, Sheets, Slides), use drive_export_url instead."),
@@ -260,8 +496,7 @@ func registerDownloadURLTool(s *Server) {
 		}
 
 		// Get file metadata
-		file, err := driveSrv.API.Files.Get(fileID).
-			Fields("id, name, mimeType, size").Do()
+		file, err := driveSrv.Backend.GetFile(ctx, fileID, "id, name, mimeType, size")
 		if err != nil {
 			return logToolCall("drive_download_url", start, nil, fmt.Errorf("file not found: %w", err))
 		}
@@ -295,9 +530,9 @@ func registerDownloadURLTool(s *Server) {
 
 func registerExportURLTool(s *Server) {
 	tool := mcp.NewTool("drive_export_url",
-		mcp.WithDescription("Get an authenticated export URL for Google Workspace files (Docs, Sheets, Slides). Converts to standard formats like PDF, DOCX, XLSX, PPTX, CSV, TXT, HTML."),
+		mcp.WithDescription("Get an authenticated export URL for Google Workspace files (Docs, Sheets, Slides, Drawings, Apps Script, ...). Converts to any format Drive offers for the type, e.g. PDF, DOCX, ODT, EPUB, XLSX, ODS, TSV, PPTX, PNG. drive_file_info lists the formats of a file."),
 		mcp.WithString("fileId", mcp.Required(), mcp.Description("Google Drive file ID (must be a Google Workspace file)")),
-		mcp.WithString("format", mcp.Required(), mcp.Description("Export format: pdf, docx, xlsx, pptx, csv, txt, html")),
+		mcp.WithString("format", mcp.Required(), mcp.Description("Export format as a file extension, e.g. pdf, docx, odt, md, xlsx, csv, pptx, png")),
 	)
 
 	s.mcpServer.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -312,8 +547,7 @@ func registerExportURLTool(s *Server) {
 		}
 
 		// Get file metadata
-		file, err := driveSrv.API.Files.Get(fileID).
-			Fields("id, name, mimeType").Do()
+		file, err := driveSrv.Backend.GetFile(ctx, fileID, "id, name, mimeType")
 		if err != nil {
 			return logToolCall("drive_export_url", start, nil, fmt.Errorf("file not found: %w", err))
 		}
@@ -325,16 +559,9 @@ func registerExportURLTool(s *Server) {
 		}
 
 		// Validate export format
-		exportMimeType := driveSrv.GetExportMimeType(file.MimeType, format)
-		if exportMimeType == "" {
-			supportedFormats := []string{}
-			if formats, ok := drive.ExportFormats[file.MimeType]; ok {
-				for f := range formats {
-					supportedFormats = append(supportedFormats, f)
-				}
-			}
-			return logToolCall("drive_export_url", start, nil,
-				fmt.Errorf("unsupported export format '%s' for %s. Supported formats: %s", format, file.MimeType, strings.Join(supportedFormats, ", ")))
+		format, exportMimeType, err := driveSrv.ResolveExportFormat(ctx, file.MimeType, format)
+		if err != nil {
+			return logToolCall("drive_export_url", start, nil, err)
 		}
 
 		// Get access token from context for URL
@@ -379,7 +606,7 @@ func registerActivityChangesTool(s *Server) {
 			return logToolCall("drive_activity_changes", start, nil, err)
 		}
 
-		changes, err := driveSrv.ListChanges(maxResults)
+		changes, err := driveSrv.ListChanges(ctx, maxResults)
 		if err != nil {
 			return logToolCall("drive_activity_changes", start, nil, fmt.Errorf("list changes failed: %w", err))
 		}
@@ -424,7 +651,7 @@ func registerActivityDeletedTool(s *Server) {
 			return logToolCall("drive_activity_deleted", start, nil, err)
 		}
 
-		files, err := driveSrv.ListTrashedFiles(daysBack, maxResults)
+		files, err := driveSrv.ListTrashedFiles(ctx, daysBack, maxResults)
 		if err != nil {
 			return logToolCall("drive_activity_deleted", start, nil, fmt.Errorf("list trashed files failed: %w", err))
 		}
@@ -451,6 +678,56 @@ func registerActivityDeletedTool(s *Server) {
 	})
 }
 
+func registerTrashListTool(s *Server) {
+	tool := mcp.NewTool("drive_trash_list",
+		mcp.WithDescription("List items in the Google Drive trash, most recently trashed first. Items with restorable=true can be restored with drive_restore; the others were trashed along with their folder, which must be restored instead."),
+		mcp.WithNumber("daysBack", mcp.Description("Only list items trashed in the last N days (default: all)")),
+		mcp.WithNumber("maxResults", mcp.Description("Maximum number of results (default: 100)")),
+	)
+
+	s.mcpServer.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+
+		daysBack := 0
+		if db, ok := req.GetArguments()["daysBack"].(float64); ok && db > 0 {
+			daysBack = int(db)
+		}
+		maxResults := int64(100)
+		if mr, ok := req.GetArguments()["maxResults"].(float64); ok && mr > 0 {
+			maxResults = int64(mr)
+		}
+
+		driveSrv, err := getDriveService(ctx)
+		if err != nil {
+			return logToolCall("drive_trash_list", start, nil, err)
+		}
+
+		files, err := driveSrv.ListTrashedFiles(ctx, daysBack, maxResults)
+		if err != nil {
+			return logToolCall("drive_trash_list", start, nil, fmt.Errorf("list trash failed: %w", err))
+		}
+
+		results := make([]map[string]interface{}, 0, len(files))
+		for _, f := range files {
+			entry := map[string]interface{}{
+				"id":          f.Id,
+				"name":        f.Name,
+				"mimeType":    f.MimeType,
+				"trashedTime": f.TrashedTime,
+				"size":        f.Size,
+				"restorable":  f.ExplicitlyTrashed,
+			}
+			if len(f.Parents) > 0 {
+				entry["parentId"] = f.Parents[0]
+			}
+			results = append(results, entry)
+		}
+
+		result, err := toolResult(results)
+		return logToolCall("drive_trash_list", start, result, err)
+	})
+}
+
 func registerActivityHistoryTool(s *Server) {
 	tool := mcp.NewTool("drive_activity_history",
 		mcp.WithDescription("Query comprehensive activity history from Google Drive Activity API. Includes edits, moves, permission changes, deletions, and more. Hard cap of 200 results."),
@@ -479,7 +756,7 @@ func registerActivityHistoryTool(s *Server) {
 			return logToolCall("drive_activity_history", start, nil, err)
 		}
 
-		activities, err := drive.QueryDriveActivity(activitySrv, daysBack, maxResults)
+		activities, err := drive.QueryDriveActivity(ctx, activitySrv, daysBack, maxResults)
 		if err != nil {
 			return logToolCall("drive_activity_history", start, nil, fmt.Errorf("query activity failed: %w", err))
 		}
@@ -517,7 +794,7 @@ func registerFileRevisionsTool(s *Server) {
 			return logToolCall("drive_file_revisions", start, nil, err)
 		}
 
-		revisions, err := driveSrv.ListRevisions(fileID)
+		revisions, err := driveSrv.ListRevisions(ctx, fileID)
 		if err != nil {
 			return logToolCall("drive_file_revisions", start, nil, fmt.Errorf("list revisions failed: %w", err))
 		}
@@ -538,8 +815,294 @@ func registerFileRevisionsTool(s *Server) {
 	})
 }
 
+func registerRevisionDownloadTool(s *Server) {
+	tool := mcp.NewTool("drive_revision_download",
+		mcp.WithDescription("Download the content of one revision of a Google Drive file as base64. Revisions of Google Workspace files are exported to exportMimeType (defaults to Markdown for Docs, CSV for Sheets and plain text for Slides)."),
+		mcp.WithString("fileId", mcp.Required(), mcp.Description("Google Drive file ID")),
+		mcp.WithString("revisionId", mcp.Required(), mcp.Description("Revision ID (see drive_file_revisions)")),
+		mcp.WithString("exportMimeType", mcp.Description("For Google Workspace files: MIME type to export to (e.g., 'application/pdf', 'text/csv'). Ignored for non-Workspace files.")),
+	)
+
+	s.mcpServer.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+
+		fileID, _ := req.GetArguments()["fileId"].(string)
+		revisionID, _ := req.GetArguments()["revisionId"].(string)
+		exportMimeType, _ := req.GetArguments()["exportMimeType"].(string)
+
+		driveSrv, err := getDriveService(ctx)
+		if err != nil {
+			return logToolCall("drive_revision_download", start, nil, err)
+		}
+
+		data, mimeType, err := driveSrv.RevisionContent(ctx, fileID, revisionID, exportMimeType)
+		if err != nil {
+			return logToolCall("drive_revision_download", start, nil, fmt.Errorf("download failed: %w", err))
+		}
+
+		resultData := map[string]interface{}{
+			"fileId":     fileID,
+			"revisionId": revisionID,
+			"mimeType":   mimeType,
+			"data":       base64.StdEncoding.EncodeToString(data),
+			"size":       len(data),
+		}
+
+		result, err := toolResult(resultData)
+		return logToolCall("drive_revision_download", start, result, err)
+	})
+}
+
+func registerRevisionRestoreTool(s *Server) {
+	tool := mcp.NewTool("drive_revision_restore",
+		mcp.WithDescription("Make an old revision the current content of a Google Drive file by uploading it again as a new revision. Later revisions are kept. Google Workspace revisions go through DOCX, XLSX or PPTX, which may lose some formatting."),
+		mcp.WithString("fileId", mcp.Required(), mcp.Description("Google Drive file ID")),
+		mcp.WithString("revisionId", mcp.Required(), mcp.Description("Revision ID to restore (see drive_file_revisions)")),
+	)
+
+	s.mcpServer.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+
+		fileID, _ := req.GetArguments()["fileId"].(string)
+		revisionID, _ := req.GetArguments()["revisionId"].(string)
+
+		driveSrv, err := getDriveService(ctx)
+		if err != nil {
+			return logToolCall("drive_revision_restore", start, nil, err)
+		}
+
+		file, err := driveSrv.RestoreRevision(ctx, fileID, revisionID)
+		if err != nil {
+			return logToolCall("drive_revision_restore", start, nil, fmt.Errorf("restore failed: %w", err))
+		}
+
+		data := map[string]interface{}{
+			"fileId":           file.Id,
+			"fileName":         file.Name,
+			"restoredRevision": revisionID,
+			"headRevisionId":   file.HeadRevisionId,
+			"message":          "Revision restored as the current content",
+		}
+
+		result, err := toolResult(data)
+		return logToolCall("drive_revision_restore", start, result, err)
+	})
+}
+
+func registerRevisionPinTool(s *Server) {
+	tool := mcp.NewTool("drive_revision_pin",
+		mcp.WithDescription("Pin or unpin a revision of a binary Google Drive file. Pinned revisions are kept forever; Drive purges other old revisions after 30 days or beyond 100 revisions. Google Workspace revisions cannot be pinned."),
+		mcp.WithString("fileId", mcp.Required(), mcp.Description("Google Drive file ID")),
+		mcp.WithString("revisionId", mcp.Required(), mcp.Description("Revision ID (see drive_file_revisions)")),
+		mcp.WithBoolean("keepForever", mcp.Description("true to pin the revision, false to unpin it (default: true)")),
+	)
+
+	s.mcpServer.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+
+		fileID, _ := req.GetArguments()["fileId"].(string)
+		revisionID, _ := req.GetArguments()["revisionId"].(string)
+		keep := true
+		if k, ok := req.GetArguments()["keepForever"].(bool); ok {
+			keep = k
+		}
+
+		driveSrv, err := getDriveService(ctx)
+		if err != nil {
+			return logToolCall("drive_revision_pin", start, nil, err)
+		}
+
+		rev, err := driveSrv.SetRevisionKeepForever(ctx, fileID, revisionID, keep)
+		if err != nil {
+			return logToolCall("drive_revision_pin", start, nil, fmt.Errorf("pin failed: %w", err))
+		}
+
+		data := map[string]interface{}{
+			"fileId":      fileID,
+			"revisionId":  rev.ID,
+			"keepForever": rev.KeepForever,
+		}
+
+		result, err := toolResult(data)
+		return logToolCall("drive_revision_pin", start, result, err)
+	})
+}
+
+func registerRevisionDeleteTool(s *Server) {
+	tool := mcp.NewTool("drive_revision_delete",
+		mcp.WithDescription("Permanently delete a revision of a binary Google Drive file. Deleting the current revision reverts the file to the previous one. The only revision of a file and Google Workspace revisions cannot be deleted."),
+		mcp.WithString("fileId", mcp.Required(), mcp.Description("Google Drive file ID")),
+		mcp.WithString("revisionId", mcp.Required(), mcp.Description("Revision ID to delete (see drive_file_revisions)")),
+	)
+
+	s.mcpServer.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+
+		fileID, _ := req.GetArguments()["fileId"].(string)
+		revisionID, _ := req.GetArguments()["revisionId"].(string)
+
+		driveSrv, err := getDriveService(ctx)
+		if err != nil {
+			return logToolCall("drive_revision_delete", start, nil, err)
+		}
+
+		if err := driveSrv.DeleteRevision(ctx, fileID, revisionID); err != nil {
+			return logToolCall("drive_revision_delete", start, nil, fmt.Errorf("delete failed: %w", err))
+		}
+
+		data := map[string]interface{}{
+			"fileId":     fileID,
+			"revisionId": revisionID,
+			"message":    "Revision permanently deleted",
+		}
+
+		result, err := toolResult(data)
+		return logToolCall("drive_revision_delete", start, result, err)
+	})
+}
+
 // --- Write Tools ---
 
+// commentResult is the result of the comment tools for one comment.
+func commentResult(fileID string, c *drive.Comment) map[string]interface{} {
+	replies := make([]map[string]interface{}, 0, len(c.Replies))
+	for _, r := range c.Replies {
+		replies = append(replies, map[string]interface{}{
+			"id":          r.ID,
+			"author":      r.Author,
+			"mine":        r.Mine,
+			"content":     r.Content,
+			"action":      r.Action,
+			"createdTime": r.CreatedTime.Format(time.RFC3339),
+		})
+	}
+	return map[string]interface{}{
+		"fileId":       fileID,
+		"id":           c.ID,
+		"author":       c.Author,
+		"authorEmail":  c.AuthorEmail,
+		"mine":         c.Mine,
+		"content":      c.Content,
+		"quotedText":   c.QuotedText,
+		"resolved":     c.Resolved,
+		"createdTime":  c.CreatedTime.Format(time.RFC3339),
+		"modifiedTime": c.ModifiedTime.Format(time.RFC3339),
+		"replies":      replies,
+	}
+}
+
+func registerCommentsListTool(s *Server) {
+	tool := mcp.NewTool("drive_comments_list",
+		mcp.WithDescription("List the comments of a Google Drive file, oldest first, with the text each one quotes, its author, whether it is resolved, and its replies. Use it to read review feedback on a document."),
+		mcp.WithString("fileId", mcp.Required(), mcp.Description("Google Drive file ID")),
+		mcp.WithBoolean("unresolved", mcp.Description("Only return comments that are not resolved (default: false)")),
+		mcp.WithBoolean("mine", mcp.Description("Only return comments written by the authenticated user (default: false)")),
+	)
+
+	s.mcpServer.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+
+		fileID, _ := req.GetArguments()["fileId"].(string)
+		var filter drive.CommentFilter
+		filter.Unresolved, _ = req.GetArguments()["unresolved"].(bool)
+		filter.Mine, _ = req.GetArguments()["mine"].(bool)
+
+		driveSrv, err := getDriveService(ctx)
+		if err != nil {
+			return logToolCall("drive_comments_list", start, nil, err)
+		}
+
+		comments, err := driveSrv.ListComments(ctx, fileID, filter)
+		if err != nil {
+			return logToolCall("drive_comments_list", start, nil, err)
+		}
+
+		results := make([]map[string]interface{}, 0, len(comments))
+		for _, c := range comments {
+			results = append(results, commentResult(fileID, c))
+		}
+
+		result, err := toolResult(results)
+		return logToolCall("drive_comments_list", start, result, err)
+	})
+}
+
+func registerCommentAddTool(s *Server) {
+	tool := mcp.NewTool("drive_comment_add",
+		mcp.WithDescription("Add a comment to a Google Drive file. quotedText is the text of the file the comment is about; Drive shows it with the comment, but the Docs editors do not highlight it."),
+		mcp.WithString("fileId", mcp.Required(), mcp.Description("Google Drive file ID")),
+		mcp.WithString("content", mcp.Required(), mcp.Description("Text of the comment")),
+		mcp.WithString("quotedText", mcp.Description("Text of the file the comment is about")),
+	)
+
+	s.mcpServer.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+
+		fileID, _ := req.GetArguments()["fileId"].(string)
+		content, _ := req.GetArguments()["content"].(string)
+		quote, _ := req.GetArguments()["quotedText"].(string)
+		if content == "" {
+			return logToolCall("drive_comment_add", start, nil, errors.New("content is required"))
+		}
+
+		driveSrv, err := getDriveService(ctx)
+		if err != nil {
+			return logToolCall("drive_comment_add", start, nil, err)
+		}
+
+		comment, err := driveSrv.AddComment(ctx, fileID, content, quote)
+		if err != nil {
+			return logToolCall("drive_comment_add", start, nil, err)
+		}
+
+		result, err := toolResult(commentResult(fileID, comment))
+		return logToolCall("drive_comment_add", start, result, err)
+	})
+}
+
+func registerCommentReplyTool(s *Server) {
+	tool := mcp.NewTool("drive_comment_reply",
+		mcp.WithDescription("Reply to a comment on a Google Drive file, optionally resolving it, or reopen a resolved comment. Returns the comment with its replies afterwards."),
+		mcp.WithString("fileId", mcp.Required(), mcp.Description("Google Drive file ID")),
+		mcp.WithString("commentId", mcp.Required(), mcp.Description("Comment ID (see drive_comments_list)")),
+		mcp.WithString("content", mcp.Description("Text of the reply; may be empty when resolving or reopening")),
+		mcp.WithString("action", mcp.Description("Also change the state of the comment: 'resolve' or 'reopen'"), mcp.Enum("resolve", "reopen")),
+	)
+
+	s.mcpServer.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+
+		fileID, _ := req.GetArguments()["fileId"].(string)
+		commentID, _ := req.GetArguments()["commentId"].(string)
+		content, _ := req.GetArguments()["content"].(string)
+		action, _ := req.GetArguments()["action"].(string)
+		switch {
+		case action != "" && action != "resolve" && action != "reopen":
+			return logToolCall("drive_comment_reply", start, nil, fmt.Errorf("invalid action %q: use resolve or reopen", action))
+		case action == "" && content == "":
+			return logToolCall("drive_comment_reply", start, nil, errors.New("content is required unless action is set"))
+		}
+
+		driveSrv, err := getDriveService(ctx)
+		if err != nil {
+			return logToolCall("drive_comment_reply", start, nil, err)
+		}
+
+		var comment *drive.Comment
+		if action != "" {
+			comment, err = driveSrv.ResolveComment(ctx, fileID, commentID, content, action == "reopen")
+		} else if _, err = driveSrv.ReplyToComment(ctx, fileID, commentID, content); err == nil {
+			comment, err = driveSrv.GetComment(ctx, fileID, commentID)
+		}
+		if err != nil {
+			return logToolCall("drive_comment_reply", start, nil, err)
+		}
+
+		result, err := toolResult(commentResult(fileID, comment))
+		return logToolCall("drive_comment_reply", start, result, err)
+	})
+}
+
 func registerDeleteTool(s *Server) {
 	tool := mcp.NewTool("drive_delete",
 		mcp.WithDescription("Move a file or folder to trash in Google Drive. This is a soft delete - files can be recovered from trash."),
@@ -557,7 +1120,7 @@ func registerDeleteTool(s *Server) {
 		}
 
 		// Get file info first for the response
-		file, err := driveSrv.API.Files.Get(fileID).Fields("id, name, trashed").Do()
+		file, err := driveSrv.Backend.GetFile(ctx, fileID, "id, name, trashed")
 		if err != nil {
 			return logToolCall("drive_delete", start, nil, fmt.Errorf("file not found: %w", err))
 		}
@@ -573,8 +1136,7 @@ func registerDeleteTool(s *Server) {
 		}
 
 		// Soft delete: set trashed = true
-		_, err = driveSrv.API.Files.Update(fileID, &driveapi.File{Trashed: true}).Do()
-		if err != nil {
+		if _, err := driveSrv.TrashFile(ctx, fileID); err != nil {
 			return logToolCall("drive_delete", start, nil, fmt.Errorf("trash file failed: %w", err))
 		}
 
@@ -589,6 +1151,39 @@ func registerDeleteTool(s *Server) {
 	})
 }
 
+func registerRestoreTool(s *Server) {
+	tool := mcp.NewTool("drive_restore",
+		mcp.WithDescription("Restore a trashed file or folder in Google Drive to its original folder. Restoring a folder restores its content."),
+		mcp.WithString("fileId", mcp.Required(), mcp.Description("ID of the trashed file or folder (see drive_trash_list)")),
+	)
+
+	s.mcpServer.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+
+		fileID, _ := req.GetArguments()["fileId"].(string)
+
+		driveSrv, err := getDriveService(ctx)
+		if err != nil {
+			return logToolCall("drive_restore", start, nil, err)
+		}
+
+		file, err := driveSrv.RestoreFile(ctx, fileID)
+		if err != nil {
+			return logToolCall("drive_restore", start, nil, fmt.Errorf("restore failed: %w", err))
+		}
+
+		data := map[string]interface{}{
+			"fileId":   file.Id,
+			"fileName": file.Name,
+			"parents":  file.Parents,
+			"message":  "File restored to its original folder",
+		}
+
+		result, err := toolResult(data)
+		return logToolCall("drive_restore", start, result, err)
+	})
+}
+
 func registerRenameTool(s *Server) {
 	tool := mcp.NewTool("drive_rename",
 		mcp.WithDescription("Rename a file or folder in Google Drive."),
@@ -611,7 +1206,7 @@ func registerRenameTool(s *Server) {
 			return logToolCall("drive_rename", start, nil, err)
 		}
 
-		file, err := driveSrv.RenameFile(fileID, newName)
+		file, err := driveSrv.RenameFile(ctx, fileID, newName)
 		if err != nil {
 			return logToolCall("drive_rename", start, nil, fmt.Errorf("rename failed: %w", err))
 		}
@@ -645,7 +1240,7 @@ func registerMoveTool(s *Server) {
 			return logToolCall("drive_move", start, nil, err)
 		}
 
-		file, err := driveSrv.MoveFile(fileID, targetFolderID)
+		file, err := driveSrv.MoveFile(ctx, fileID, targetFolderID)
 		if err != nil {
 			return logToolCall("drive_move", start, nil, fmt.Errorf("move failed: %w", err))
 		}
@@ -680,7 +1275,7 @@ func registerCopyTool(s *Server) {
 			return logToolCall("drive_copy", start, nil, err)
 		}
 
-		file, err := driveSrv.CopyFile(fileID, drive.CopyOptions{
+		file, err := driveSrv.CopyFile(ctx, fileID, drive.CopyOptions{
 			NewName:        newName,
 			ParentFolderID: targetFolderID,
 		})
@@ -727,8 +1322,7 @@ func registerFolderCreateTool(s *Server) {
 			Parents:  []string{parentFolderID},
 		}
 
-		created, err := driveSrv.API.Files.Create(folder).
-			Fields("id, name, mimeType, webViewLink").Do()
+		created, err := driveSrv.Backend.CreateFile(ctx, folder, nil, "id, name, mimeType, webViewLink")
 		if err != nil {
 			return logToolCall("drive_folder_create", start, nil, fmt.Errorf("create folder failed: %w", err))
 		}
@@ -745,6 +1339,94 @@ func registerFolderCreateTool(s *Server) {
 	})
 }
 
+func registerShortcutCreateTool(s *Server) {
+	tool := mcp.NewTool("drive_shortcut_create",
+		mcp.WithDescription("Create a shortcut to a file or folder in another folder, e.g. to make a shared item appear in My Drive. The shortcut is named after its target unless a name is given."),
+		mcp.WithString("targetId", mcp.Required(), mcp.Description("ID of the file or folder the shortcut points to")),
+		mcp.WithString("parentFolderId", mcp.Required(), mcp.Description("ID of the folder to create the shortcut in (use 'root' for My Drive root)")),
+		mcp.WithString("name", mcp.Description("Optional name for the shortcut")),
+	)
+
+	s.mcpServer.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+
+		targetID, _ := req.GetArguments()["targetId"].(string)
+		parentFolderID, _ := req.GetArguments()["parentFolderId"].(string)
+		name, _ := req.GetArguments()["name"].(string)
+
+		driveSrv, err := getDriveService(ctx)
+		if err != nil {
+			return logToolCall("drive_shortcut_create", start, nil, err)
+		}
+
+		shortcut, err := driveSrv.CreateShortcut(ctx, targetID, parentFolderID, name)
+		if err != nil {
+			return logToolCall("drive_shortcut_create", start, nil, err)
+		}
+
+		data := map[string]interface{}{
+			"id":          shortcut.Id,
+			"name":        shortcut.Name,
+			"targetId":    targetID,
+			"webViewLink": shortcut.WebViewLink,
+		}
+
+		result, err := toolResult(data)
+		return logToolCall("drive_shortcut_create", start, result, err)
+	})
+}
+
+func registerMetadataUpdateTool(s *Server) {
+	tool := mcp.NewTool("drive_metadata_update",
+		mcp.WithDescription("Update the description, starred flag or custom properties of a Google Drive file. Properties not mentioned are kept; a null value removes a property. Each property may hold 124 bytes, key and value together."),
+		mcp.WithString("fileId", mcp.Required(), mcp.Description("Google Drive file ID")),
+		mcp.WithObject("properties", mcp.Description("Properties to set, e.g. {\"project\": \"ACME-42\", \"draft\": null}")),
+		mcp.WithObject("appProperties", mcp.Description("appProperties to set, with null to remove a key")),
+		mcp.WithString("description", mcp.Description("New description; an empty string clears it")),
+		mcp.WithBoolean("starred", mcp.Description("true to star the file, false to unstar it")),
+	)
+
+	s.mcpServer.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+
+		args := req.GetArguments()
+		fileID, _ := args["fileId"].(string)
+
+		var (
+			update drive.MetadataUpdate
+			err    error
+		)
+		if update.Properties, update.Unset, err = propertyArgs(args, "properties"); err != nil {
+			return logToolCall("drive_metadata_update", start, nil, err)
+		}
+		if update.AppProperties, update.UnsetApp, err = propertyArgs(args, "appProperties"); err != nil {
+			return logToolCall("drive_metadata_update", start, nil, err)
+		}
+		if d, ok := args["description"].(string); ok {
+			update.Description = &d
+		}
+		if st, ok := args["starred"].(bool); ok {
+			update.Starred = &st
+		}
+		if update.IsEmpty() {
+			return logToolCall("drive_metadata_update", start, nil, errors.New("nothing to update: give properties, appProperties, description or starred"))
+		}
+
+		driveSrv, err := getDriveService(ctx)
+		if err != nil {
+			return logToolCall("drive_metadata_update", start, nil, err)
+		}
+
+		meta, err := driveSrv.UpdateMetadata(ctx, fileID, update)
+		if err != nil {
+			return logToolCall("drive_metadata_update", start, nil, err)
+		}
+
+		result, err := toolResult(metadataResult(meta))
+		return logToolCall("drive_metadata_update", start, result, err)
+	})
+}
+
 func registerPermissionsListTool(s *Server) {
 	tool := mcp.NewTool("drive_permissions_list",
 		mcp.WithDescription("List all permissions (sharing settings) for a Google Drive file or folder."),
@@ -761,120 +1443,169 @@ func registerPermissionsListTool(s *Server) {
 			return logToolCall("drive_permissions_list", start, nil, err)
 		}
 
-		perms, err := driveSrv.ListPermissions(fileID)
+		perms, err := driveSrv.ListPermissions(ctx, fileID)
 		if err != nil {
 			return logToolCall("drive_permissions_list", start, nil, fmt.Errorf("list permissions failed: %w", err))
 		}
 
-		results := make([]map[string]interface{}, 0, len(perms))
-		for _, p := range perms {
-			results = append(results, map[string]interface{}{
-				"id":           p.Id,
-				"type":         p.Type,
-				"role":         p.Role,
-				"emailAddress": p.EmailAddress,
-				"displayName":  p.DisplayName,
-				"domain":       p.Domain,
-			})
-		}
-
-		result, err := toolResult(results)
+		result, err := toolResult(permissionResults(perms))
 		return logToolCall("drive_permissions_list", start, result, err)
 	})
 }
 
 func registerPermissionsUpdateTool(s *Server) {
 	tool := mcp.NewTool("drive_permissions_update",
-		mcp.WithDescription("Add or remove permissions on a Google Drive file. For adding: specify type (user/anyone), role (reader/writer/commenter), and email (if type=user). For removing: specify permissionId."),
+		mcp.WithDescription("Add, update or remove permissions on a Google Drive file, or transfer its ownership. "+
+			"add: specify type (user/group/domain/anyone), role (reader/writer/commenter), email (user, group) or domain, and optionally expirationTime (user, group). "+
+			"update: specify permissionId and a new role, expirationTime or removeExpiration. "+
+			"remove: specify permissionId. "+
+			"transfer-ownership: specify email; the user becomes pending owner and owns the file once they accept."),
 		mcp.WithString("fileId", mcp.Required(), mcp.Description("Google Drive file or folder ID")),
-		mcp.WithString("action", mcp.Required(), mcp.Description("Action to perform: 'add' or 'remove'")),
-		mcp.WithString("type", mcp.Description("Permission type for add: 'user' or 'anyone'")),
-		mcp.WithString("role", mcp.Description("Permission role for add: 'reader', 'writer', or 'commenter'")),
-		mcp.WithString("email", mcp.Description("Email address (required when type='user')")),
-		mcp.WithBoolean("notify", mcp.Description("Send notification email (default: true, only for add+user)")),
+		mcp.WithString("action", mcp.Required(), mcp.Description("Action to perform"),
+			mcp.Enum("add", "update", "remove", "transfer-ownership")),
+		mcp.WithString("type", mcp.Description("Permission type for add"), mcp.Enum("user", "group", "domain", "anyone")),
+		mcp.WithString("role", mcp.Description("Permission role for add and update: 'reader', 'writer', or 'commenter'")),
+		mcp.WithString("email", mcp.Description("Email address (required for add with type 'user' or 'group', and for transfer-ownership)")),
+		mcp.WithString("domain", mcp.Description("Domain name (required for add with type 'domain')")),
+		mcp.WithString("expirationTime", mcp.Description("When the access ends, YYYY-MM-DD or RFC 3339 (add and update, users and groups only)")),
+		mcp.WithBoolean("removeExpiration", mcp.Description("Make the permission permanent (update only)")),
+		mcp.WithBoolean("discoverable", mcp.Description("Let people find the file by search (add with type 'domain' or 'anyone')")),
+		mcp.WithBoolean("notify", mcp.Description("Send notification email (default: true, only for add with type 'user' or 'group')")),
 		mcp.WithString("message", mcp.Description("Custom message for notification email")),
-		mcp.WithString("permissionId", mcp.Description("Permission ID to remove (required for 'remove' action)")),
+		mcp.WithString("permissionId", mcp.Description("Permission ID to update or remove (required for 'update' and 'remove')")),
 	)
 
 	s.mcpServer.AddTool(tool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		start := time.Now()
 
-		fileID, _ := req.GetArguments()["fileId"].(string)
-		action, _ := req.GetArguments()["action"].(string)
+		args := req.GetArguments()
+		fileID, _ := args["fileId"].(string)
+		action, _ := args["action"].(string)
+		role, _ := args["role"].(string)
+		permissionID, _ := args["permissionId"].(string)
+
+		var expires time.Time
+		if v, _ := args["expirationTime"].(string); v != "" {
+			t, err := drive.ParseDate(v)
+			if err != nil {
+				return logToolCall("drive_permissions_update", start, nil, fmt.Errorf("invalid expirationTime: %w", err))
+			}
+			expires = t
+		}
 
 		driveSrv, err := getDriveService(ctx)
 		if err != nil {
 			return logToolCall("drive_permissions_update", start, nil, err)
 		}
+		// Administrators go past the policy with the CLI, not through tools
+		driveSrv.Policy = s.policy
 
 		switch action {
 		case "add":
-			permType, _ := req.GetArguments()["type"].(string)
-			role, _ := req.GetArguments()["role"].(string)
-			email, _ := req.GetArguments()["email"].(string)
-			message, _ := req.GetArguments()["message"].(string)
+			permType, _ := args["type"].(string)
+			email, _ := args["email"].(string)
+			domain, _ := args["domain"].(string)
+			message, _ := args["message"].(string)
+			discoverable, _ := args["discoverable"].(bool)
 			notify := true
-			if n, ok := req.GetArguments()["notify"].(bool); ok {
+			if n, ok := args["notify"].(bool); ok {
 				notify = n
 			}
 
-			if permType == "user" {
+			switch permType {
+			case "user", "group":
 				if email == "" {
-					return logToolCall("drive_permissions_update", start, nil, fmt.Errorf("email is required when type is 'user'"))
+					return logToolCall("drive_permissions_update", start, nil, fmt.Errorf("email is required when type is '%s'", permType))
+				}
+			case "domain":
+				if domain == "" {
+					return logToolCall("drive_permissions_update", start, nil, fmt.Errorf("domain is required when type is 'domain'"))
 				}
-				err = driveSrv.ShareFile(fileID, drive.ShareOptions{
-					Email:   email,
-					Role:    role,
-					Notify:  notify,
-					Message: message,
-				})
-			} else if permType == "anyone" {
-				err = driveSrv.ShareWithAnyone(fileID, role)
-			} else {
-				return logToolCall("drive_permissions_update", start, nil, fmt.Errorf("type must be 'user' or 'anyone'"))
+			case "anyone":
+			default:
+				return logToolCall("drive_permissions_update", start, nil, fmt.Errorf("type must be 'user', 'group', 'domain' or 'anyone'"))
 			}
 
+			_, err = driveSrv.ShareFile(ctx, fileID, drive.ShareOptions{
+				Type:         permType,
+				Email:        email,
+				Domain:       domain,
+				Role:         role,
+				Notify:       notify,
+				Message:      message,
+				Expires:      expires,
+				Discoverable: discoverable,
+			})
 			if err != nil {
 				return logToolCall("drive_permissions_update", start, nil, fmt.Errorf("add permission failed: %w", err))
 			}
 
+		case "update":
+			if permissionID == "" {
+				return logToolCall("drive_permissions_update", start, nil, fmt.Errorf("permissionId is required for update action"))
+			}
+			removeExpiration, _ := args["removeExpiration"].(bool)
+			_, err = driveSrv.UpdatePermission(ctx, fileID, permissionID, drive.PermissionUpdate{
+				Role:             role,
+				Expires:          expires,
+				RemoveExpiration: removeExpiration,
+			})
+			if err != nil {
+				return logToolCall("drive_permissions_update", start, nil, fmt.Errorf("update permission failed: %w", err))
+			}
+
 		case "remove":
-			permissionID, _ := req.GetArguments()["permissionId"].(string)
 			if permissionID == "" {
 				return logToolCall("drive_permissions_update", start, nil, fmt.Errorf("permissionId is required for remove action"))
 			}
-			err = driveSrv.RemovePermission(fileID, permissionID)
+			err = driveSrv.RemovePermission(ctx, fileID, permissionID)
 			if err != nil {
 				return logToolCall("drive_permissions_update", start, nil, fmt.Errorf("remove permission failed: %w", err))
 			}
 
+		case "transfer-ownership":
+			email, _ := args["email"].(string)
+			if email == "" {
+				return logToolCall("drive_permissions_update", start, nil, fmt.Errorf("email is required for transfer-ownership action"))
+			}
+			if _, err = driveSrv.TransferOwnership(ctx, fileID, email); err != nil {
+				return logToolCall("drive_permissions_update", start, nil, fmt.Errorf("transfer ownership failed: %w", err))
+			}
+
 		default:
-			return logToolCall("drive_permissions_update", start, nil, fmt.Errorf("action must be 'add' or 'remove'"))
+			return logToolCall("drive_permissions_update", start, nil, fmt.Errorf("action must be 'add', 'update', 'remove' or 'transfer-ownership'"))
 		}
 
 		// Return updated permissions list
-		perms, err := driveSrv.ListPermissions(fileID)
+		perms, err := driveSrv.ListPermissions(ctx, fileID)
 		if err != nil {
 			return logToolCall("drive_permissions_update", start, nil, fmt.Errorf("list permissions failed: %w", err))
 		}
 
-		results := make([]map[string]interface{}, 0, len(perms))
-		for _, p := range perms {
-			results = append(results, map[string]interface{}{
-				"id":           p.Id,
-				"type":         p.Type,
-				"role":         p.Role,
-				"emailAddress": p.EmailAddress,
-				"displayName":  p.DisplayName,
-				"domain":       p.Domain,
-			})
-		}
-
-		result, err := toolResult(results)
+		result, err := toolResult(permissionResults(perms))
 		return logToolCall("drive_permissions_update", start, result, err)
 	})
 }
 
+// permissionResults converts permissions to the results of the permission
+// tools.
+func permissionResults(perms []*driveapi.Permission) []map[string]interface{} {
+	results := make([]map[string]interface{}, 0, len(perms))
+	for _, p := range perms {
+		results = append(results, map[string]interface{}{
+			"id":             p.Id,
+			"type":           p.Type,
+			"role":           p.Role,
+			"emailAddress":   p.EmailAddress,
+			"displayName":    p.DisplayName,
+			"domain":         p.Domain,
+			"expirationTime": p.ExpirationTime,
+			"pendingOwner":   p.PendingOwner,
+		})
+	}
+	return results
+}
+
 func registerCreateUploadURLTool(s *Server) {
 	tool := mcp.NewTool("drive_create_upload_url",
 		mcp.WithDescription("Create a resumable upload URL for uploading a file to Google Drive. If a file with the same name exists in the target folder, it will create a new version (update)."),
@@ -901,7 +1632,11 @@ func registerCreateUploadURLTool(s *Server) {
 		}
 
 		// Check if file already exists (for versioning)
-		existing, _ := driveSrv.FindFile(fileName, folderID)
+		existing, err := driveSrv.FindFile(ctx, fileName, folderID)
+		var ambiguous *drive.AmbiguousPathError
+		if errors.As(err, &ambiguous) {
+			return logToolCall("drive_create_upload_url", start, nil, fmt.Errorf("cannot choose the file to version: %w", err))
+		}
 		isUpdate := existing != nil
 
 		// Get access token
@@ -952,7 +1687,7 @@ func registerReadContentTool(s *Server) {
 			return logToolCall("drive_read_content", start, nil, err)
 		}
 
-		content, mimeType, truncated, err := driveSrv.ReadFileContent(fileID)
+		content, mimeType, truncated, err := driveSrv.ReadFileContent(ctx, fileID)
 		if err != nil {
 			return logToolCall("drive_read_content", start, nil, fmt.Errorf("read failed: %w", err))
 		}
@@ -992,7 +1727,7 @@ func registerListRecentTool(s *Server) {
 			return logToolCall("drive_list_recent", start, nil, err)
 		}
 
-		files, nextPageToken, err := driveSrv.ListRecentFiles(orderBy, pageSize, pageToken)
+		files, nextPageToken, err := driveSrv.ListRecentFiles(ctx, orderBy, pageSize, pageToken)
 		if err != nil {
 			return logToolCall("drive_list_recent", start, nil, fmt.Errorf("list failed: %w", err))
 		}
@@ -1036,7 +1771,7 @@ func registerDownloadContentTool(s *Server) {
 			return logToolCall("drive_download_content", start, nil, err)
 		}
 
-		data, mimeType, err := driveSrv.DownloadFileContent(fileID, exportMimeType)
+		data, mimeType, err := driveSrv.DownloadFileContent(ctx, fileID, exportMimeType)
 		if err != nil {
 			return logToolCall("drive_download_content", start, nil, fmt.Errorf("download failed: %w", err))
 		}
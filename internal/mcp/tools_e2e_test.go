@@ -1,6 +1,7 @@
 package mcp
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -52,6 +53,45 @@ func TestDriveSearch(t *testing.T) {
 			t.Errorf("expected no results, got %d", len(data))
 		}
 	})
+
+	t.Run("restricted to shared drive", func(t *testing.T) {
+		result, err := callTool(t, srv, "drive_search", map[string]interface{}{
+			"query":   "Team",
+			"driveId": "shared-drive-1",
+		})
+		if err != nil {
+			t.Fatalf("search failed: %v", err)
+		}
+
+		data := extractResultArray(t, result)
+		if len(data) != 1 {
+			t.Fatalf("expected 1 result, got %d", len(data))
+		}
+		item, _ := data[0].(map[string]interface{})
+		if item["id"] != "shared-doc" {
+			t.Errorf("expected shared-doc, got %v", item["id"])
+		}
+	})
+}
+
+// --- drive_shared_drives_list ---
+
+func TestDriveSharedDrivesList(t *testing.T) {
+	srv := setupToolTest(t)
+
+	result, err := callTool(t, srv, "drive_shared_drives_list", nil)
+	if err != nil {
+		t.Fatalf("shared drives list failed: %v", err)
+	}
+
+	data := extractResultArray(t, result)
+	if len(data) != 1 {
+		t.Fatalf("expected 1 shared drive, got %d", len(data))
+	}
+	sd, _ := data[0].(map[string]interface{})
+	if sd["name"] != "Engineering" {
+		t.Errorf("expected name=Engineering, got %v", sd["name"])
+	}
 }
 
 // --- drive_folder_list ---
@@ -86,6 +126,51 @@ func TestDriveFolderList(t *testing.T) {
 			t.Errorf("expected empty, got %d items", len(data))
 		}
 	})
+
+	t.Run("paginated", func(t *testing.T) {
+		seen := map[string]bool{}
+		pageToken := ""
+		for pages := 1; ; pages++ {
+			if pages > 10 {
+				t.Fatal("pagination did not terminate")
+			}
+			args := map[string]interface{}{"folderId": "folder-1", "pageSize": float64(3)}
+			if pageToken != "" {
+				args["pageToken"] = pageToken
+			}
+			result, err := callTool(t, srv, "drive_folder_list", args)
+			if err != nil {
+				t.Fatalf("folder list failed: %v", err)
+			}
+
+			data := extractResultJSON(t, result)
+			files, _ := data["files"].([]interface{})
+			if len(files) > 3 {
+				t.Fatalf("page has %d items, want at most 3", len(files))
+			}
+			for _, f := range files {
+				id, _ := f.(map[string]interface{})["id"].(string)
+				if seen[id] {
+					t.Errorf("item %s returned twice", id)
+				}
+				seen[id] = true
+			}
+			pageToken, _ = data["nextPageToken"].(string)
+			if pageToken == "" {
+				break
+			}
+		}
+
+		// Every page together must match the unpaginated listing.
+		result, err := callTool(t, srv, "drive_folder_list", map[string]interface{}{"folderId": "folder-1"})
+		if err != nil {
+			t.Fatalf("folder list failed: %v", err)
+		}
+		all := extractResultArray(t, result)
+		if len(all) != len(seen) {
+			t.Errorf("pages returned %d items, full listing %d", len(seen), len(all))
+		}
+	})
 }
 
 // --- drive_file_info ---
@@ -157,6 +242,21 @@ func TestDriveDownloadURL(t *testing.T) {
 			t.Error("expected error (no access token in test context)")
 		}
 	})
+
+	t.Run("ambiguous existing file", func(t *testing.T) {
+		_, err := callTool(t, srv, "drive_create_upload_url", map[string]interface{}{
+			"fileName": "Minutes.txt",
+			"folderId": "dup-folder",
+		})
+		if err == nil || !strings.Contains(err.Error(), "ambiguous") {
+			t.Fatalf("expected ambiguous name error, got %v", err)
+		}
+		for _, id := range []string{"minutes-old", "minutes-new"} {
+			if !strings.Contains(err.Error(), id) {
+				t.Errorf("error does not list candidate %s: %v", id, err)
+			}
+		}
+	})
 }
 
 // --- drive_export_url ---
@@ -0,0 +1,459 @@
+package mcp
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	driveapi "google.golang.org/api/drive/v3"
+
+	"gdrive/internal/drivetest"
+)
+
+// Tests in this file run the tools against the stateful fake Drive server
+// and check their effect on Drive, not just the shape of the response.
+
+func TestWriteToolsChangeDriveState(t *testing.T) {
+	srv, fake := setupFakeDriveTest(t)
+	fileID := fake.AddFile(drivetest.RootID, "report.pdf", []byte("%PDF"))
+
+	result, err := callTool(t, srv, "drive_folder_create", map[string]interface{}{
+		"parentFolderId": "root",
+		"name":           "Reports",
+	})
+	if err != nil {
+		t.Fatalf("folder create failed: %v", err)
+	}
+	folderID, _ := extractResultJSON(t, result)["id"].(string)
+	if f := fake.File(folderID); f == nil || f.Name != "Reports" {
+		t.Fatalf("folder not created: %+v", f)
+	}
+
+	if _, err := callTool(t, srv, "drive_move", map[string]interface{}{
+		"fileId":         fileID,
+		"targetFolderId": folderID,
+	}); err != nil {
+		t.Fatalf("move failed: %v", err)
+	}
+	if parents := fake.File(fileID).Parents; len(parents) != 1 || parents[0] != folderID {
+		t.Errorf("parents after move = %v", parents)
+	}
+
+	if _, err := callTool(t, srv, "drive_delete", map[string]interface{}{"fileId": folderID}); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if !fake.File(folderID).Trashed || !fake.File(fileID).Trashed {
+		t.Error("trashing the folder did not trash its content")
+	}
+}
+
+func TestFileRevisionsFromFakeDrive(t *testing.T) {
+	srv, fake := setupFakeDriveTest(t)
+	fileID := fake.AddFile(drivetest.RootID, "notes.txt", []byte("v1"))
+	fake.Update(fileID, []byte("v2"))
+
+	result, err := callTool(t, srv, "drive_file_revisions", map[string]interface{}{"fileId": fileID})
+	if err != nil {
+		t.Fatalf("revisions failed: %v", err)
+	}
+	if revs := extractResultArray(t, result); len(revs) != 2 {
+		t.Errorf("got %d revisions, want 2", len(revs))
+	}
+}
+
+func TestTrashListAndRestore(t *testing.T) {
+	srv, fake := setupFakeDriveTest(t)
+	folderID := fake.AddFolder(drivetest.RootID, "Old")
+	fileID := fake.AddFile(folderID, "notes.txt", []byte("notes"))
+
+	if _, err := callTool(t, srv, "drive_delete", map[string]interface{}{"fileId": folderID}); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+
+	result, err := callTool(t, srv, "drive_trash_list", nil)
+	if err != nil {
+		t.Fatalf("trash list failed: %v", err)
+	}
+	restorable := map[string]bool{}
+	for _, item := range extractResultArray(t, result) {
+		entry := item.(map[string]interface{})
+		restorable[entry["id"].(string)], _ = entry["restorable"].(bool)
+	}
+	if !restorable[folderID] || restorable[fileID] {
+		t.Errorf("restorable = %v; want only the folder", restorable)
+	}
+
+	if _, err := callTool(t, srv, "drive_restore", map[string]interface{}{"fileId": fileID}); err == nil {
+		t.Error("restoring a file trashed with its folder should fail")
+	}
+	if _, err := callTool(t, srv, "drive_restore", map[string]interface{}{"fileId": folderID}); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if fake.File(folderID).Trashed || fake.File(fileID).Trashed {
+		t.Error("folder or its content still trashed")
+	}
+}
+
+func TestRevisionTools(t *testing.T) {
+	srv, fake := setupFakeDriveTest(t)
+	fileID := fake.AddFile(drivetest.RootID, "notes.txt", []byte("v1"))
+	fake.Update(fileID, []byte("v2"))
+	first := fake.Revisions(fileID)[0].Id
+	args := map[string]interface{}{"fileId": fileID, "revisionId": first}
+
+	result, err := callTool(t, srv, "drive_revision_download", args)
+	if err != nil {
+		t.Fatalf("revision download failed: %v", err)
+	}
+	if data, _ := extractResultJSON(t, result)["data"].(string); data != base64.StdEncoding.EncodeToString([]byte("v1")) {
+		t.Errorf("revision data = %q", data)
+	}
+
+	if _, err := callTool(t, srv, "drive_revision_pin", args); err != nil {
+		t.Fatalf("pin failed: %v", err)
+	}
+	if !fake.Revisions(fileID)[0].KeepForever {
+		t.Error("revision not pinned")
+	}
+
+	if _, err := callTool(t, srv, "drive_revision_restore", args); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if got := string(fake.Content(fileID)); got != "v1" {
+		t.Errorf("content after restore = %q", got)
+	}
+
+	if _, err := callTool(t, srv, "drive_revision_delete", args); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if n := len(fake.Revisions(fileID)); n != 2 {
+		t.Errorf("got %d revisions after delete, want 2", n)
+	}
+}
+
+func TestFileInfoListsExportFormats(t *testing.T) {
+	srv, fake := setupFakeDriveTest(t)
+	docID := fake.AddDoc(drivetest.RootID, "Plan", "application/vnd.google-apps.document", []byte("plan"))
+
+	result, err := callTool(t, srv, "drive_file_info", map[string]interface{}{"fileId": docID})
+	if err != nil {
+		t.Fatalf("file info failed: %v", err)
+	}
+	data := extractResultJSON(t, result)
+	formats, _ := data["exportFormats"].([]interface{})
+	if !slices.Contains(formats, interface{}("odt")) || data["defaultExportFormat"] != "pdf" {
+		t.Errorf("exportFormats = %v, default = %v", formats, data["defaultExportFormat"])
+	}
+
+	fileID := fake.AddFile(drivetest.RootID, "notes.txt", []byte("notes"))
+	result, err = callTool(t, srv, "drive_file_info", map[string]interface{}{"fileId": fileID})
+	if err != nil {
+		t.Fatalf("file info failed: %v", err)
+	}
+	if _, ok := extractResultJSON(t, result)["exportFormats"]; ok {
+		t.Error("binary file lists export formats")
+	}
+}
+
+func TestDownloadContentOverExportLimit(t *testing.T) {
+	srv, fake := setupFakeDriveTest(t)
+	docID := fake.AddDoc(drivetest.RootID, "Handbook", "application/vnd.google-apps.document", []byte("a very long document"))
+	fake.SetExportLimit(4)
+
+	result, err := callTool(t, srv, "drive_download_content", map[string]interface{}{
+		"fileId":         docID,
+		"exportMimeType": "application/pdf",
+	})
+	if err != nil {
+		t.Fatalf("download content failed: %v", err)
+	}
+	if data, _ := extractResultJSON(t, result)["data"].(string); data != base64.StdEncoding.EncodeToString([]byte("a very long document")) {
+		t.Errorf("exported data = %q", data)
+	}
+}
+
+func TestShortcutTools(t *testing.T) {
+	srv, fake := setupFakeDriveTest(t)
+	folderID := fake.AddFolder(drivetest.RootID, "Inbox")
+	targetID := fake.AddFile(drivetest.RootID, "report.pdf", []byte("%PDF"))
+
+	result, err := callTool(t, srv, "drive_shortcut_create", map[string]interface{}{
+		"targetId":       targetID,
+		"parentFolderId": folderID,
+	})
+	if err != nil {
+		t.Fatalf("shortcut create failed: %v", err)
+	}
+	shortcutID, _ := extractResultJSON(t, result)["id"].(string)
+	if f := fake.File(shortcutID); f == nil || f.Name != "report.pdf" || f.ShortcutDetails.TargetId != targetID {
+		t.Fatalf("shortcut not created: %+v", f)
+	}
+
+	result, err = callTool(t, srv, "drive_folder_list", map[string]interface{}{"folderId": folderID})
+	if err != nil {
+		t.Fatalf("folder list failed: %v", err)
+	}
+	if items := extractResultArray(t, result); len(items) != 1 || items[0].(map[string]interface{})["targetId"] != targetID {
+		t.Errorf("folder list = %v", items)
+	}
+
+	result, err = callTool(t, srv, "drive_file_info", map[string]interface{}{"fileId": shortcutID})
+	if err != nil {
+		t.Fatalf("file info failed: %v", err)
+	}
+	if target, _ := extractResultJSON(t, result)["shortcutTarget"].(map[string]interface{}); target["id"] != targetID {
+		t.Errorf("shortcutTarget = %v", target)
+	}
+
+	result, err = callTool(t, srv, "drive_download_content", map[string]interface{}{"fileId": shortcutID})
+	if err != nil {
+		t.Fatalf("download content failed: %v", err)
+	}
+	if data, _ := extractResultJSON(t, result)["data"].(string); data != base64.StdEncoding.EncodeToString([]byte("%PDF")) {
+		t.Errorf("content through shortcut = %q", data)
+	}
+}
+
+func TestMetadataTools(t *testing.T) {
+	srv, fake := setupFakeDriveTest(t)
+	id := fake.AddFile(drivetest.RootID, "q3.pdf", []byte("%PDF"))
+
+	if _, err := callTool(t, srv, "drive_metadata_update", map[string]interface{}{
+		"fileId":        id,
+		"properties":    map[string]interface{}{"project": "ACME-42", "state": "draft"},
+		"appProperties": map[string]interface{}{"pipeline": "ingested"},
+		"description":   "Q3 figures",
+		"starred":       true,
+	}); err != nil {
+		t.Fatalf("metadata update failed: %v", err)
+	}
+	result, err := callTool(t, srv, "drive_metadata_update", map[string]interface{}{
+		"fileId":     id,
+		"properties": map[string]interface{}{"state": nil},
+	})
+	if err != nil {
+		t.Fatalf("metadata unset failed: %v", err)
+	}
+	props, _ := extractResultJSON(t, result)["properties"].(map[string]interface{})
+	if len(props) != 1 || props["project"] != "ACME-42" {
+		t.Errorf("properties after unset = %v", props)
+	}
+
+	result, err = callTool(t, srv, "drive_metadata_get", map[string]interface{}{"fileId": id})
+	if err != nil {
+		t.Fatalf("metadata get failed: %v", err)
+	}
+	meta := extractResultJSON(t, result)
+	if meta["description"] != "Q3 figures" || meta["starred"] != true {
+		t.Errorf("metadata = %v", meta)
+	}
+
+	result, err = callTool(t, srv, "drive_search", map[string]interface{}{
+		"query":         "",
+		"properties":    map[string]interface{}{"project": "ACME-42"},
+		"appProperties": map[string]interface{}{"pipeline": "ingested"},
+	})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if items := extractResultArray(t, result); len(items) != 1 || items[0].(map[string]interface{})["id"] != id {
+		t.Errorf("search by properties = %v", items)
+	}
+
+	if _, err := callTool(t, srv, "drive_metadata_update", map[string]interface{}{"fileId": id}); err == nil {
+		t.Error("update without changes should fail")
+	}
+}
+
+func TestCommentTools(t *testing.T) {
+	srv, fake := setupFakeDriveTest(t)
+	id := fake.AddDoc(drivetest.RootID, "Plan", "application/vnd.google-apps.document", []byte("The launch is in May."))
+	alice := driveapi.User{DisplayName: "Alice", EmailAddress: "alice@example.com"}
+	review := fake.AddComment(id, alice, "Is May still realistic?", "launch is in May")
+
+	result, err := callTool(t, srv, "drive_comment_add", map[string]interface{}{
+		"fileId":     id,
+		"content":    "Add the budget",
+		"quotedText": "The launch",
+	})
+	if err != nil {
+		t.Fatalf("comment add failed: %v", err)
+	}
+	if added := extractResultJSON(t, result); added["mine"] != true || added["quotedText"] != "The launch" {
+		t.Errorf("added comment = %v", added)
+	}
+
+	result, err = callTool(t, srv, "drive_comment_reply", map[string]interface{}{
+		"fileId":    id,
+		"commentId": review,
+		"content":   "Yes, QA signed off",
+		"action":    "resolve",
+	})
+	if err != nil {
+		t.Fatalf("comment reply failed: %v", err)
+	}
+	comment := extractResultJSON(t, result)
+	if replies, _ := comment["replies"].([]interface{}); comment["resolved"] != true || len(replies) != 1 {
+		t.Errorf("comment after reply = %v", comment)
+	}
+
+	result, err = callTool(t, srv, "drive_comments_list", map[string]interface{}{"fileId": id, "unresolved": true})
+	if err != nil {
+		t.Fatalf("comments list failed: %v", err)
+	}
+	if items := extractResultArray(t, result); len(items) != 1 || items[0].(map[string]interface{})["content"] != "Add the budget" {
+		t.Errorf("unresolved comments = %v", items)
+	}
+	result, err = callTool(t, srv, "drive_comments_list", map[string]interface{}{"fileId": id})
+	if err != nil {
+		t.Fatalf("comments list failed: %v", err)
+	}
+	if items := extractResultArray(t, result); len(items) != 2 || items[0].(map[string]interface{})["author"] != "Alice" {
+		t.Errorf("comments = %v", items)
+	}
+
+	if _, err := callTool(t, srv, "drive_comment_reply", map[string]interface{}{"fileId": id, "commentId": review}); err == nil {
+		t.Error("reply without content or action should fail")
+	}
+}
+
+func TestPermissionTools(t *testing.T) {
+	srv, fake := setupFakeDriveTest(t)
+	id := fake.AddFile(drivetest.RootID, "budget.xlsx", []byte("1,2"))
+
+	result, err := callTool(t, srv, "drive_permissions_update", map[string]interface{}{
+		"fileId":         id,
+		"action":         "add",
+		"type":           "group",
+		"email":          "team@example.com",
+		"role":           "reader",
+		"expirationTime": "2099-12-31",
+	})
+	if err != nil {
+		t.Fatalf("add group failed: %v", err)
+	}
+	var groupID string
+	for _, item := range extractResultArray(t, result) {
+		if p := item.(map[string]interface{}); p["type"] == "group" {
+			groupID, _ = p["id"].(string)
+			if p["expirationTime"] == "" {
+				t.Errorf("group permission has no expiration: %v", p)
+			}
+		}
+	}
+	if groupID == "" {
+		t.Fatal("group permission not listed")
+	}
+
+	if _, err := callTool(t, srv, "drive_permissions_update", map[string]interface{}{
+		"fileId":           id,
+		"action":           "update",
+		"permissionId":     groupID,
+		"role":             "writer",
+		"removeExpiration": true,
+	}); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if _, err := callTool(t, srv, "drive_permissions_update", map[string]interface{}{
+		"fileId": id,
+		"action": "transfer-ownership",
+		"email":  "carol@example.com",
+	}); err != nil {
+		t.Fatalf("transfer ownership failed: %v", err)
+	}
+
+	for _, p := range fake.Permissions(id) {
+		switch p.EmailAddress {
+		case "team@example.com":
+			if p.Role != "writer" || p.ExpirationTime != "" {
+				t.Errorf("updated group permission = %+v", p)
+			}
+		case "carol@example.com":
+			if !p.PendingOwner {
+				t.Errorf("new owner permission = %+v", p)
+			}
+		}
+	}
+
+	if _, err := callTool(t, srv, "drive_permissions_update", map[string]interface{}{
+		"fileId": id,
+		"action": "update",
+	}); err == nil {
+		t.Error("update without permissionId should fail")
+	}
+}
+
+func TestPermissionToolsEnforceSharingPolicy(t *testing.T) {
+	_, fake := setupFakeDriveTest(t)
+	confidential := fake.AddFolder(drivetest.RootID, "Confidential")
+	secret := fake.AddFile(confidential, "salaries.xlsx", []byte("1,2"))
+	flyer := fake.AddFile(drivetest.RootID, "flyer.pdf", []byte("%PDF"))
+
+	policy := filepath.Join(t.TempDir(), "policy.yaml")
+	os.WriteFile(policy, []byte("allowedDomains: [example.com]\nforbiddenAnyoneRoles: [writer]\nprotectedFolderIds: ["+confidential+"]\n"), 0600)
+	cfg := testServerConfig(t)
+	cfg.SharingPolicyFile = policy
+	srv, err := NewServer(t.Context(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, args := range map[string]map[string]interface{}{
+		"anyone writer":    {"fileId": flyer, "action": "add", "type": "anyone", "role": "writer"},
+		"personal account": {"fileId": flyer, "action": "add", "type": "user", "email": "someone@gmail.com", "role": "writer"},
+		"protected folder": {"fileId": secret, "action": "add", "type": "anyone", "role": "reader"},
+	} {
+		_, err := callTool(t, srv, "drive_permissions_update", args)
+		if err == nil || !strings.Contains(err.Error(), "denied by the sharing policy") {
+			t.Errorf("%s: err = %v, want a policy denial", name, err)
+		}
+	}
+	if n := len(fake.Permissions(flyer)) + len(fake.Permissions(secret)); n != 2 {
+		t.Errorf("denied sharing created permissions: %d left", n)
+	}
+
+	if _, err := callTool(t, srv, "drive_permissions_update", map[string]interface{}{
+		"fileId": flyer, "action": "add", "type": "user", "email": "alice@example.com", "role": "writer",
+	}); err != nil {
+		t.Errorf("sharing within the policy failed: %v", err)
+	}
+}
+
+func TestSearchToolFilters(t *testing.T) {
+	srv, fake := setupFakeDriveTest(t)
+	invoice := fake.AddFile(drivetest.RootID, "invoice.pdf", []byte("purchase order"))
+	clip := fake.AddFile(drivetest.RootID, "clip.mp4", make([]byte, 2048))
+
+	result, err := callTool(t, srv, "drive_search", map[string]interface{}{
+		"fullText":      "purchase",
+		"modifiedAfter": "2000-01-01",
+		"owner":         "me",
+	})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	if items := extractResultArray(t, result); len(items) != 1 || items[0].(map[string]interface{})["id"] != invoice {
+		t.Errorf("full-text search = %v", items)
+	}
+
+	result, err = callTool(t, srv, "drive_search", map[string]interface{}{"minSize": 1, "orderBy": "size"})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	items := extractResultArray(t, result)
+	if len(items) != 2 || items[0].(map[string]interface{})["id"] != clip {
+		t.Errorf("search sorted by size = %v", items)
+	}
+
+	for _, args := range []map[string]interface{}{
+		{"modifiedBefore": "tomorrow"},
+		{"fullText": "order", "orderBy": "name"},
+	} {
+		if _, err := callTool(t, srv, "drive_search", args); err == nil {
+			t.Errorf("search with %v should fail", args)
+		}
+	}
+}
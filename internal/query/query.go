@@ -0,0 +1,194 @@
+// Package query builds Google Drive search queries (the Files.List "q"
+// parameter) from typed clauses.
+//
+// Every value is escaped before it is quoted, so user-supplied names such as
+// "Bob's notes.txt" neither break the query nor inject extra clauses:
+//
+//	q := query.And(
+//		query.Name("Bob's notes.txt"),
+//		query.InParents(folderID),
+//		query.Trashed(false),
+//	)
+//	call.Q(q.String())
+package query
+
+import (
+	"strings"
+	"time"
+)
+
+// Clause is a rendered, safely escaped query expression.
+// The zero value is an empty clause and is ignored by And, Or and Not.
+type Clause struct {
+	expr  string
+	group bool // expr combines several clauses and needs parentheses when nested
+}
+
+// String returns the query expression, suitable for FilesListCall.Q.
+func (c Clause) String() string { return c.expr }
+
+// IsZero reports whether c is the empty clause.
+func (c Clause) IsZero() bool { return c.expr == "" }
+
+// Op is a comparison operator for time clauses.
+type Op string
+
+// Comparison operators.
+const (
+	Eq Op = "="
+	Ne Op = "!="
+	Lt Op = "<"
+	Le Op = "<="
+	Gt Op = ">"
+	Ge Op = ">="
+)
+
+// Escape escapes s for use inside a single-quoted query string.
+func Escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s)
+}
+
+// quote escapes and single-quotes s.
+func quote(s string) string {
+	return "'" + Escape(s) + "'"
+}
+
+func clause(expr string) Clause { return Clause{expr: expr} }
+
+// Name matches items whose name is exactly name.
+func Name(name string) Clause {
+	return clause("name = " + quote(name))
+}
+
+// NameContains matches items whose name contains s.
+func NameContains(s string) Clause {
+	return clause("name contains " + quote(s))
+}
+
+// FullText matches items whose name, description or content contains s.
+func FullText(s string) Clause {
+	return clause("fullText contains " + quote(s))
+}
+
+// InParents matches direct children of the folder with the given ID.
+func InParents(folderID string) Clause {
+	return clause(quote(folderID) + " in parents")
+}
+
+// MimeType matches items of the given MIME type.
+func MimeType(mimeType string) Clause {
+	return clause("mimeType = " + quote(mimeType))
+}
+
+// MimeTypes matches items of any of the given MIME types.
+func MimeTypes(mimeTypes ...string) Clause {
+	clauses := make([]Clause, 0, len(mimeTypes))
+	for _, m := range mimeTypes {
+		clauses = append(clauses, MimeType(m))
+	}
+	return Or(clauses...)
+}
+
+// ModifiedTime compares the item's modification time with t.
+func ModifiedTime(op Op, t time.Time) Clause {
+	return timeClause("modifiedTime", op, t)
+}
+
+// CreatedTime compares the item's creation time with t.
+func CreatedTime(op Op, t time.Time) Clause {
+	return timeClause("createdTime", op, t)
+}
+
+// TrashedTime compares the time the item was trashed with t.
+func TrashedTime(op Op, t time.Time) Clause {
+	return timeClause("trashedTime", op, t)
+}
+
+func timeClause(field string, op Op, t time.Time) Clause {
+	return clause(field + " " + string(op) + " " + quote(t.UTC().Format(time.RFC3339)))
+}
+
+// Property matches items carrying the public custom property key=value.
+func Property(key, value string) Clause {
+	return clause("properties has { key=" + quote(key) + " and value=" + quote(value) + " }")
+}
+
+// AppProperty matches items carrying the private app property key=value.
+func AppProperty(key, value string) Clause {
+	return clause("appProperties has { key=" + quote(key) + " and value=" + quote(value) + " }")
+}
+
+// Trashed matches items that are (or are not) in the trash.
+func Trashed(trashed bool) Clause {
+	if trashed {
+		return clause("trashed = true")
+	}
+	return clause("trashed = false")
+}
+
+// Owner matches items owned by the given email address.
+func Owner(email string) Clause {
+	return clause(quote(email) + " in owners")
+}
+
+// Starred matches items that are (or are not) starred.
+func Starred(starred bool) Clause {
+	if starred {
+		return clause("starred = true")
+	}
+	return clause("starred = false")
+}
+
+// SharedWithMe matches items in the user's "Shared with me" collection.
+func SharedWithMe() Clause {
+	return clause("sharedWithMe = true")
+}
+
+// Visibility matches items with the given visibility, such as
+// "anyoneWithLink" or "limited".
+func Visibility(visibility string) Clause {
+	return clause("visibility = " + quote(visibility))
+}
+
+// And combines clauses with "and", skipping empty ones. Combined clauses
+// are parenthesized when nested in another And, Or or Not.
+func And(clauses ...Clause) Clause {
+	return join(" and ", clauses)
+}
+
+// Or combines clauses with "or", skipping empty ones.
+func Or(clauses ...Clause) Clause {
+	return join(" or ", clauses)
+}
+
+// Not negates c. Not of the empty clause is the empty clause.
+func Not(c Clause) Clause {
+	if c.IsZero() {
+		return c
+	}
+	return clause("not " + c.nested())
+}
+
+// nested renders c for use as an operand of another clause.
+func (c Clause) nested() string {
+	if c.group {
+		return "(" + c.expr + ")"
+	}
+	return c.expr
+}
+
+func join(sep string, clauses []Clause) Clause {
+	parts := make([]string, 0, len(clauses))
+	var only Clause
+	for _, c := range clauses {
+		if c.IsZero() {
+			continue
+		}
+		only = c
+		parts = append(parts, c.nested())
+	}
+	if len(parts) == 1 {
+		return only
+	}
+	return Clause{expr: strings.Join(parts, sep), group: len(parts) > 1}
+}
@@ -0,0 +1,93 @@
+package query
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEscape(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"report", "report"},
+		{"Bob's notes.txt", `Bob\'s notes.txt`},
+		{`C:\temp`, `C:\\temp`},
+		{`it\'s`, `it\\\'s`},
+	}
+	for _, tc := range cases {
+		if got := Escape(tc.in); got != tc.want {
+			t.Errorf("Escape(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestClauses(t *testing.T) {
+	ts := time.Date(2025, 3, 1, 10, 30, 0, 0, time.FixedZone("CET", 3600))
+	cases := []struct {
+		name string
+		got  Clause
+		want string
+	}{
+		{"name", Name("Bob's notes.txt"), `name = 'Bob\'s notes.txt'`},
+		{"name contains", NameContains("report"), "name contains 'report'"},
+		{"full text", FullText("quarterly"), "fullText contains 'quarterly'"},
+		{"parents", InParents("abc123"), "'abc123' in parents"},
+		{"mime type", MimeType("application/pdf"), "mimeType = 'application/pdf'"},
+		{"modified time in UTC", ModifiedTime(Gt, ts), "modifiedTime > '2025-03-01T09:30:00Z'"},
+		{"created time", CreatedTime(Le, ts), "createdTime <= '2025-03-01T09:30:00Z'"},
+		{"property", Property("project", "o'hare"), `properties has { key='project' and value='o\'hare' }`},
+		{"app property", AppProperty("k", "v"), "appProperties has { key='k' and value='v' }"},
+		{"trashed", Trashed(true), "trashed = true"},
+		{"not trashed", Trashed(false), "trashed = false"},
+		{"owner", Owner("bob@example.com"), "'bob@example.com' in owners"},
+		{"starred", Starred(true), "starred = true"},
+		{"not starred", Starred(false), "starred = false"},
+		{"shared with me", SharedWithMe(), "sharedWithMe = true"},
+		{"visibility", Visibility("anyoneWithLink"), "visibility = 'anyoneWithLink'"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.got.String(); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCombinators(t *testing.T) {
+	cases := []struct {
+		name string
+		got  Clause
+		want string
+	}{
+		{"and", And(Name("a"), Trashed(false)), "name = 'a' and trashed = false"},
+		{"and skips empty", And(Clause{}, Name("a"), Clause{}), "name = 'a'"},
+		{"and of nothing", And(), ""},
+		{"or nested in and", And(NameContains("x"), MimeTypes("a/b", "c/d")),
+			"name contains 'x' and (mimeType = 'a/b' or mimeType = 'c/d')"},
+		{"single mime type is not parenthesized", And(NameContains("x"), MimeTypes("a/b")),
+			"name contains 'x' and mimeType = 'a/b'"},
+		{"empty mime types", And(NameContains("x"), MimeTypes()), "name contains 'x'"},
+		{"and nested in or", Or(And(Name("a"), Trashed(false)), Name("b")),
+			"(name = 'a' and trashed = false) or name = 'b'"},
+		{"not", Not(MimeType("a/b")), "not mimeType = 'a/b'"},
+		{"not of group", Not(Or(Name("a"), Name("b"))), "not (name = 'a' or name = 'b')"},
+		{"not of empty", Not(Clause{}), ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.got.String(); got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestInjectionIsQuoted(t *testing.T) {
+	// A crafted name must stay inside its string literal.
+	got := And(Name("x' or name != '"), Trashed(false)).String()
+	want := `name = 'x\' or name != \'' and trashed = false`
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
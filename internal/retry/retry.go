@@ -0,0 +1,272 @@
+// Package retry provides an HTTP transport that retries transient Google API
+// failures (rate limits, quota errors and 5xx responses) with exponential
+// backoff and jitter.
+package retry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Default retry budget.
+const (
+	DefaultMaxRetries = 5
+	DefaultBaseDelay  = 1 * time.Second
+	DefaultMaxDelay   = 32 * time.Second
+	DefaultMaxElapsed = 2 * time.Minute
+)
+
+// maxErrorBody bounds how much of a 403 body is read to find the error reason.
+const maxErrorBody = 64 << 10
+
+// retryableReasons are the Google API error reasons that mark a 403 as a
+// transient quota error rather than a permission problem. The value tells
+// whether the request was refused before Drive processed it: backend errors
+// may follow a change Drive already made.
+var retryableReasons = map[string]bool{
+	"userRateLimitExceeded": true,
+	"rateLimitExceeded":     true,
+	"backendError":          false,
+}
+
+// Policy is a retry budget.
+type Policy struct {
+	// MaxRetries is the number of retries after the first attempt.
+	// Zero disables retries.
+	MaxRetries int
+	// BaseDelay is the backoff before the first retry; it doubles on each
+	// subsequent retry.
+	BaseDelay time.Duration
+	// MaxDelay caps a single computed backoff. A longer Retry-After from
+	// the server is still honored, within MaxElapsed.
+	MaxDelay time.Duration
+	// MaxElapsed caps the total time spent waiting between attempts.
+	// Zero means no limit beyond MaxRetries.
+	MaxElapsed time.Duration
+}
+
+// DefaultPolicy returns the retry budget used when none is configured.
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxRetries: DefaultMaxRetries,
+		BaseDelay:  DefaultBaseDelay,
+		MaxDelay:   DefaultMaxDelay,
+		MaxElapsed: DefaultMaxElapsed,
+	}
+}
+
+// backoff returns the jittered delay before retry number attempt (0-based):
+// a random duration in [delay/2, delay], where delay doubles from BaseDelay
+// up to MaxDelay.
+func (p Policy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 0; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+// Transport is an http.RoundTripper that retries transient failures.
+type Transport struct {
+	Base   http.RoundTripper
+	Policy Policy
+}
+
+// NewTransport wraps base with the given retry policy. A nil base uses
+// http.DefaultTransport.
+func NewTransport(base http.RoundTripper, policy Policy) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{Base: base, Policy: policy}
+}
+
+// WrapClient returns a copy of client whose transport retries transient
+// failures according to policy.
+func WrapClient(client *http.Client, policy Policy) *http.Client {
+	wrapped := *client
+	wrapped.Transport = NewTransport(client.Transport, policy)
+	return &wrapped
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	var waited time.Duration
+
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 {
+			var err error
+			attemptReq, err = rewind(req)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := t.Base.RoundTrip(attemptReq)
+
+		retryable, reason := classify(req, resp, err)
+		if !retryable || attempt >= t.Policy.MaxRetries || !replayable(req) {
+			return resp, err
+		}
+
+		// The server's Retry-After wins over our own backoff; if it asks
+		// for more than the remaining budget, give up instead.
+		delay := t.Policy.backoff(attempt)
+		if after, ok := retryAfter(resp); ok && after > delay {
+			delay = after
+		}
+		if t.Policy.MaxElapsed > 0 && waited+delay > t.Policy.MaxElapsed {
+			return resp, err
+		}
+
+		// The response is being discarded; drain it so the connection
+		// can be reused.
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBody))
+			resp.Body.Close()
+		}
+
+		slog.Warn("retrying Google API request",
+			"method", req.Method, "host", req.URL.Host, "reason", reason,
+			"attempt", attempt+1, "max_retries", t.Policy.MaxRetries, "delay", delay)
+
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+		waited += delay
+	}
+}
+
+// classify reports whether an attempt failed transiently, and why.
+// Non-idempotent requests, such as the POST creating a file, permission or
+// comment, are only retried when Drive refused them unprocessed (429 and
+// rate limits): after a 5xx the first attempt may have been applied, and
+// sending it again would create a duplicate.
+func classify(req *http.Request, resp *http.Response, err error) (bool, string) {
+	if err != nil {
+		// A cancelled or expired request must not be retried.
+		if req.Context().Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false, ""
+		}
+		// Network errors are only safe to retry on idempotent requests.
+		return idempotent(req.Method), "transport error"
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return true, "429 too many requests"
+	case resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented:
+		return idempotent(req.Method), strconv.Itoa(resp.StatusCode)
+	case resp.StatusCode == http.StatusForbidden:
+		reason := errorReason(resp)
+		if unprocessed, ok := retryableReasons[reason]; ok {
+			return unprocessed || idempotent(req.Method), "403 " + reason
+		}
+	}
+	return false, ""
+}
+
+// errorReason extracts the first error reason from a Google API error body,
+// leaving the body readable for the caller.
+func errorReason(resp *http.Response) string {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+
+	var apiErr struct {
+		Error struct {
+			Errors []struct {
+				Reason string `json:"reason"`
+			} `json:"errors"`
+			Status string `json:"status"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &apiErr) != nil {
+		return ""
+	}
+	if len(apiErr.Error.Errors) > 0 {
+		return apiErr.Error.Errors[0].Reason
+	}
+	return apiErr.Error.Status
+}
+
+// retryAfter parses the Retry-After header, in seconds or as an HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// replayable reports whether the request body can be sent again.
+func replayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewind returns a copy of req with a fresh body for another attempt.
+func rewind(req *http.Request) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return r, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	r.Body = body
+	return r, nil
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// sleep waits for d or until ctx is done, whichever comes first.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,248 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testPolicy keeps backoffs short so tests run fast.
+func testPolicy() Policy {
+	return Policy{
+		MaxRetries: 3,
+		BaseDelay:  time.Millisecond,
+		MaxDelay:   4 * time.Millisecond,
+		MaxElapsed: time.Second,
+	}
+}
+
+// newFlakyServer fails the first `failures` requests with status and body,
+// then answers 200 "ok". It returns the server and the request counter.
+func newFlakyServer(t *testing.T, failures int32, status int, body string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			w.WriteHeader(status)
+			io.WriteString(w, body)
+			return
+		}
+		io.WriteString(w, "ok")
+	}))
+	t.Cleanup(ts.Close)
+	return ts, &calls
+}
+
+func TestTransportRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		wantCalls int32
+		wantCode  int
+	}{
+		{"503 is retried", http.StatusServiceUnavailable, "", 3, http.StatusOK},
+		{"429 is retried", http.StatusTooManyRequests, "", 3, http.StatusOK},
+		{"403 userRateLimitExceeded is retried", http.StatusForbidden,
+			`{"error":{"code":403,"errors":[{"reason":"userRateLimitExceeded"}]}}`, 3, http.StatusOK},
+		{"403 rateLimitExceeded is retried", http.StatusForbidden,
+			`{"error":{"code":403,"errors":[{"reason":"rateLimitExceeded"}]}}`, 3, http.StatusOK},
+		{"403 insufficientPermissions is not retried", http.StatusForbidden,
+			`{"error":{"code":403,"errors":[{"reason":"insufficientPermissions"}]}}`, 1, http.StatusForbidden},
+		{"404 is not retried", http.StatusNotFound, "", 1, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts, calls := newFlakyServer(t, 2, tt.status, tt.body)
+			client := &http.Client{Transport: NewTransport(nil, testPolicy())}
+
+			resp, err := client.Get(ts.URL)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantCode {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantCode)
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestTransportPreservesErrorBody(t *testing.T) {
+	body := `{"error":{"code":403,"errors":[{"reason":"forbidden"}]}}`
+	ts, _ := newFlakyServer(t, 1, http.StatusForbidden, body)
+	client := &http.Client{Transport: NewTransport(nil, testPolicy())}
+
+	resp, err := client.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	got, _ := io.ReadAll(resp.Body)
+	if string(got) != body {
+		t.Errorf("body = %q, want %q", got, body)
+	}
+}
+
+func TestTransportBudgetExhausted(t *testing.T) {
+	ts, calls := newFlakyServer(t, 100, http.StatusInternalServerError, "")
+	client := &http.Client{Transport: NewTransport(nil, testPolicy())}
+
+	resp, err := client.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", resp.StatusCode)
+	}
+	if got := calls.Load(); got != 4 {
+		t.Errorf("calls = %d, want 4 (1 attempt + 3 retries)", got)
+	}
+}
+
+func TestTransportRetryAfterExceedsBudget(t *testing.T) {
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+	client := &http.Client{Transport: NewTransport(nil, testPolicy())}
+
+	resp, err := client.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("calls = %d, want 1 (Retry-After beyond MaxElapsed)", got)
+	}
+}
+
+func TestTransportReplaysBody(t *testing.T) {
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		if string(b) != `{"name":"x"}` {
+			t.Errorf("attempt %d body = %q", calls.Load()+1, b)
+		}
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer ts.Close()
+	client := &http.Client{Transport: NewTransport(nil, testPolicy())}
+
+	resp, err := client.Post(ts.URL, "application/json", strings.NewReader(`{"name":"x"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("calls = %d, want 2", got)
+	}
+}
+
+func TestTransportDoesNotReplayProcessedPost(t *testing.T) {
+	for name, tt := range map[string]struct {
+		status int
+		body   string
+	}{
+		"503":          {http.StatusServiceUnavailable, ""},
+		"backendError": {http.StatusForbidden, `{"error":{"code":403,"errors":[{"reason":"backendError"}]}}`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ts, calls := newFlakyServer(t, 1, tt.status, tt.body)
+			client := &http.Client{Transport: NewTransport(nil, testPolicy())}
+
+			resp, err := client.Post(ts.URL, "application/json", strings.NewReader(`{"name":"x"}`))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if got := calls.Load(); got != 1 {
+				t.Errorf("calls = %d, want 1 (the first attempt may have been applied)", got)
+			}
+		})
+	}
+}
+
+func TestTransportStopsOnCancel(t *testing.T) {
+	ts, _ := newFlakyServer(t, 100, http.StatusServiceUnavailable, "")
+	policy := testPolicy()
+	policy.BaseDelay = time.Hour
+	policy.MaxDelay = time.Hour
+	policy.MaxElapsed = 0
+	client := &http.Client{Transport: NewTransport(nil, policy)}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL, nil)
+
+	_, err := client.Do(req)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{"", 0, false},
+		{"7", 7 * time.Second, true},
+		{"soon", 0, false},
+		{"Mon, 02 Jan 2006 15:04:05 GMT", 0, true}, // in the past
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			resp := &http.Response{Header: http.Header{}}
+			if tt.header != "" {
+				resp.Header.Set("Retry-After", tt.header)
+			}
+			got, ok := retryAfter(resp)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("retryAfter(%q) = (%s, %v), want (%s, %v)", tt.header, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	p := Policy{BaseDelay: 100 * time.Millisecond, MaxDelay: 400 * time.Millisecond}
+	for attempt, max := range []time.Duration{100, 200, 400, 400} {
+		max *= time.Millisecond
+		for range 20 {
+			d := p.backoff(attempt)
+			if d < max/2 || d > max {
+				t.Fatalf("backoff(%d) = %s, want in [%s, %s]", attempt, d, max/2, max)
+			}
+		}
+	}
+}